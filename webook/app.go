@@ -0,0 +1,15 @@
+package main
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/robfig/cron/v3"
+	"webook/webook/internal/job"
+)
+
+// App 整个应用需要启动的东西
+type App struct {
+	server *gin.Engine
+	cron   *cron.Cron
+	// MySQL 里的定时任务
+	scheduler *job.Scheduler
+}
@@ -1,11 +1,76 @@
 package domain
 
+import "time"
+
 type Article struct {
-	Id      int64
-	Title   string
+	Id    int64
+	Title string
+	// Content 作者写的 Markdown 原文
 	Content string
+	// HTML 渲染并且过滤之后的 HTML，读者看到的是这个
+	HTML string
+	// Summary 从 HTML 里面提取出来的纯文本摘要，保存的时候生成
+	Summary string
 	Author  Author
 	Status  ArticleStatus
+	// Category 固定的分类，Tags 是作者自己打的标签，已经规范化过了
+	Category ArticleCategory
+	Tags     []string
+	Ctime    time.Time
+	Utime    time.Time
+	// Dtime 放进回收站的时间，零值表示没有删除
+	Dtime time.Time
+	// PublishAt 定时发表的时间，只有 ArticleStatusScheduled 状态才有
+	PublishAt time.Time
+}
+
+// Abstract 文章摘要，用于列表页
+// 优先使用保存时生成的纯文本摘要，早期的文章没有，就取内容的前 128 个字符
+// 注意中文要按照 rune 截取
+func (a Article) Abstract() string {
+	if a.Summary != "" {
+		return a.Summary
+	}
+	cs := []rune(a.Content)
+	if len(cs) < 128 {
+		return a.Content
+	}
+	return string(cs[:128])
+}
+
+// ArticleRevision 文章的历史版本
+type ArticleRevision struct {
+	Id        int64
+	ArticleId int64
+	Title     string
+	Content   string
+	Status    ArticleStatus
+	Ctime     time.Time
+}
+
+// Abstract 历史版本列表只展示摘要
+func (r ArticleRevision) Abstract() string {
+	return Article{Content: r.Content}.Abstract()
+}
+
+// RevisionRetention 历史版本的保留策略
+// 两个条件同时生效，为 0 表示不限制
+type RevisionRetention struct {
+	// 每篇文章最多保留多少个版本
+	KeepCount int
+	// 最多保留多少天之内的版本
+	KeepDays int
+}
+
+// RecycleBinRetention 回收站的保留策略
+type RecycleBinRetention struct {
+	// 删除之后多少天之内可以恢复，过期之后会被后台任务彻底删除
+	KeepDays int
+}
+
+// ExpiredBefore 在这个时间点之前删除的文章已经过期了
+func (r RecycleBinRetention) ExpiredBefore(now time.Time) time.Time {
+	return now.AddDate(0, 0, -r.KeepDays)
 }
 
 // Author 文章作者
@@ -18,22 +83,69 @@ type Author struct {
 type ArticleStatus uint8
 
 const (
+	// ArticleStatusUnknown 新建的文章，还没有保存过
 	ArticleStatusUnknown ArticleStatus = iota
+	// ArticleStatusUnpublished 草稿，或者已发表的文章有未发表的修改
 	ArticleStatusUnpublished
+	// ArticleStatusPublished 已发表，读者可见
 	ArticleStatusPublished
+	// ArticleStatusPrivate 仅自己可见，也就是撤回
 	ArticleStatusPrivate
+	// ArticleStatusScheduled 等待定时发表，到时间之后变成已发表
+	ArticleStatusScheduled
+	// ArticleStatusPendingReview 内容需要人工审核，审核通过之后才会发表
+	ArticleStatusPendingReview
 )
 
+// articleStatusTransitions 文章状态机，key 是当前状态，value 是允许迁移到的状态
+//
+//	未保存 --保存--> 未发表 --发表--> 已发表 --撤回--> 仅自己可见 --发表--> 已发表
+//	未发表 --定时发表--> 等待发表 --到时间--> 已发表
+//	未发表 --发表（需要审核）--> 等待审核 --审核通过--> 已发表
+//
+// 已发表和仅自己可见的文章都还能继续编辑，编辑之后就是未发表的状态，线上库里还是之前的版本
+// 等待发表的文章可以改期，也可以取消（回到未发表），或者直接发表
+// 能发表的文章都有可能进入等待审核，等待审核的文章作者可以继续编辑，也可以重新发表，
+// 但是不能直接定时发表，要么先审核完，要么改完之后重新走一遍流程
+//
+// 只有已发表才能迁移到仅自己可见。撤回看的是线上库的状态：
+// 制作库里有未发表修改的文章，线上库还是已发表，一样可以撤回
+//
+// 未保存的文章没有状态，不在这里面，不能迁移到任何状态，新建文章不走状态机
+var articleStatusTransitions = map[ArticleStatus][]ArticleStatus{
+	ArticleStatusUnpublished: {ArticleStatusUnpublished, ArticleStatusPublished, ArticleStatusScheduled,
+		ArticleStatusPendingReview},
+	ArticleStatusPublished: {ArticleStatusUnpublished, ArticleStatusPublished, ArticleStatusPrivate,
+		ArticleStatusScheduled, ArticleStatusPendingReview},
+	ArticleStatusPrivate: {ArticleStatusUnpublished, ArticleStatusPublished, ArticleStatusScheduled,
+		ArticleStatusPendingReview},
+	ArticleStatusScheduled: {ArticleStatusUnpublished, ArticleStatusPublished, ArticleStatusScheduled,
+		ArticleStatusPendingReview},
+	ArticleStatusPendingReview: {ArticleStatusUnpublished, ArticleStatusPublished},
+}
+
 func (s ArticleStatus) ToUint8() uint8 {
-	return uint8(8)
+	return uint8(s)
 }
 
+// Valid 是不是一个可以存储的状态
 func (s ArticleStatus) Valid() bool {
-	return s.ToUint8() > 0
+	_, ok := articleStatusTransitions[s]
+	return ok
 }
 
 func (s ArticleStatus) NonPublished() bool {
-	return s != ArticleStatusUnpublished
+	return s != ArticleStatusPublished
+}
+
+// CanTransitTo 当前状态能不能迁移到 to
+func (s ArticleStatus) CanTransitTo(to ArticleStatus) bool {
+	for _, st := range articleStatusTransitions[s] {
+		if st == to {
+			return true
+		}
+	}
+	return false
 }
 
 func (s ArticleStatus) String() string {
@@ -44,6 +156,10 @@ func (s ArticleStatus) String() string {
 		return "published"
 	case ArticleStatusPrivate:
 		return "private"
+	case ArticleStatusScheduled:
+		return "scheduled"
+	case ArticleStatusPendingReview:
+		return "pending_review"
 	default:
 		return "unknown"
 	}
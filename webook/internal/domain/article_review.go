@@ -0,0 +1,29 @@
+package domain
+
+import "time"
+
+// ArticleReview 管理员对一篇文章的审核结果，驳回的时候 Reason 会展示给作者
+type ArticleReview struct {
+	Id       int64
+	ArtId    int64
+	AuthorId int64
+	// Reviewer 审核的管理员
+	Reviewer int64
+	Result   ReviewResult
+	Reason   string
+	Ctime    time.Time
+}
+
+type ReviewResult uint8
+
+const (
+	ReviewResultUnknown ReviewResult = iota
+	// ReviewResultApproved 审核通过，文章已经发表
+	ReviewResultApproved
+	// ReviewResultRejected 驳回，文章回到未发表的状态
+	ReviewResultRejected
+)
+
+func (r ReviewResult) ToUint8() uint8 {
+	return uint8(r)
+}
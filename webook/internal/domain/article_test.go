@@ -0,0 +1,76 @@
+package domain
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestArticleStatus_CanTransitTo(t *testing.T) {
+	testCases := []struct {
+		name string
+		from ArticleStatus
+		to   ArticleStatus
+		want bool
+	}{
+		{
+			name: "已发表的文章可以撤回",
+			from: ArticleStatusPublished,
+			to:   ArticleStatusUnpublished,
+			want: true,
+		},
+		{
+			name: "已发表的文章可以改成仅自己可见",
+			from: ArticleStatusPublished,
+			to:   ArticleStatusPrivate,
+			want: true,
+		},
+		{
+			name: "等待发表的文章不能改成仅自己可见",
+			from: ArticleStatusScheduled,
+			to:   ArticleStatusPrivate,
+		},
+		{
+			name: "未发表的文章不能改成仅自己可见",
+			from: ArticleStatusUnpublished,
+			to:   ArticleStatusPrivate,
+		},
+		{
+			name: "等待发表的文章可以取消",
+			from: ArticleStatusScheduled,
+			to:   ArticleStatusUnpublished,
+			want: true,
+		},
+		{
+			name: "等待审核的文章不能直接定时发表",
+			from: ArticleStatusPendingReview,
+			to:   ArticleStatusScheduled,
+		},
+		{
+			name: "不认识的状态不能迁移",
+			from: ArticleStatus(100),
+			to:   ArticleStatusUnpublished,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, tc.from.CanTransitTo(tc.to))
+		})
+	}
+}
+
+func TestArticleStatus_CanTransitTo_Unknown(t *testing.T) {
+	// 未保存的文章不能迁移到任何状态
+	for _, to := range []ArticleStatus{ArticleStatusUnknown, ArticleStatusUnpublished, ArticleStatusPublished,
+		ArticleStatusPrivate, ArticleStatusScheduled, ArticleStatusPendingReview} {
+		assert.False(t, ArticleStatusUnknown.CanTransitTo(to), "to %d", to)
+	}
+}
+
+func TestArticleStatus_CanTransitTo_PendingReview(t *testing.T) {
+	// 等待审核的文章只能审核通过（或者作者重新发表）变成已发表，或者驳回、编辑之后变成未发表
+	for _, to := range []ArticleStatus{ArticleStatusUnknown, ArticleStatusUnpublished, ArticleStatusPublished,
+		ArticleStatusPrivate, ArticleStatusScheduled, ArticleStatusPendingReview} {
+		want := to == ArticleStatusPublished || to == ArticleStatusUnpublished
+		assert.Equal(t, want, ArticleStatusPendingReview.CanTransitTo(to), "to %d", to)
+	}
+}
@@ -0,0 +1,42 @@
+package domain
+
+import "time"
+
+type AsyncSmsStatus uint8
+
+const (
+	AsyncSmsStatusUnknown AsyncSmsStatus = iota
+	// AsyncSmsStatusWaiting 等待发送，到了 NextTime 才会发
+	AsyncSmsStatusWaiting
+	// AsyncSmsStatusSending 被某个 worker 抢到了，正在发送
+	AsyncSmsStatusSending
+	AsyncSmsStatusSuccess
+	// AsyncSmsStatusFailed 重试次数用完了，或者重试也不会成功，不再发送，留着人工排查
+	AsyncSmsStatusFailed
+	// AsyncSmsStatusExpired 还没发出去就过期了，例如验证码
+	AsyncSmsStatusExpired
+)
+
+func (s AsyncSmsStatus) ToUint8() uint8 {
+	return uint8(s)
+}
+
+// AsyncSms 异步发送的短信
+// 发送成功、失败或者过期之后 Args 会被清空，验证码之类的内容不会一直留在数据库里
+type AsyncSms struct {
+	Id      int64
+	TplId   string
+	Args    []string
+	Numbers []string
+	Status  AsyncSmsStatus
+	// RetryCnt 已经失败的次数
+	RetryCnt int
+	MaxRetry int
+	// LastErr 最近一次失败的原因
+	LastErr  string
+	NextTime time.Time
+	// ExpireAt 过了这个时间就不再发送，零值表示不会过期
+	ExpireAt time.Time
+	// Version 抢占的时候用，防止两个 worker 同时发送
+	Version int64
+}
@@ -0,0 +1,12 @@
+package domain
+
+import "time"
+
+// CollectionFolder 收藏夹，只有创建者自己能看到
+type CollectionFolder struct {
+	Id    int64
+	Uid   int64
+	Name  string
+	Ctime time.Time
+	Utime time.Time
+}
@@ -0,0 +1,24 @@
+package domain
+
+import "time"
+
+// Comment 文章的评论
+// 只有两层：根评论和挂在它下面的回复，回复的回复也挂在同一个根评论下面
+type Comment struct {
+	Id    int64
+	ArtId int64
+	// 评论的人，Name 是昵称
+	Commentator Author
+	Content     string
+	// 根评论的 RootId 和 ParentId 都是 0
+	RootId   int64
+	ParentId int64
+	// 根评论下面有多少条回复
+	ReplyCnt int64
+	Ctime    time.Time
+}
+
+// IsRoot 是不是根评论
+func (c Comment) IsRoot() bool {
+	return c.RootId == 0
+}
@@ -0,0 +1,54 @@
+package domain
+
+import (
+	"context"
+	"github.com/robfig/cron/v3"
+	"time"
+)
+
+// CronJob 存在 MySQL 里的定时任务，多个实例抢占，同一时间只有一个实例在运行
+type CronJob struct {
+	Id   int64
+	Name string
+	// Executor 用哪个执行器来运行
+	Executor string
+	// Expression 标准的 cron 表达式，五个字段
+	Expression string
+	// Cfg 执行器需要的配置，怎么解析由执行器决定
+	Cfg      string
+	NextTime time.Time
+	// 每一次抢占都会加一，用来确认任务还是自己的
+	Version int64
+
+	// 下面两个字段只有抢占到的任务才有
+	// Ctx 续约失败之后会被取消，这个时候别的实例可能已经抢到任务了
+	Ctx context.Context
+	// CancelFunc 运行完之后调用，停止续约并且释放任务
+	CancelFunc func()
+}
+
+// NextTimeAfter 按照 cron 表达式计算 t 之后的下一次运行时间
+func (j CronJob) NextTimeAfter(t time.Time) (time.Time, error) {
+	s, err := cron.ParseStandard(j.Expression)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return s.Next(t), nil
+}
+
+type CronJobStatus uint8
+
+const (
+	// CronJobStatusUnknown 未知状态
+	CronJobStatusUnknown CronJobStatus = iota
+	// CronJobStatusWaiting 等待运行，到时间之后可以被抢占
+	CronJobStatusWaiting
+	// CronJobStatusRunning 已经被某个实例抢占了
+	CronJobStatusRunning
+	// CronJobStatusPaused 暂停，不会被抢占
+	CronJobStatusPaused
+)
+
+func (s CronJobStatus) ToUint8() uint8 {
+	return uint8(s)
+}
@@ -0,0 +1,36 @@
+package domain
+
+import "time"
+
+// FollowRelation 关注关系，Follower 关注了 Followee
+type FollowRelation struct {
+	Id       int64
+	Follower Author
+	Followee Author
+	Ctime    time.Time
+}
+
+// FollowStatics 用户的关注数据
+type FollowStatics struct {
+	Uid int64
+	// 粉丝数
+	Followers int64
+	// 关注了多少人
+	Followees int64
+	// 当前用户有没有关注这个用户，没有登录的时候是 false
+	Followed bool
+}
+
+// FeedConfig 关注流的配置
+type FeedConfig struct {
+	// 粉丝数不超过这个值的作者，发表文章的时候直接推送到粉丝的收件箱
+	// 超过的读的时候再去拉，0 表示全部都是读的时候拉
+	PushThreshold int64
+	// 只看最近关注的这么多个作者，关注了很多人的时候不用每次都查出所有的关注关系
+	MaxFollowees int
+}
+
+// UsePush 这个作者发表的文章要不要推送给粉丝
+func (c FeedConfig) UsePush(followers int64) bool {
+	return followers <= c.PushThreshold
+}
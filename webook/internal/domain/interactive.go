@@ -0,0 +1,17 @@
+package domain
+
+// BizArticle 互动数据里面文章的业务标识
+const BizArticle = "article"
+
+// Interactive 阅读、点赞、收藏这些互动数据
+// Biz 和 BizId 确定是哪个业务的哪个资源，目前只有文章
+type Interactive struct {
+	Biz        string
+	BizId      int64
+	ReadCnt    int64
+	LikeCnt    int64
+	CollectCnt int64
+	// 当前用户有没有点赞、收藏，没有登录的时候都是 false
+	Liked     bool
+	Collected bool
+}
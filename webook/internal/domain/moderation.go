@@ -0,0 +1,28 @@
+package domain
+
+// ModerationLevel 内容审核的结果
+type ModerationLevel uint8
+
+const (
+	// ModerationPass 没有问题
+	ModerationPass ModerationLevel = iota
+	// ModerationReview 有擦边的内容，需要人工审核
+	ModerationReview
+	// ModerationBlock 有违禁的内容，不允许发表
+	ModerationBlock
+)
+
+// ModerationResult 内容审核的结果，Words 是命中的敏感词
+type ModerationResult struct {
+	Level ModerationLevel
+	Words []string
+}
+
+// ModerationConfig 内容审核的配置
+type ModerationConfig struct {
+	// Review 命中需要人工审核的词的时候，是否进入人工审核
+	// 为 false 的时候只拦截违禁词，擦边的内容直接发表
+	Review bool
+	// ReviewAll 所有的文章发表之前都要人工审核，评论不受影响
+	ReviewAll bool
+}
@@ -0,0 +1,39 @@
+package domain
+
+// Permission 权限，中间件按照权限保护接口，不直接检查角色
+type Permission string
+
+const (
+	// PermissionArticleReview 审核文章
+	PermissionArticleReview Permission = "article:review"
+	// PermissionRoleGrant 给用户授予、撤销角色
+	PermissionRoleGrant Permission = "role:grant"
+)
+
+const (
+	// RoleSuperAdmin 超级管理员，拥有所有权限
+	RoleSuperAdmin = "super_admin"
+	// RoleModerator 审核员，只能审核内容
+	RoleModerator = "moderator"
+)
+
+// Role 角色，用户通过角色获得权限
+type Role struct {
+	Id          int64
+	Name        string
+	Permissions []Permission
+}
+
+// BuiltinRoles 内置的角色，启动的时候写入数据库
+func BuiltinRoles() []Role {
+	return []Role{
+		{
+			Name:        RoleSuperAdmin,
+			Permissions: []Permission{PermissionArticleReview, PermissionRoleGrant},
+		},
+		{
+			Name:        RoleModerator,
+			Permissions: []Permission{PermissionArticleReview},
+		},
+	}
+}
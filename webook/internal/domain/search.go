@@ -0,0 +1,16 @@
+package domain
+
+// ArticleSearchResult 搜索文章的结果
+type ArticleSearchResult struct {
+	// 一共有多少篇文章命中，用于分页
+	Total int
+	Hits  []ArticleHit
+}
+
+// ArticleHit 命中的一篇文章，Article 里面没有内容
+type ArticleHit struct {
+	Article Article
+	// 高亮之后的标题和内容片段，已经转义过 HTML，命中的词用 <em> 包起来
+	TitleHighlight   string
+	ContentHighlight string
+}
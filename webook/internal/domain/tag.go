@@ -0,0 +1,117 @@
+package domain
+
+import (
+	"errors"
+	"golang.org/x/text/unicode/norm"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+const (
+	// MaxArticleTags 一篇文章最多几个标签
+	MaxArticleTags = 5
+	// MaxTagLength 一个标签最多多少个字符，按照 rune 计算
+	MaxTagLength = 20
+)
+
+var (
+	ErrTooManyTags = errors.New("标签太多")
+	ErrInvalidTag  = errors.New("标签不合法")
+)
+
+// Tag 标签，以及带有这个标签的已发表文章数量
+type Tag struct {
+	Name string
+	Cnt  int64
+}
+
+// NormalizeTag 规范化标签，避免出现 "Go"、"go "、"#ＧＯ" 这种近似重复的标签
+// 全角转半角，统一小写，去掉开头的 #，连续的空白、- 和 _ 合并成一个 -
+func NormalizeTag(name string) string {
+	name = strings.ToLower(norm.NFKC.String(name))
+	name = strings.TrimLeft(strings.TrimSpace(name), "#")
+	var sb strings.Builder
+	sep := false
+	for _, r := range name {
+		if unicode.IsSpace(r) || r == '-' || r == '_' {
+			sep = true
+			continue
+		}
+		if !unicode.IsPrint(r) {
+			continue
+		}
+		if sep && sb.Len() > 0 {
+			sb.WriteByte('-')
+		}
+		sep = false
+		sb.WriteRune(r)
+	}
+	return sb.String()
+}
+
+// NormalizeTags 规范化并且去重，保持作者给出的顺序
+func NormalizeTags(names []string) ([]string, error) {
+	if len(names) == 0 {
+		return nil, nil
+	}
+	res := make([]string, 0, len(names))
+	seen := make(map[string]struct{}, len(names))
+	for _, name := range names {
+		tag := NormalizeTag(name)
+		if tag == "" || utf8.RuneCountInString(tag) > MaxTagLength {
+			return nil, ErrInvalidTag
+		}
+		if _, ok := seen[tag]; ok {
+			continue
+		}
+		seen[tag] = struct{}{}
+		res = append(res, tag)
+	}
+	if len(res) > MaxArticleTags {
+		return nil, ErrTooManyTags
+	}
+	return res, nil
+}
+
+// ArticleCategory 文章分类，分类是固定的，由产品决定，不允许作者自己创建
+type ArticleCategory uint8
+
+const (
+	// ArticleCategoryUnknown 未分类，早期的文章都是这个
+	ArticleCategoryUnknown ArticleCategory = iota
+	ArticleCategoryBackend
+	ArticleCategoryFrontend
+	ArticleCategoryMobile
+	ArticleCategoryAI
+	ArticleCategoryDevOps
+	ArticleCategoryOther
+)
+
+func (c ArticleCategory) ToUint8() uint8 {
+	return uint8(c)
+}
+
+// Valid 是不是一个已经定义的分类，未分类也是合法的
+func (c ArticleCategory) Valid() bool {
+	return c <= ArticleCategoryOther
+}
+
+func (c ArticleCategory) String() string {
+	switch c {
+	case ArticleCategoryBackend:
+		return "backend"
+	case ArticleCategoryFrontend:
+		return "frontend"
+	case ArticleCategoryMobile:
+		return "mobile"
+	case ArticleCategoryAI:
+		return "ai"
+	case ArticleCategoryDevOps:
+		return "devops"
+	case ArticleCategoryOther:
+		return "other"
+	default:
+		return "unknown"
+	}
+}
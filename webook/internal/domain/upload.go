@@ -0,0 +1,30 @@
+package domain
+
+import "time"
+
+// MaxUploadSize 上传文件的最大字节数
+const MaxUploadSize = 5 << 20
+
+// UploadContentTypes 允许上传的类型和保存时的扩展名，类型按照内容判断，不相信客户端说的
+// SVG 里面可以有脚本，不允许上传
+var UploadContentTypes = map[string]string{
+	"image/png":       ".png",
+	"image/jpeg":      ".jpg",
+	"image/gif":       ".gif",
+	"image/webp":      ".webp",
+	"application/pdf": ".pdf",
+}
+
+// UploadObject 上传的文件，内容相同的文件只保存一份
+// 文章内容通过 Key 引用，不保存下载链接，链接会过期
+type UploadObject struct {
+	Id int64
+	// Hash 内容的 SHA-256
+	Hash        string
+	Key         string
+	Size        int64
+	ContentType string
+	// Uid 第一个上传的人
+	Uid   int64
+	Ctime time.Time
+}
@@ -0,0 +1,39 @@
+package errs
+
+// 业务错误码
+// 早期的接口只用了 4（用户输入错误）和 5（系统错误），前端只能靠 Msg 区分
+// 新的错误码一共六位：前三位对应 HTTP 状态码的含义，后三位是具体的业务错误
+const (
+	// ArticleNotFound 文章不存在，或者当前用户无权查看
+	ArticleNotFound = 404001
+	// ArticleRevisionNotFound 文章的历史版本不存在
+	ArticleRevisionNotFound = 404002
+	// CollectionFolderNotFound 收藏夹不存在，或者不是当前用户的
+	CollectionFolderNotFound = 404003
+	// CommentNotFound 评论不存在，或者当前用户无权操作
+	CommentNotFound = 404004
+	// UserNotFound 用户不存在
+	UserNotFound = 404005
+	// ArticleReviewNotFound 文章没有审核记录，或者不是当前用户的文章
+	ArticleReviewNotFound = 404006
+	// RoleNotFound 角色不存在
+	RoleNotFound = 404007
+	// ArticleInvalidStatus 文章当前的状态不允许执行这个操作
+	ArticleInvalidStatus = 409001
+	// ArticleAlreadyLiked 重复点赞
+	ArticleAlreadyLiked = 409002
+	// ArticleAlreadyCollected 重复收藏
+	ArticleAlreadyCollected = 409003
+	// CollectionFolderNameDuplicate 收藏夹重名
+	CollectionFolderNameDuplicate = 409004
+	// CollectionFolderItemDuplicate 文章已经在这个收藏夹里了
+	CollectionFolderItemDuplicate = 409005
+	// UserAlreadyFollowed 重复关注
+	UserAlreadyFollowed = 409006
+	// ContentSensitive 文章或者评论包含违禁的内容，Data 里面是命中的敏感词
+	ContentSensitive = 451001
+	// UploadTooLarge 上传的文件太大
+	UploadTooLarge = 413001
+	// UploadUnsupportedType 不支持上传这种类型的文件
+	UploadUnsupportedType = 415001
+)
@@ -0,0 +1,38 @@
+package startup
+
+import (
+	"webook/webook/internal/domain"
+	"webook/webook/internal/repository"
+	"webook/webook/internal/service"
+	"webook/webook/pkg/logger"
+	"webook/webook/pkg/redislock"
+	"webook/webook/pkg/sensitive"
+)
+
+func InitRevisionRetention() domain.RevisionRetention {
+	return domain.RevisionRetention{
+		KeepCount: 50,
+	}
+}
+
+func InitRecycleBinRetention() domain.RecycleBinRetention {
+	return domain.RecycleBinRetention{
+		KeepDays: 30,
+	}
+}
+
+// InitArticleListeners 测试里面发表文章不通知其它业务
+func InitArticleListeners() []service.ArticleListener {
+	return nil
+}
+
+// InitArticleScheduler 测试里面不加载数据库里的定时任务
+func InitArticleScheduler(repo repository.ArticleRepository, listeners []service.ArticleListener,
+	lockClient *redislock.Client, l logger.Logger) service.ArticleScheduler {
+	return service.NewLocalArticleScheduler(repo, listeners, lockClient, l)
+}
+
+// InitModerationService 测试里面用一个空的词库，想测审核的时候再换
+func InitModerationService() service.ModerationService {
+	return service.NewDictModerationService(sensitive.NewDictionary(nil, nil), domain.ModerationConfig{})
+}
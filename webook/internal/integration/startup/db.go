@@ -56,5 +56,12 @@ func InitTestDB() *gorm.DB {
 
 func initTable(db *gorm.DB) error {
 	// gorm自动建表
-	return db.AutoMigrate(&dao.User{}, &dao.Article{})
+	return db.AutoMigrate(&dao.User{}, &dao.Article{}, &dao.ArticleRevision{},
+		&dao.Interactive{}, &dao.UserLikeBiz{}, &dao.UserCollectionBiz{},
+		&dao.CollectionFolder{}, &dao.CollectionFolderItem{},
+		&dao.Comment{}, &dao.CommentCount{}, &dao.CronJob{},
+		&dao.FollowRelation{}, &dao.FollowStatics{}, &dao.FeedInbox{},
+		&dao.Tag{}, &dao.ArticleTag{}, &dao.PublishedArticleTag{}, &dao.ArticleReview{},
+		&dao.Role{}, &dao.RolePermission{}, &dao.UserRole{}, &dao.UploadObject{},
+		&dao.AsyncSms{})
 }
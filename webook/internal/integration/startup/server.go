@@ -9,6 +9,7 @@ import (
 	"strings"
 	"time"
 	"webook/webook/internal/web"
+	web2 "webook/webook/internal/web/jwt"
 	"webook/webook/internal/web/middleware"
 	"webook/webook/pkg/ginx/middlewares/ratelimit"
 	ratelimit2 "webook/webook/pkg/ginx/ratelimit"
@@ -24,10 +25,10 @@ func InitGinServer(middlewares []gin.HandlerFunc, userHandler *web.UserHandler,
 	return server
 }
 
-func InitGinMiddlewares(redisClient redis.Cmdable) []gin.HandlerFunc {
+func InitGinMiddlewares(redisClient redis.Cmdable, jwtHdl web2.JWTHandler) []gin.HandlerFunc {
 	return []gin.HandlerFunc{
 		cordHdl(),
-		middleware.NewLoginJWTMiddleWareBuilder().
+		middleware.NewLoginJWTMiddleWareBuilder(jwtHdl).
 			IgnorePaths("/users/signup").
 			IgnorePaths("/users/login").
 			IgnorePaths("/users/login_sms/code/send").
@@ -14,7 +14,7 @@ func InitSMSService() sms.Service {
 	return initMemorySMSService()
 }
 
-func initTencentSMSService() sms.Service {
+func initTencentSMSService(tpls *sms.TemplateRegistry) sms.Service {
 	secretId, ok := os.LookupEnv("SMS_SECRET_ID")
 	if !ok {
 		panic("找不到环境变量 SMS_SECRET_ID")
@@ -26,7 +26,7 @@ func initTencentSMSService() sms.Service {
 	if err != nil {
 		panic("找不到环境变量 SMS_SECRET_KEY")
 	}
-	return tencent.NewSMSService(c, "1400853424", "猜猜我是谁")
+	return tencent.NewSMSService(c, "1400853424", "猜猜我是谁", tpls)
 }
 
 func initMemorySMSService() sms.Service {
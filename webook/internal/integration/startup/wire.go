@@ -11,6 +11,7 @@ import (
 	"webook/webook/internal/service"
 	"webook/webook/internal/web"
 	web2 "webook/webook/internal/web/jwt"
+	"webook/webook/pkg/redislock"
 )
 
 func InitApp() *gin.Engine {
@@ -37,6 +38,10 @@ var thirdProvider = wire.NewSet(InitDB, InitRedis, InitZapLogger)
 func InitArticleHandler() *web.ArticleHandler {
 	wire.Build(web.NewArticleHandler, service.NewArticleService, repository.NewCacheArticleRepository,
 		dao.NewGORMArticleDAO,
-		thirdProvider)
+		// 读者查看文章的时候要查作者昵称
+		repository.NewUserRepository, dao.NewUserDAO, cache.NewRedisUserCache,
+		repository.NewCacheArticleRevisionRepository, dao.NewGORMArticleRevisionDAO,
+		InitRevisionRetention, InitRecycleBinRetention, InitArticleScheduler, InitArticleListeners,
+		InitModerationService, redislock.NewClient, thirdProvider)
 	return new(web.ArticleHandler)
 }
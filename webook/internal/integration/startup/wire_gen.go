@@ -15,13 +15,15 @@ import (
 	"webook/webook/internal/service"
 	web2 "webook/webook/internal/web"
 	"webook/webook/internal/web/jwt"
+	"webook/webook/pkg/redislock"
 )
 
 // Injectors from wire.go:
 
 func InitApp() *gin.Engine {
 	cmdable := InitRedis()
-	v := InitGinMiddlewares(cmdable)
+	jwtHandler := web.NewRedisJWTHandler(cmdable)
+	v := InitGinMiddlewares(cmdable, jwtHandler)
 	db := InitDB()
 	userDAO := dao.NewUserDAO(db)
 	userCache := cache.NewRedisUserCache(cmdable)
@@ -31,7 +33,6 @@ func InitApp() *gin.Engine {
 	codeRepository := repository.NewCacheCodeRepository(codeCache)
 	smsService := InitSMSService()
 	codeService := service.NewSmsCodeService(codeRepository, smsService)
-	jwtHandler := web.NewRedisJWTHandler(cmdable)
 	logger := InitZapLogger()
 	userHandler := web2.NewUserHandler(userService, codeService, jwtHandler, logger)
 	wechatService := InitOAuth2WechatService()
@@ -44,9 +45,21 @@ func InitApp() *gin.Engine {
 func InitArticleHandler() *web2.ArticleHandler {
 	db := InitDB()
 	articleDAO := dao.NewGORMArticleDAO(db)
-	articleRepository := repository.NewCacheArticleRepository(articleDAO)
-	articleService := service.NewArticleService(articleRepository)
+	userDAO := dao.NewUserDAO(db)
+	cmdable := InitRedis()
+	userCache := cache.NewRedisUserCache(cmdable)
+	userRepository := repository.NewUserRepository(userDAO, userCache)
 	logger := InitZapLogger()
+	articleRepository := repository.NewCacheArticleRepository(articleDAO, userRepository, logger)
+	articleRevisionDAO := dao.NewGORMArticleRevisionDAO(db)
+	articleRevisionRepository := repository.NewCacheArticleRevisionRepository(articleRevisionDAO)
+	revisionRetention := InitRevisionRetention()
+	recycleBinRetention := InitRecycleBinRetention()
+	v := InitArticleListeners()
+	client := redislock.NewClient(cmdable)
+	articleScheduler := InitArticleScheduler(articleRepository, v, client, logger)
+	moderationService := InitModerationService()
+	articleService := service.NewArticleService(articleRepository, articleRevisionRepository, revisionRetention, recycleBinRetention, articleScheduler, moderationService, v, logger)
 	articleHandler := web2.NewArticleHandler(articleService, logger)
 	return articleHandler
 }
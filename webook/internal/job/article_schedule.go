@@ -0,0 +1,31 @@
+package job
+
+import (
+	"context"
+	"time"
+	"webook/webook/internal/service"
+)
+
+var _ Job = (*ArticleScheduleLoadJob)(nil)
+
+// ArticleScheduleLoadJob 定期重新加载等待发表的文章
+// 定时器只在创建定时任务的实例里面，这个实例挂了之后要靠别的实例重新加载才能发表，
+// 所以每个实例都要在本地运行，不能交给 MySQL 里的定时任务调度
+type ArticleScheduleLoadJob struct {
+	scheduler service.ArticleScheduler
+	timeout   time.Duration
+}
+
+func NewArticleScheduleLoadJob(scheduler service.ArticleScheduler, timeout time.Duration) *ArticleScheduleLoadJob {
+	return &ArticleScheduleLoadJob{scheduler: scheduler, timeout: timeout}
+}
+
+func (a *ArticleScheduleLoadJob) Name() string {
+	return "article_schedule_load"
+}
+
+func (a *ArticleScheduleLoadJob) Run(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, a.timeout)
+	defer cancel()
+	return a.scheduler.Load(ctx)
+}
@@ -0,0 +1,44 @@
+package job
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+	"testing"
+	"time"
+	svcmocks "webook/webook/internal/service/mocks"
+)
+
+func TestArticleScheduleLoadJob_Run(t *testing.T) {
+	testCases := []struct {
+		name    string
+		loadErr error
+		wantErr error
+	}{
+		{
+			name: "加载成功",
+		},
+		{
+			name:    "加载失败",
+			loadErr: errors.New("mock db error"),
+			wantErr: errors.New("mock db error"),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			scheduler := svcmocks.NewMockArticleScheduler(ctrl)
+			scheduler.EXPECT().Load(gomock.Any()).DoAndReturn(func(ctx context.Context) error {
+				// 超时的 context 传下去了
+				_, ok := ctx.Deadline()
+				assert.True(t, ok)
+				return tc.loadErr
+			})
+			j := NewArticleScheduleLoadJob(scheduler, time.Minute)
+			err := j.Run(context.Background())
+			assert.Equal(t, tc.wantErr, err)
+		})
+	}
+}
@@ -0,0 +1,32 @@
+package job
+
+import (
+	"context"
+	"github.com/robfig/cron/v3"
+	"time"
+	"webook/webook/pkg/logger"
+)
+
+// CronJobBuilder 把 Job 适配成 cron.Job，统一记录执行时间和错误
+type CronJobBuilder struct {
+	l logger.Logger
+}
+
+func NewCronJobBuilder(l logger.Logger) *CronJobBuilder {
+	return &CronJobBuilder{l: l}
+}
+
+func (b *CronJobBuilder) Build(job Job) cron.Job {
+	name := job.Name()
+	return cron.FuncJob(func() {
+		start := time.Now()
+		b.l.Debug("开始运行任务", logger.String("name", name))
+		// cron 没有 ctx，超时由任务自己控制
+		err := job.Run(context.Background())
+		if err != nil {
+			b.l.Error("运行任务失败", logger.String("name", name), logger.Error(err))
+		}
+		b.l.Debug("结束运行任务", logger.String("name", name),
+			logger.Duration("duration", time.Since(start)))
+	})
+}
@@ -0,0 +1,42 @@
+package job
+
+import (
+	"context"
+	"fmt"
+	"webook/webook/internal/domain"
+)
+
+// Executor 执行 MySQL 里面的定时任务，任务的 Executor 字段就是执行器的名字
+type Executor interface {
+	Name() string
+	// Exec ctx 在任务被别的实例抢走之后会被取消
+	Exec(ctx context.Context, j domain.CronJob) error
+}
+
+var _ Executor = (*LocalFuncExecutor)(nil)
+
+// LocalFuncExecutor 在本地执行注册好的方法，按照任务的名字找到方法
+type LocalFuncExecutor struct {
+	funcs map[string]func(ctx context.Context, j domain.CronJob) error
+}
+
+func NewLocalFuncExecutor() *LocalFuncExecutor {
+	return &LocalFuncExecutor{funcs: make(map[string]func(ctx context.Context, j domain.CronJob) error)}
+}
+
+func (l *LocalFuncExecutor) Name() string {
+	return "local"
+}
+
+// RegisterFunc 注册任务，启动之前调用，不是并发安全的
+func (l *LocalFuncExecutor) RegisterFunc(name string, fn func(ctx context.Context, j domain.CronJob) error) {
+	l.funcs[name] = fn
+}
+
+func (l *LocalFuncExecutor) Exec(ctx context.Context, j domain.CronJob) error {
+	fn, ok := l.funcs[j.Name]
+	if !ok {
+		return fmt.Errorf("没有注册任务 %s", j.Name)
+	}
+	return fn(ctx, j)
+}
@@ -0,0 +1,74 @@
+package job
+
+import (
+	"context"
+	"errors"
+	"time"
+	"webook/webook/internal/service"
+	"webook/webook/pkg/logger"
+	"webook/webook/pkg/redislock"
+)
+
+var _ Job = (*RankingJob)(nil)
+
+// RankingJob 计算热榜
+// 计算一次要扫描最近的全部文章，多个实例只需要一个去算，用分布式锁抢占。
+// 算完之后不释放锁，在这一轮剩下的时间里继续挡住别的实例，
+// 否则时钟慢一点的实例晚触发的时候又会重新算一遍
+type RankingJob struct {
+	svc        service.RankingService
+	lockClient *redislock.Client
+	l          logger.Logger
+	// 整个任务的超时时间
+	timeout time.Duration
+	// 两次计算之间的间隔，和调度的周期一致
+	interval time.Duration
+	key      string
+}
+
+func NewRankingJob(svc service.RankingService, lockClient *redislock.Client, l logger.Logger,
+	timeout time.Duration, interval time.Duration) *RankingJob {
+	return &RankingJob{svc: svc, lockClient: lockClient, l: l, timeout: timeout,
+		interval: interval, key: "job:ranking:lock"}
+}
+
+func (r *RankingJob) Name() string {
+	return "ranking"
+}
+
+func (r *RankingJob) Run(ctx context.Context) (err error) {
+	start := time.Now()
+	lock, err := r.lockClient.TryLock(ctx, r.key, r.timeout)
+	if errors.Is(err, redislock.ErrFailedToPreemptLock) {
+		r.l.Debug("别的实例正在计算热榜，或者这一轮已经算过了")
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		// 算失败了马上释放，别的实例还能再试。
+		// 算成功了留十分之一的余量给下一轮，不然下一轮准时触发的时候锁还没过期
+		hold := time.Duration(0)
+		if err == nil {
+			hold = r.interval - r.interval/10 - time.Since(start)
+		}
+		er := lock.Keep(ctx, hold)
+		if er != nil {
+			r.l.Error("释放热榜的锁失败", logger.Error(er))
+		}
+	}()
+	// 调用者取消或者锁丢了都要停下来，别的实例会接着算
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+	go func() {
+		select {
+		case <-lock.Ctx().Done():
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+	return r.svc.RankTopN(ctx)
+}
@@ -0,0 +1,87 @@
+package job
+
+import (
+	"context"
+	"errors"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+	"testing"
+	"time"
+	"webook/webook/internal/service"
+	svcmocks "webook/webook/internal/service/mocks"
+	redismock "webook/webook/mock/redis"
+	"webook/webook/pkg/logger"
+	"webook/webook/pkg/redislock"
+)
+
+func TestRankingJob_Run(t *testing.T) {
+	testCases := []struct {
+		name    string
+		mock    func(ctrl *gomock.Controller) (service.RankingService, redis.Cmdable)
+		wantErr error
+	}{
+		{
+			name: "抢到了锁，计算热榜",
+			mock: func(ctrl *gomock.Controller) (service.RankingService, redis.Cmdable) {
+				client := redismock.NewMockCmdable(ctrl)
+				client.EXPECT().SetNX(gomock.Any(), "job:ranking:lock", gomock.Any(), time.Minute).
+					Return(redis.NewBoolResult(true, nil))
+				svc := svcmocks.NewMockRankingService(ctrl)
+				svc.EXPECT().RankTopN(gomock.Any()).Return(nil)
+				// 算完了不释放锁，这一轮剩下的时间里继续拿着
+				client.EXPECT().Eval(gomock.Any(), gomock.Any(), []string{"job:ranking:lock"}, gomock.Any(), gomock.Any()).
+					DoAndReturn(func(ctx context.Context, script string, keys []string, args ...any) *redis.Cmd {
+						ms := args[1].(int64)
+						assert.True(t, ms > 0 && ms <= (time.Minute*3-time.Second*18).Milliseconds())
+						return redis.NewCmdResult(int64(1), nil)
+					})
+				return svc, client
+			},
+		},
+		{
+			name: "计算失败，马上释放锁",
+			mock: func(ctrl *gomock.Controller) (service.RankingService, redis.Cmdable) {
+				client := redismock.NewMockCmdable(ctrl)
+				client.EXPECT().SetNX(gomock.Any(), "job:ranking:lock", gomock.Any(), time.Minute).
+					Return(redis.NewBoolResult(true, nil))
+				svc := svcmocks.NewMockRankingService(ctrl)
+				svc.EXPECT().RankTopN(gomock.Any()).Return(errors.New("mock db error"))
+				// 别的实例还能接着算
+				client.EXPECT().Eval(gomock.Any(), gomock.Any(), []string{"job:ranking:lock"}, gomock.Any()).
+					Return(redis.NewCmdResult(int64(1), nil))
+				return svc, client
+			},
+			wantErr: errors.New("mock db error"),
+		},
+		{
+			name: "别的实例在算，直接返回",
+			mock: func(ctrl *gomock.Controller) (service.RankingService, redis.Cmdable) {
+				client := redismock.NewMockCmdable(ctrl)
+				client.EXPECT().SetNX(gomock.Any(), "job:ranking:lock", gomock.Any(), time.Minute).
+					Return(redis.NewBoolResult(false, nil))
+				return svcmocks.NewMockRankingService(ctrl), client
+			},
+		},
+		{
+			name: "Redis 出错",
+			mock: func(ctrl *gomock.Controller) (service.RankingService, redis.Cmdable) {
+				client := redismock.NewMockCmdable(ctrl)
+				client.EXPECT().SetNX(gomock.Any(), "job:ranking:lock", gomock.Any(), time.Minute).
+					Return(redis.NewBoolResult(false, errors.New("mock redis error")))
+				return svcmocks.NewMockRankingService(ctrl), client
+			},
+			wantErr: errors.New("mock redis error"),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			svc, client := tc.mock(ctrl)
+			j := NewRankingJob(svc, redislock.NewClient(client), logger.NewNoOpLogger(), time.Minute, time.Minute*3)
+			err := j.Run(context.Background())
+			assert.Equal(t, tc.wantErr, err)
+		})
+	}
+}
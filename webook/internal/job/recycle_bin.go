@@ -0,0 +1,51 @@
+package job
+
+import (
+	"context"
+	"time"
+	"webook/webook/internal/service"
+	"webook/webook/pkg/logger"
+)
+
+var _ Job = (*RecycleBinPurgeJob)(nil)
+
+// RecycleBinPurgeJob 彻底删除回收站里过期的文章
+// 多个实例同时运行也没有关系，删除是幂等的
+type RecycleBinPurgeJob struct {
+	svc service.ArticleService
+	l   logger.Logger
+	// 整个任务的超时时间
+	timeout time.Duration
+	// 每一批删除多少篇
+	batchSize int
+}
+
+func NewRecycleBinPurgeJob(svc service.ArticleService, l logger.Logger,
+	timeout time.Duration, batchSize int) *RecycleBinPurgeJob {
+	return &RecycleBinPurgeJob{svc: svc, l: l, timeout: timeout, batchSize: batchSize}
+}
+
+func (r *RecycleBinPurgeJob) Name() string {
+	return "recycle_bin_purge"
+}
+
+func (r *RecycleBinPurgeJob) Run(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+	total := 0
+	for {
+		n, err := r.svc.PurgeRecycleBin(ctx, r.batchSize)
+		total += n
+		if err != nil {
+			return err
+		}
+		// 不满一批，说明已经删完了
+		if n < r.batchSize {
+			break
+		}
+	}
+	if total > 0 {
+		r.l.Info("清理回收站", logger.Int("total", total))
+	}
+	return nil
+}
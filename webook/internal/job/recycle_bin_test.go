@@ -0,0 +1,86 @@
+package job
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+	"testing"
+	"time"
+	"webook/webook/internal/service"
+	svcmocks "webook/webook/internal/service/mocks"
+	"webook/webook/pkg/logger"
+)
+
+func TestRecycleBinPurgeJob_Run(t *testing.T) {
+	testCases := []struct {
+		name    string
+		mock    func(ctrl *gomock.Controller) service.ArticleService
+		wantErr error
+	}{
+		{
+			name: "分批删除，直到不满一批",
+			mock: func(ctrl *gomock.Controller) service.ArticleService {
+				svc := svcmocks.NewMockArticleService(ctrl)
+				svc.EXPECT().PurgeRecycleBin(gomock.Any(), 10).Times(2).Return(10, nil)
+				svc.EXPECT().PurgeRecycleBin(gomock.Any(), 10).Return(3, nil)
+				return svc
+			},
+		},
+		{
+			name: "没有过期的文章",
+			mock: func(ctrl *gomock.Controller) service.ArticleService {
+				svc := svcmocks.NewMockArticleService(ctrl)
+				svc.EXPECT().PurgeRecycleBin(gomock.Any(), 10).Return(0, nil)
+				return svc
+			},
+		},
+		{
+			name: "删除失败",
+			mock: func(ctrl *gomock.Controller) service.ArticleService {
+				svc := svcmocks.NewMockArticleService(ctrl)
+				svc.EXPECT().PurgeRecycleBin(gomock.Any(), 10).Return(10, nil)
+				svc.EXPECT().PurgeRecycleBin(gomock.Any(), 10).Return(0, errors.New("mock db error"))
+				return svc
+			},
+			wantErr: errors.New("mock db error"),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			j := NewRecycleBinPurgeJob(tc.mock(ctrl), logger.NewNoOpLogger(), time.Minute, 10)
+			err := j.Run(context.Background())
+			assert.Equal(t, tc.wantErr, err)
+		})
+	}
+}
+
+// 确认超时的 context 传下去了
+func TestRecycleBinPurgeJob_Timeout(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	svc := svcmocks.NewMockArticleService(ctrl)
+	svc.EXPECT().PurgeRecycleBin(gomock.Any(), 10).DoAndReturn(func(ctx context.Context, limit int) (int, error) {
+		_, ok := ctx.Deadline()
+		assert.True(t, ok)
+		return 0, nil
+	})
+	j := NewRecycleBinPurgeJob(svc, logger.NewNoOpLogger(), time.Minute, 10)
+	assert.NoError(t, j.Run(context.Background()))
+}
+
+// 调度器取消了 ctx，任务也要停下来
+func TestRecycleBinPurgeJob_Cancel(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	svc := svcmocks.NewMockArticleService(ctrl)
+	svc.EXPECT().PurgeRecycleBin(gomock.Any(), 10).DoAndReturn(func(ctx context.Context, limit int) (int, error) {
+		return 0, ctx.Err()
+	})
+	j := NewRecycleBinPurgeJob(svc, logger.NewNoOpLogger(), time.Minute, 10)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	assert.Equal(t, context.Canceled, j.Run(ctx))
+}
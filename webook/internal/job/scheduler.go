@@ -0,0 +1,91 @@
+package job
+
+import (
+	"context"
+	"errors"
+	"time"
+	"webook/webook/internal/domain"
+	"webook/webook/internal/service"
+	"webook/webook/pkg/logger"
+)
+
+// Scheduler 调度 MySQL 里面的定时任务
+// 每个实例都在不断地抢占到时间的任务，抢到了就交给对应的执行器
+type Scheduler struct {
+	svc   service.CronJobService
+	execs map[string]Executor
+	l     logger.Logger
+	// 没有任务的时候隔多久再抢
+	interval time.Duration
+	// 单次抢占的超时时间
+	dbTimeout time.Duration
+	// 一个实例最多同时运行多少个任务
+	limiter chan struct{}
+}
+
+func NewScheduler(svc service.CronJobService, l logger.Logger) *Scheduler {
+	return &Scheduler{
+		svc:       svc,
+		execs:     make(map[string]Executor),
+		l:         l,
+		interval:  time.Second,
+		dbTimeout: time.Second,
+		limiter:   make(chan struct{}, 16),
+	}
+}
+
+// RegisterExecutor 注册执行器，启动之前调用，不是并发安全的
+func (s *Scheduler) RegisterExecutor(exec Executor) {
+	s.execs[exec.Name()] = exec
+}
+
+// Schedule 一直运行到 ctx 被取消
+func (s *Scheduler) Schedule(ctx context.Context) error {
+	for {
+		select {
+		case s.limiter <- struct{}{}:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+		dbCtx, cancel := context.WithTimeout(ctx, s.dbTimeout)
+		j, err := s.svc.Preempt(dbCtx)
+		cancel()
+		if err != nil {
+			<-s.limiter
+			if !errors.Is(err, service.ErrNoCronJob) {
+				s.l.Error("抢占任务失败", logger.Error(err))
+			}
+			select {
+			case <-time.After(s.interval):
+				continue
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+		exec, ok := s.execs[j.Executor]
+		if !ok {
+			// 释放掉，等下一次运行时间再看看有没有注册
+			s.l.Error("找不到任务的执行器", logger.String("name", j.Name),
+				logger.String("executor", j.Executor))
+			j.CancelFunc()
+			<-s.limiter
+			continue
+		}
+		go s.run(exec, j)
+	}
+}
+
+func (s *Scheduler) run(exec Executor, j domain.CronJob) {
+	defer func() {
+		j.CancelFunc()
+		<-s.limiter
+	}()
+	start := time.Now()
+	err := exec.Exec(j.Ctx, j)
+	if err != nil {
+		s.l.Error("运行任务失败", logger.String("name", j.Name), logger.Error(err))
+		return
+	}
+	s.l.Debug("运行任务成功", logger.String("name", j.Name),
+		logger.Duration("duration", time.Since(start)))
+}
@@ -0,0 +1,94 @@
+package job
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+	"testing"
+	"time"
+	"webook/webook/internal/domain"
+	"webook/webook/internal/service"
+	svcmocks "webook/webook/internal/service/mocks"
+	"webook/webook/pkg/logger"
+)
+
+func TestScheduler_Schedule(t *testing.T) {
+	testCases := []struct {
+		name string
+		mock func(ctrl *gomock.Controller, released chan string) service.CronJobService
+		// 期望被执行的任务
+		wantExec string
+		// 期望被释放的任务
+		wantReleased string
+	}{
+		{
+			name: "抢到任务并且执行",
+			mock: func(ctrl *gomock.Controller, released chan string) service.CronJobService {
+				svc := svcmocks.NewMockCronJobService(ctrl)
+				svc.EXPECT().Preempt(gomock.Any()).Return(domain.CronJob{
+					Name:       "ranking",
+					Executor:   "local",
+					Ctx:        context.Background(),
+					CancelFunc: func() { released <- "ranking" },
+				}, nil)
+				svc.EXPECT().Preempt(gomock.Any()).Return(domain.CronJob{}, service.ErrNoCronJob).AnyTimes()
+				return svc
+			},
+			wantExec:     "ranking",
+			wantReleased: "ranking",
+		},
+		{
+			name: "找不到执行器，直接释放",
+			mock: func(ctrl *gomock.Controller, released chan string) service.CronJobService {
+				svc := svcmocks.NewMockCronJobService(ctrl)
+				svc.EXPECT().Preempt(gomock.Any()).Return(domain.CronJob{
+					Name:       "unknown",
+					Executor:   "http",
+					Ctx:        context.Background(),
+					CancelFunc: func() { released <- "unknown" },
+				}, nil)
+				svc.EXPECT().Preempt(gomock.Any()).Return(domain.CronJob{}, service.ErrNoCronJob).AnyTimes()
+				return svc
+			},
+			wantReleased: "unknown",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			released := make(chan string, 1)
+			executed := make(chan string, 1)
+			exec := NewLocalFuncExecutor()
+			exec.RegisterFunc("ranking", func(ctx context.Context, j domain.CronJob) error {
+				executed <- j.Name
+				return nil
+			})
+			s := NewScheduler(tc.mock(ctrl, released), logger.NewNoOpLogger())
+			s.interval = time.Millisecond * 10
+			s.RegisterExecutor(exec)
+
+			ctx, cancel := context.WithCancel(context.Background())
+			done := make(chan error, 1)
+			go func() {
+				done <- s.Schedule(ctx)
+			}()
+			if tc.wantExec != "" {
+				select {
+				case name := <-executed:
+					assert.Equal(t, tc.wantExec, name)
+				case <-time.After(time.Second):
+					t.Fatal("任务没有执行")
+				}
+			}
+			select {
+			case name := <-released:
+				assert.Equal(t, tc.wantReleased, name)
+			case <-time.After(time.Second):
+				t.Fatal("任务没有释放")
+			}
+			cancel()
+			assert.Equal(t, context.Canceled, <-done)
+		})
+	}
+}
@@ -0,0 +1,10 @@
+package job
+
+import "context"
+
+// Job 后台任务，由定时任务框架调度
+type Job interface {
+	Name() string
+	// Run ctx 被取消的时候要尽快退出，例如 MySQL 里的定时任务续约失败了
+	Run(ctx context.Context) error
+}
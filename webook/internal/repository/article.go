@@ -2,13 +2,22 @@ package repository
 
 import (
 	"context"
+	"github.com/ecodeclub/ekit/slice"
 	"gorm.io/gorm"
+	"time"
 	"webook/webook/internal/domain"
 	"webook/webook/internal/repository/dao"
+	"webook/webook/pkg/logger"
 )
 
+var ErrArticleNotFound = dao.ErrArticleNotFound
+var ErrArticleNotPublished = dao.ErrArticleNotPublished
+
 type CacheArticleRepository struct {
 	dao dao.ArticleDAO
+	// 用于查询作者的昵称
+	userRepo UserRepository
+	l        logger.Logger
 
 	// V1
 	authorDAO dao.ArticleAuthorDAO
@@ -22,14 +31,193 @@ func NewCacheArticleRepositoryV1(authorDAO dao.ArticleAuthorDAO, readerDAO dao.A
 	return &CacheArticleRepository{authorDAO: authorDAO, readerDAO: readerDAO}
 }
 
-func NewCacheArticleRepository(dao dao.ArticleDAO) ArticleRepository {
-	return &CacheArticleRepository{dao: dao}
+func NewCacheArticleRepository(dao dao.ArticleDAO, userRepo UserRepository, l logger.Logger) ArticleRepository {
+	return &CacheArticleRepository{dao: dao, userRepo: userRepo, l: l}
+}
+
+func (r *CacheArticleRepository) List(ctx context.Context, uid int64, offset int, limit int) ([]domain.Article, error) {
+	arts, err := r.dao.GetByAuthor(ctx, uid, offset, limit)
+	if err != nil {
+		return nil, err
+	}
+	return slice.Map[dao.Article, domain.Article](arts, func(idx int, src dao.Article) domain.Article {
+		return r.toDomain(src)
+	}), nil
+}
+
+func (r *CacheArticleRepository) ListByCursor(ctx context.Context, uid int64,
+	utime time.Time, id int64, limit int) ([]domain.Article, error) {
+	arts, err := r.dao.GetByAuthorCursor(ctx, uid, utime.UnixMilli(), id, limit)
+	if err != nil {
+		return nil, err
+	}
+	return slice.Map[dao.Article, domain.Article](arts, func(idx int, src dao.Article) domain.Article {
+		return r.toDomain(src)
+	}), nil
+}
+
+func (r *CacheArticleRepository) GetById(ctx context.Context, id int64) (domain.Article, error) {
+	art, err := r.dao.GetById(ctx, id)
+	if err != nil {
+		return domain.Article{}, err
+	}
+	return r.toDomain(art), nil
+}
+
+func (r *CacheArticleRepository) GetPublishedById(ctx context.Context, id int64) (domain.Article, error) {
+	art, err := r.dao.GetPubById(ctx, id, domain.ArticleStatusPublished.ToUint8())
+	if err != nil {
+		return domain.Article{}, err
+	}
+	res := r.toDomain(art.Article)
+	// 线上库只存了作者 ID，昵称要去用户模块拿
+	// 用户信息是有缓存的，这里直接查；和列表一样，查不到作者的时候昵称留空
+	author, err := r.userRepo.FindById(ctx, res.Author.Id)
+	if err != nil {
+		r.l.Error("查询文章作者失败", logger.Error(err), logger.Int64("authorId", res.Author.Id))
+	}
+	res.Author.Name = author.NickName
+	return res, nil
+}
+
+func (r *CacheArticleRepository) ListPub(ctx context.Context, offset int, limit int) ([]domain.Article, error) {
+	arts, err := r.dao.ListPub(ctx, domain.ArticleStatusPublished.ToUint8(), offset, limit)
+	if err != nil {
+		return nil, err
+	}
+	return r.toDomainWithAuthorName(ctx, arts)
+}
+
+func (r *CacheArticleRepository) ListPubSince(ctx context.Context, since time.Time,
+	offset int, limit int) ([]domain.Article, error) {
+	arts, err := r.dao.ListPubSince(ctx, domain.ArticleStatusPublished.ToUint8(), since.UnixMilli(), offset, limit)
+	if err != nil {
+		return nil, err
+	}
+	return r.toDomainWithAuthorName(ctx, arts)
+}
+
+func (r *CacheArticleRepository) ListPubByIds(ctx context.Context, ids []int64) ([]domain.Article, error) {
+	arts, err := r.dao.ListPubByIds(ctx, ids, domain.ArticleStatusPublished.ToUint8())
+	if err != nil {
+		return nil, err
+	}
+	return r.toDomainWithAuthorName(ctx, arts)
+}
+
+func (r *CacheArticleRepository) ListPubByAuthors(ctx context.Context, authorIds []int64,
+	before time.Time, limit int) ([]domain.Article, error) {
+	arts, err := r.dao.ListPubByAuthors(ctx, authorIds, domain.ArticleStatusPublished.ToUint8(), before.UnixMilli(), limit)
+	if err != nil {
+		return nil, err
+	}
+	return r.toDomainWithAuthorName(ctx, arts)
+}
+
+func (r *CacheArticleRepository) ListPubByTag(ctx context.Context, tag string,
+	offset int, limit int) ([]domain.Article, error) {
+	arts, err := r.dao.ListPubByTag(ctx, tag, domain.ArticleStatusPublished.ToUint8(), offset, limit)
+	if err != nil {
+		return nil, err
+	}
+	return r.toDomainWithAuthorName(ctx, arts)
+}
+
+func (r *CacheArticleRepository) ListPubTags(ctx context.Context, limit int) ([]domain.Tag, error) {
+	tags, err := r.dao.ListPubTags(ctx, domain.ArticleStatusPublished.ToUint8(), limit)
+	if err != nil {
+		return nil, err
+	}
+	return slice.Map[dao.TagCount, domain.Tag](tags, func(idx int, src dao.TagCount) domain.Tag {
+		return domain.Tag{
+			Name: src.Name,
+			Cnt:  src.Cnt,
+		}
+	}), nil
+}
+
+// toDomainWithAuthorName 转换线上库的文章，并且带上作者的昵称
+// 查不到作者的时候昵称留空，不影响整个列表
+func (r *CacheArticleRepository) toDomainWithAuthorName(ctx context.Context,
+	arts []dao.PublishedArticle) ([]domain.Article, error) {
+	res := slice.Map[dao.PublishedArticle, domain.Article](arts, func(idx int, src dao.PublishedArticle) domain.Article {
+		return r.toDomain(src.Article)
+	})
+	// 一页里面同一个作者可能有多篇文章，每个作者只查一次
+	names := make(map[int64]string, len(res))
+	for i := range res {
+		aid := res[i].Author.Id
+		name, ok := names[aid]
+		if !ok {
+			author, err := r.userRepo.FindById(ctx, aid)
+			if err != nil {
+				r.l.Error("查询文章作者失败", logger.Error(err), logger.Int64("authorId", aid))
+			}
+			name = author.NickName
+			names[aid] = name
+		}
+		res[i].Author.Name = name
+	}
+	return res, nil
+}
+
+func (r *CacheArticleRepository) Delete(ctx context.Context, id int64, uid int64) error {
+	return r.dao.SoftDelete(ctx, id, uid)
+}
+
+func (r *CacheArticleRepository) ListDeleted(ctx context.Context, uid int64, after time.Time,
+	offset int, limit int) ([]domain.Article, error) {
+	arts, err := r.dao.ListDeleted(ctx, uid, after.UnixMilli(), offset, limit)
+	if err != nil {
+		return nil, err
+	}
+	return slice.Map[dao.Article, domain.Article](arts, func(idx int, src dao.Article) domain.Article {
+		return r.toDomain(src)
+	}), nil
+}
+
+func (r *CacheArticleRepository) RestoreDeleted(ctx context.Context, id int64, uid int64, after time.Time) error {
+	return r.dao.RestoreDeleted(ctx, id, uid, after.UnixMilli())
+}
+
+func (r *CacheArticleRepository) PurgeDeleted(ctx context.Context, before time.Time, limit int) ([]int64, error) {
+	return r.dao.PurgeDeleted(ctx, domain.BizArticle, before.UnixMilli(), limit)
+}
+
+func (r *CacheArticleRepository) ListScheduled(ctx context.Context) ([]domain.Article, error) {
+	arts, err := r.dao.ListByStatus(ctx, domain.ArticleStatusScheduled.ToUint8())
+	if err != nil {
+		return nil, err
+	}
+	return slice.Map[dao.Article, domain.Article](arts, func(idx int, src dao.Article) domain.Article {
+		return r.toDomain(src)
+	}), nil
+}
+
+func (r *CacheArticleRepository) ListPendingReview(ctx context.Context, offset int, limit int) ([]domain.Article, error) {
+	arts, err := r.dao.ListByStatusAsc(ctx, domain.ArticleStatusPendingReview.ToUint8(), offset, limit)
+	if err != nil {
+		return nil, err
+	}
+	return slice.Map[dao.Article, domain.Article](arts, func(idx int, src dao.Article) domain.Article {
+		return r.toDomain(src)
+	}), nil
+}
+
+func (r *CacheArticleRepository) UpdateSchedule(ctx context.Context, id int64, uid int64,
+	from domain.ArticleStatus, to domain.ArticleStatus, publishAt time.Time) error {
+	return r.dao.UpdateSchedule(ctx, id, uid, from.ToUint8(), to.ToUint8(), r.toMilli(publishAt))
 }
 
 func (r *CacheArticleRepository) SyncStatus(ctx context.Context, id int64, authorId int64, status domain.ArticleStatus) error {
 	return r.dao.SyncStatus(ctx, id, authorId, status.ToUint8())
 }
 
+func (r *CacheArticleRepository) Withdraw(ctx context.Context, id int64, authorId int64) error {
+	return r.dao.Withdraw(ctx, id, authorId,
+		domain.ArticleStatusPublished.ToUint8(), domain.ArticleStatusPrivate.ToUint8())
+}
+
 // Sync 数据同步交给DAO层解决，在 repository 这一层认为只有一个DAO
 func (r *CacheArticleRepository) Sync(ctx context.Context, art domain.Article) (int64, error) {
 	return r.dao.Sync(ctx, r.toEntity(art))
@@ -99,30 +287,57 @@ func (r *CacheArticleRepository) SyncV1(ctx context.Context, art domain.Article)
 }
 
 func (r *CacheArticleRepository) Create(ctx context.Context, art domain.Article) (int64, error) {
-	return r.dao.Insert(ctx, dao.Article{
-		Title:    art.Title,
-		Content:  art.Content,
-		AuthorId: art.Author.Id,
-		Status:   art.Status.ToUint8(),
-	})
+	return r.dao.Insert(ctx, r.toEntity(art))
 }
 
 func (r *CacheArticleRepository) Update(ctx context.Context, art domain.Article) error {
-	return r.dao.UpdateById(ctx, dao.Article{
-		Id:       art.Id,
-		Title:    art.Title,
-		Content:  art.Content,
-		AuthorId: art.Author.Id,
-		Status:   art.Status.ToUint8(),
-	})
+	return r.dao.UpdateById(ctx, r.toEntity(art))
 }
 
 func (r *CacheArticleRepository) toEntity(art domain.Article) dao.Article {
 	return dao.Article{
-		Id:       art.Id,
-		Title:    art.Title,
-		Content:  art.Content,
-		AuthorId: art.Author.Id,
-		Status:   art.Status.ToUint8(),
+		Id:        art.Id,
+		Title:     art.Title,
+		Content:   art.Content,
+		Html:      art.HTML,
+		Summary:   art.Summary,
+		AuthorId:  art.Author.Id,
+		Status:    art.Status.ToUint8(),
+		Category:  art.Category.ToUint8(),
+		Tags:      art.Tags,
+		PublishAt: r.toMilli(art.PublishAt),
+	}
+}
+
+// toMilli 零值的时间转成 0，而不是一个负数
+func (r *CacheArticleRepository) toMilli(t time.Time) int64 {
+	if t.IsZero() {
+		return 0
+	}
+	return t.UnixMilli()
+}
+
+func (r *CacheArticleRepository) toDomain(art dao.Article) domain.Article {
+	res := domain.Article{
+		Id:      art.Id,
+		Title:   art.Title,
+		Content: art.Content,
+		HTML:    art.Html,
+		Summary: art.Summary,
+		Author: domain.Author{
+			Id: art.AuthorId,
+		},
+		Status:   domain.ArticleStatus(art.Status),
+		Category: domain.ArticleCategory(art.Category),
+		Tags:     art.Tags,
+		Ctime:    time.UnixMilli(art.Ctime),
+		Utime:    time.UnixMilli(art.Utime),
+	}
+	if art.Dtime > 0 {
+		res.Dtime = time.UnixMilli(art.Dtime)
+	}
+	if art.PublishAt > 0 {
+		res.PublishAt = time.UnixMilli(art.PublishAt)
 	}
+	return res
 }
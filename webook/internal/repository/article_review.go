@@ -0,0 +1,44 @@
+package repository
+
+import (
+	"context"
+	"time"
+	"webook/webook/internal/domain"
+	"webook/webook/internal/repository/dao"
+)
+
+var ErrReviewNotFound = dao.ErrReviewNotFound
+
+type CacheArticleReviewRepository struct {
+	dao dao.ArticleReviewDAO
+}
+
+func NewCacheArticleReviewRepository(dao dao.ArticleReviewDAO) ArticleReviewRepository {
+	return &CacheArticleReviewRepository{dao: dao}
+}
+
+func (r *CacheArticleReviewRepository) Create(ctx context.Context, review domain.ArticleReview) (int64, error) {
+	return r.dao.Insert(ctx, dao.ArticleReview{
+		ArtId:    review.ArtId,
+		AuthorId: review.AuthorId,
+		Reviewer: review.Reviewer,
+		Result:   review.Result.ToUint8(),
+		Reason:   review.Reason,
+	})
+}
+
+func (r *CacheArticleReviewRepository) FindLatest(ctx context.Context, artId int64) (domain.ArticleReview, error) {
+	review, err := r.dao.FindLatest(ctx, artId)
+	if err != nil {
+		return domain.ArticleReview{}, err
+	}
+	return domain.ArticleReview{
+		Id:       review.Id,
+		ArtId:    review.ArtId,
+		AuthorId: review.AuthorId,
+		Reviewer: review.Reviewer,
+		Result:   domain.ReviewResult(review.Result),
+		Reason:   review.Reason,
+		Ctime:    time.UnixMilli(review.Ctime),
+	}, nil
+}
@@ -0,0 +1,56 @@
+package repository
+
+import (
+	"context"
+	"github.com/ecodeclub/ekit/slice"
+	"time"
+	"webook/webook/internal/domain"
+	"webook/webook/internal/repository/dao"
+)
+
+var ErrRevisionNotFound = dao.ErrRevisionNotFound
+
+type CacheArticleRevisionRepository struct {
+	dao dao.ArticleRevisionDAO
+}
+
+func NewCacheArticleRevisionRepository(dao dao.ArticleRevisionDAO) ArticleRevisionRepository {
+	return &CacheArticleRevisionRepository{dao: dao}
+}
+
+func (r *CacheArticleRevisionRepository) List(ctx context.Context, artId int64, offset int, limit int) ([]domain.ArticleRevision, error) {
+	revs, err := r.dao.List(ctx, artId, offset, limit)
+	if err != nil {
+		return nil, err
+	}
+	return slice.Map[dao.ArticleRevision, domain.ArticleRevision](revs, func(idx int, src dao.ArticleRevision) domain.ArticleRevision {
+		return r.toDomain(src)
+	}), nil
+}
+
+func (r *CacheArticleRevisionRepository) GetById(ctx context.Context, id int64) (domain.ArticleRevision, error) {
+	rev, err := r.dao.GetById(ctx, id)
+	if err != nil {
+		return domain.ArticleRevision{}, err
+	}
+	return r.toDomain(rev), nil
+}
+
+func (r *CacheArticleRevisionRepository) Prune(ctx context.Context, artId int64, retention domain.RevisionRetention) error {
+	var before int64
+	if retention.KeepDays > 0 {
+		before = time.Now().AddDate(0, 0, -retention.KeepDays).UnixMilli()
+	}
+	return r.dao.Prune(ctx, artId, retention.KeepCount, before)
+}
+
+func (r *CacheArticleRevisionRepository) toDomain(rev dao.ArticleRevision) domain.ArticleRevision {
+	return domain.ArticleRevision{
+		Id:        rev.Id,
+		ArticleId: rev.ArticleId,
+		Title:     rev.Title,
+		Content:   rev.Content,
+		Status:    domain.ArticleStatus(rev.Status),
+		Ctime:     time.UnixMilli(rev.Ctime),
+	}
+}
@@ -0,0 +1,149 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+	"testing"
+	"time"
+	"webook/webook/internal/domain"
+	"webook/webook/internal/repository/dao"
+	daomocks "webook/webook/internal/repository/dao/mocks"
+	repomocks "webook/webook/internal/repository/mocks"
+	"webook/webook/pkg/logger"
+)
+
+func TestCacheArticleRepository_ListPub(t *testing.T) {
+	now := time.UnixMilli(time.Now().UnixMilli())
+	testCases := []struct {
+		name     string
+		mock     func(ctrl *gomock.Controller) (dao.ArticleDAO, UserRepository)
+		wantArts []domain.Article
+		wantErr  error
+	}{
+		{
+			name: "查询成功，同一个作者只查一次",
+			mock: func(ctrl *gomock.Controller) (dao.ArticleDAO, UserRepository) {
+				d := daomocks.NewMockArticleDAO(ctrl)
+				d.EXPECT().ListPub(gomock.Any(), domain.ArticleStatusPublished.ToUint8(), 0, 10).
+					Return([]dao.PublishedArticle{
+						{Article: dao.Article{Id: 1, Title: "标题1", AuthorId: 123, Ctime: now.UnixMilli(), Utime: now.UnixMilli()}},
+						{Article: dao.Article{Id: 2, Title: "标题2", AuthorId: 123, Ctime: now.UnixMilli(), Utime: now.UnixMilli()}},
+						{Article: dao.Article{Id: 3, Title: "标题3", AuthorId: 456, Ctime: now.UnixMilli(), Utime: now.UnixMilli()}},
+					}, nil)
+				u := repomocks.NewMockUserRepository(ctrl)
+				u.EXPECT().FindById(gomock.Any(), int64(123)).Return(domain.User{
+					Id:       123,
+					UserInfo: domain.UserInfo{NickName: "张三"},
+				}, nil)
+				u.EXPECT().FindById(gomock.Any(), int64(456)).Return(domain.User{
+					Id:       456,
+					UserInfo: domain.UserInfo{NickName: "李四"},
+				}, nil)
+				return d, u
+			},
+			wantArts: []domain.Article{
+				{Id: 1, Title: "标题1", Author: domain.Author{Id: 123, Name: "张三"}, Ctime: now, Utime: now},
+				{Id: 2, Title: "标题2", Author: domain.Author{Id: 123, Name: "张三"}, Ctime: now, Utime: now},
+				{Id: 3, Title: "标题3", Author: domain.Author{Id: 456, Name: "李四"}, Ctime: now, Utime: now},
+			},
+		},
+		{
+			name: "查询作者失败，昵称留空",
+			mock: func(ctrl *gomock.Controller) (dao.ArticleDAO, UserRepository) {
+				d := daomocks.NewMockArticleDAO(ctrl)
+				d.EXPECT().ListPub(gomock.Any(), domain.ArticleStatusPublished.ToUint8(), 0, 10).
+					Return([]dao.PublishedArticle{
+						{Article: dao.Article{Id: 1, Title: "标题1", AuthorId: 123, Ctime: now.UnixMilli(), Utime: now.UnixMilli()}},
+						{Article: dao.Article{Id: 2, Title: "标题2", AuthorId: 456, Ctime: now.UnixMilli(), Utime: now.UnixMilli()}},
+					}, nil)
+				u := repomocks.NewMockUserRepository(ctrl)
+				u.EXPECT().FindById(gomock.Any(), int64(123)).
+					Return(domain.User{}, errors.New("mock db error"))
+				u.EXPECT().FindById(gomock.Any(), int64(456)).Return(domain.User{
+					Id:       456,
+					UserInfo: domain.UserInfo{NickName: "李四"},
+				}, nil)
+				return d, u
+			},
+			wantArts: []domain.Article{
+				{Id: 1, Title: "标题1", Author: domain.Author{Id: 123}, Ctime: now, Utime: now},
+				{Id: 2, Title: "标题2", Author: domain.Author{Id: 456, Name: "李四"}, Ctime: now, Utime: now},
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			d, u := tc.mock(ctrl)
+			repo := NewCacheArticleRepository(d, u, logger.NewNoOpLogger())
+			arts, err := repo.ListPub(context.Background(), 0, 10)
+			assert.Equal(t, tc.wantErr, err)
+			assert.Equal(t, tc.wantArts, arts)
+		})
+	}
+}
+
+func TestCacheArticleRepository_GetPublishedById(t *testing.T) {
+	now := time.UnixMilli(time.Now().UnixMilli())
+	testCases := []struct {
+		name    string
+		mock    func(ctrl *gomock.Controller) (dao.ArticleDAO, UserRepository)
+		wantArt domain.Article
+		wantErr error
+	}{
+		{
+			name: "查询成功",
+			mock: func(ctrl *gomock.Controller) (dao.ArticleDAO, UserRepository) {
+				d := daomocks.NewMockArticleDAO(ctrl)
+				d.EXPECT().GetPubById(gomock.Any(), int64(1), domain.ArticleStatusPublished.ToUint8()).
+					Return(dao.PublishedArticle{Article: dao.Article{Id: 1, Title: "标题", AuthorId: 123,
+						Ctime: now.UnixMilli(), Utime: now.UnixMilli()}}, nil)
+				u := repomocks.NewMockUserRepository(ctrl)
+				u.EXPECT().FindById(gomock.Any(), int64(123)).Return(domain.User{
+					Id:       123,
+					UserInfo: domain.UserInfo{NickName: "张三"},
+				}, nil)
+				return d, u
+			},
+			wantArt: domain.Article{Id: 1, Title: "标题", Author: domain.Author{Id: 123, Name: "张三"}, Ctime: now, Utime: now},
+		},
+		{
+			name: "查询作者失败，昵称留空",
+			mock: func(ctrl *gomock.Controller) (dao.ArticleDAO, UserRepository) {
+				d := daomocks.NewMockArticleDAO(ctrl)
+				d.EXPECT().GetPubById(gomock.Any(), int64(1), domain.ArticleStatusPublished.ToUint8()).
+					Return(dao.PublishedArticle{Article: dao.Article{Id: 1, Title: "标题", AuthorId: 123,
+						Ctime: now.UnixMilli(), Utime: now.UnixMilli()}}, nil)
+				u := repomocks.NewMockUserRepository(ctrl)
+				u.EXPECT().FindById(gomock.Any(), int64(123)).
+					Return(domain.User{}, errors.New("mock db error"))
+				return d, u
+			},
+			wantArt: domain.Article{Id: 1, Title: "标题", Author: domain.Author{Id: 123}, Ctime: now, Utime: now},
+		},
+		{
+			name: "文章没有发表",
+			mock: func(ctrl *gomock.Controller) (dao.ArticleDAO, UserRepository) {
+				d := daomocks.NewMockArticleDAO(ctrl)
+				d.EXPECT().GetPubById(gomock.Any(), int64(1), domain.ArticleStatusPublished.ToUint8()).
+					Return(dao.PublishedArticle{}, ErrArticleNotFound)
+				return d, repomocks.NewMockUserRepository(ctrl)
+			},
+			wantErr: ErrArticleNotFound,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			d, u := tc.mock(ctrl)
+			repo := NewCacheArticleRepository(d, u, logger.NewNoOpLogger())
+			art, err := repo.GetPublishedById(context.Background(), 1)
+			assert.Equal(t, tc.wantErr, err)
+			assert.Equal(t, tc.wantArt, art)
+		})
+	}
+}
@@ -0,0 +1,105 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+	"webook/webook/internal/domain"
+	"webook/webook/internal/repository/dao"
+)
+
+var (
+	ErrNoAsyncSms      = dao.ErrNoAsyncSms
+	ErrAsyncSmsNotHold = dao.ErrAsyncSmsNotHold
+)
+
+// maxLastErrLength 和数据库的字段长度一致
+const maxLastErrLength = 1024
+
+type PreemptAsyncSmsRepository struct {
+	dao dao.AsyncSmsDAO
+}
+
+func NewPreemptAsyncSmsRepository(dao dao.AsyncSmsDAO) AsyncSmsRepository {
+	return &PreemptAsyncSmsRepository{dao: dao}
+}
+
+func (r *PreemptAsyncSmsRepository) Add(ctx context.Context, s domain.AsyncSms) (int64, error) {
+	args, err := json.Marshal(s.Args)
+	if err != nil {
+		return 0, err
+	}
+	numbers, err := json.Marshal(s.Numbers)
+	if err != nil {
+		return 0, err
+	}
+	return r.dao.Insert(ctx, dao.AsyncSms{
+		TplId:    s.TplId,
+		Args:     string(args),
+		Numbers:  string(numbers),
+		MaxRetry: s.MaxRetry,
+		ExpireAt: r.toMilli(s.ExpireAt),
+	})
+}
+
+func (r *PreemptAsyncSmsRepository) Preempt(ctx context.Context, staleBefore time.Time) (domain.AsyncSms, error) {
+	s, err := r.dao.Preempt(ctx, staleBefore.UnixMilli())
+	if err != nil {
+		return domain.AsyncSms{}, err
+	}
+	res := domain.AsyncSms{
+		Id:       s.Id,
+		TplId:    s.TplId,
+		Status:   domain.AsyncSmsStatus(s.Status),
+		RetryCnt: s.RetryCnt,
+		MaxRetry: s.MaxRetry,
+		LastErr:  s.LastErr,
+		NextTime: time.UnixMilli(s.NextTime),
+		Version:  s.Version,
+	}
+	if s.ExpireAt > 0 {
+		res.ExpireAt = time.UnixMilli(s.ExpireAt)
+	}
+	err = json.Unmarshal([]byte(s.Args), &res.Args)
+	if err != nil {
+		return domain.AsyncSms{}, err
+	}
+	err = json.Unmarshal([]byte(s.Numbers), &res.Numbers)
+	return res, err
+}
+
+func (r *PreemptAsyncSmsRepository) MarkSuccess(ctx context.Context, s domain.AsyncSms) error {
+	return r.dao.MarkSuccess(ctx, s.Id, s.Version)
+}
+
+func (r *PreemptAsyncSmsRepository) MarkRetry(ctx context.Context, s domain.AsyncSms, nextTime time.Time) error {
+	numbers, err := json.Marshal(s.Numbers)
+	if err != nil {
+		return err
+	}
+	return r.dao.MarkRetry(ctx, s.Id, s.Version, string(numbers), s.RetryCnt, nextTime.UnixMilli(), r.truncate(s.LastErr))
+}
+
+func (r *PreemptAsyncSmsRepository) MarkFailed(ctx context.Context, s domain.AsyncSms) error {
+	return r.dao.MarkFailed(ctx, s.Id, s.Version, s.RetryCnt, r.truncate(s.LastErr))
+}
+
+func (r *PreemptAsyncSmsRepository) MarkExpired(ctx context.Context, s domain.AsyncSms) error {
+	return r.dao.MarkExpired(ctx, s.Id, s.Version, r.truncate(s.LastErr))
+}
+
+// toMilli 零值表示不会过期，存 0
+func (r *PreemptAsyncSmsRepository) toMilli(t time.Time) int64 {
+	if t.IsZero() {
+		return 0
+	}
+	return t.UnixMilli()
+}
+
+func (r *PreemptAsyncSmsRepository) truncate(msg string) string {
+	runes := []rune(msg)
+	if len(runes) > maxLastErrLength {
+		return string(runes[:maxLastErrLength])
+	}
+	return msg
+}
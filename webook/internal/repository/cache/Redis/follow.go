@@ -0,0 +1,74 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"github.com/redis/go-redis/v9"
+	"strconv"
+	"time"
+	"webook/webook/internal/domain"
+	"webook/webook/internal/repository/cache"
+)
+
+const (
+	fieldFollowers = "followers"
+	fieldFollowees = "followees"
+)
+
+type RedisFollowCache struct {
+	client     redis.Cmdable
+	expiration time.Duration
+}
+
+func NewRedisFollowCache(client redis.Cmdable) cache.FollowCache {
+	return &RedisFollowCache{client: client, expiration: time.Minute * 15}
+}
+
+func (c *RedisFollowCache) GetStatics(ctx context.Context, uid int64) (domain.FollowStatics, error) {
+	res, err := c.client.HGetAll(ctx, c.staticsKey(uid)).Result()
+	if err != nil {
+		return domain.FollowStatics{}, err
+	}
+	if len(res) == 0 {
+		return domain.FollowStatics{}, ErrKeyNotExist
+	}
+	followers, _ := strconv.ParseInt(res[fieldFollowers], 10, 64)
+	followees, _ := strconv.ParseInt(res[fieldFollowees], 10, 64)
+	return domain.FollowStatics{
+		Uid:       uid,
+		Followers: followers,
+		Followees: followees,
+	}, nil
+}
+
+func (c *RedisFollowCache) SetStatics(ctx context.Context, statics domain.FollowStatics) error {
+	key := c.staticsKey(statics.Uid)
+	err := c.client.HSet(ctx, key,
+		fieldFollowers, statics.Followers,
+		fieldFollowees, statics.Followees).Err()
+	if err != nil {
+		return err
+	}
+	return c.client.Expire(ctx, key, c.expiration).Err()
+}
+
+func (c *RedisFollowCache) Follow(ctx context.Context, follower int64, followee int64) error {
+	return c.update(ctx, follower, followee, 1)
+}
+
+func (c *RedisFollowCache) CancelFollow(ctx context.Context, follower int64, followee int64) error {
+	return c.update(ctx, follower, followee, -1)
+}
+
+// update 和互动计数用同一个脚本，key 不存在的时候不更新
+func (c *RedisFollowCache) update(ctx context.Context, follower int64, followee int64, delta int) error {
+	err := c.client.Eval(ctx, luaIncrCnt, []string{c.staticsKey(follower)}, fieldFollowees, delta).Err()
+	if err != nil {
+		return err
+	}
+	return c.client.Eval(ctx, luaIncrCnt, []string{c.staticsKey(followee)}, fieldFollowers, delta).Err()
+}
+
+func (c *RedisFollowCache) staticsKey(uid int64) string {
+	return fmt.Sprintf("follow:statics:%d", uid)
+}
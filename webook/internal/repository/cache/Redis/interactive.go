@@ -0,0 +1,94 @@
+package cache
+
+import (
+	"context"
+	_ "embed"
+	"fmt"
+	"github.com/redis/go-redis/v9"
+	"strconv"
+	"time"
+	"webook/webook/internal/domain"
+	"webook/webook/internal/repository/cache"
+)
+
+//go:embed lua/incr_cnt.lua
+var luaIncrCnt string
+
+const (
+	fieldReadCnt    = "read_cnt"
+	fieldLikeCnt    = "like_cnt"
+	fieldCollectCnt = "collect_cnt"
+)
+
+type RedisInteractiveCache struct {
+	client     redis.Cmdable
+	expiration time.Duration
+}
+
+func NewRedisInteractiveCache(client redis.Cmdable) cache.InteractiveCache {
+	return &RedisInteractiveCache{client: client, expiration: time.Minute * 15}
+}
+
+func (c *RedisInteractiveCache) IncrReadCntIfPresent(ctx context.Context, biz string, bizId int64) error {
+	return c.incr(ctx, biz, bizId, fieldReadCnt, 1)
+}
+
+func (c *RedisInteractiveCache) IncrLikeCntIfPresent(ctx context.Context, biz string, bizId int64) error {
+	return c.incr(ctx, biz, bizId, fieldLikeCnt, 1)
+}
+
+func (c *RedisInteractiveCache) DecrLikeCntIfPresent(ctx context.Context, biz string, bizId int64) error {
+	return c.incr(ctx, biz, bizId, fieldLikeCnt, -1)
+}
+
+func (c *RedisInteractiveCache) IncrCollectCntIfPresent(ctx context.Context, biz string, bizId int64) error {
+	return c.incr(ctx, biz, bizId, fieldCollectCnt, 1)
+}
+
+func (c *RedisInteractiveCache) DecrCollectCntIfPresent(ctx context.Context, biz string, bizId int64) error {
+	return c.incr(ctx, biz, bizId, fieldCollectCnt, -1)
+}
+
+// incr 判断 key 是否存在和自增要是一个原子操作，不然 key 刚好过期的时候
+// HINCRBY 会创建一个只有一个字段的 hash，后面的查询就拿到了错误的数据
+func (c *RedisInteractiveCache) incr(ctx context.Context, biz string, bizId int64, field string, delta int) error {
+	return c.client.Eval(ctx, luaIncrCnt, []string{c.key(biz, bizId)}, field, delta).Err()
+}
+
+func (c *RedisInteractiveCache) Get(ctx context.Context, biz string, bizId int64) (domain.Interactive, error) {
+	res, err := c.client.HGetAll(ctx, c.key(biz, bizId)).Result()
+	if err != nil {
+		return domain.Interactive{}, err
+	}
+	if len(res) == 0 {
+		// HGETALL 在 key 不存在的时候不会返回 redis.Nil
+		return domain.Interactive{}, ErrKeyNotExist
+	}
+	// 缓存里的数据都是自己写进去的，解析失败就当作 0
+	readCnt, _ := strconv.ParseInt(res[fieldReadCnt], 10, 64)
+	likeCnt, _ := strconv.ParseInt(res[fieldLikeCnt], 10, 64)
+	collectCnt, _ := strconv.ParseInt(res[fieldCollectCnt], 10, 64)
+	return domain.Interactive{
+		Biz:        biz,
+		BizId:      bizId,
+		ReadCnt:    readCnt,
+		LikeCnt:    likeCnt,
+		CollectCnt: collectCnt,
+	}, nil
+}
+
+func (c *RedisInteractiveCache) Set(ctx context.Context, intr domain.Interactive) error {
+	key := c.key(intr.Biz, intr.BizId)
+	err := c.client.HSet(ctx, key,
+		fieldReadCnt, intr.ReadCnt,
+		fieldLikeCnt, intr.LikeCnt,
+		fieldCollectCnt, intr.CollectCnt).Err()
+	if err != nil {
+		return err
+	}
+	return c.client.Expire(ctx, key, c.expiration).Err()
+}
+
+func (c *RedisInteractiveCache) key(biz string, bizId int64) string {
+	return fmt.Sprintf("interactive:%s:%d", biz, bizId)
+}
@@ -0,0 +1,14 @@
+-- 互动计数的 key，是一个 hash
+local key = KEYS[1]
+-- 对应的字段，比如 like_cnt
+local cntKey = ARGV[1]
+-- 1 或者 -1
+local delta = tonumber(ARGV[2])
+local exists = redis.call("EXISTS", key)
+if exists == 1 then
+    -- 缓存里有数据才更新，没有的话等下一次查询的时候从数据库加载
+    redis.call("HINCRBY", key, cntKey, delta)
+    return 1
+else
+    return 0
+end
@@ -0,0 +1,71 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/redis/go-redis/v9"
+	"time"
+	"webook/webook/internal/domain"
+	"webook/webook/internal/repository/cache"
+)
+
+type RedisRankingCache struct {
+	client redis.Cmdable
+	key    string
+	// 要比计算热榜的间隔长，否则两次计算之间会有一段时间查不到
+	expiration time.Duration
+}
+
+func NewRedisRankingCache(client redis.Cmdable) cache.RankingCache {
+	return &RedisRankingCache{
+		client:     client,
+		key:        "ranking:top_n:article",
+		expiration: time.Minute * 10,
+	}
+}
+
+func (c *RedisRankingCache) Set(ctx context.Context, arts []domain.Article) error {
+	val, err := json.Marshal(arts)
+	if err != nil {
+		return err
+	}
+	return c.client.Set(ctx, c.key, val, c.expiration).Err()
+}
+
+func (c *RedisRankingCache) Get(ctx context.Context) ([]domain.Article, error) {
+	val, err := c.client.Get(ctx, c.key).Bytes()
+	if err != nil {
+		return nil, err
+	}
+	var res []domain.Article
+	err = json.Unmarshal(val, &res)
+	return res, err
+}
+
+// Remove 读出来去掉之后写回去，保留原来的过期时间。
+// 和重新计算热榜并发的时候可能覆盖掉新的结果，下一次计算就会恢复
+func (c *RedisRankingCache) Remove(ctx context.Context, artId int64) error {
+	arts, err := c.Get(ctx)
+	if errors.Is(err, redis.Nil) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	res := make([]domain.Article, 0, len(arts))
+	for _, art := range arts {
+		if art.Id != artId {
+			res = append(res, art)
+		}
+	}
+	if len(res) == len(arts) {
+		// 不在热榜里面
+		return nil
+	}
+	val, err := json.Marshal(res)
+	if err != nil {
+		return err
+	}
+	return c.client.Set(ctx, c.key, val, redis.KeepTTL).Err()
+}
@@ -0,0 +1,55 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/redis/go-redis/v9"
+	"time"
+	"webook/webook/internal/domain"
+	"webook/webook/internal/repository/cache"
+)
+
+type RedisRBACCache struct {
+	client     redis.Cmdable
+	expiration time.Duration
+}
+
+func NewRedisRBACCache(client redis.Cmdable) cache.RBACCache {
+	// 撤销角色会删除缓存，过期时间只是兜底删除失败的情况
+	return &RedisRBACCache{client: client, expiration: time.Minute * 10}
+}
+
+func (c *RedisRBACCache) GetPermissions(ctx context.Context, uid int64) ([]domain.Permission, error) {
+	val, err := c.client.Get(ctx, c.key(uid)).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, ErrKeyNotExist
+	}
+	if err != nil {
+		return nil, err
+	}
+	var perms []domain.Permission
+	err = json.Unmarshal(val, &perms)
+	return perms, err
+}
+
+func (c *RedisRBACCache) SetPermissions(ctx context.Context, uid int64, perms []domain.Permission) error {
+	// 没有权限的用户也要缓存，存成 []
+	if perms == nil {
+		perms = []domain.Permission{}
+	}
+	val, err := json.Marshal(perms)
+	if err != nil {
+		return err
+	}
+	return c.client.Set(ctx, c.key(uid), val, c.expiration).Err()
+}
+
+func (c *RedisRBACCache) DelPermissions(ctx context.Context, uid int64) error {
+	return c.client.Del(ctx, c.key(uid)).Err()
+}
+
+func (c *RedisRBACCache) key(uid int64) string {
+	return fmt.Sprintf("rbac:permissions:%d", uid)
+}
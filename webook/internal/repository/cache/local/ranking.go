@@ -0,0 +1,68 @@
+package local
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+	"webook/webook/internal/domain"
+	"webook/webook/internal/repository/cache"
+)
+
+var ErrLocalCacheMiss = errors.New("本地缓存没有数据或者已经过期")
+
+// RankingLocalCache 热榜的本地缓存
+// 热榜每个实例都是同一份，数据量也小，直接放在内存里
+type RankingLocalCache struct {
+	mutex      sync.RWMutex
+	arts       []domain.Article
+	ddl        time.Time
+	expiration time.Duration
+}
+
+func NewRankingLocalCache() cache.RankingLocalCache {
+	return &RankingLocalCache{
+		// 比 Redis 短，过期之后就去 Redis 拿别的实例算出来的最新结果
+		expiration: time.Minute * 3,
+	}
+}
+
+func (c *RankingLocalCache) Set(ctx context.Context, arts []domain.Article) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.arts = arts
+	c.ddl = time.Now().Add(c.expiration)
+	return nil
+}
+
+func (c *RankingLocalCache) Get(ctx context.Context) ([]domain.Article, error) {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	if len(c.arts) == 0 || time.Now().After(c.ddl) {
+		return nil, ErrLocalCacheMiss
+	}
+	return c.arts, nil
+}
+
+func (c *RankingLocalCache) ForceGet(ctx context.Context) ([]domain.Article, error) {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	if len(c.arts) == 0 {
+		return nil, ErrLocalCacheMiss
+	}
+	return c.arts, nil
+}
+
+// Remove 不改变过期时间。Get 返回的切片可能还在被读，所以这里换一个新的切片
+func (c *RankingLocalCache) Remove(ctx context.Context, artId int64) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	arts := make([]domain.Article, 0, len(c.arts))
+	for _, art := range c.arts {
+		if art.Id != artId {
+			arts = append(arts, art)
+		}
+	}
+	c.arts = arts
+	return nil
+}
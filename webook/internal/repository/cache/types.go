@@ -19,8 +19,53 @@ type CodeCache interface {
 	Verify(ctx context.Context, biz, phone, inputCode string) (bool, error)
 }
 
+// InteractiveCache 互动计数缓存
+// 只缓存计数，用户有没有点赞、收藏不缓存
+type InteractiveCache interface {
+	// IncrReadCntIfPresent 缓存里有这个资源的时候才增加阅读数
+	IncrReadCntIfPresent(ctx context.Context, biz string, bizId int64) error
+	IncrLikeCntIfPresent(ctx context.Context, biz string, bizId int64) error
+	DecrLikeCntIfPresent(ctx context.Context, biz string, bizId int64) error
+	IncrCollectCntIfPresent(ctx context.Context, biz string, bizId int64) error
+	DecrCollectCntIfPresent(ctx context.Context, biz string, bizId int64) error
+	Get(ctx context.Context, biz string, bizId int64) (domain.Interactive, error)
+	Set(ctx context.Context, intr domain.Interactive) error
+}
+
 // Cache 统一缓存API
 //type Cache interface {
 //	Get(ctx context.Context, key string) (any, error)
 //	Set(ctx context.Context, key string, val any, expiration time.Duration)error
 //}
+
+// RankingCache 热榜
+type RankingCache interface {
+	Set(ctx context.Context, arts []domain.Article) error
+	Get(ctx context.Context) ([]domain.Article, error)
+	// Remove 去掉一篇文章，不在热榜里面或者没有缓存都不算错误
+	Remove(ctx context.Context, artId int64) error
+}
+
+// RankingLocalCache 本地缓存的热榜，Redis 出问题的时候兜底
+type RankingLocalCache interface {
+	RankingCache
+	// ForceGet 不管有没有过期都返回，只有没有数据的时候才返回错误
+	ForceGet(ctx context.Context) ([]domain.Article, error)
+}
+
+// FollowCache 关注数和粉丝数缓存，关注列表不缓存
+type FollowCache interface {
+	GetStatics(ctx context.Context, uid int64) (domain.FollowStatics, error)
+	SetStatics(ctx context.Context, statics domain.FollowStatics) error
+	// Follow 缓存里有的时候才更新双方的计数
+	Follow(ctx context.Context, follower int64, followee int64) error
+	CancelFollow(ctx context.Context, follower int64, followee int64) error
+}
+
+// RBACCache 用户的权限缓存，授予、撤销角色的时候删除
+type RBACCache interface {
+	// GetPermissions 缓存里没有返回 ErrKeyNotExist，没有任何权限的用户返回空切片
+	GetPermissions(ctx context.Context, uid int64) ([]domain.Permission, error)
+	SetPermissions(ctx context.Context, uid int64, perms []domain.Permission) error
+	DelPermissions(ctx context.Context, uid int64) error
+}
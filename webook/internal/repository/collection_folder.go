@@ -0,0 +1,87 @@
+package repository
+
+import (
+	"context"
+	"github.com/ecodeclub/ekit/slice"
+	"time"
+	"webook/webook/internal/domain"
+	"webook/webook/internal/repository/dao"
+)
+
+var (
+	ErrFolderNotFound      = dao.ErrFolderNotFound
+	ErrFolderNameDuplicate = dao.ErrFolderNameDuplicate
+	ErrFolderItemDuplicate = dao.ErrFolderItemDuplicate
+)
+
+type CacheCollectionFolderRepository struct {
+	dao dao.CollectionFolderDAO
+}
+
+func NewCacheCollectionFolderRepository(dao dao.CollectionFolderDAO) CollectionFolderRepository {
+	return &CacheCollectionFolderRepository{dao: dao}
+}
+
+func (r *CacheCollectionFolderRepository) Create(ctx context.Context, folder domain.CollectionFolder) (int64, error) {
+	return r.dao.Insert(ctx, dao.CollectionFolder{
+		Uid:  folder.Uid,
+		Name: folder.Name,
+	})
+}
+
+func (r *CacheCollectionFolderRepository) Rename(ctx context.Context, id int64, uid int64, name string) error {
+	return r.dao.UpdateName(ctx, id, uid, name)
+}
+
+func (r *CacheCollectionFolderRepository) Delete(ctx context.Context, id int64, uid int64) error {
+	return r.dao.Delete(ctx, id, uid)
+}
+
+func (r *CacheCollectionFolderRepository) GetById(ctx context.Context, id int64) (domain.CollectionFolder, error) {
+	folder, err := r.dao.GetById(ctx, id)
+	if err != nil {
+		return domain.CollectionFolder{}, err
+	}
+	return r.toDomain(folder), nil
+}
+
+func (r *CacheCollectionFolderRepository) List(ctx context.Context, uid int64, offset int, limit int) ([]domain.CollectionFolder, error) {
+	folders, err := r.dao.ListByUid(ctx, uid, offset, limit)
+	if err != nil {
+		return nil, err
+	}
+	return slice.Map[dao.CollectionFolder, domain.CollectionFolder](folders, func(idx int, src dao.CollectionFolder) domain.CollectionFolder {
+		return r.toDomain(src)
+	}), nil
+}
+
+func (r *CacheCollectionFolderRepository) AddItem(ctx context.Context, id int64, artId int64) error {
+	return r.dao.InsertItem(ctx, dao.CollectionFolderItem{
+		Fid:   id,
+		ArtId: artId,
+	})
+}
+
+func (r *CacheCollectionFolderRepository) RemoveItem(ctx context.Context, id int64, artId int64) error {
+	return r.dao.DeleteItem(ctx, id, artId)
+}
+
+func (r *CacheCollectionFolderRepository) ListItems(ctx context.Context, id int64, offset int, limit int) ([]int64, error) {
+	items, err := r.dao.ListItems(ctx, id, offset, limit)
+	if err != nil {
+		return nil, err
+	}
+	return slice.Map[dao.CollectionFolderItem, int64](items, func(idx int, src dao.CollectionFolderItem) int64 {
+		return src.ArtId
+	}), nil
+}
+
+func (r *CacheCollectionFolderRepository) toDomain(folder dao.CollectionFolder) domain.CollectionFolder {
+	return domain.CollectionFolder{
+		Id:    folder.Id,
+		Uid:   folder.Uid,
+		Name:  folder.Name,
+		Ctime: time.UnixMilli(folder.Ctime),
+		Utime: time.UnixMilli(folder.Utime),
+	}
+}
@@ -0,0 +1,103 @@
+package repository
+
+import (
+	"context"
+	"github.com/ecodeclub/ekit/slice"
+	"time"
+	"webook/webook/internal/domain"
+	"webook/webook/internal/repository/dao"
+)
+
+var ErrCommentNotFound = dao.ErrCommentNotFound
+
+type CacheCommentRepository struct {
+	dao      dao.CommentDAO
+	userRepo UserRepository
+}
+
+func NewCacheCommentRepository(dao dao.CommentDAO, userRepo UserRepository) CommentRepository {
+	return &CacheCommentRepository{dao: dao, userRepo: userRepo}
+}
+
+func (r *CacheCommentRepository) Create(ctx context.Context, c domain.Comment) (int64, error) {
+	return r.dao.Insert(ctx, r.toEntity(c))
+}
+
+func (r *CacheCommentRepository) Delete(ctx context.Context, c domain.Comment) error {
+	return r.dao.Delete(ctx, r.toEntity(c))
+}
+
+func (r *CacheCommentRepository) GetById(ctx context.Context, id int64) (domain.Comment, error) {
+	c, err := r.dao.GetById(ctx, id)
+	if err != nil {
+		return domain.Comment{}, err
+	}
+	return r.toDomain(c), nil
+}
+
+func (r *CacheCommentRepository) FindRoots(ctx context.Context, artId int64, minId int64, limit int) ([]domain.Comment, error) {
+	cs, err := r.dao.FindRoots(ctx, artId, minId, limit)
+	if err != nil {
+		return nil, err
+	}
+	return r.toDomainWithName(ctx, cs)
+}
+
+func (r *CacheCommentRepository) FindReplies(ctx context.Context, rootId int64, maxId int64, limit int) ([]domain.Comment, error) {
+	cs, err := r.dao.FindReplies(ctx, rootId, maxId, limit)
+	if err != nil {
+		return nil, err
+	}
+	return r.toDomainWithName(ctx, cs)
+}
+
+func (r *CacheCommentRepository) GetCount(ctx context.Context, artId int64) (int64, error) {
+	return r.dao.GetCount(ctx, artId)
+}
+
+// toDomainWithName 转换评论，并且带上评论者的昵称
+func (r *CacheCommentRepository) toDomainWithName(ctx context.Context, cs []dao.Comment) ([]domain.Comment, error) {
+	res := slice.Map[dao.Comment, domain.Comment](cs, func(idx int, src dao.Comment) domain.Comment {
+		return r.toDomain(src)
+	})
+	// 同一个人可能在一页里面评论了好几次，每个人只查一次
+	names := make(map[int64]string, len(res))
+	for i := range res {
+		uid := res[i].Commentator.Id
+		name, ok := names[uid]
+		if !ok {
+			u, err := r.userRepo.FindById(ctx, uid)
+			if err != nil {
+				return nil, err
+			}
+			name = u.NickName
+			names[uid] = name
+		}
+		res[i].Commentator.Name = name
+	}
+	return res, nil
+}
+
+func (r *CacheCommentRepository) toEntity(c domain.Comment) dao.Comment {
+	return dao.Comment{
+		Id:       c.Id,
+		ArtId:    c.ArtId,
+		RootId:   c.RootId,
+		ParentId: c.ParentId,
+		Uid:      c.Commentator.Id,
+		Content:  c.Content,
+	}
+}
+
+func (r *CacheCommentRepository) toDomain(c dao.Comment) domain.Comment {
+	return domain.Comment{
+		Id:          c.Id,
+		ArtId:       c.ArtId,
+		Commentator: domain.Author{Id: c.Uid},
+		Content:     c.Content,
+		RootId:      c.RootId,
+		ParentId:    c.ParentId,
+		ReplyCnt:    c.ReplyCnt,
+		Ctime:       time.UnixMilli(c.Ctime),
+	}
+}
@@ -0,0 +1,49 @@
+package repository
+
+import (
+	"context"
+	"time"
+	"webook/webook/internal/domain"
+	"webook/webook/internal/repository/dao"
+)
+
+var (
+	ErrNoCronJob      = dao.ErrNoCronJob
+	ErrCronJobNotHold = dao.ErrCronJobNotHold
+)
+
+type PreemptCronJobRepository struct {
+	dao dao.CronJobDAO
+}
+
+func NewPreemptCronJobRepository(dao dao.CronJobDAO) CronJobRepository {
+	return &PreemptCronJobRepository{dao: dao}
+}
+
+func (r *PreemptCronJobRepository) Preempt(ctx context.Context, staleBefore time.Time) (domain.CronJob, error) {
+	j, err := r.dao.Preempt(ctx, staleBefore.UnixMilli())
+	if err != nil {
+		return domain.CronJob{}, err
+	}
+	return domain.CronJob{
+		Id:         j.Id,
+		Name:       j.Name,
+		Executor:   j.Executor,
+		Expression: j.Expression,
+		Cfg:        j.Cfg,
+		NextTime:   time.UnixMilli(j.NextTime),
+		Version:    j.Version,
+	}, nil
+}
+
+func (r *PreemptCronJobRepository) UpdateUtime(ctx context.Context, id int64, version int64) error {
+	return r.dao.UpdateUtime(ctx, id, version)
+}
+
+func (r *PreemptCronJobRepository) Release(ctx context.Context, id int64, version int64, nextTime time.Time) error {
+	return r.dao.Release(ctx, id, version, nextTime.UnixMilli())
+}
+
+func (r *PreemptCronJobRepository) Pause(ctx context.Context, id int64, version int64) error {
+	return r.dao.Pause(ctx, id, version)
+}
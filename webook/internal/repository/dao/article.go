@@ -2,12 +2,18 @@ package dao
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"gorm.io/gorm"
 	"gorm.io/gorm/clause"
 	"time"
 )
 
+var ErrArticleNotFound = gorm.ErrRecordNotFound
+
+// ErrArticleNotPublished 线上库里没有处于发表状态的文章
+var ErrArticleNotPublished = errors.New("文章没有发表")
+
 type GORMArticleDAO struct {
 	db *gorm.DB
 }
@@ -16,10 +22,129 @@ func NewGORMArticleDAO(db *gorm.DB) ArticleDAO {
 	return &GORMArticleDAO{db: db}
 }
 
+func (dao *GORMArticleDAO) GetByAuthor(ctx context.Context, authorId int64, offset int, limit int) ([]Article, error) {
+	var arts []Article
+	// 这里可以命中 aid_utime 联合索引，不需要额外的排序
+	err := dao.db.WithContext(ctx).Where("author_id = ? AND dtime = ?", authorId, 0).
+		Order("utime DESC, id DESC").
+		Offset(offset).Limit(limit).
+		Find(&arts).Error
+	return arts, err
+}
+
+func (dao *GORMArticleDAO) GetByAuthorCursor(ctx context.Context, authorId int64,
+	utime int64, id int64, limit int) ([]Article, error) {
+	var arts []Article
+	// 游标分页不需要扫描 offset 之前的行，翻到很后面也不会变慢
+	// 更新时间相同的情况下，用 id 来保证顺序稳定
+	err := dao.db.WithContext(ctx).
+		Where("author_id = ? AND dtime = ? AND (utime < ? OR (utime = ? AND id < ?))",
+			authorId, 0, utime, utime, id).
+		Order("utime DESC, id DESC").
+		Limit(limit).
+		Find(&arts).Error
+	return arts, err
+}
+
+func (dao *GORMArticleDAO) GetById(ctx context.Context, id int64) (Article, error) {
+	var art Article
+	// 在回收站里面的文章对任何读路径都不可见
+	err := dao.db.WithContext(ctx).Where("id = ? AND dtime = ?", id, 0).First(&art).Error
+	if err != nil {
+		return Article{}, err
+	}
+	art.Tags, err = dao.getTags(ctx, articleTagTable, id)
+	return art, err
+}
+
+func (dao *GORMArticleDAO) GetPubById(ctx context.Context, id int64, status uint8) (PublishedArticle, error) {
+	var art PublishedArticle
+	// 带上状态，仅自己可见的和撤回的文章就查不出来
+	err := dao.db.WithContext(ctx).Where("id = ? AND status = ? AND dtime = ?", id, status, 0).
+		First(&art).Error
+	if err != nil {
+		return PublishedArticle{}, err
+	}
+	art.Tags, err = dao.getTags(ctx, publishedArticleTagTable, id)
+	return art, err
+}
+
+func (dao *GORMArticleDAO) ListPubByTag(ctx context.Context, tag string, status uint8,
+	offset int, limit int) ([]PublishedArticle, error) {
+	var arts []PublishedArticle
+	db := dao.db.WithContext(ctx)
+	// 标签不存在的时候子查询是空的，自然也查不到文章
+	artIds := db.Model(&PublishedArticleTag{}).Select("art_id").
+		Where("tag_id = (?)", db.Model(&Tag{}).Select("id").Where("name = ?", tag))
+	err := db.Where("id IN (?) AND status = ? AND dtime = ?", artIds, status, 0).
+		Order("utime DESC, id DESC").
+		Offset(offset).Limit(limit).
+		Find(&arts).Error
+	return arts, err
+}
+
+func (dao *GORMArticleDAO) ListPubTags(ctx context.Context, status uint8, limit int) ([]TagCount, error) {
+	var res []TagCount
+	// 撤回和删除的文章不算在里面
+	err := dao.db.WithContext(ctx).Table(publishedArticleTagTable).
+		Select("tags.name AS name, COUNT(*) AS cnt").
+		Joins("JOIN tags ON tags.id = published_article_tags.tag_id").
+		Joins("JOIN published_articles ON published_articles.id = published_article_tags.art_id").
+		Where("published_articles.status = ? AND published_articles.dtime = ?", status, 0).
+		Group("tags.id, tags.name").
+		Order("cnt DESC, tags.id").
+		Limit(limit).
+		Scan(&res).Error
+	return res, err
+}
+
+func (dao *GORMArticleDAO) ListPub(ctx context.Context, status uint8, offset int, limit int) ([]PublishedArticle, error) {
+	var arts []PublishedArticle
+	err := dao.db.WithContext(ctx).Where("status = ? AND dtime = ?", status, 0).
+		Order("utime DESC, id DESC").
+		Offset(offset).Limit(limit).
+		Find(&arts).Error
+	return arts, err
+}
+
+func (dao *GORMArticleDAO) ListPubSince(ctx context.Context, status uint8, since int64,
+	offset int, limit int) ([]PublishedArticle, error) {
+	var arts []PublishedArticle
+	err := dao.db.WithContext(ctx).Where("status = ? AND dtime = ? AND ctime >= ?", status, 0, since).
+		Order("ctime DESC, id DESC").
+		Offset(offset).Limit(limit).
+		Find(&arts).Error
+	return arts, err
+}
+
+func (dao *GORMArticleDAO) ListPubByIds(ctx context.Context, ids []int64, status uint8) ([]PublishedArticle, error) {
+	var arts []PublishedArticle
+	if len(ids) == 0 {
+		return arts, nil
+	}
+	err := dao.db.WithContext(ctx).Where("id IN ? AND status = ? AND dtime = ?", ids, status, 0).
+		Find(&arts).Error
+	return arts, err
+}
+
+func (dao *GORMArticleDAO) ListPubByAuthors(ctx context.Context, authorIds []int64, status uint8,
+	before int64, limit int) ([]PublishedArticle, error) {
+	var arts []PublishedArticle
+	if len(authorIds) == 0 {
+		return arts, nil
+	}
+	err := dao.db.WithContext(ctx).
+		Where("author_id IN ? AND status = ? AND dtime = ? AND ctime < ?", authorIds, status, 0, before).
+		Order("ctime DESC, id DESC").
+		Limit(limit).
+		Find(&arts).Error
+	return arts, err
+}
+
 func (dao *GORMArticleDAO) SyncStatus(ctx context.Context, id int64, authorId int64, status uint8) error {
 	return dao.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
 		now := time.Now().UnixMilli()
-		res := tx.Model(&Article{}).Where("id = ? AND author_id = ?", id, authorId).
+		res := tx.Model(&Article{}).Where("id = ? AND author_id = ? AND dtime = ?", id, authorId, 0).
 			Updates(map[string]any{
 				"status": status,
 				"utime":  now,
@@ -41,22 +166,177 @@ func (dao *GORMArticleDAO) SyncStatus(ctx context.Context, id int64, authorId in
 	})
 }
 
+// Withdraw 以线上库为准撤回文章：只有线上库里处于 published 状态的文章才能撤回。
+// 制作库里的文章可能有还没发表的修改，这时候只更新线上库，保留作者的草稿状态
+func (dao *GORMArticleDAO) Withdraw(ctx context.Context, id int64, authorId int64, published uint8, private uint8) error {
+	return dao.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		now := time.Now().UnixMilli()
+		res := tx.Model(&PublishedArticle{}).
+			Where("id = ? AND author_id = ? AND status = ? AND dtime = ?", id, authorId, published, 0).
+			Updates(map[string]any{
+				"status": private,
+				"utime":  now,
+			})
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return ErrArticleNotPublished
+		}
+		return tx.Model(&Article{}).Where("id = ? AND status = ?", id, published).
+			Updates(map[string]any{
+				"status": private,
+				"utime":  now,
+			}).Error
+	})
+}
+
+func (dao *GORMArticleDAO) SoftDelete(ctx context.Context, id int64, authorId int64) error {
+	return dao.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		now := time.Now().UnixMilli()
+		res := tx.Model(&Article{}).Where("id = ? AND author_id = ? AND dtime = ?", id, authorId, 0).
+			Update("dtime", now)
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			// 文章不存在、已经删除了，或者不是作者本人
+			return ErrArticleNotFound
+		}
+		// 没有发表过的文章在线上库里没有数据，不需要检查影响的行数
+		return tx.Model(&PublishedArticle{}).Where("id = ?", id).
+			Update("dtime", now).Error
+	})
+}
+
+func (dao *GORMArticleDAO) ListDeleted(ctx context.Context, authorId int64, after int64,
+	offset int, limit int) ([]Article, error) {
+	var arts []Article
+	err := dao.db.WithContext(ctx).Where("author_id = ? AND dtime > ?", authorId, after).
+		Order("dtime DESC, id DESC").
+		Offset(offset).Limit(limit).
+		Find(&arts).Error
+	return arts, err
+}
+
+func (dao *GORMArticleDAO) RestoreDeleted(ctx context.Context, id int64, authorId int64, after int64) error {
+	return dao.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		res := tx.Model(&Article{}).Where("id = ? AND author_id = ? AND dtime > ?", id, authorId, after).
+			Update("dtime", 0)
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			// 不在回收站里，或者已经过了可以恢复的时间
+			return ErrArticleNotFound
+		}
+		return tx.Model(&PublishedArticle{}).Where("id = ?", id).
+			Update("dtime", 0).Error
+	})
+}
+
+func (dao *GORMArticleDAO) PurgeDeleted(ctx context.Context, biz string, before int64, limit int) ([]int64, error) {
+	var ids []int64
+	err := dao.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// 分批删除，避免一个大事务长时间锁表
+		// 在事务里面锁住要删除的文章，查询之后作者恢复的文章不会被删掉，恢复会等到删除完成之后再失败
+		err := tx.Model(&Article{}).Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("dtime > ? AND dtime < ?", 0, before).
+			Limit(limit).Pluck("id", &ids).Error
+		if err != nil || len(ids) == 0 {
+			return err
+		}
+		// 其它业务挂在文章上面的数据一起删掉，标签本身是共用的，只删除关系
+		for _, model := range []any{&PublishedArticleTag{}, &ArticleTag{}, &ArticleReview{},
+			&Comment{}, &CommentCount{}, &CollectionFolderItem{}, &FeedInbox{}} {
+			err := tx.Where("art_id IN ?", ids).Delete(model).Error
+			if err != nil {
+				return err
+			}
+		}
+		// 互动计数和用户的点赞、收藏记录
+		for _, model := range []any{&Interactive{}, &UserLikeBiz{}, &UserCollectionBiz{}} {
+			err := tx.Where("biz_id IN ? AND biz = ?", ids, biz).Delete(model).Error
+			if err != nil {
+				return err
+			}
+		}
+		err = tx.Where("article_id IN ?", ids).Delete(&ArticleRevision{}).Error
+		if err != nil {
+			return err
+		}
+		// 线上库的 dtime 是和制作库一起更新的，这里直接按照 id 删除
+		err = tx.Where("id IN ?", ids).Delete(&PublishedArticle{}).Error
+		if err != nil {
+			return err
+		}
+		return tx.Where("id IN ?", ids).Delete(&Article{}).Error
+	})
+	if err != nil || len(ids) == 0 {
+		return nil, err
+	}
+	return ids, nil
+}
+
+func (dao *GORMArticleDAO) ListByStatus(ctx context.Context, status uint8) ([]Article, error) {
+	var arts []Article
+	err := dao.db.WithContext(ctx).Where("status = ? AND dtime = ?", status, 0).
+		Find(&arts).Error
+	return arts, err
+}
+
+func (dao *GORMArticleDAO) ListByStatusAsc(ctx context.Context, status uint8, offset int, limit int) ([]Article, error) {
+	var arts []Article
+	err := dao.db.WithContext(ctx).Where("status = ? AND dtime = ?", status, 0).
+		Order("utime ASC, id ASC").
+		Offset(offset).Limit(limit).
+		Find(&arts).Error
+	return arts, err
+}
+
+func (dao *GORMArticleDAO) UpdateSchedule(ctx context.Context, id int64, authorId int64,
+	from uint8, to uint8, publishAt int64) error {
+	// 带上当前的状态，防止查询和更新之间文章已经被发表了
+	res := dao.db.WithContext(ctx).Model(&Article{}).
+		Where("id = ? AND author_id = ? AND status = ? AND dtime = ?", id, authorId, from, 0).
+		Updates(map[string]any{
+			"status":     to,
+			"publish_at": publishAt,
+			"utime":      time.Now().UnixMilli(),
+		})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return ErrArticleNotFound
+	}
+	return nil
+}
+
 func (dao *GORMArticleDAO) Upsert(ctx context.Context, art PublishedArticle) error {
 	now := time.Now().UnixMilli()
 	art.Ctime = now
 	art.Utime = now
-	err := dao.db.Clauses(clause.OnConflict{
-		// MySql 只需要这个字段
-		DoUpdates: clause.Assignments(map[string]interface{}{
-			"title":   art.Title,
-			"content": art.Content,
-			"status":  art.Status,
-			"utime":   now,
-		}),
-	}).Create(&art).Error
-	// MySQL生成的语句: INSERT xxx ON DUPLICATE KEY UPDATE xxx
-	// MySQL 的 upsert 语句不支持查询条件
-	return err
+	return dao.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.OnConflict{
+			// MySql 只需要这个字段
+			DoUpdates: clause.Assignments(map[string]interface{}{
+				"title":    art.Title,
+				"content":  art.Content,
+				"html":     art.Html,
+				"summary":  art.Summary,
+				"status":   art.Status,
+				"category": art.Category,
+				"utime":    now,
+			}),
+		}).Create(&art).Error
+		// MySQL生成的语句: INSERT xxx ON DUPLICATE KEY UPDATE xxx
+		// MySQL 的 upsert 语句不支持查询条件
+		if err != nil {
+			return err
+		}
+		return dao.replaceTags(tx, publishedArticleTagTable, art.Id, art.Tags)
+	})
 }
 
 func (dao *GORMArticleDAO) Sync(ctx context.Context, art Article) (int64, error) {
@@ -79,6 +359,8 @@ func (dao *GORMArticleDAO) Sync(ctx context.Context, art Article) (int64, error)
 		if err != nil {
 			return err
 		}
+		// 线上库和制作库的 id 要一致
+		art.Id = id
 		// 操作线上库
 		return txDAO.Upsert(ctx, PublishedArticle{Article: art})
 	})
@@ -89,7 +371,18 @@ func (dao *GORMArticleDAO) Insert(ctx context.Context, art Article) (int64, erro
 	now := time.Now().UnixMilli()
 	art.Ctime = now
 	art.Utime = now
-	err := dao.db.WithContext(ctx).Create(&art).Error
+	// 文章和历史版本要么一起成功，要么一起失败
+	err := dao.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Create(&art).Error
+		if err != nil {
+			return err
+		}
+		err = dao.replaceTags(tx, articleTagTable, art.Id, art.Tags)
+		if err != nil {
+			return err
+		}
+		return tx.Create(dao.newRevision(art)).Error
+	})
 	return art.Id, err
 }
 
@@ -99,15 +392,121 @@ func (dao *GORMArticleDAO) UpdateById(ctx context.Context, art Article) error {
 	// 不要依赖 gorm 忽略零值更新的特性，会用主键进行更新
 	// 这样可读性很差
 	//err := dao.db.WithContext(ctx).Create(&art).Error
-	res := dao.db.WithContext(ctx).Model(&art).
-		Where("id = ? AND author_id = ?", art.Id, art.AuthorId).Updates(map[string]any{
-		"title":   art.Title,
-		"content": art.Content,
-		"status":  art.Status,
-		"utime":   art.Utime,
+	return dao.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		res := tx.Model(&art).
+			Where("id = ? AND author_id = ? AND dtime = ?", art.Id, art.AuthorId, 0).Updates(map[string]any{
+			"title":    art.Title,
+			"content":  art.Content,
+			"html":     art.Html,
+			"summary":  art.Summary,
+			"status":   art.Status,
+			"category": art.Category,
+			"utime":    art.Utime,
+			// 保存和发表都会清掉定时发表的时间
+			"publish_at": art.PublishAt,
+		})
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return fmt.Errorf("更新失败，可能是用户非法: id = %d, authorId = %d", art.Id, art.AuthorId)
+		}
+		err := dao.replaceTags(tx, articleTagTable, art.Id, art.Tags)
+		if err != nil {
+			return err
+		}
+		// 更新前的内容在上一个版本里面，这里记录更新后的内容
+		art.Ctime = art.Utime
+		return tx.Create(dao.newRevision(art)).Error
 	})
-	if res.RowsAffected == 0 {
-		return fmt.Errorf("更新失败，可能是用户非法: id = %d, authorId = %d", art.Id, art.AuthorId)
+}
+
+const (
+	articleTagTable          = "article_tags"
+	publishedArticleTagTable = "published_article_tags"
+)
+
+// replaceTags 把文章的标签整体替换成 names，标签不存在的时候顺便创建
+// table 是制作库或者线上库的关系表，需要在事务里面调用
+func (dao *GORMArticleDAO) replaceTags(tx *gorm.DB, table string, artId int64, names []string) error {
+	err := tx.Table(table).Where("art_id = ?", artId).Delete(&ArticleTag{}).Error
+	if err != nil || len(names) == 0 {
+		return err
+	}
+	now := time.Now().UnixMilli()
+	tags := make([]Tag, 0, len(names))
+	for _, name := range names {
+		tags = append(tags, Tag{Name: name, Ctime: now})
+	}
+	// 别的文章可能已经创建过同名的标签了
+	err = tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&tags).Error
+	if err != nil {
+		return err
+	}
+	var found []Tag
+	err = tx.Where("name IN ?", names).Find(&found).Error
+	if err != nil {
+		return err
+	}
+	ids := make(map[string]int64, len(found))
+	for _, tag := range found {
+		ids[tag.Name] = tag.Id
+	}
+	rels := make([]ArticleTag, 0, len(names))
+	for _, name := range names {
+		rels = append(rels, ArticleTag{ArtId: artId, TagId: ids[name], Ctime: now})
+	}
+	return tx.Table(table).Create(&rels).Error
+}
+
+// getTags 按照作者给出的顺序查询文章的标签
+func (dao *GORMArticleDAO) getTags(ctx context.Context, table string, artId int64) ([]string, error) {
+	var names []string
+	err := dao.db.WithContext(ctx).Table(table).
+		Joins("JOIN tags ON tags.id = "+table+".tag_id").
+		Where(table+".art_id = ?", artId).
+		Order(table+".id").
+		Pluck("tags.name", &names).Error
+	return names, err
+}
+
+func (dao *GORMArticleDAO) newRevision(art Article) *ArticleRevision {
+	return &ArticleRevision{
+		ArticleId: art.Id,
+		AuthorId:  art.AuthorId,
+		Title:     art.Title,
+		Content:   art.Content,
+		Status:    art.Status,
+		Ctime:     art.Ctime,
 	}
-	return res.Error
+}
+
+// legacyArticleStatus 早期 domain.ArticleStatus.ToUint8 有 bug，不管什么状态都写成了 8
+const legacyArticleStatus uint8 = 8
+
+// MigrateLegacyArticleStatus 把早期写入的错误状态修正过来，可以重复执行
+// 早期的数据已经没办法区分已发表和撤回了：线上库里有的都当作已发表，
+// 只在制作库里的当作未发表，历史版本也都当作未发表
+func MigrateLegacyArticleStatus(db *gorm.DB, published uint8, unpublished uint8) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&PublishedArticle{}).Where("status = ?", legacyArticleStatus).
+			Update("status", published).Error
+		if err != nil {
+			return err
+		}
+		err = tx.Model(&Article{}).
+			Where("status = ? AND id IN (?)", legacyArticleStatus,
+				tx.Model(&PublishedArticle{}).Select("id")).
+			Update("status", published).Error
+		if err != nil {
+			return err
+		}
+		err = tx.Model(&Article{}).Where("status = ?", legacyArticleStatus).
+			Update("status", unpublished).Error
+		if err != nil {
+			return err
+		}
+		return tx.Model(&ArticleRevision{}).Where("status = ?", legacyArticleStatus).
+			Update("status", unpublished).Error
+	})
 }
@@ -0,0 +1,30 @@
+package dao
+
+import (
+	"context"
+	"gorm.io/gorm"
+	"time"
+)
+
+var ErrReviewNotFound = gorm.ErrRecordNotFound
+
+type GORMArticleReviewDAO struct {
+	db *gorm.DB
+}
+
+func NewGORMArticleReviewDAO(db *gorm.DB) ArticleReviewDAO {
+	return &GORMArticleReviewDAO{db: db}
+}
+
+func (dao *GORMArticleReviewDAO) Insert(ctx context.Context, r ArticleReview) (int64, error) {
+	r.Ctime = time.Now().UnixMilli()
+	err := dao.db.WithContext(ctx).Create(&r).Error
+	return r.Id, err
+}
+
+func (dao *GORMArticleReviewDAO) FindLatest(ctx context.Context, artId int64) (ArticleReview, error) {
+	var r ArticleReview
+	err := dao.db.WithContext(ctx).Where("art_id = ?", artId).
+		Order("ctime DESC, id DESC").First(&r).Error
+	return r, err
+}
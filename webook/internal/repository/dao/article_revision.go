@@ -0,0 +1,68 @@
+package dao
+
+import (
+	"context"
+	"errors"
+	"gorm.io/gorm"
+)
+
+var ErrRevisionNotFound = gorm.ErrRecordNotFound
+
+type GORMArticleRevisionDAO struct {
+	db *gorm.DB
+}
+
+func NewGORMArticleRevisionDAO(db *gorm.DB) ArticleRevisionDAO {
+	return &GORMArticleRevisionDAO{db: db}
+}
+
+func (dao *GORMArticleRevisionDAO) List(ctx context.Context, artId int64, offset int, limit int) ([]ArticleRevision, error) {
+	var revs []ArticleRevision
+	err := dao.db.WithContext(ctx).Where("article_id = ?", artId).
+		Order("id DESC").
+		Offset(offset).Limit(limit).
+		Find(&revs).Error
+	return revs, err
+}
+
+func (dao *GORMArticleRevisionDAO) GetById(ctx context.Context, id int64) (ArticleRevision, error) {
+	var rev ArticleRevision
+	err := dao.db.WithContext(ctx).Where("id = ?", id).First(&rev).Error
+	return rev, err
+}
+
+func (dao *GORMArticleRevisionDAO) Prune(ctx context.Context, artId int64, keep int, before int64) error {
+	return dao.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// 最新的版本就是文章当前的内容，无论如何都不能删
+		var latest ArticleRevision
+		err := tx.Where("article_id = ?", artId).Order("id DESC").First(&latest).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if keep > 0 {
+			// 找到第 keep+1 个版本，它以及比它更老的都要删掉
+			var ids []int64
+			err = tx.Model(&ArticleRevision{}).Where("article_id = ?", artId).
+				Order("id DESC").Offset(keep).Limit(1).
+				Pluck("id", &ids).Error
+			if err != nil {
+				return err
+			}
+			if len(ids) > 0 {
+				err = tx.Where("article_id = ? AND id <= ?", artId, ids[0]).
+					Delete(&ArticleRevision{}).Error
+				if err != nil {
+					return err
+				}
+			}
+		}
+		if before > 0 {
+			return tx.Where("article_id = ? AND ctime < ? AND id < ?", artId, before, latest.Id).
+				Delete(&ArticleRevision{}).Error
+		}
+		return nil
+	})
+}
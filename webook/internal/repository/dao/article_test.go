@@ -0,0 +1,360 @@
+package dao
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+	"testing"
+)
+
+func TestGORMArticleDAO_SoftDelete(t *testing.T) {
+	testCases := []struct {
+		name    string
+		sqlMock func(t *testing.T) *sql.DB
+		wantErr error
+	}{
+		{
+			name: "制作库和线上库一起删除",
+			sqlMock: func(t *testing.T) *sql.DB {
+				mockDB, mock, err := sqlmock.New()
+				require.NoError(t, err)
+				mock.ExpectBegin()
+				mock.ExpectExec("UPDATE `articles` SET `dtime`=.* WHERE id = .* AND author_id = .* AND dtime = .*").
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec("UPDATE `published_articles` SET `dtime`=.* WHERE id = .*").
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+				return mockDB
+			},
+		},
+		{
+			name: "文章不存在或者不是作者",
+			sqlMock: func(t *testing.T) *sql.DB {
+				mockDB, mock, err := sqlmock.New()
+				require.NoError(t, err)
+				mock.ExpectBegin()
+				mock.ExpectExec("UPDATE `articles` SET `dtime`=.*").
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectRollback()
+				return mockDB
+			},
+			wantErr: ErrArticleNotFound,
+		},
+		{
+			name: "线上库删除失败，回滚",
+			sqlMock: func(t *testing.T) *sql.DB {
+				mockDB, mock, err := sqlmock.New()
+				require.NoError(t, err)
+				mock.ExpectBegin()
+				mock.ExpectExec("UPDATE `articles` SET `dtime`=.*").
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec("UPDATE `published_articles` SET `dtime`=.*").
+					WillReturnError(errors.New("数据库错误"))
+				mock.ExpectRollback()
+				return mockDB
+			},
+			wantErr: errors.New("数据库错误"),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			sqlDB := tc.sqlMock(t)
+			db, err := gorm.Open(mysql.New(mysql.Config{
+				Conn:                      sqlDB,
+				SkipInitializeWithVersion: true,
+			}), &gorm.Config{
+				DisableAutomaticPing:   true,
+				SkipDefaultTransaction: true,
+			})
+			require.NoError(t, err)
+			dao := NewGORMArticleDAO(db)
+			err = dao.SoftDelete(context.Background(), 1, 123)
+			assert.Equal(t, tc.wantErr, err)
+		})
+	}
+}
+
+func TestGORMArticleDAO_PurgeDeleted(t *testing.T) {
+	testCases := []struct {
+		name    string
+		sqlMock func(t *testing.T) *sql.DB
+		wantIds []int64
+		wantErr error
+	}{
+		{
+			name: "挂在文章上面的数据一起删除",
+			sqlMock: func(t *testing.T) *sql.DB {
+				mockDB, mock, err := sqlmock.New()
+				require.NoError(t, err)
+				mock.ExpectBegin()
+				mock.ExpectQuery("SELECT `id` FROM `articles` WHERE dtime > .* AND dtime < .* LIMIT .* FOR UPDATE").
+					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1).AddRow(2))
+				for _, table := range []string{"published_article_tags", "article_tags", "article_reviews",
+					"comments", "comment_counts", "collection_folder_items", "feed_inboxes"} {
+					mock.ExpectExec("DELETE FROM `"+table+"` WHERE art_id IN").
+						WithArgs(int64(1), int64(2)).
+						WillReturnResult(sqlmock.NewResult(0, 1))
+				}
+				for _, table := range []string{"interactives", "user_like_bizs", "user_collection_bizs"} {
+					mock.ExpectExec("DELETE FROM `"+table+"` WHERE biz_id IN .* AND biz = ").
+						WithArgs(int64(1), int64(2), "article").
+						WillReturnResult(sqlmock.NewResult(0, 1))
+				}
+				mock.ExpectExec("DELETE FROM `article_revisions` WHERE article_id IN").
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec("DELETE FROM `published_articles` WHERE id IN").
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec("DELETE FROM `articles` WHERE id IN").
+					WillReturnResult(sqlmock.NewResult(0, 2))
+				mock.ExpectCommit()
+				return mockDB
+			},
+			wantIds: []int64{1, 2},
+		},
+		{
+			name: "没有过期的文章",
+			sqlMock: func(t *testing.T) *sql.DB {
+				mockDB, mock, err := sqlmock.New()
+				require.NoError(t, err)
+				mock.ExpectBegin()
+				mock.ExpectQuery("SELECT `id` FROM `articles`").
+					WillReturnRows(sqlmock.NewRows([]string{"id"}))
+				mock.ExpectCommit()
+				return mockDB
+			},
+		},
+		{
+			name: "删除评论失败，回滚",
+			sqlMock: func(t *testing.T) *sql.DB {
+				mockDB, mock, err := sqlmock.New()
+				require.NoError(t, err)
+				mock.ExpectBegin()
+				mock.ExpectQuery("SELECT `id` FROM `articles`").
+					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+				for _, table := range []string{"published_article_tags", "article_tags", "article_reviews"} {
+					mock.ExpectExec("DELETE FROM `" + table + "`").
+						WillReturnResult(sqlmock.NewResult(0, 1))
+				}
+				mock.ExpectExec("DELETE FROM `comments`").
+					WillReturnError(errors.New("数据库错误"))
+				mock.ExpectRollback()
+				return mockDB
+			},
+			wantErr: errors.New("数据库错误"),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			sqlDB := tc.sqlMock(t)
+			db, err := gorm.Open(mysql.New(mysql.Config{
+				Conn:                      sqlDB,
+				SkipInitializeWithVersion: true,
+			}), &gorm.Config{
+				DisableAutomaticPing:   true,
+				SkipDefaultTransaction: true,
+			})
+			require.NoError(t, err)
+			dao := NewGORMArticleDAO(db)
+			ids, err := dao.PurgeDeleted(context.Background(), "article", 1000, 10)
+			assert.Equal(t, tc.wantErr, err)
+			assert.Equal(t, tc.wantIds, ids)
+		})
+	}
+}
+
+func TestGORMArticleDAO_Withdraw(t *testing.T) {
+	testCases := []struct {
+		name    string
+		sqlMock func(t *testing.T) *sql.DB
+		wantErr error
+	}{
+		{
+			name: "以线上库为准撤回，制作库只更新已发表的状态",
+			sqlMock: func(t *testing.T) *sql.DB {
+				mockDB, mock, err := sqlmock.New()
+				require.NoError(t, err)
+				mock.ExpectBegin()
+				mock.ExpectExec("UPDATE `published_articles` SET .* WHERE id = .* AND author_id = .* AND status = .* AND dtime = .*").
+					WithArgs(uint8(3), sqlmock.AnyArg(), int64(1), int64(123), uint8(2), 0).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				// 制作库里有未发表修改的时候，这里影响 0 行也是正常的
+				mock.ExpectExec("UPDATE `articles` SET .* WHERE id = .* AND status = .*").
+					WithArgs(uint8(3), sqlmock.AnyArg(), int64(1), uint8(2)).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectCommit()
+				return mockDB
+			},
+		},
+		{
+			name: "线上库没有已发表的版本",
+			sqlMock: func(t *testing.T) *sql.DB {
+				mockDB, mock, err := sqlmock.New()
+				require.NoError(t, err)
+				mock.ExpectBegin()
+				mock.ExpectExec("UPDATE `published_articles` SET .*").
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectRollback()
+				return mockDB
+			},
+			wantErr: ErrArticleNotPublished,
+		},
+		{
+			name: "制作库更新失败，回滚",
+			sqlMock: func(t *testing.T) *sql.DB {
+				mockDB, mock, err := sqlmock.New()
+				require.NoError(t, err)
+				mock.ExpectBegin()
+				mock.ExpectExec("UPDATE `published_articles` SET .*").
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec("UPDATE `articles` SET .*").
+					WillReturnError(errors.New("数据库错误"))
+				mock.ExpectRollback()
+				return mockDB
+			},
+			wantErr: errors.New("数据库错误"),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			sqlDB := tc.sqlMock(t)
+			db, err := gorm.Open(mysql.New(mysql.Config{
+				Conn:                      sqlDB,
+				SkipInitializeWithVersion: true,
+			}), &gorm.Config{
+				DisableAutomaticPing:   true,
+				SkipDefaultTransaction: true,
+			})
+			require.NoError(t, err)
+			dao := NewGORMArticleDAO(db)
+			err = dao.Withdraw(context.Background(), 1, 123, 2, 3)
+			assert.Equal(t, tc.wantErr, err)
+		})
+	}
+}
+
+func TestGORMArticleDAO_UpdateById(t *testing.T) {
+	testCases := []struct {
+		name    string
+		art     Article
+		sqlMock func(t *testing.T) *sql.DB
+		wantErr error
+	}{
+		{
+			name: "整体替换标签",
+			art:  Article{Id: 1, AuthorId: 123, Title: "标题", Tags: []string{"go", "gorm"}},
+			sqlMock: func(t *testing.T) *sql.DB {
+				mockDB, mock, err := sqlmock.New()
+				require.NoError(t, err)
+				mock.ExpectBegin()
+				mock.ExpectExec("UPDATE `articles` SET .*`category`=.*").
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec("DELETE FROM `article_tags` WHERE art_id = ?").
+					WithArgs(int64(1)).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec("INSERT INTO `tags` .* ON DUPLICATE KEY UPDATE").
+					WillReturnResult(sqlmock.NewResult(10, 1))
+				mock.ExpectQuery("SELECT \\* FROM `tags` WHERE name IN").
+					WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).
+						AddRow(int64(10), "gorm").AddRow(int64(9), "go"))
+				// 关系表按照作者给出的顺序插入
+				mock.ExpectExec("INSERT INTO `article_tags`").
+					WithArgs(int64(1), int64(9), sqlmock.AnyArg(), int64(1), int64(10), sqlmock.AnyArg()).
+					WillReturnResult(sqlmock.NewResult(1, 2))
+				mock.ExpectExec("INSERT INTO `article_revisions`").
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectCommit()
+				return mockDB
+			},
+		},
+		{
+			name: "不是作者，不动标签",
+			art:  Article{Id: 1, AuthorId: 456, Tags: []string{"go"}},
+			sqlMock: func(t *testing.T) *sql.DB {
+				mockDB, mock, err := sqlmock.New()
+				require.NoError(t, err)
+				mock.ExpectBegin()
+				mock.ExpectExec("UPDATE `articles` SET .*").
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectRollback()
+				return mockDB
+			},
+			wantErr: errors.New("更新失败，可能是用户非法: id = 1, authorId = 456"),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			sqlDB := tc.sqlMock(t)
+			db, err := gorm.Open(mysql.New(mysql.Config{
+				Conn:                      sqlDB,
+				SkipInitializeWithVersion: true,
+			}), &gorm.Config{
+				DisableAutomaticPing:   true,
+				SkipDefaultTransaction: true,
+			})
+			require.NoError(t, err)
+			dao := NewGORMArticleDAO(db)
+			err = dao.UpdateById(context.Background(), tc.art)
+			assert.Equal(t, tc.wantErr, err)
+		})
+	}
+}
+
+func TestGORMArticleDAO_Sync(t *testing.T) {
+	testCases := []struct {
+		name    string
+		art     Article
+		sqlMock func(t *testing.T) *sql.DB
+		wantId  int64
+		wantErr error
+	}{
+		{
+			name: "新建并发表，线上库使用制作库的 id",
+			art:  Article{AuthorId: 123, Title: "标题"},
+			sqlMock: func(t *testing.T) *sql.DB {
+				mockDB, mock, err := sqlmock.New()
+				require.NoError(t, err)
+				mock.ExpectBegin()
+				mock.ExpectExec("SAVEPOINT").WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectExec("INSERT INTO `articles`").
+					WillReturnResult(sqlmock.NewResult(5, 1))
+				mock.ExpectExec("DELETE FROM `article_tags` WHERE art_id = ?").
+					WithArgs(int64(5)).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectExec("INSERT INTO `article_revisions`").
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectExec("SAVEPOINT").WillReturnResult(sqlmock.NewResult(0, 0))
+				// 带上了 id，不会让 MySQL 自增生成新的 id
+				mock.ExpectExec("INSERT INTO `published_articles` \\(.*`id`\\) VALUES .* ON DUPLICATE KEY UPDATE").
+					WillReturnResult(sqlmock.NewResult(5, 1))
+				mock.ExpectExec("DELETE FROM `published_article_tags` WHERE art_id = ?").
+					WithArgs(int64(5)).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectCommit()
+				return mockDB
+			},
+			wantId: 5,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			sqlDB := tc.sqlMock(t)
+			db, err := gorm.Open(mysql.New(mysql.Config{
+				Conn:                      sqlDB,
+				SkipInitializeWithVersion: true,
+			}), &gorm.Config{
+				DisableAutomaticPing:   true,
+				SkipDefaultTransaction: true,
+			})
+			require.NoError(t, err)
+			dao := NewGORMArticleDAO(db)
+			id, err := dao.Sync(context.Background(), tc.art)
+			assert.Equal(t, tc.wantErr, err)
+			assert.Equal(t, tc.wantId, id)
+		})
+	}
+}
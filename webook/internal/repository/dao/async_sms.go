@@ -0,0 +1,129 @@
+package dao
+
+import (
+	"context"
+	"errors"
+	"gorm.io/gorm"
+	"time"
+)
+
+var (
+	ErrNoAsyncSms      = gorm.ErrRecordNotFound
+	ErrAsyncSmsNotHold = errors.New("短信已经被别的 worker 抢走了")
+)
+
+// 和 domain.AsyncSmsStatus 保持一致
+const (
+	asyncSmsStatusWaiting uint8 = iota + 1
+	asyncSmsStatusSending
+	asyncSmsStatusSuccess
+	asyncSmsStatusFailed
+	asyncSmsStatusExpired
+)
+
+// purgedArgs 短信不会再发送之后清空参数，参数里可能有验证码
+const purgedArgs = "[]"
+
+type GORMAsyncSmsDAO struct {
+	db *gorm.DB
+}
+
+func NewGORMAsyncSmsDAO(db *gorm.DB) AsyncSmsDAO {
+	return &GORMAsyncSmsDAO{db: db}
+}
+
+func (dao *GORMAsyncSmsDAO) Insert(ctx context.Context, s AsyncSms) (int64, error) {
+	now := time.Now().UnixMilli()
+	s.Status = asyncSmsStatusWaiting
+	s.NextTime = now
+	s.Ctime = now
+	s.Utime = now
+	err := dao.db.WithContext(ctx).Create(&s).Error
+	return s.Id, err
+}
+
+func (dao *GORMAsyncSmsDAO) Preempt(ctx context.Context, staleBefore int64) (AsyncSms, error) {
+	db := dao.db.WithContext(ctx)
+	for {
+		now := time.Now().UnixMilli()
+		var s AsyncSms
+		err := db.Where("(status = ? AND next_time <= ?) OR (status = ? AND utime < ?)",
+			asyncSmsStatusWaiting, now, asyncSmsStatusSending, staleBefore).
+			Order("next_time ASC").
+			First(&s).Error
+		if err != nil {
+			return AsyncSms{}, err
+		}
+		res := db.Model(&AsyncSms{}).
+			Where("id = ? AND version = ?", s.Id, s.Version).
+			Updates(map[string]any{
+				"status":  asyncSmsStatusSending,
+				"version": s.Version + 1,
+				"utime":   now,
+			})
+		if res.Error != nil {
+			return AsyncSms{}, res.Error
+		}
+		if res.RowsAffected == 1 {
+			s.Status = asyncSmsStatusSending
+			s.Version++
+			s.Utime = now
+			return s, nil
+		}
+		// 被别的 worker 抢先了，再找下一条
+	}
+}
+
+func (dao *GORMAsyncSmsDAO) MarkSuccess(ctx context.Context, id int64, version int64) error {
+	return dao.updateHeld(ctx, id, version, map[string]any{
+		"status": asyncSmsStatusSuccess,
+		"args":   purgedArgs,
+		"utime":  time.Now().UnixMilli(),
+	})
+}
+
+func (dao *GORMAsyncSmsDAO) MarkRetry(ctx context.Context, id int64, version int64, numbers string,
+	retryCnt int, nextTime int64, lastErr string) error {
+	return dao.updateHeld(ctx, id, version, map[string]any{
+		"status":    asyncSmsStatusWaiting,
+		"numbers":   numbers,
+		"retry_cnt": retryCnt,
+		"next_time": nextTime,
+		"last_err":  lastErr,
+		"utime":     time.Now().UnixMilli(),
+	})
+}
+
+func (dao *GORMAsyncSmsDAO) MarkFailed(ctx context.Context, id int64, version int64,
+	retryCnt int, lastErr string) error {
+	return dao.updateHeld(ctx, id, version, map[string]any{
+		"status":    asyncSmsStatusFailed,
+		"args":      purgedArgs,
+		"retry_cnt": retryCnt,
+		"last_err":  lastErr,
+		"utime":     time.Now().UnixMilli(),
+	})
+}
+
+func (dao *GORMAsyncSmsDAO) MarkExpired(ctx context.Context, id int64, version int64, lastErr string) error {
+	return dao.updateHeld(ctx, id, version, map[string]any{
+		"status":   asyncSmsStatusExpired,
+		"args":     purgedArgs,
+		"last_err": lastErr,
+		"utime":    time.Now().UnixMilli(),
+	})
+}
+
+// updateHeld 只有短信还是自己抢到的时候才更新
+func (dao *GORMAsyncSmsDAO) updateHeld(ctx context.Context, id int64, version int64, updates map[string]any) error {
+	res := dao.db.WithContext(ctx).Model(&AsyncSms{}).
+		Where("id = ? AND version = ? AND status = ?", id, version, asyncSmsStatusSending).
+		Updates(updates)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return ErrAsyncSmsNotHold
+	}
+	return nil
+}
@@ -0,0 +1,156 @@
+package dao
+
+import (
+	"context"
+	"database/sql"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+	"testing"
+)
+
+func TestGORMAsyncSmsDAO_Preempt(t *testing.T) {
+	testCases := []struct {
+		name    string
+		sqlMock func(t *testing.T) *sql.DB
+		wantSms AsyncSms
+		wantErr error
+	}{
+		{
+			name: "抢占成功",
+			sqlMock: func(t *testing.T) *sql.DB {
+				mockDB, mock, err := sqlmock.New()
+				require.NoError(t, err)
+				rows := sqlmock.NewRows([]string{"id", "tpl_id", "status", "retry_cnt", "version"}).
+					AddRow(1, "tpl", asyncSmsStatusWaiting, 1, 3)
+				mock.ExpectQuery("SELECT \\* FROM `async_sms` WHERE .*").WillReturnRows(rows)
+				mock.ExpectExec("UPDATE `async_sms` SET .* WHERE id = .* AND version = .*").
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				return mockDB
+			},
+			wantSms: AsyncSms{Id: 1, TplId: "tpl", Status: asyncSmsStatusSending, RetryCnt: 1, Version: 4},
+		},
+		{
+			name: "被别的 worker 抢先了，再找下一条",
+			sqlMock: func(t *testing.T) *sql.DB {
+				mockDB, mock, err := sqlmock.New()
+				require.NoError(t, err)
+				mock.ExpectQuery("SELECT \\* FROM `async_sms` WHERE .*").
+					WillReturnRows(sqlmock.NewRows([]string{"id", "status", "version"}).
+						AddRow(1, asyncSmsStatusWaiting, 3))
+				mock.ExpectExec("UPDATE `async_sms` SET .*").
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectQuery("SELECT \\* FROM `async_sms` WHERE .*").
+					WillReturnRows(sqlmock.NewRows([]string{"id", "status", "version"}).
+						AddRow(2, asyncSmsStatusSending, 1))
+				mock.ExpectExec("UPDATE `async_sms` SET .*").
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				return mockDB
+			},
+			wantSms: AsyncSms{Id: 2, Status: asyncSmsStatusSending, Version: 2},
+		},
+		{
+			name: "没有要发送的短信",
+			sqlMock: func(t *testing.T) *sql.DB {
+				mockDB, mock, err := sqlmock.New()
+				require.NoError(t, err)
+				mock.ExpectQuery("SELECT \\* FROM `async_sms` WHERE .*").
+					WillReturnRows(sqlmock.NewRows([]string{"id"}))
+				return mockDB
+			},
+			wantErr: ErrNoAsyncSms,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			db := newAsyncSmsTestDB(t, tc.sqlMock(t))
+			dao := NewGORMAsyncSmsDAO(db)
+			s, err := dao.Preempt(context.Background(), 0)
+			assert.Equal(t, tc.wantErr, err)
+			if err != nil {
+				return
+			}
+			// utime 是抢占的时候设置的
+			assert.True(t, s.Utime > 0)
+			s.Utime = 0
+			assert.Equal(t, tc.wantSms, s)
+		})
+	}
+}
+
+func TestGORMAsyncSmsDAO_MarkRetry(t *testing.T) {
+	testCases := []struct {
+		name    string
+		sqlMock func(t *testing.T) *sql.DB
+		wantErr error
+	}{
+		{
+			name: "更新成功",
+			sqlMock: func(t *testing.T) *sql.DB {
+				mockDB, mock, err := sqlmock.New()
+				require.NoError(t, err)
+				mock.ExpectExec("UPDATE `async_sms` SET .* WHERE id = .* AND version = .* AND status = .*").
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				return mockDB
+			},
+		},
+		{
+			name: "已经被别的 worker 抢走了",
+			sqlMock: func(t *testing.T) *sql.DB {
+				mockDB, mock, err := sqlmock.New()
+				require.NoError(t, err)
+				mock.ExpectExec("UPDATE `async_sms` SET .*").
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				return mockDB
+			},
+			wantErr: ErrAsyncSmsNotHold,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			db := newAsyncSmsTestDB(t, tc.sqlMock(t))
+			dao := NewGORMAsyncSmsDAO(db)
+			err := dao.MarkRetry(context.Background(), 1, 2, `["13800000000"]`, 1, 123, "服务商出错")
+			assert.Equal(t, tc.wantErr, err)
+		})
+	}
+}
+
+func TestGORMAsyncSmsDAO_MarkSuccess(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	// 发送成功之后清空参数，验证码不会一直留在数据库里
+	mock.ExpectExec("UPDATE `async_sms` SET `args`=\\?,`status`=\\?,`utime`=\\? WHERE id = .* AND version = .* AND status = .*").
+		WithArgs("[]", asyncSmsStatusSuccess, sqlmock.AnyArg(), int64(1), int64(2), asyncSmsStatusSending).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	dao := NewGORMAsyncSmsDAO(newAsyncSmsTestDB(t, mockDB))
+	err = dao.MarkSuccess(context.Background(), 1, 2)
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGORMAsyncSmsDAO_MarkExpired(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	mock.ExpectExec("UPDATE `async_sms` SET `args`=\\?,`last_err`=\\?,`status`=\\?,`utime`=\\? WHERE .*").
+		WithArgs("[]", "短信已经过期", asyncSmsStatusExpired, sqlmock.AnyArg(), int64(1), int64(2), asyncSmsStatusSending).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	dao := NewGORMAsyncSmsDAO(newAsyncSmsTestDB(t, mockDB))
+	err = dao.MarkExpired(context.Background(), 1, 2, "短信已经过期")
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func newAsyncSmsTestDB(t *testing.T, sqlDB *sql.DB) *gorm.DB {
+	db, err := gorm.Open(mysql.New(mysql.Config{
+		Conn:                      sqlDB,
+		SkipInitializeWithVersion: true,
+	}), &gorm.Config{
+		DisableAutomaticPing:   true,
+		SkipDefaultTransaction: true,
+	})
+	require.NoError(t, err)
+	return db
+}
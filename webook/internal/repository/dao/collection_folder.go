@@ -0,0 +1,103 @@
+package dao
+
+import (
+	"context"
+	"errors"
+	"gorm.io/gorm"
+	"time"
+)
+
+var (
+	ErrFolderNotFound      = gorm.ErrRecordNotFound
+	ErrFolderNameDuplicate = errors.New("收藏夹重名")
+	ErrFolderItemDuplicate = errors.New("文章已经在收藏夹里了")
+)
+
+type GORMCollectionFolderDAO struct {
+	db *gorm.DB
+}
+
+func NewGORMCollectionFolderDAO(db *gorm.DB) CollectionFolderDAO {
+	return &GORMCollectionFolderDAO{db: db}
+}
+
+func (dao *GORMCollectionFolderDAO) Insert(ctx context.Context, folder CollectionFolder) (int64, error) {
+	now := time.Now().UnixMilli()
+	folder.Ctime = now
+	folder.Utime = now
+	err := dao.db.WithContext(ctx).Create(&folder).Error
+	if isUniqueConflict(err) {
+		return 0, ErrFolderNameDuplicate
+	}
+	return folder.Id, err
+}
+
+func (dao *GORMCollectionFolderDAO) UpdateName(ctx context.Context, id int64, uid int64, name string) error {
+	res := dao.db.WithContext(ctx).Model(&CollectionFolder{}).
+		Where("id = ? AND uid = ?", id, uid).
+		Updates(map[string]any{
+			"name":  name,
+			"utime": time.Now().UnixMilli(),
+		})
+	if isUniqueConflict(res.Error) {
+		return ErrFolderNameDuplicate
+	}
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return ErrFolderNotFound
+	}
+	return nil
+}
+
+func (dao *GORMCollectionFolderDAO) Delete(ctx context.Context, id int64, uid int64) error {
+	return dao.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		res := tx.Where("id = ? AND uid = ?", id, uid).Delete(&CollectionFolder{})
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return ErrFolderNotFound
+		}
+		return tx.Where("fid = ?", id).Delete(&CollectionFolderItem{}).Error
+	})
+}
+
+func (dao *GORMCollectionFolderDAO) GetById(ctx context.Context, id int64) (CollectionFolder, error) {
+	var folder CollectionFolder
+	err := dao.db.WithContext(ctx).Where("id = ?", id).First(&folder).Error
+	return folder, err
+}
+
+func (dao *GORMCollectionFolderDAO) ListByUid(ctx context.Context, uid int64, offset int, limit int) ([]CollectionFolder, error) {
+	var folders []CollectionFolder
+	err := dao.db.WithContext(ctx).Where("uid = ?", uid).
+		Order("id DESC").
+		Offset(offset).Limit(limit).
+		Find(&folders).Error
+	return folders, err
+}
+
+func (dao *GORMCollectionFolderDAO) InsertItem(ctx context.Context, item CollectionFolderItem) error {
+	item.Ctime = time.Now().UnixMilli()
+	err := dao.db.WithContext(ctx).Create(&item).Error
+	if isUniqueConflict(err) {
+		return ErrFolderItemDuplicate
+	}
+	return err
+}
+
+func (dao *GORMCollectionFolderDAO) DeleteItem(ctx context.Context, fid int64, artId int64) error {
+	return dao.db.WithContext(ctx).Where("fid = ? AND art_id = ?", fid, artId).
+		Delete(&CollectionFolderItem{}).Error
+}
+
+func (dao *GORMCollectionFolderDAO) ListItems(ctx context.Context, fid int64, offset int, limit int) ([]CollectionFolderItem, error) {
+	var items []CollectionFolderItem
+	err := dao.db.WithContext(ctx).Where("fid = ?", fid).
+		Order("id DESC").
+		Offset(offset).Limit(limit).
+		Find(&items).Error
+	return items, err
+}
@@ -0,0 +1,114 @@
+package dao
+
+import (
+	"context"
+	"errors"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"time"
+)
+
+var ErrCommentNotFound = gorm.ErrRecordNotFound
+
+type GORMCommentDAO struct {
+	db *gorm.DB
+}
+
+func NewGORMCommentDAO(db *gorm.DB) CommentDAO {
+	return &GORMCommentDAO{db: db}
+}
+
+func (dao *GORMCommentDAO) Insert(ctx context.Context, c Comment) (int64, error) {
+	now := time.Now().UnixMilli()
+	c.Ctime = now
+	c.Utime = now
+	err := dao.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Create(&c).Error
+		if err != nil {
+			return err
+		}
+		if c.RootId > 0 {
+			err = tx.Model(&Comment{}).Where("id = ?", c.RootId).
+				Update("reply_cnt", gorm.Expr("`reply_cnt` + 1")).Error
+			if err != nil {
+				return err
+			}
+		}
+		return dao.updateCount(tx, c.ArtId, 1)
+	})
+	return c.Id, err
+}
+
+func (dao *GORMCommentDAO) Delete(ctx context.Context, c Comment) error {
+	return dao.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var res *gorm.DB
+		if c.RootId == 0 {
+			res = tx.Where("id = ? OR root_id = ?", c.Id, c.Id).Delete(&Comment{})
+		} else {
+			res = tx.Where("id = ?", c.Id).Delete(&Comment{})
+		}
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			// 并发删除，已经被删掉了
+			return ErrCommentNotFound
+		}
+		if c.RootId > 0 {
+			err := tx.Model(&Comment{}).Where("id = ?", c.RootId).
+				Update("reply_cnt", gorm.Expr("`reply_cnt` - 1")).Error
+			if err != nil {
+				return err
+			}
+		}
+		return dao.updateCount(tx, c.ArtId, -res.RowsAffected)
+	})
+}
+
+func (dao *GORMCommentDAO) GetById(ctx context.Context, id int64) (Comment, error) {
+	var c Comment
+	err := dao.db.WithContext(ctx).Where("id = ?", id).First(&c).Error
+	return c, err
+}
+
+func (dao *GORMCommentDAO) FindRoots(ctx context.Context, artId int64, minId int64, limit int) ([]Comment, error) {
+	var res []Comment
+	query := dao.db.WithContext(ctx).Where("art_id = ? AND root_id = ?", artId, 0)
+	if minId > 0 {
+		query = query.Where("id < ?", minId)
+	}
+	err := query.Order("id DESC").Limit(limit).Find(&res).Error
+	return res, err
+}
+
+func (dao *GORMCommentDAO) FindReplies(ctx context.Context, rootId int64, maxId int64, limit int) ([]Comment, error) {
+	var res []Comment
+	err := dao.db.WithContext(ctx).Where("root_id = ? AND id > ?", rootId, maxId).
+		Order("id ASC").Limit(limit).Find(&res).Error
+	return res, err
+}
+
+func (dao *GORMCommentDAO) GetCount(ctx context.Context, artId int64) (int64, error) {
+	var cnt CommentCount
+	err := dao.db.WithContext(ctx).Where("art_id = ?", artId).First(&cnt).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return 0, nil
+	}
+	return cnt.Cnt, err
+}
+
+// updateCount 修改文章的评论数，没有记录的时候插入一条
+func (dao *GORMCommentDAO) updateCount(tx *gorm.DB, artId int64, delta int64) error {
+	now := time.Now().UnixMilli()
+	return tx.Clauses(clause.OnConflict{
+		DoUpdates: clause.Assignments(map[string]any{
+			"cnt":   gorm.Expr("`cnt` + ?", delta),
+			"utime": now,
+		}),
+	}).Create(&CommentCount{
+		ArtId: artId,
+		Cnt:   delta,
+		Ctime: now,
+		Utime: now,
+	}).Error
+}
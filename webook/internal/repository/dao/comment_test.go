@@ -0,0 +1,89 @@
+package dao
+
+import (
+	"context"
+	"database/sql"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+	"testing"
+)
+
+func TestGORMCommentDAO_Delete(t *testing.T) {
+	testCases := []struct {
+		name    string
+		sqlMock func(t *testing.T) *sql.DB
+		c       Comment
+		wantErr error
+	}{
+		{
+			name: "删除根评论，回复一起删除",
+			sqlMock: func(t *testing.T) *sql.DB {
+				mockDB, mock, err := sqlmock.New()
+				require.NoError(t, err)
+				mock.ExpectBegin()
+				mock.ExpectExec("DELETE FROM `comments` WHERE id = .* OR root_id = .*").
+					WithArgs(int64(10), int64(10)).
+					WillReturnResult(sqlmock.NewResult(0, 3))
+				// 一条根评论加两条回复
+				mock.ExpectExec("INSERT INTO `comment_counts` .* ON DUPLICATE KEY UPDATE `cnt`=`cnt` \\+ .*").
+					WithArgs(int64(1), int64(-3), sqlmock.AnyArg(), sqlmock.AnyArg(), int64(-3), sqlmock.AnyArg()).
+					WillReturnResult(sqlmock.NewResult(0, 2))
+				mock.ExpectCommit()
+				return mockDB
+			},
+			c: Comment{Id: 10, ArtId: 1},
+		},
+		{
+			name: "删除回复，扣减根评论的回复数",
+			sqlMock: func(t *testing.T) *sql.DB {
+				mockDB, mock, err := sqlmock.New()
+				require.NoError(t, err)
+				mock.ExpectBegin()
+				mock.ExpectExec("DELETE FROM `comments` WHERE id = .*").
+					WithArgs(int64(11)).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec("UPDATE `comments` SET `reply_cnt`=`reply_cnt` - 1 WHERE id = .*").
+					WithArgs(int64(10)).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec("INSERT INTO `comment_counts` .*").
+					WillReturnResult(sqlmock.NewResult(0, 2))
+				mock.ExpectCommit()
+				return mockDB
+			},
+			c: Comment{Id: 11, ArtId: 1, RootId: 10, ParentId: 10},
+		},
+		{
+			name: "已经被删除了",
+			sqlMock: func(t *testing.T) *sql.DB {
+				mockDB, mock, err := sqlmock.New()
+				require.NoError(t, err)
+				mock.ExpectBegin()
+				mock.ExpectExec("DELETE FROM `comments` WHERE id = .*").
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectRollback()
+				return mockDB
+			},
+			c:       Comment{Id: 11, ArtId: 1, RootId: 10, ParentId: 10},
+			wantErr: ErrCommentNotFound,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			sqlDB := tc.sqlMock(t)
+			db, err := gorm.Open(mysql.New(mysql.Config{
+				Conn:                      sqlDB,
+				SkipInitializeWithVersion: true,
+			}), &gorm.Config{
+				DisableAutomaticPing:   true,
+				SkipDefaultTransaction: true,
+			})
+			require.NoError(t, err)
+			dao := NewGORMCommentDAO(db)
+			err = dao.Delete(context.Background(), tc.c)
+			assert.Equal(t, tc.wantErr, err)
+		})
+	}
+}
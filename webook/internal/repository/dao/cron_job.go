@@ -0,0 +1,102 @@
+package dao
+
+import (
+	"context"
+	"errors"
+	"gorm.io/gorm"
+	"time"
+)
+
+var (
+	ErrNoCronJob      = gorm.ErrRecordNotFound
+	ErrCronJobNotHold = errors.New("任务已经被别的实例抢走了")
+)
+
+// 和 domain.CronJobStatus 保持一致
+const (
+	cronJobStatusWaiting uint8 = iota + 1
+	cronJobStatusRunning
+	cronJobStatusPaused
+)
+
+type GORMCronJobDAO struct {
+	db *gorm.DB
+}
+
+func NewGORMCronJobDAO(db *gorm.DB) CronJobDAO {
+	return &GORMCronJobDAO{db: db}
+}
+
+func (dao *GORMCronJobDAO) Preempt(ctx context.Context, staleBefore int64) (CronJob, error) {
+	db := dao.db.WithContext(ctx)
+	for {
+		now := time.Now().UnixMilli()
+		var j CronJob
+		// 到时间的任务，或者抢到的实例很久没有续约了
+		err := db.Where("(status = ? AND next_time <= ?) OR (status = ? AND utime < ?)",
+			cronJobStatusWaiting, now, cronJobStatusRunning, staleBefore).
+			First(&j).Error
+		if err != nil {
+			return CronJob{}, err
+		}
+		res := db.Model(&CronJob{}).
+			Where("id = ? AND version = ?", j.Id, j.Version).
+			Updates(map[string]any{
+				"status":  cronJobStatusRunning,
+				"version": j.Version + 1,
+				"utime":   now,
+			})
+		if res.Error != nil {
+			return CronJob{}, res.Error
+		}
+		if res.RowsAffected == 1 {
+			j.Status = cronJobStatusRunning
+			j.Version++
+			j.Utime = now
+			return j, nil
+		}
+		// 被别的实例抢先了，再找下一个
+	}
+}
+
+func (dao *GORMCronJobDAO) UpdateUtime(ctx context.Context, id int64, version int64) error {
+	res := dao.db.WithContext(ctx).Model(&CronJob{}).
+		Where("id = ? AND version = ? AND status = ?", id, version, cronJobStatusRunning).
+		Update("utime", time.Now().UnixMilli())
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return ErrCronJobNotHold
+	}
+	return nil
+}
+
+func (dao *GORMCronJobDAO) Release(ctx context.Context, id int64, version int64, nextTime int64) error {
+	return dao.updateHeld(ctx, id, version, map[string]any{
+		"status":    cronJobStatusWaiting,
+		"next_time": nextTime,
+		"utime":     time.Now().UnixMilli(),
+	})
+}
+
+func (dao *GORMCronJobDAO) Pause(ctx context.Context, id int64, version int64) error {
+	return dao.updateHeld(ctx, id, version, map[string]any{
+		"status": cronJobStatusPaused,
+		"utime":  time.Now().UnixMilli(),
+	})
+}
+
+// updateHeld 只有任务还是自己的时候才更新
+func (dao *GORMCronJobDAO) updateHeld(ctx context.Context, id int64, version int64, updates map[string]any) error {
+	res := dao.db.WithContext(ctx).Model(&CronJob{}).
+		Where("id = ? AND version = ? AND status = ?", id, version, cronJobStatusRunning).
+		Updates(updates)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return ErrCronJobNotHold
+	}
+	return nil
+}
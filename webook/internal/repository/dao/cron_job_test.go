@@ -0,0 +1,90 @@
+package dao
+
+import (
+	"context"
+	"database/sql"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+	"testing"
+)
+
+func TestGORMCronJobDAO_Preempt(t *testing.T) {
+	testCases := []struct {
+		name    string
+		sqlMock func(t *testing.T) *sql.DB
+		wantJob CronJob
+		wantErr error
+	}{
+		{
+			name: "抢占成功",
+			sqlMock: func(t *testing.T) *sql.DB {
+				mockDB, mock, err := sqlmock.New()
+				require.NoError(t, err)
+				rows := sqlmock.NewRows([]string{"id", "name", "executor", "status", "version"}).
+					AddRow(1, "ranking", "local", cronJobStatusWaiting, 3)
+				mock.ExpectQuery("SELECT \\* FROM `cron_jobs` WHERE .*").WillReturnRows(rows)
+				mock.ExpectExec("UPDATE `cron_jobs` SET .* WHERE id = .* AND version = .*").
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				return mockDB
+			},
+			wantJob: CronJob{Id: 1, Name: "ranking", Executor: "local",
+				Status: cronJobStatusRunning, Version: 4},
+		},
+		{
+			name: "被别人抢先了，再找下一个",
+			sqlMock: func(t *testing.T) *sql.DB {
+				mockDB, mock, err := sqlmock.New()
+				require.NoError(t, err)
+				mock.ExpectQuery("SELECT \\* FROM `cron_jobs` WHERE .*").
+					WillReturnRows(sqlmock.NewRows([]string{"id", "name", "status", "version"}).
+						AddRow(1, "ranking", cronJobStatusWaiting, 3))
+				mock.ExpectExec("UPDATE `cron_jobs` SET .*").
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectQuery("SELECT \\* FROM `cron_jobs` WHERE .*").
+					WillReturnRows(sqlmock.NewRows([]string{"id", "name", "status", "version"}).
+						AddRow(2, "recycle_bin_purge", cronJobStatusWaiting, 1))
+				mock.ExpectExec("UPDATE `cron_jobs` SET .*").
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				return mockDB
+			},
+			wantJob: CronJob{Id: 2, Name: "recycle_bin_purge", Status: cronJobStatusRunning, Version: 2},
+		},
+		{
+			name: "没有到时间的任务",
+			sqlMock: func(t *testing.T) *sql.DB {
+				mockDB, mock, err := sqlmock.New()
+				require.NoError(t, err)
+				mock.ExpectQuery("SELECT \\* FROM `cron_jobs` WHERE .*").
+					WillReturnRows(sqlmock.NewRows([]string{"id"}))
+				return mockDB
+			},
+			wantErr: ErrNoCronJob,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			sqlDB := tc.sqlMock(t)
+			db, err := gorm.Open(mysql.New(mysql.Config{
+				Conn:                      sqlDB,
+				SkipInitializeWithVersion: true,
+			}), &gorm.Config{
+				DisableAutomaticPing:   true,
+				SkipDefaultTransaction: true,
+			})
+			require.NoError(t, err)
+			dao := NewGORMCronJobDAO(db)
+			j, err := dao.Preempt(context.Background(), 0)
+			assert.Equal(t, tc.wantErr, err)
+			if err != nil {
+				return
+			}
+			// utime 是抢占的时候设置的
+			assert.True(t, j.Utime > 0)
+			j.Utime = 0
+			assert.Equal(t, tc.wantJob, j)
+		})
+	}
+}
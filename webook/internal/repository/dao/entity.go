@@ -32,6 +32,9 @@ type Article struct {
 	Title string `gorm:"type=varchar(1024)"`
 	// 对于关系型数据库用
 	Content string `gorm:"type=BLOB"`
+	// Html 渲染之后的内容，Summary 是列表页用的纯文本摘要
+	Html    string `gorm:"type:mediumtext"`
+	Summary string `gorm:"type:varchar(1024)"`
 	// 如何设计索引
 	// 最常用的就是在 WHERE 的字段上创建
 	// 在帖子这里，查询场景是什么样的？
@@ -48,13 +51,270 @@ type Article struct {
 	//Ctime    int64 `gorm:"index=aid_ctime"`
 
 	// 2. 在 author_id上创建索引
-	AuthorId int64 `gorm:"index"`
-	Status   uint8
-	Ctime    int64
-	Utime    int64
+	//AuthorId int64 `gorm:"index"`
+
+	// 创作者列表是按照更新时间倒序的，并且用 (utime, id) 作为游标分页
+	// 所以在 author_id 和 utime 上创建联合索引
+	// 	SELECT * FROM articles WHERE author_id = xxx ORDER BY utime DESC, id DESC;
+	// 关注流按照作者和发表时间查询线上库，所以还有 author_id 和 ctime 的联合索引
+	AuthorId int64 `gorm:"index:aid_utime;index:aid_ctime"`
+	// 管理员按照状态查询等待审核的文章，先提交的先审核
+	Status uint8 `gorm:"index:status_utime"`
+	// 线上库的 ctime 就是第一次发表的时间，之后修改、撤回再发表都不会变
+	// 热榜按照发表时间查询最近的文章
+	Ctime int64 `gorm:"index;index:aid_ctime"`
+	Utime int64 `gorm:"index:aid_utime;index:status_utime"`
+	// Dtime 放进回收站的时间，毫秒数，0 表示没有删除
+	// 后台任务按照这个字段清理过期的文章
+	Dtime int64 `gorm:"index"`
+	// PublishAt 定时发表的时间，毫秒数，0 表示没有定时
+	// 启动的时候按照状态把等待发表的文章加载出来，数量不会很多，不需要索引
+	PublishAt int64
+	// Category 固定的分类
+	Category uint8
+	// Tags 标签名字，存在 ArticleTag 和 PublishedArticleTag 里面
+	// 写入的时候整体替换，只有查询详情的时候才会填充
+	Tags []string `gorm:"-"`
 }
 
 // PublishedArticle 代表线上库的文章
 type PublishedArticle struct {
 	Article
 }
+
+// Tag 标签，规范化之后的名字是唯一的，所有文章共用
+type Tag struct {
+	Id    int64  `gorm:"primaryKey,autoIncrement"`
+	Name  string `gorm:"type:varchar(128);unique"`
+	Ctime int64
+}
+
+// ArticleTag 制作库的文章和标签的关系，id 的顺序就是作者给出的顺序
+type ArticleTag struct {
+	Id    int64 `gorm:"primaryKey,autoIncrement"`
+	ArtId int64 `gorm:"uniqueIndex:art_id_tag_id"`
+	// 按照标签查询文章用得上
+	TagId int64 `gorm:"uniqueIndex:art_id_tag_id;index"`
+	Ctime int64
+}
+
+// PublishedArticleTag 线上库的文章和标签的关系
+type PublishedArticleTag struct {
+	ArticleTag
+}
+
+// TagCount 标签以及带有这个标签的文章数量
+type TagCount struct {
+	Name string
+	Cnt  int64
+}
+
+// ArticleReview 文章的审核记录，作者查看最近一次的结果
+type ArticleReview struct {
+	Id       int64 `gorm:"primaryKey,autoIncrement"`
+	ArtId    int64 `gorm:"index:art_id_ctime"`
+	AuthorId int64
+	Reviewer int64
+	Result   uint8
+	Reason   string `gorm:"type:varchar(1024)"`
+	Ctime    int64  `gorm:"index:art_id_ctime"`
+}
+
+// AsyncSms 异步发送的短信，worker 按照 next_time 抢占发送
+type AsyncSms struct {
+	Id    int64  `gorm:"primaryKey,autoIncrement"`
+	TplId string `gorm:"type:varchar(128)"`
+	// Args 和 Numbers 都是 JSON 数组
+	Args     string `gorm:"type:text"`
+	Numbers  string `gorm:"type:text"`
+	Status   uint8  `gorm:"index:status_next_time"`
+	RetryCnt int
+	MaxRetry int
+	LastErr  string `gorm:"type:varchar(1024)"`
+	NextTime int64  `gorm:"index:status_next_time"`
+	// ExpireAt 0 表示不会过期
+	ExpireAt int64
+	Version  int64
+	Ctime    int64
+	Utime    int64
+}
+
+// UploadObject 上传的文件，按照内容的摘要去重
+type UploadObject struct {
+	Id          int64  `gorm:"primaryKey,autoIncrement"`
+	Hash        string `gorm:"type:char(64);unique"`
+	Key         string `gorm:"type:varchar(255)"`
+	Size        int64
+	ContentType string `gorm:"type:varchar(128)"`
+	Uid         int64
+	Ctime       int64
+}
+
+// Role 角色，权限挂在角色上
+type Role struct {
+	Id    int64  `gorm:"primaryKey,autoIncrement"`
+	Name  string `gorm:"type:varchar(64);unique"`
+	Ctime int64
+	Utime int64
+}
+
+// RolePermission 角色拥有的权限
+type RolePermission struct {
+	Id         int64  `gorm:"primaryKey,autoIncrement"`
+	RoleId     int64  `gorm:"uniqueIndex:role_id_permission"`
+	Permission string `gorm:"type:varchar(64);uniqueIndex:role_id_permission"`
+	Ctime      int64
+}
+
+// UserRole 用户拥有的角色
+type UserRole struct {
+	Id     int64 `gorm:"primaryKey,autoIncrement"`
+	Uid    int64 `gorm:"uniqueIndex:uid_role_id"`
+	RoleId int64 `gorm:"uniqueIndex:uid_role_id"`
+	Ctime  int64
+}
+
+// ArticleRevision 文章的历史版本
+// 每一次保存和发表都会记录一个版本，方便找回被覆盖的内容
+type ArticleRevision struct {
+	Id int64 `gorm:"primaryKey,autoIncrement"`
+	// 查询场景是查看某篇文章的历史版本，按照时间倒序
+	ArticleId int64 `gorm:"index:aid_ctime"`
+	AuthorId  int64
+	Title     string `gorm:"type=varchar(1024)"`
+	Content   string `gorm:"type=BLOB"`
+	Status    uint8
+	Ctime     int64 `gorm:"index:aid_ctime"`
+}
+
+// Interactive 互动计数，每个资源一行
+type Interactive struct {
+	Id int64 `gorm:"primaryKey,autoIncrement"`
+	// 查询的时候总是同时带上 biz 和 biz_id
+	// biz_id 的区分度更高，放在前面
+	BizId      int64  `gorm:"uniqueIndex:biz_type_id"`
+	Biz        string `gorm:"type:varchar(128);uniqueIndex:biz_type_id"`
+	ReadCnt    int64
+	LikeCnt    int64
+	CollectCnt int64
+	Ctime      int64
+	Utime      int64
+}
+
+// UserLikeBiz 用户点赞记录
+// 取消点赞只修改状态，不删除数据，文章彻底删除的时候按照 biz_id 和 biz 删除
+type UserLikeBiz struct {
+	Id    int64  `gorm:"primaryKey,autoIncrement"`
+	Uid   int64  `gorm:"uniqueIndex:uid_biz_type_id"`
+	BizId int64  `gorm:"uniqueIndex:uid_biz_type_id;index:biz_type_id"`
+	Biz   string `gorm:"type:varchar(128);uniqueIndex:uid_biz_type_id;index:biz_type_id"`
+	// 1 表示点赞，0 表示取消了点赞
+	Status uint8
+	Ctime  int64
+	Utime  int64
+}
+
+// UserCollectionBiz 用户收藏记录
+type UserCollectionBiz struct {
+	Id    int64  `gorm:"primaryKey,autoIncrement"`
+	Uid   int64  `gorm:"uniqueIndex:uid_biz_type_id"`
+	BizId int64  `gorm:"uniqueIndex:uid_biz_type_id;index:biz_type_id"`
+	Biz   string `gorm:"type:varchar(128);uniqueIndex:uid_biz_type_id;index:biz_type_id"`
+	Ctime int64
+	Utime int64
+}
+
+// CollectionFolder 收藏夹，只有创建者自己能看到
+type CollectionFolder struct {
+	Id int64 `gorm:"primaryKey,autoIncrement"`
+	// 同一个用户的收藏夹不能重名，查询列表的时候也用得上
+	Uid   int64  `gorm:"uniqueIndex:uid_name"`
+	Name  string `gorm:"type:varchar(128);uniqueIndex:uid_name"`
+	Ctime int64
+	Utime int64
+}
+
+// CollectionFolderItem 收藏夹里面的文章，同一篇文章可以放进多个收藏夹
+type CollectionFolderItem struct {
+	Id  int64 `gorm:"primaryKey,autoIncrement"`
+	Fid int64 `gorm:"uniqueIndex:fid_art_id"`
+	// 文章彻底删除的时候按照 art_id 删除
+	ArtId int64 `gorm:"uniqueIndex:fid_art_id;index"`
+	Ctime int64
+}
+
+// Comment 评论，只有两层：根评论和它下面的回复
+// 回复的回复也挂在同一个根评论下面，用 ParentId 记录回复的是哪一条
+type Comment struct {
+	Id int64 `gorm:"primaryKey,autoIncrement"`
+	// 按照文章分页查询根评论，根评论的 root_id 是 0
+	ArtId  int64 `gorm:"index:art_root_id"`
+	RootId int64 `gorm:"index:art_root_id;index"`
+	// 0 表示根评论
+	ParentId int64
+	Uid      int64
+	Content  string `gorm:"type:text"`
+	// 根评论下面有多少条回复，回复自己的这个字段没有用
+	ReplyCnt int64
+	Ctime    int64
+	Utime    int64
+}
+
+// CommentCount 每篇文章的评论数，包括回复
+type CommentCount struct {
+	Id    int64 `gorm:"primaryKey,autoIncrement"`
+	ArtId int64 `gorm:"uniqueIndex"`
+	Cnt   int64
+	Ctime int64
+	Utime int64
+}
+
+// CronJob 定时任务，一行一个任务
+type CronJob struct {
+	Id       int64  `gorm:"primaryKey,autoIncrement"`
+	Name     string `gorm:"type:varchar(128);unique"`
+	Executor string `gorm:"type:varchar(128)"`
+	// cron 表达式
+	Expression string `gorm:"type:varchar(128)"`
+	Cfg        string `gorm:"type:text"`
+	// 抢占的时候按照状态和下一次运行时间查询
+	Status   uint8 `gorm:"index:status_next_time"`
+	NextTime int64 `gorm:"index:status_next_time"`
+	// 乐观锁，每一次抢占都会加一
+	Version int64
+	Ctime   int64
+	// 运行中的任务会不断更新这个字段，很久没更新说明抢到的实例已经挂了
+	Utime int64
+}
+
+// FollowRelation 关注关系，取消关注直接删掉
+type FollowRelation struct {
+	Id int64 `gorm:"primaryKey,autoIncrement"`
+	// 查询关注了哪些人走唯一索引的最左前缀，关注流按照关注时间倒序查询最近关注的人
+	Follower int64 `gorm:"uniqueIndex:follower_followee;index:follower_ctime"`
+	// 查询粉丝列表
+	Followee int64 `gorm:"uniqueIndex:follower_followee;index"`
+	Ctime    int64 `gorm:"index:follower_ctime"`
+	Utime    int64
+}
+
+// FollowStatics 用户的关注数和粉丝数，每个用户一行
+type FollowStatics struct {
+	Id        int64 `gorm:"primaryKey,autoIncrement"`
+	Uid       int64 `gorm:"uniqueIndex"`
+	Followers int64
+	Followees int64
+	Ctime     int64
+	Utime     int64
+}
+
+// FeedInbox 关注流的收件箱，粉丝少的作者发表文章的时候推送到每个粉丝这里
+type FeedInbox struct {
+	Id int64 `gorm:"primaryKey,autoIncrement"`
+	// 按照收件人分页查询，重新发表的时候只更新时间
+	Uid int64 `gorm:"uniqueIndex:uid_art_id;index:uid_ctime"`
+	// 文章彻底删除的时候按照 art_id 删除
+	ArtId int64 `gorm:"uniqueIndex:uid_art_id;index"`
+	// 文章的发表时间，和拉取的时候用同一个字段分页
+	Ctime int64 `gorm:"index:uid_ctime"`
+}
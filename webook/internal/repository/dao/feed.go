@@ -0,0 +1,34 @@
+package dao
+
+import (
+	"context"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type GORMFeedDAO struct {
+	db *gorm.DB
+}
+
+func NewGORMFeedDAO(db *gorm.DB) FeedDAO {
+	return &GORMFeedDAO{db: db}
+}
+
+func (dao *GORMFeedDAO) InsertInbox(ctx context.Context, items []FeedInbox) error {
+	if len(items) == 0 {
+		return nil
+	}
+	// 撤回之后重新发表的文章会再推送一次，发表时间没有变，覆盖掉就可以
+	return dao.db.WithContext(ctx).Clauses(clause.OnConflict{
+		DoUpdates: clause.AssignmentColumns([]string{"ctime"}),
+	}).Create(&items).Error
+}
+
+func (dao *GORMFeedDAO) ListInbox(ctx context.Context, uid int64, before int64, limit int) ([]FeedInbox, error) {
+	var res []FeedInbox
+	err := dao.db.WithContext(ctx).Where("uid = ? AND ctime < ?", uid, before).
+		Order("ctime DESC, id DESC").
+		Limit(limit).
+		Find(&res).Error
+	return res, err
+}
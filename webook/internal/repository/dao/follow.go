@@ -0,0 +1,149 @@
+package dao
+
+import (
+	"context"
+	"errors"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"time"
+)
+
+var (
+	ErrFollowNotFound  = gorm.ErrRecordNotFound
+	ErrFollowDuplicate = errors.New("已经关注过了")
+)
+
+type GORMFollowDAO struct {
+	db *gorm.DB
+}
+
+func NewGORMFollowDAO(db *gorm.DB) FollowDAO {
+	return &GORMFollowDAO{db: db}
+}
+
+func (dao *GORMFollowDAO) Insert(ctx context.Context, follower int64, followee int64) error {
+	now := time.Now().UnixMilli()
+	return dao.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Create(&FollowRelation{
+			Follower: follower,
+			Followee: followee,
+			Ctime:    now,
+			Utime:    now,
+		}).Error
+		if isUniqueConflict(err) {
+			return ErrFollowDuplicate
+		}
+		if err != nil {
+			return err
+		}
+		err = dao.updateStatics(tx, follower, "followees", 1)
+		if err != nil {
+			return err
+		}
+		return dao.updateStatics(tx, followee, "followers", 1)
+	})
+}
+
+func (dao *GORMFollowDAO) Delete(ctx context.Context, follower int64, followee int64) error {
+	return dao.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		res := tx.Where("follower = ? AND followee = ?", follower, followee).
+			Delete(&FollowRelation{})
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return ErrFollowNotFound
+		}
+		err := dao.updateStatics(tx, follower, "followees", -1)
+		if err != nil {
+			return err
+		}
+		return dao.updateStatics(tx, followee, "followers", -1)
+	})
+}
+
+func (dao *GORMFollowDAO) Get(ctx context.Context, follower int64, followee int64) (FollowRelation, error) {
+	var res FollowRelation
+	err := dao.db.WithContext(ctx).
+		Where("follower = ? AND followee = ?", follower, followee).
+		First(&res).Error
+	return res, err
+}
+
+func (dao *GORMFollowDAO) ListFollowers(ctx context.Context, followee int64, offset int, limit int) ([]FollowRelation, error) {
+	var res []FollowRelation
+	err := dao.db.WithContext(ctx).Where("followee = ?", followee).
+		Order("id DESC").
+		Offset(offset).Limit(limit).
+		Find(&res).Error
+	return res, err
+}
+
+func (dao *GORMFollowDAO) ListFollowees(ctx context.Context, follower int64, offset int, limit int) ([]FollowRelation, error) {
+	var res []FollowRelation
+	err := dao.db.WithContext(ctx).Where("follower = ?", follower).
+		Order("id DESC").
+		Offset(offset).Limit(limit).
+		Find(&res).Error
+	return res, err
+}
+
+func (dao *GORMFollowDAO) FolloweeIds(ctx context.Context, follower int64, limit int) ([]int64, error) {
+	var res []int64
+	err := dao.db.WithContext(ctx).Model(&FollowRelation{}).
+		Where("follower = ?", follower).
+		Order("ctime DESC, id DESC").
+		Limit(limit).
+		Pluck("followee", &res).Error
+	return res, err
+}
+
+func (dao *GORMFollowDAO) FollowerIds(ctx context.Context, followee int64) ([]int64, error) {
+	var res []int64
+	err := dao.db.WithContext(ctx).Model(&FollowRelation{}).
+		Where("followee = ?", followee).
+		Pluck("follower", &res).Error
+	return res, err
+}
+
+func (dao *GORMFollowDAO) GetStatics(ctx context.Context, uid int64) (FollowStatics, error) {
+	var res FollowStatics
+	err := dao.db.WithContext(ctx).Where("uid = ?", uid).First(&res).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		// 没有关注过别人，也没有被别人关注过
+		return FollowStatics{Uid: uid}, nil
+	}
+	return res, err
+}
+
+func (dao *GORMFollowDAO) GetStaticsByUids(ctx context.Context, uids []int64) ([]FollowStatics, error) {
+	var res []FollowStatics
+	if len(uids) == 0 {
+		return res, nil
+	}
+	err := dao.db.WithContext(ctx).Where("uid IN ?", uids).Find(&res).Error
+	return res, err
+}
+
+// updateStatics 修改计数，第一次的时候插入一行
+// 减少的时候行一定已经存在了，不会插入负数
+func (dao *GORMFollowDAO) updateStatics(tx *gorm.DB, uid int64, column string, delta int64) error {
+	now := time.Now().UnixMilli()
+	s := FollowStatics{
+		Uid:   uid,
+		Ctime: now,
+		Utime: now,
+	}
+	switch column {
+	case "followers":
+		s.Followers = delta
+	case "followees":
+		s.Followees = delta
+	}
+	return tx.Clauses(clause.OnConflict{
+		DoUpdates: clause.Assignments(map[string]any{
+			column:  gorm.Expr("`"+column+"` + ?", delta),
+			"utime": now,
+		}),
+	}).Create(&s).Error
+}
@@ -0,0 +1,200 @@
+package dao
+
+import (
+	"context"
+	"errors"
+	"github.com/go-sql-driver/mysql"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"time"
+)
+
+var (
+	ErrInteractiveNotFound = gorm.ErrRecordNotFound
+	ErrLikeDuplicate       = errors.New("已经点过赞了")
+	ErrLikeNotFound        = errors.New("没有点过赞")
+	ErrCollectionDuplicate = errors.New("已经收藏过了")
+	ErrCollectionNotFound  = errors.New("没有收藏过")
+)
+
+const (
+	likeStatusCanceled uint8 = iota
+	likeStatusValid
+)
+
+type GORMInteractiveDAO struct {
+	db *gorm.DB
+}
+
+func NewGORMInteractiveDAO(db *gorm.DB) InteractiveDAO {
+	return &GORMInteractiveDAO{db: db}
+}
+
+func (dao *GORMInteractiveDAO) IncrReadCnt(ctx context.Context, biz string, bizId int64) error {
+	return dao.incr(dao.db.WithContext(ctx), biz, bizId, "read_cnt")
+}
+
+func (dao *GORMInteractiveDAO) InsertLikeInfo(ctx context.Context, biz string, bizId int64, uid int64) error {
+	now := time.Now().UnixMilli()
+	return dao.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// 之前取消过点赞，就把状态改回来
+		res := tx.Model(&UserLikeBiz{}).
+			Where("uid = ? AND biz_id = ? AND biz = ? AND status = ?", uid, bizId, biz, likeStatusCanceled).
+			Updates(map[string]any{
+				"status": likeStatusValid,
+				"utime":  now,
+			})
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			// 要么从来没有点过赞，要么已经点过赞了，交给唯一索引来判断
+			// 并发点赞的时候也只有一个请求能插入成功
+			err := tx.Create(&UserLikeBiz{
+				Uid:    uid,
+				BizId:  bizId,
+				Biz:    biz,
+				Status: likeStatusValid,
+				Ctime:  now,
+				Utime:  now,
+			}).Error
+			if isUniqueConflict(err) {
+				return ErrLikeDuplicate
+			}
+			if err != nil {
+				return err
+			}
+		}
+		return dao.incr(tx, biz, bizId, "like_cnt")
+	})
+}
+
+func (dao *GORMInteractiveDAO) DeleteLikeInfo(ctx context.Context, biz string, bizId int64, uid int64) error {
+	now := time.Now().UnixMilli()
+	return dao.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		res := tx.Model(&UserLikeBiz{}).
+			Where("uid = ? AND biz_id = ? AND biz = ? AND status = ?", uid, bizId, biz, likeStatusValid).
+			Updates(map[string]any{
+				"status": likeStatusCanceled,
+				"utime":  now,
+			})
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return ErrLikeNotFound
+		}
+		return dao.decr(tx, biz, bizId, "like_cnt")
+	})
+}
+
+func (dao *GORMInteractiveDAO) InsertCollectionInfo(ctx context.Context, biz string, bizId int64, uid int64) error {
+	now := time.Now().UnixMilli()
+	return dao.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Create(&UserCollectionBiz{
+			Uid:   uid,
+			BizId: bizId,
+			Biz:   biz,
+			Ctime: now,
+			Utime: now,
+		}).Error
+		if isUniqueConflict(err) {
+			return ErrCollectionDuplicate
+		}
+		if err != nil {
+			return err
+		}
+		return dao.incr(tx, biz, bizId, "collect_cnt")
+	})
+}
+
+func (dao *GORMInteractiveDAO) DeleteCollectionInfo(ctx context.Context, biz string, bizId int64, uid int64) error {
+	return dao.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		res := tx.Where("uid = ? AND biz_id = ? AND biz = ?", uid, bizId, biz).
+			Delete(&UserCollectionBiz{})
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return ErrCollectionNotFound
+		}
+		return dao.decr(tx, biz, bizId, "collect_cnt")
+	})
+}
+
+func (dao *GORMInteractiveDAO) Get(ctx context.Context, biz string, bizId int64) (Interactive, error) {
+	var res Interactive
+	err := dao.db.WithContext(ctx).Where("biz_id = ? AND biz = ?", bizId, biz).First(&res).Error
+	return res, err
+}
+
+func (dao *GORMInteractiveDAO) GetByIds(ctx context.Context, biz string, bizIds []int64) ([]Interactive, error) {
+	var res []Interactive
+	if len(bizIds) == 0 {
+		return res, nil
+	}
+	err := dao.db.WithContext(ctx).Where("biz_id IN ? AND biz = ?", bizIds, biz).Find(&res).Error
+	return res, err
+}
+
+func (dao *GORMInteractiveDAO) GetLikeInfo(ctx context.Context, biz string, bizId int64, uid int64) (UserLikeBiz, error) {
+	var res UserLikeBiz
+	err := dao.db.WithContext(ctx).
+		Where("uid = ? AND biz_id = ? AND biz = ? AND status = ?", uid, bizId, biz, likeStatusValid).
+		First(&res).Error
+	return res, err
+}
+
+func (dao *GORMInteractiveDAO) GetCollectionInfo(ctx context.Context, biz string, bizId int64, uid int64) (UserCollectionBiz, error) {
+	var res UserCollectionBiz
+	err := dao.db.WithContext(ctx).
+		Where("uid = ? AND biz_id = ? AND biz = ?", uid, bizId, biz).
+		First(&res).Error
+	return res, err
+}
+
+// incr 计数加一，第一次互动的时候插入一行
+// INSERT xxx ON DUPLICATE KEY UPDATE cnt = cnt + 1，由数据库保证并发安全
+func (dao *GORMInteractiveDAO) incr(tx *gorm.DB, biz string, bizId int64, column string) error {
+	now := time.Now().UnixMilli()
+	intr := Interactive{
+		Biz:   biz,
+		BizId: bizId,
+		Ctime: now,
+		Utime: now,
+	}
+	switch column {
+	case "read_cnt":
+		intr.ReadCnt = 1
+	case "like_cnt":
+		intr.LikeCnt = 1
+	case "collect_cnt":
+		intr.CollectCnt = 1
+	}
+	return tx.Clauses(clause.OnConflict{
+		DoUpdates: clause.Assignments(map[string]any{
+			column:  gorm.Expr("`" + column + "` + 1"),
+			"utime": now,
+		}),
+	}).Create(&intr).Error
+}
+
+// decr 计数减一，能走到这里说明之前一定增加过，行是存在的
+func (dao *GORMInteractiveDAO) decr(tx *gorm.DB, biz string, bizId int64, column string) error {
+	return tx.Model(&Interactive{}).
+		Where("biz_id = ? AND biz = ?", bizId, biz).
+		Updates(map[string]any{
+			column:  gorm.Expr("`" + column + "` - 1"),
+			"utime": time.Now().UnixMilli(),
+		}).Error
+}
+
+// isUniqueConflict 是不是唯一索引冲突
+func isUniqueConflict(err error) bool {
+	var mysqlErr *mysql.MySQLError
+	if errors.As(err, &mysqlErr) {
+		const uniqueConflictsErr = 1062
+		return mysqlErr.Number == uniqueConflictsErr
+	}
+	return false
+}
@@ -137,3 +137,1536 @@ func (mr *MockUserDAOMockRecorder) Update(ctx, u interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockUserDAO)(nil).Update), ctx, u)
 }
+
+// MockArticleDAO is a mock of ArticleDAO interface.
+type MockArticleDAO struct {
+	ctrl     *gomock.Controller
+	recorder *MockArticleDAOMockRecorder
+}
+
+// MockArticleDAOMockRecorder is the mock recorder for MockArticleDAO.
+type MockArticleDAOMockRecorder struct {
+	mock *MockArticleDAO
+}
+
+// NewMockArticleDAO creates a new mock instance.
+func NewMockArticleDAO(ctrl *gomock.Controller) *MockArticleDAO {
+	mock := &MockArticleDAO{ctrl: ctrl}
+	mock.recorder = &MockArticleDAOMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockArticleDAO) EXPECT() *MockArticleDAOMockRecorder {
+	return m.recorder
+}
+
+// GetByAuthor mocks base method.
+func (m *MockArticleDAO) GetByAuthor(ctx context.Context, authorId int64, offset, limit int) ([]dao.Article, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByAuthor", ctx, authorId, offset, limit)
+	ret0, _ := ret[0].([]dao.Article)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByAuthor indicates an expected call of GetByAuthor.
+func (mr *MockArticleDAOMockRecorder) GetByAuthor(ctx, authorId, offset, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByAuthor", reflect.TypeOf((*MockArticleDAO)(nil).GetByAuthor), ctx, authorId, offset, limit)
+}
+
+// GetByAuthorCursor mocks base method.
+func (m *MockArticleDAO) GetByAuthorCursor(ctx context.Context, authorId, utime, id int64, limit int) ([]dao.Article, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByAuthorCursor", ctx, authorId, utime, id, limit)
+	ret0, _ := ret[0].([]dao.Article)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByAuthorCursor indicates an expected call of GetByAuthorCursor.
+func (mr *MockArticleDAOMockRecorder) GetByAuthorCursor(ctx, authorId, utime, id, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByAuthorCursor", reflect.TypeOf((*MockArticleDAO)(nil).GetByAuthorCursor), ctx, authorId, utime, id, limit)
+}
+
+// GetById mocks base method.
+func (m *MockArticleDAO) GetById(ctx context.Context, id int64) (dao.Article, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetById", ctx, id)
+	ret0, _ := ret[0].(dao.Article)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetById indicates an expected call of GetById.
+func (mr *MockArticleDAOMockRecorder) GetById(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetById", reflect.TypeOf((*MockArticleDAO)(nil).GetById), ctx, id)
+}
+
+// GetPubById mocks base method.
+func (m *MockArticleDAO) GetPubById(ctx context.Context, id int64, status uint8) (dao.PublishedArticle, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPubById", ctx, id, status)
+	ret0, _ := ret[0].(dao.PublishedArticle)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPubById indicates an expected call of GetPubById.
+func (mr *MockArticleDAOMockRecorder) GetPubById(ctx, id, status interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPubById", reflect.TypeOf((*MockArticleDAO)(nil).GetPubById), ctx, id, status)
+}
+
+// Insert mocks base method.
+func (m *MockArticleDAO) Insert(ctx context.Context, art dao.Article) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Insert", ctx, art)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Insert indicates an expected call of Insert.
+func (mr *MockArticleDAOMockRecorder) Insert(ctx, art interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Insert", reflect.TypeOf((*MockArticleDAO)(nil).Insert), ctx, art)
+}
+
+// ListByStatus mocks base method.
+func (m *MockArticleDAO) ListByStatus(ctx context.Context, status uint8) ([]dao.Article, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListByStatus", ctx, status)
+	ret0, _ := ret[0].([]dao.Article)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListByStatus indicates an expected call of ListByStatus.
+func (mr *MockArticleDAOMockRecorder) ListByStatus(ctx, status interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListByStatus", reflect.TypeOf((*MockArticleDAO)(nil).ListByStatus), ctx, status)
+}
+
+// ListByStatusAsc mocks base method.
+func (m *MockArticleDAO) ListByStatusAsc(ctx context.Context, status uint8, offset, limit int) ([]dao.Article, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListByStatusAsc", ctx, status, offset, limit)
+	ret0, _ := ret[0].([]dao.Article)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListByStatusAsc indicates an expected call of ListByStatusAsc.
+func (mr *MockArticleDAOMockRecorder) ListByStatusAsc(ctx, status, offset, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListByStatusAsc", reflect.TypeOf((*MockArticleDAO)(nil).ListByStatusAsc), ctx, status, offset, limit)
+}
+
+// ListDeleted mocks base method.
+func (m *MockArticleDAO) ListDeleted(ctx context.Context, authorId, after int64, offset, limit int) ([]dao.Article, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListDeleted", ctx, authorId, after, offset, limit)
+	ret0, _ := ret[0].([]dao.Article)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListDeleted indicates an expected call of ListDeleted.
+func (mr *MockArticleDAOMockRecorder) ListDeleted(ctx, authorId, after, offset, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListDeleted", reflect.TypeOf((*MockArticleDAO)(nil).ListDeleted), ctx, authorId, after, offset, limit)
+}
+
+// ListPub mocks base method.
+func (m *MockArticleDAO) ListPub(ctx context.Context, status uint8, offset, limit int) ([]dao.PublishedArticle, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListPub", ctx, status, offset, limit)
+	ret0, _ := ret[0].([]dao.PublishedArticle)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListPub indicates an expected call of ListPub.
+func (mr *MockArticleDAOMockRecorder) ListPub(ctx, status, offset, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListPub", reflect.TypeOf((*MockArticleDAO)(nil).ListPub), ctx, status, offset, limit)
+}
+
+// ListPubByAuthors mocks base method.
+func (m *MockArticleDAO) ListPubByAuthors(ctx context.Context, authorIds []int64, status uint8, before int64, limit int) ([]dao.PublishedArticle, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListPubByAuthors", ctx, authorIds, status, before, limit)
+	ret0, _ := ret[0].([]dao.PublishedArticle)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListPubByAuthors indicates an expected call of ListPubByAuthors.
+func (mr *MockArticleDAOMockRecorder) ListPubByAuthors(ctx, authorIds, status, before, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListPubByAuthors", reflect.TypeOf((*MockArticleDAO)(nil).ListPubByAuthors), ctx, authorIds, status, before, limit)
+}
+
+// ListPubByIds mocks base method.
+func (m *MockArticleDAO) ListPubByIds(ctx context.Context, ids []int64, status uint8) ([]dao.PublishedArticle, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListPubByIds", ctx, ids, status)
+	ret0, _ := ret[0].([]dao.PublishedArticle)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListPubByIds indicates an expected call of ListPubByIds.
+func (mr *MockArticleDAOMockRecorder) ListPubByIds(ctx, ids, status interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListPubByIds", reflect.TypeOf((*MockArticleDAO)(nil).ListPubByIds), ctx, ids, status)
+}
+
+// ListPubByTag mocks base method.
+func (m *MockArticleDAO) ListPubByTag(ctx context.Context, tag string, status uint8, offset, limit int) ([]dao.PublishedArticle, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListPubByTag", ctx, tag, status, offset, limit)
+	ret0, _ := ret[0].([]dao.PublishedArticle)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListPubByTag indicates an expected call of ListPubByTag.
+func (mr *MockArticleDAOMockRecorder) ListPubByTag(ctx, tag, status, offset, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListPubByTag", reflect.TypeOf((*MockArticleDAO)(nil).ListPubByTag), ctx, tag, status, offset, limit)
+}
+
+// ListPubSince mocks base method.
+func (m *MockArticleDAO) ListPubSince(ctx context.Context, status uint8, since int64, offset, limit int) ([]dao.PublishedArticle, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListPubSince", ctx, status, since, offset, limit)
+	ret0, _ := ret[0].([]dao.PublishedArticle)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListPubSince indicates an expected call of ListPubSince.
+func (mr *MockArticleDAOMockRecorder) ListPubSince(ctx, status, since, offset, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListPubSince", reflect.TypeOf((*MockArticleDAO)(nil).ListPubSince), ctx, status, since, offset, limit)
+}
+
+// ListPubTags mocks base method.
+func (m *MockArticleDAO) ListPubTags(ctx context.Context, status uint8, limit int) ([]dao.TagCount, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListPubTags", ctx, status, limit)
+	ret0, _ := ret[0].([]dao.TagCount)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListPubTags indicates an expected call of ListPubTags.
+func (mr *MockArticleDAOMockRecorder) ListPubTags(ctx, status, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListPubTags", reflect.TypeOf((*MockArticleDAO)(nil).ListPubTags), ctx, status, limit)
+}
+
+// PurgeDeleted mocks base method.
+func (m *MockArticleDAO) PurgeDeleted(ctx context.Context, biz string, before int64, limit int) ([]int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PurgeDeleted", ctx, biz, before, limit)
+	ret0, _ := ret[0].([]int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PurgeDeleted indicates an expected call of PurgeDeleted.
+func (mr *MockArticleDAOMockRecorder) PurgeDeleted(ctx, biz, before, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PurgeDeleted", reflect.TypeOf((*MockArticleDAO)(nil).PurgeDeleted), ctx, biz, before, limit)
+}
+
+// RestoreDeleted mocks base method.
+func (m *MockArticleDAO) RestoreDeleted(ctx context.Context, id, authorId, after int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RestoreDeleted", ctx, id, authorId, after)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RestoreDeleted indicates an expected call of RestoreDeleted.
+func (mr *MockArticleDAOMockRecorder) RestoreDeleted(ctx, id, authorId, after interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RestoreDeleted", reflect.TypeOf((*MockArticleDAO)(nil).RestoreDeleted), ctx, id, authorId, after)
+}
+
+// SoftDelete mocks base method.
+func (m *MockArticleDAO) SoftDelete(ctx context.Context, id, authorId int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SoftDelete", ctx, id, authorId)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SoftDelete indicates an expected call of SoftDelete.
+func (mr *MockArticleDAOMockRecorder) SoftDelete(ctx, id, authorId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SoftDelete", reflect.TypeOf((*MockArticleDAO)(nil).SoftDelete), ctx, id, authorId)
+}
+
+// Sync mocks base method.
+func (m *MockArticleDAO) Sync(ctx context.Context, art dao.Article) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Sync", ctx, art)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Sync indicates an expected call of Sync.
+func (mr *MockArticleDAOMockRecorder) Sync(ctx, art interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Sync", reflect.TypeOf((*MockArticleDAO)(nil).Sync), ctx, art)
+}
+
+// SyncStatus mocks base method.
+func (m *MockArticleDAO) SyncStatus(ctx context.Context, id, authorId int64, status uint8) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SyncStatus", ctx, id, authorId, status)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SyncStatus indicates an expected call of SyncStatus.
+func (mr *MockArticleDAOMockRecorder) SyncStatus(ctx, id, authorId, status interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SyncStatus", reflect.TypeOf((*MockArticleDAO)(nil).SyncStatus), ctx, id, authorId, status)
+}
+
+// UpdateById mocks base method.
+func (m *MockArticleDAO) UpdateById(ctx context.Context, art dao.Article) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateById", ctx, art)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateById indicates an expected call of UpdateById.
+func (mr *MockArticleDAOMockRecorder) UpdateById(ctx, art interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateById", reflect.TypeOf((*MockArticleDAO)(nil).UpdateById), ctx, art)
+}
+
+// UpdateSchedule mocks base method.
+func (m *MockArticleDAO) UpdateSchedule(ctx context.Context, id, authorId int64, from, to uint8, publishAt int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateSchedule", ctx, id, authorId, from, to, publishAt)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateSchedule indicates an expected call of UpdateSchedule.
+func (mr *MockArticleDAOMockRecorder) UpdateSchedule(ctx, id, authorId, from, to, publishAt interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateSchedule", reflect.TypeOf((*MockArticleDAO)(nil).UpdateSchedule), ctx, id, authorId, from, to, publishAt)
+}
+
+// Upsert mocks base method.
+func (m *MockArticleDAO) Upsert(ctx context.Context, art dao.PublishedArticle) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Upsert", ctx, art)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Upsert indicates an expected call of Upsert.
+func (mr *MockArticleDAOMockRecorder) Upsert(ctx, art interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Upsert", reflect.TypeOf((*MockArticleDAO)(nil).Upsert), ctx, art)
+}
+
+// Withdraw mocks base method.
+func (m *MockArticleDAO) Withdraw(ctx context.Context, id, authorId int64, published, private uint8) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Withdraw", ctx, id, authorId, published, private)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Withdraw indicates an expected call of Withdraw.
+func (mr *MockArticleDAOMockRecorder) Withdraw(ctx, id, authorId, published, private interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Withdraw", reflect.TypeOf((*MockArticleDAO)(nil).Withdraw), ctx, id, authorId, published, private)
+}
+
+// MockArticleReviewDAO is a mock of ArticleReviewDAO interface.
+type MockArticleReviewDAO struct {
+	ctrl     *gomock.Controller
+	recorder *MockArticleReviewDAOMockRecorder
+}
+
+// MockArticleReviewDAOMockRecorder is the mock recorder for MockArticleReviewDAO.
+type MockArticleReviewDAOMockRecorder struct {
+	mock *MockArticleReviewDAO
+}
+
+// NewMockArticleReviewDAO creates a new mock instance.
+func NewMockArticleReviewDAO(ctrl *gomock.Controller) *MockArticleReviewDAO {
+	mock := &MockArticleReviewDAO{ctrl: ctrl}
+	mock.recorder = &MockArticleReviewDAOMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockArticleReviewDAO) EXPECT() *MockArticleReviewDAOMockRecorder {
+	return m.recorder
+}
+
+// FindLatest mocks base method.
+func (m *MockArticleReviewDAO) FindLatest(ctx context.Context, artId int64) (dao.ArticleReview, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindLatest", ctx, artId)
+	ret0, _ := ret[0].(dao.ArticleReview)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindLatest indicates an expected call of FindLatest.
+func (mr *MockArticleReviewDAOMockRecorder) FindLatest(ctx, artId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindLatest", reflect.TypeOf((*MockArticleReviewDAO)(nil).FindLatest), ctx, artId)
+}
+
+// Insert mocks base method.
+func (m *MockArticleReviewDAO) Insert(ctx context.Context, r dao.ArticleReview) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Insert", ctx, r)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Insert indicates an expected call of Insert.
+func (mr *MockArticleReviewDAOMockRecorder) Insert(ctx, r interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Insert", reflect.TypeOf((*MockArticleReviewDAO)(nil).Insert), ctx, r)
+}
+
+// MockArticleRevisionDAO is a mock of ArticleRevisionDAO interface.
+type MockArticleRevisionDAO struct {
+	ctrl     *gomock.Controller
+	recorder *MockArticleRevisionDAOMockRecorder
+}
+
+// MockArticleRevisionDAOMockRecorder is the mock recorder for MockArticleRevisionDAO.
+type MockArticleRevisionDAOMockRecorder struct {
+	mock *MockArticleRevisionDAO
+}
+
+// NewMockArticleRevisionDAO creates a new mock instance.
+func NewMockArticleRevisionDAO(ctrl *gomock.Controller) *MockArticleRevisionDAO {
+	mock := &MockArticleRevisionDAO{ctrl: ctrl}
+	mock.recorder = &MockArticleRevisionDAOMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockArticleRevisionDAO) EXPECT() *MockArticleRevisionDAOMockRecorder {
+	return m.recorder
+}
+
+// GetById mocks base method.
+func (m *MockArticleRevisionDAO) GetById(ctx context.Context, id int64) (dao.ArticleRevision, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetById", ctx, id)
+	ret0, _ := ret[0].(dao.ArticleRevision)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetById indicates an expected call of GetById.
+func (mr *MockArticleRevisionDAOMockRecorder) GetById(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetById", reflect.TypeOf((*MockArticleRevisionDAO)(nil).GetById), ctx, id)
+}
+
+// List mocks base method.
+func (m *MockArticleRevisionDAO) List(ctx context.Context, artId int64, offset, limit int) ([]dao.ArticleRevision, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "List", ctx, artId, offset, limit)
+	ret0, _ := ret[0].([]dao.ArticleRevision)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// List indicates an expected call of List.
+func (mr *MockArticleRevisionDAOMockRecorder) List(ctx, artId, offset, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockArticleRevisionDAO)(nil).List), ctx, artId, offset, limit)
+}
+
+// Prune mocks base method.
+func (m *MockArticleRevisionDAO) Prune(ctx context.Context, artId int64, keep int, before int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Prune", ctx, artId, keep, before)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Prune indicates an expected call of Prune.
+func (mr *MockArticleRevisionDAOMockRecorder) Prune(ctx, artId, keep, before interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Prune", reflect.TypeOf((*MockArticleRevisionDAO)(nil).Prune), ctx, artId, keep, before)
+}
+
+// MockInteractiveDAO is a mock of InteractiveDAO interface.
+type MockInteractiveDAO struct {
+	ctrl     *gomock.Controller
+	recorder *MockInteractiveDAOMockRecorder
+}
+
+// MockInteractiveDAOMockRecorder is the mock recorder for MockInteractiveDAO.
+type MockInteractiveDAOMockRecorder struct {
+	mock *MockInteractiveDAO
+}
+
+// NewMockInteractiveDAO creates a new mock instance.
+func NewMockInteractiveDAO(ctrl *gomock.Controller) *MockInteractiveDAO {
+	mock := &MockInteractiveDAO{ctrl: ctrl}
+	mock.recorder = &MockInteractiveDAOMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockInteractiveDAO) EXPECT() *MockInteractiveDAOMockRecorder {
+	return m.recorder
+}
+
+// DeleteCollectionInfo mocks base method.
+func (m *MockInteractiveDAO) DeleteCollectionInfo(ctx context.Context, biz string, bizId, uid int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteCollectionInfo", ctx, biz, bizId, uid)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteCollectionInfo indicates an expected call of DeleteCollectionInfo.
+func (mr *MockInteractiveDAOMockRecorder) DeleteCollectionInfo(ctx, biz, bizId, uid interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteCollectionInfo", reflect.TypeOf((*MockInteractiveDAO)(nil).DeleteCollectionInfo), ctx, biz, bizId, uid)
+}
+
+// DeleteLikeInfo mocks base method.
+func (m *MockInteractiveDAO) DeleteLikeInfo(ctx context.Context, biz string, bizId, uid int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteLikeInfo", ctx, biz, bizId, uid)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteLikeInfo indicates an expected call of DeleteLikeInfo.
+func (mr *MockInteractiveDAOMockRecorder) DeleteLikeInfo(ctx, biz, bizId, uid interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteLikeInfo", reflect.TypeOf((*MockInteractiveDAO)(nil).DeleteLikeInfo), ctx, biz, bizId, uid)
+}
+
+// Get mocks base method.
+func (m *MockInteractiveDAO) Get(ctx context.Context, biz string, bizId int64) (dao.Interactive, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Get", ctx, biz, bizId)
+	ret0, _ := ret[0].(dao.Interactive)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Get indicates an expected call of Get.
+func (mr *MockInteractiveDAOMockRecorder) Get(ctx, biz, bizId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockInteractiveDAO)(nil).Get), ctx, biz, bizId)
+}
+
+// GetByIds mocks base method.
+func (m *MockInteractiveDAO) GetByIds(ctx context.Context, biz string, bizIds []int64) ([]dao.Interactive, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByIds", ctx, biz, bizIds)
+	ret0, _ := ret[0].([]dao.Interactive)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByIds indicates an expected call of GetByIds.
+func (mr *MockInteractiveDAOMockRecorder) GetByIds(ctx, biz, bizIds interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByIds", reflect.TypeOf((*MockInteractiveDAO)(nil).GetByIds), ctx, biz, bizIds)
+}
+
+// GetCollectionInfo mocks base method.
+func (m *MockInteractiveDAO) GetCollectionInfo(ctx context.Context, biz string, bizId, uid int64) (dao.UserCollectionBiz, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCollectionInfo", ctx, biz, bizId, uid)
+	ret0, _ := ret[0].(dao.UserCollectionBiz)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCollectionInfo indicates an expected call of GetCollectionInfo.
+func (mr *MockInteractiveDAOMockRecorder) GetCollectionInfo(ctx, biz, bizId, uid interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCollectionInfo", reflect.TypeOf((*MockInteractiveDAO)(nil).GetCollectionInfo), ctx, biz, bizId, uid)
+}
+
+// GetLikeInfo mocks base method.
+func (m *MockInteractiveDAO) GetLikeInfo(ctx context.Context, biz string, bizId, uid int64) (dao.UserLikeBiz, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLikeInfo", ctx, biz, bizId, uid)
+	ret0, _ := ret[0].(dao.UserLikeBiz)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetLikeInfo indicates an expected call of GetLikeInfo.
+func (mr *MockInteractiveDAOMockRecorder) GetLikeInfo(ctx, biz, bizId, uid interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLikeInfo", reflect.TypeOf((*MockInteractiveDAO)(nil).GetLikeInfo), ctx, biz, bizId, uid)
+}
+
+// IncrReadCnt mocks base method.
+func (m *MockInteractiveDAO) IncrReadCnt(ctx context.Context, biz string, bizId int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IncrReadCnt", ctx, biz, bizId)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// IncrReadCnt indicates an expected call of IncrReadCnt.
+func (mr *MockInteractiveDAOMockRecorder) IncrReadCnt(ctx, biz, bizId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IncrReadCnt", reflect.TypeOf((*MockInteractiveDAO)(nil).IncrReadCnt), ctx, biz, bizId)
+}
+
+// InsertCollectionInfo mocks base method.
+func (m *MockInteractiveDAO) InsertCollectionInfo(ctx context.Context, biz string, bizId, uid int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InsertCollectionInfo", ctx, biz, bizId, uid)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// InsertCollectionInfo indicates an expected call of InsertCollectionInfo.
+func (mr *MockInteractiveDAOMockRecorder) InsertCollectionInfo(ctx, biz, bizId, uid interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertCollectionInfo", reflect.TypeOf((*MockInteractiveDAO)(nil).InsertCollectionInfo), ctx, biz, bizId, uid)
+}
+
+// InsertLikeInfo mocks base method.
+func (m *MockInteractiveDAO) InsertLikeInfo(ctx context.Context, biz string, bizId, uid int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InsertLikeInfo", ctx, biz, bizId, uid)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// InsertLikeInfo indicates an expected call of InsertLikeInfo.
+func (mr *MockInteractiveDAOMockRecorder) InsertLikeInfo(ctx, biz, bizId, uid interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertLikeInfo", reflect.TypeOf((*MockInteractiveDAO)(nil).InsertLikeInfo), ctx, biz, bizId, uid)
+}
+
+// MockArticleAuthorDAO is a mock of ArticleAuthorDAO interface.
+type MockArticleAuthorDAO struct {
+	ctrl     *gomock.Controller
+	recorder *MockArticleAuthorDAOMockRecorder
+}
+
+// MockArticleAuthorDAOMockRecorder is the mock recorder for MockArticleAuthorDAO.
+type MockArticleAuthorDAOMockRecorder struct {
+	mock *MockArticleAuthorDAO
+}
+
+// NewMockArticleAuthorDAO creates a new mock instance.
+func NewMockArticleAuthorDAO(ctrl *gomock.Controller) *MockArticleAuthorDAO {
+	mock := &MockArticleAuthorDAO{ctrl: ctrl}
+	mock.recorder = &MockArticleAuthorDAOMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockArticleAuthorDAO) EXPECT() *MockArticleAuthorDAOMockRecorder {
+	return m.recorder
+}
+
+// Insert mocks base method.
+func (m *MockArticleAuthorDAO) Insert(ctx context.Context, art dao.Article) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Insert", ctx, art)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Insert indicates an expected call of Insert.
+func (mr *MockArticleAuthorDAOMockRecorder) Insert(ctx, art interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Insert", reflect.TypeOf((*MockArticleAuthorDAO)(nil).Insert), ctx, art)
+}
+
+// UpdateById mocks base method.
+func (m *MockArticleAuthorDAO) UpdateById(ctx context.Context, art dao.Article) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateById", ctx, art)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateById indicates an expected call of UpdateById.
+func (mr *MockArticleAuthorDAOMockRecorder) UpdateById(ctx, art interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateById", reflect.TypeOf((*MockArticleAuthorDAO)(nil).UpdateById), ctx, art)
+}
+
+// MockArticleReaderDAO is a mock of ArticleReaderDAO interface.
+type MockArticleReaderDAO struct {
+	ctrl     *gomock.Controller
+	recorder *MockArticleReaderDAOMockRecorder
+}
+
+// MockArticleReaderDAOMockRecorder is the mock recorder for MockArticleReaderDAO.
+type MockArticleReaderDAOMockRecorder struct {
+	mock *MockArticleReaderDAO
+}
+
+// NewMockArticleReaderDAO creates a new mock instance.
+func NewMockArticleReaderDAO(ctrl *gomock.Controller) *MockArticleReaderDAO {
+	mock := &MockArticleReaderDAO{ctrl: ctrl}
+	mock.recorder = &MockArticleReaderDAOMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockArticleReaderDAO) EXPECT() *MockArticleReaderDAOMockRecorder {
+	return m.recorder
+}
+
+// Upsert mocks base method.
+func (m *MockArticleReaderDAO) Upsert(ctx context.Context, art dao.Article) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Upsert", ctx, art)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Upsert indicates an expected call of Upsert.
+func (mr *MockArticleReaderDAOMockRecorder) Upsert(ctx, art interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Upsert", reflect.TypeOf((*MockArticleReaderDAO)(nil).Upsert), ctx, art)
+}
+
+// UpsertV2 mocks base method.
+func (m *MockArticleReaderDAO) UpsertV2(ctx context.Context, art dao.PublishedArticle) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpsertV2", ctx, art)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpsertV2 indicates an expected call of UpsertV2.
+func (mr *MockArticleReaderDAOMockRecorder) UpsertV2(ctx, art interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpsertV2", reflect.TypeOf((*MockArticleReaderDAO)(nil).UpsertV2), ctx, art)
+}
+
+// MockCollectionFolderDAO is a mock of CollectionFolderDAO interface.
+type MockCollectionFolderDAO struct {
+	ctrl     *gomock.Controller
+	recorder *MockCollectionFolderDAOMockRecorder
+}
+
+// MockCollectionFolderDAOMockRecorder is the mock recorder for MockCollectionFolderDAO.
+type MockCollectionFolderDAOMockRecorder struct {
+	mock *MockCollectionFolderDAO
+}
+
+// NewMockCollectionFolderDAO creates a new mock instance.
+func NewMockCollectionFolderDAO(ctrl *gomock.Controller) *MockCollectionFolderDAO {
+	mock := &MockCollectionFolderDAO{ctrl: ctrl}
+	mock.recorder = &MockCollectionFolderDAOMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockCollectionFolderDAO) EXPECT() *MockCollectionFolderDAOMockRecorder {
+	return m.recorder
+}
+
+// Delete mocks base method.
+func (m *MockCollectionFolderDAO) Delete(ctx context.Context, id, uid int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", ctx, id, uid)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete.
+func (mr *MockCollectionFolderDAOMockRecorder) Delete(ctx, id, uid interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockCollectionFolderDAO)(nil).Delete), ctx, id, uid)
+}
+
+// DeleteItem mocks base method.
+func (m *MockCollectionFolderDAO) DeleteItem(ctx context.Context, fid, artId int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteItem", ctx, fid, artId)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteItem indicates an expected call of DeleteItem.
+func (mr *MockCollectionFolderDAOMockRecorder) DeleteItem(ctx, fid, artId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteItem", reflect.TypeOf((*MockCollectionFolderDAO)(nil).DeleteItem), ctx, fid, artId)
+}
+
+// GetById mocks base method.
+func (m *MockCollectionFolderDAO) GetById(ctx context.Context, id int64) (dao.CollectionFolder, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetById", ctx, id)
+	ret0, _ := ret[0].(dao.CollectionFolder)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetById indicates an expected call of GetById.
+func (mr *MockCollectionFolderDAOMockRecorder) GetById(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetById", reflect.TypeOf((*MockCollectionFolderDAO)(nil).GetById), ctx, id)
+}
+
+// Insert mocks base method.
+func (m *MockCollectionFolderDAO) Insert(ctx context.Context, folder dao.CollectionFolder) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Insert", ctx, folder)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Insert indicates an expected call of Insert.
+func (mr *MockCollectionFolderDAOMockRecorder) Insert(ctx, folder interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Insert", reflect.TypeOf((*MockCollectionFolderDAO)(nil).Insert), ctx, folder)
+}
+
+// InsertItem mocks base method.
+func (m *MockCollectionFolderDAO) InsertItem(ctx context.Context, item dao.CollectionFolderItem) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InsertItem", ctx, item)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// InsertItem indicates an expected call of InsertItem.
+func (mr *MockCollectionFolderDAOMockRecorder) InsertItem(ctx, item interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertItem", reflect.TypeOf((*MockCollectionFolderDAO)(nil).InsertItem), ctx, item)
+}
+
+// ListByUid mocks base method.
+func (m *MockCollectionFolderDAO) ListByUid(ctx context.Context, uid int64, offset, limit int) ([]dao.CollectionFolder, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListByUid", ctx, uid, offset, limit)
+	ret0, _ := ret[0].([]dao.CollectionFolder)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListByUid indicates an expected call of ListByUid.
+func (mr *MockCollectionFolderDAOMockRecorder) ListByUid(ctx, uid, offset, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListByUid", reflect.TypeOf((*MockCollectionFolderDAO)(nil).ListByUid), ctx, uid, offset, limit)
+}
+
+// ListItems mocks base method.
+func (m *MockCollectionFolderDAO) ListItems(ctx context.Context, fid int64, offset, limit int) ([]dao.CollectionFolderItem, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListItems", ctx, fid, offset, limit)
+	ret0, _ := ret[0].([]dao.CollectionFolderItem)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListItems indicates an expected call of ListItems.
+func (mr *MockCollectionFolderDAOMockRecorder) ListItems(ctx, fid, offset, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListItems", reflect.TypeOf((*MockCollectionFolderDAO)(nil).ListItems), ctx, fid, offset, limit)
+}
+
+// UpdateName mocks base method.
+func (m *MockCollectionFolderDAO) UpdateName(ctx context.Context, id, uid int64, name string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateName", ctx, id, uid, name)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateName indicates an expected call of UpdateName.
+func (mr *MockCollectionFolderDAOMockRecorder) UpdateName(ctx, id, uid, name interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateName", reflect.TypeOf((*MockCollectionFolderDAO)(nil).UpdateName), ctx, id, uid, name)
+}
+
+// MockCommentDAO is a mock of CommentDAO interface.
+type MockCommentDAO struct {
+	ctrl     *gomock.Controller
+	recorder *MockCommentDAOMockRecorder
+}
+
+// MockCommentDAOMockRecorder is the mock recorder for MockCommentDAO.
+type MockCommentDAOMockRecorder struct {
+	mock *MockCommentDAO
+}
+
+// NewMockCommentDAO creates a new mock instance.
+func NewMockCommentDAO(ctrl *gomock.Controller) *MockCommentDAO {
+	mock := &MockCommentDAO{ctrl: ctrl}
+	mock.recorder = &MockCommentDAOMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockCommentDAO) EXPECT() *MockCommentDAOMockRecorder {
+	return m.recorder
+}
+
+// Delete mocks base method.
+func (m *MockCommentDAO) Delete(ctx context.Context, c dao.Comment) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", ctx, c)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete.
+func (mr *MockCommentDAOMockRecorder) Delete(ctx, c interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockCommentDAO)(nil).Delete), ctx, c)
+}
+
+// FindReplies mocks base method.
+func (m *MockCommentDAO) FindReplies(ctx context.Context, rootId, maxId int64, limit int) ([]dao.Comment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindReplies", ctx, rootId, maxId, limit)
+	ret0, _ := ret[0].([]dao.Comment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindReplies indicates an expected call of FindReplies.
+func (mr *MockCommentDAOMockRecorder) FindReplies(ctx, rootId, maxId, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindReplies", reflect.TypeOf((*MockCommentDAO)(nil).FindReplies), ctx, rootId, maxId, limit)
+}
+
+// FindRoots mocks base method.
+func (m *MockCommentDAO) FindRoots(ctx context.Context, artId, minId int64, limit int) ([]dao.Comment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindRoots", ctx, artId, minId, limit)
+	ret0, _ := ret[0].([]dao.Comment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindRoots indicates an expected call of FindRoots.
+func (mr *MockCommentDAOMockRecorder) FindRoots(ctx, artId, minId, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindRoots", reflect.TypeOf((*MockCommentDAO)(nil).FindRoots), ctx, artId, minId, limit)
+}
+
+// GetById mocks base method.
+func (m *MockCommentDAO) GetById(ctx context.Context, id int64) (dao.Comment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetById", ctx, id)
+	ret0, _ := ret[0].(dao.Comment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetById indicates an expected call of GetById.
+func (mr *MockCommentDAOMockRecorder) GetById(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetById", reflect.TypeOf((*MockCommentDAO)(nil).GetById), ctx, id)
+}
+
+// GetCount mocks base method.
+func (m *MockCommentDAO) GetCount(ctx context.Context, artId int64) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCount", ctx, artId)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCount indicates an expected call of GetCount.
+func (mr *MockCommentDAOMockRecorder) GetCount(ctx, artId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCount", reflect.TypeOf((*MockCommentDAO)(nil).GetCount), ctx, artId)
+}
+
+// Insert mocks base method.
+func (m *MockCommentDAO) Insert(ctx context.Context, c dao.Comment) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Insert", ctx, c)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Insert indicates an expected call of Insert.
+func (mr *MockCommentDAOMockRecorder) Insert(ctx, c interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Insert", reflect.TypeOf((*MockCommentDAO)(nil).Insert), ctx, c)
+}
+
+// MockCronJobDAO is a mock of CronJobDAO interface.
+type MockCronJobDAO struct {
+	ctrl     *gomock.Controller
+	recorder *MockCronJobDAOMockRecorder
+}
+
+// MockCronJobDAOMockRecorder is the mock recorder for MockCronJobDAO.
+type MockCronJobDAOMockRecorder struct {
+	mock *MockCronJobDAO
+}
+
+// NewMockCronJobDAO creates a new mock instance.
+func NewMockCronJobDAO(ctrl *gomock.Controller) *MockCronJobDAO {
+	mock := &MockCronJobDAO{ctrl: ctrl}
+	mock.recorder = &MockCronJobDAOMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockCronJobDAO) EXPECT() *MockCronJobDAOMockRecorder {
+	return m.recorder
+}
+
+// Pause mocks base method.
+func (m *MockCronJobDAO) Pause(ctx context.Context, id, version int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Pause", ctx, id, version)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Pause indicates an expected call of Pause.
+func (mr *MockCronJobDAOMockRecorder) Pause(ctx, id, version interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Pause", reflect.TypeOf((*MockCronJobDAO)(nil).Pause), ctx, id, version)
+}
+
+// Preempt mocks base method.
+func (m *MockCronJobDAO) Preempt(ctx context.Context, staleBefore int64) (dao.CronJob, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Preempt", ctx, staleBefore)
+	ret0, _ := ret[0].(dao.CronJob)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Preempt indicates an expected call of Preempt.
+func (mr *MockCronJobDAOMockRecorder) Preempt(ctx, staleBefore interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Preempt", reflect.TypeOf((*MockCronJobDAO)(nil).Preempt), ctx, staleBefore)
+}
+
+// Release mocks base method.
+func (m *MockCronJobDAO) Release(ctx context.Context, id, version, nextTime int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Release", ctx, id, version, nextTime)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Release indicates an expected call of Release.
+func (mr *MockCronJobDAOMockRecorder) Release(ctx, id, version, nextTime interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Release", reflect.TypeOf((*MockCronJobDAO)(nil).Release), ctx, id, version, nextTime)
+}
+
+// UpdateUtime mocks base method.
+func (m *MockCronJobDAO) UpdateUtime(ctx context.Context, id, version int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateUtime", ctx, id, version)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateUtime indicates an expected call of UpdateUtime.
+func (mr *MockCronJobDAOMockRecorder) UpdateUtime(ctx, id, version interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateUtime", reflect.TypeOf((*MockCronJobDAO)(nil).UpdateUtime), ctx, id, version)
+}
+
+// MockAsyncSmsDAO is a mock of AsyncSmsDAO interface.
+type MockAsyncSmsDAO struct {
+	ctrl     *gomock.Controller
+	recorder *MockAsyncSmsDAOMockRecorder
+}
+
+// MockAsyncSmsDAOMockRecorder is the mock recorder for MockAsyncSmsDAO.
+type MockAsyncSmsDAOMockRecorder struct {
+	mock *MockAsyncSmsDAO
+}
+
+// NewMockAsyncSmsDAO creates a new mock instance.
+func NewMockAsyncSmsDAO(ctrl *gomock.Controller) *MockAsyncSmsDAO {
+	mock := &MockAsyncSmsDAO{ctrl: ctrl}
+	mock.recorder = &MockAsyncSmsDAOMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAsyncSmsDAO) EXPECT() *MockAsyncSmsDAOMockRecorder {
+	return m.recorder
+}
+
+// Insert mocks base method.
+func (m *MockAsyncSmsDAO) Insert(ctx context.Context, s dao.AsyncSms) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Insert", ctx, s)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Insert indicates an expected call of Insert.
+func (mr *MockAsyncSmsDAOMockRecorder) Insert(ctx, s interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Insert", reflect.TypeOf((*MockAsyncSmsDAO)(nil).Insert), ctx, s)
+}
+
+// MarkExpired mocks base method.
+func (m *MockAsyncSmsDAO) MarkExpired(ctx context.Context, id, version int64, lastErr string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkExpired", ctx, id, version, lastErr)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MarkExpired indicates an expected call of MarkExpired.
+func (mr *MockAsyncSmsDAOMockRecorder) MarkExpired(ctx, id, version, lastErr interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkExpired", reflect.TypeOf((*MockAsyncSmsDAO)(nil).MarkExpired), ctx, id, version, lastErr)
+}
+
+// MarkFailed mocks base method.
+func (m *MockAsyncSmsDAO) MarkFailed(ctx context.Context, id, version int64, retryCnt int, lastErr string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkFailed", ctx, id, version, retryCnt, lastErr)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MarkFailed indicates an expected call of MarkFailed.
+func (mr *MockAsyncSmsDAOMockRecorder) MarkFailed(ctx, id, version, retryCnt, lastErr interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkFailed", reflect.TypeOf((*MockAsyncSmsDAO)(nil).MarkFailed), ctx, id, version, retryCnt, lastErr)
+}
+
+// MarkRetry mocks base method.
+func (m *MockAsyncSmsDAO) MarkRetry(ctx context.Context, id, version int64, numbers string, retryCnt int, nextTime int64, lastErr string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkRetry", ctx, id, version, numbers, retryCnt, nextTime, lastErr)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MarkRetry indicates an expected call of MarkRetry.
+func (mr *MockAsyncSmsDAOMockRecorder) MarkRetry(ctx, id, version, numbers, retryCnt, nextTime, lastErr interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkRetry", reflect.TypeOf((*MockAsyncSmsDAO)(nil).MarkRetry), ctx, id, version, numbers, retryCnt, nextTime, lastErr)
+}
+
+// MarkSuccess mocks base method.
+func (m *MockAsyncSmsDAO) MarkSuccess(ctx context.Context, id, version int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkSuccess", ctx, id, version)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MarkSuccess indicates an expected call of MarkSuccess.
+func (mr *MockAsyncSmsDAOMockRecorder) MarkSuccess(ctx, id, version interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkSuccess", reflect.TypeOf((*MockAsyncSmsDAO)(nil).MarkSuccess), ctx, id, version)
+}
+
+// Preempt mocks base method.
+func (m *MockAsyncSmsDAO) Preempt(ctx context.Context, staleBefore int64) (dao.AsyncSms, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Preempt", ctx, staleBefore)
+	ret0, _ := ret[0].(dao.AsyncSms)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Preempt indicates an expected call of Preempt.
+func (mr *MockAsyncSmsDAOMockRecorder) Preempt(ctx, staleBefore interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Preempt", reflect.TypeOf((*MockAsyncSmsDAO)(nil).Preempt), ctx, staleBefore)
+}
+
+// MockUploadDAO is a mock of UploadDAO interface.
+type MockUploadDAO struct {
+	ctrl     *gomock.Controller
+	recorder *MockUploadDAOMockRecorder
+}
+
+// MockUploadDAOMockRecorder is the mock recorder for MockUploadDAO.
+type MockUploadDAOMockRecorder struct {
+	mock *MockUploadDAO
+}
+
+// NewMockUploadDAO creates a new mock instance.
+func NewMockUploadDAO(ctrl *gomock.Controller) *MockUploadDAO {
+	mock := &MockUploadDAO{ctrl: ctrl}
+	mock.recorder = &MockUploadDAOMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockUploadDAO) EXPECT() *MockUploadDAOMockRecorder {
+	return m.recorder
+}
+
+// FindByHash mocks base method.
+func (m *MockUploadDAO) FindByHash(ctx context.Context, hash string) (dao.UploadObject, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindByHash", ctx, hash)
+	ret0, _ := ret[0].(dao.UploadObject)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindByHash indicates an expected call of FindByHash.
+func (mr *MockUploadDAOMockRecorder) FindByHash(ctx, hash interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindByHash", reflect.TypeOf((*MockUploadDAO)(nil).FindByHash), ctx, hash)
+}
+
+// Insert mocks base method.
+func (m *MockUploadDAO) Insert(ctx context.Context, obj dao.UploadObject) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Insert", ctx, obj)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Insert indicates an expected call of Insert.
+func (mr *MockUploadDAOMockRecorder) Insert(ctx, obj interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Insert", reflect.TypeOf((*MockUploadDAO)(nil).Insert), ctx, obj)
+}
+
+// MockRBACDAO is a mock of RBACDAO interface.
+type MockRBACDAO struct {
+	ctrl     *gomock.Controller
+	recorder *MockRBACDAOMockRecorder
+}
+
+// MockRBACDAOMockRecorder is the mock recorder for MockRBACDAO.
+type MockRBACDAOMockRecorder struct {
+	mock *MockRBACDAO
+}
+
+// NewMockRBACDAO creates a new mock instance.
+func NewMockRBACDAO(ctrl *gomock.Controller) *MockRBACDAO {
+	mock := &MockRBACDAO{ctrl: ctrl}
+	mock.recorder = &MockRBACDAOMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockRBACDAO) EXPECT() *MockRBACDAOMockRecorder {
+	return m.recorder
+}
+
+// DeleteUserRole mocks base method.
+func (m *MockRBACDAO) DeleteUserRole(ctx context.Context, uid, roleId int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteUserRole", ctx, uid, roleId)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteUserRole indicates an expected call of DeleteUserRole.
+func (mr *MockRBACDAOMockRecorder) DeleteUserRole(ctx, uid, roleId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteUserRole", reflect.TypeOf((*MockRBACDAO)(nil).DeleteUserRole), ctx, uid, roleId)
+}
+
+// FindPermissionsByUid mocks base method.
+func (m *MockRBACDAO) FindPermissionsByUid(ctx context.Context, uid int64) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindPermissionsByUid", ctx, uid)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindPermissionsByUid indicates an expected call of FindPermissionsByUid.
+func (mr *MockRBACDAOMockRecorder) FindPermissionsByUid(ctx, uid interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindPermissionsByUid", reflect.TypeOf((*MockRBACDAO)(nil).FindPermissionsByUid), ctx, uid)
+}
+
+// FindRoleByName mocks base method.
+func (m *MockRBACDAO) FindRoleByName(ctx context.Context, name string) (dao.Role, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindRoleByName", ctx, name)
+	ret0, _ := ret[0].(dao.Role)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindRoleByName indicates an expected call of FindRoleByName.
+func (mr *MockRBACDAOMockRecorder) FindRoleByName(ctx, name interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindRoleByName", reflect.TypeOf((*MockRBACDAO)(nil).FindRoleByName), ctx, name)
+}
+
+// FindRolesByUid mocks base method.
+func (m *MockRBACDAO) FindRolesByUid(ctx context.Context, uid int64) ([]dao.Role, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindRolesByUid", ctx, uid)
+	ret0, _ := ret[0].([]dao.Role)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindRolesByUid indicates an expected call of FindRolesByUid.
+func (mr *MockRBACDAOMockRecorder) FindRolesByUid(ctx, uid interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindRolesByUid", reflect.TypeOf((*MockRBACDAO)(nil).FindRolesByUid), ctx, uid)
+}
+
+// InsertUserRole mocks base method.
+func (m *MockRBACDAO) InsertUserRole(ctx context.Context, uid, roleId int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InsertUserRole", ctx, uid, roleId)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// InsertUserRole indicates an expected call of InsertUserRole.
+func (mr *MockRBACDAOMockRecorder) InsertUserRole(ctx, uid, roleId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertUserRole", reflect.TypeOf((*MockRBACDAO)(nil).InsertUserRole), ctx, uid, roleId)
+}
+
+// MockFollowDAO is a mock of FollowDAO interface.
+type MockFollowDAO struct {
+	ctrl     *gomock.Controller
+	recorder *MockFollowDAOMockRecorder
+}
+
+// MockFollowDAOMockRecorder is the mock recorder for MockFollowDAO.
+type MockFollowDAOMockRecorder struct {
+	mock *MockFollowDAO
+}
+
+// NewMockFollowDAO creates a new mock instance.
+func NewMockFollowDAO(ctrl *gomock.Controller) *MockFollowDAO {
+	mock := &MockFollowDAO{ctrl: ctrl}
+	mock.recorder = &MockFollowDAOMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockFollowDAO) EXPECT() *MockFollowDAOMockRecorder {
+	return m.recorder
+}
+
+// Delete mocks base method.
+func (m *MockFollowDAO) Delete(ctx context.Context, follower, followee int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", ctx, follower, followee)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete.
+func (mr *MockFollowDAOMockRecorder) Delete(ctx, follower, followee interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockFollowDAO)(nil).Delete), ctx, follower, followee)
+}
+
+// FolloweeIds mocks base method.
+func (m *MockFollowDAO) FolloweeIds(ctx context.Context, follower int64, limit int) ([]int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FolloweeIds", ctx, follower, limit)
+	ret0, _ := ret[0].([]int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FolloweeIds indicates an expected call of FolloweeIds.
+func (mr *MockFollowDAOMockRecorder) FolloweeIds(ctx, follower, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FolloweeIds", reflect.TypeOf((*MockFollowDAO)(nil).FolloweeIds), ctx, follower, limit)
+}
+
+// FollowerIds mocks base method.
+func (m *MockFollowDAO) FollowerIds(ctx context.Context, followee int64) ([]int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FollowerIds", ctx, followee)
+	ret0, _ := ret[0].([]int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FollowerIds indicates an expected call of FollowerIds.
+func (mr *MockFollowDAOMockRecorder) FollowerIds(ctx, followee interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FollowerIds", reflect.TypeOf((*MockFollowDAO)(nil).FollowerIds), ctx, followee)
+}
+
+// Get mocks base method.
+func (m *MockFollowDAO) Get(ctx context.Context, follower, followee int64) (dao.FollowRelation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Get", ctx, follower, followee)
+	ret0, _ := ret[0].(dao.FollowRelation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Get indicates an expected call of Get.
+func (mr *MockFollowDAOMockRecorder) Get(ctx, follower, followee interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockFollowDAO)(nil).Get), ctx, follower, followee)
+}
+
+// GetStatics mocks base method.
+func (m *MockFollowDAO) GetStatics(ctx context.Context, uid int64) (dao.FollowStatics, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetStatics", ctx, uid)
+	ret0, _ := ret[0].(dao.FollowStatics)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetStatics indicates an expected call of GetStatics.
+func (mr *MockFollowDAOMockRecorder) GetStatics(ctx, uid interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetStatics", reflect.TypeOf((*MockFollowDAO)(nil).GetStatics), ctx, uid)
+}
+
+// GetStaticsByUids mocks base method.
+func (m *MockFollowDAO) GetStaticsByUids(ctx context.Context, uids []int64) ([]dao.FollowStatics, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetStaticsByUids", ctx, uids)
+	ret0, _ := ret[0].([]dao.FollowStatics)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetStaticsByUids indicates an expected call of GetStaticsByUids.
+func (mr *MockFollowDAOMockRecorder) GetStaticsByUids(ctx, uids interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetStaticsByUids", reflect.TypeOf((*MockFollowDAO)(nil).GetStaticsByUids), ctx, uids)
+}
+
+// Insert mocks base method.
+func (m *MockFollowDAO) Insert(ctx context.Context, follower, followee int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Insert", ctx, follower, followee)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Insert indicates an expected call of Insert.
+func (mr *MockFollowDAOMockRecorder) Insert(ctx, follower, followee interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Insert", reflect.TypeOf((*MockFollowDAO)(nil).Insert), ctx, follower, followee)
+}
+
+// ListFollowees mocks base method.
+func (m *MockFollowDAO) ListFollowees(ctx context.Context, follower int64, offset, limit int) ([]dao.FollowRelation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListFollowees", ctx, follower, offset, limit)
+	ret0, _ := ret[0].([]dao.FollowRelation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListFollowees indicates an expected call of ListFollowees.
+func (mr *MockFollowDAOMockRecorder) ListFollowees(ctx, follower, offset, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListFollowees", reflect.TypeOf((*MockFollowDAO)(nil).ListFollowees), ctx, follower, offset, limit)
+}
+
+// ListFollowers mocks base method.
+func (m *MockFollowDAO) ListFollowers(ctx context.Context, followee int64, offset, limit int) ([]dao.FollowRelation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListFollowers", ctx, followee, offset, limit)
+	ret0, _ := ret[0].([]dao.FollowRelation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListFollowers indicates an expected call of ListFollowers.
+func (mr *MockFollowDAOMockRecorder) ListFollowers(ctx, followee, offset, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListFollowers", reflect.TypeOf((*MockFollowDAO)(nil).ListFollowers), ctx, followee, offset, limit)
+}
+
+// MockFeedDAO is a mock of FeedDAO interface.
+type MockFeedDAO struct {
+	ctrl     *gomock.Controller
+	recorder *MockFeedDAOMockRecorder
+}
+
+// MockFeedDAOMockRecorder is the mock recorder for MockFeedDAO.
+type MockFeedDAOMockRecorder struct {
+	mock *MockFeedDAO
+}
+
+// NewMockFeedDAO creates a new mock instance.
+func NewMockFeedDAO(ctrl *gomock.Controller) *MockFeedDAO {
+	mock := &MockFeedDAO{ctrl: ctrl}
+	mock.recorder = &MockFeedDAOMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockFeedDAO) EXPECT() *MockFeedDAOMockRecorder {
+	return m.recorder
+}
+
+// InsertInbox mocks base method.
+func (m *MockFeedDAO) InsertInbox(ctx context.Context, items []dao.FeedInbox) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InsertInbox", ctx, items)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// InsertInbox indicates an expected call of InsertInbox.
+func (mr *MockFeedDAOMockRecorder) InsertInbox(ctx, items interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertInbox", reflect.TypeOf((*MockFeedDAO)(nil).InsertInbox), ctx, items)
+}
+
+// ListInbox mocks base method.
+func (m *MockFeedDAO) ListInbox(ctx context.Context, uid, before int64, limit int) ([]dao.FeedInbox, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListInbox", ctx, uid, before, limit)
+	ret0, _ := ret[0].([]dao.FeedInbox)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListInbox indicates an expected call of ListInbox.
+func (mr *MockFeedDAOMockRecorder) ListInbox(ctx, uid, before, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListInbox", reflect.TypeOf((*MockFeedDAO)(nil).ListInbox), ctx, uid, before, limit)
+}
@@ -0,0 +1,85 @@
+package dao
+
+import (
+	"context"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"time"
+)
+
+var ErrRoleNotFound = gorm.ErrRecordNotFound
+
+type GORMRBACDAO struct {
+	db *gorm.DB
+}
+
+func NewGORMRBACDAO(db *gorm.DB) RBACDAO {
+	return &GORMRBACDAO{db: db}
+}
+
+func (dao *GORMRBACDAO) FindRoleByName(ctx context.Context, name string) (Role, error) {
+	var r Role
+	err := dao.db.WithContext(ctx).Where("name = ?", name).First(&r).Error
+	return r, err
+}
+
+func (dao *GORMRBACDAO) FindRolesByUid(ctx context.Context, uid int64) ([]Role, error) {
+	var roles []Role
+	err := dao.db.WithContext(ctx).
+		Where("id IN (?)", dao.db.Model(&UserRole{}).Select("role_id").Where("uid = ?", uid)).
+		Order("id ASC").
+		Find(&roles).Error
+	return roles, err
+}
+
+func (dao *GORMRBACDAO) FindPermissionsByUid(ctx context.Context, uid int64) ([]string, error) {
+	var perms []string
+	err := dao.db.WithContext(ctx).Model(&RolePermission{}).
+		Distinct("permission").
+		Where("role_id IN (?)", dao.db.Model(&UserRole{}).Select("role_id").Where("uid = ?", uid)).
+		Order("permission ASC").
+		Pluck("permission", &perms).Error
+	return perms, err
+}
+
+func (dao *GORMRBACDAO) InsertUserRole(ctx context.Context, uid int64, roleId int64) error {
+	return dao.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&UserRole{
+			Uid:    uid,
+			RoleId: roleId,
+			Ctime:  time.Now().UnixMilli(),
+		}).Error
+}
+
+func (dao *GORMRBACDAO) DeleteUserRole(ctx context.Context, uid int64, roleId int64) error {
+	return dao.db.WithContext(ctx).Where("uid = ? AND role_id = ?", uid, roleId).
+		Delete(&UserRole{}).Error
+}
+
+// InitBuiltinRoles 写入内置的角色和权限，可以重复执行
+// 只会补上缺少的，已经不在 roles 里面的权限不会删除，要手动处理
+func InitBuiltinRoles(db *gorm.DB, roles map[string][]string) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		now := time.Now().UnixMilli()
+		for name, perms := range roles {
+			err := tx.Clauses(clause.OnConflict{DoNothing: true}).
+				Create(&Role{Name: name, Ctime: now, Utime: now}).Error
+			if err != nil {
+				return err
+			}
+			var r Role
+			err = tx.Where("name = ?", name).First(&r).Error
+			if err != nil {
+				return err
+			}
+			for _, perm := range perms {
+				err = tx.Clauses(clause.OnConflict{DoNothing: true}).
+					Create(&RolePermission{RoleId: r.Id, Permission: perm, Ctime: now}).Error
+				if err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+}
@@ -22,6 +22,75 @@ type ArticleDAO interface {
 	Sync(ctx context.Context, art Article) (int64, error)
 	Upsert(ctx context.Context, art PublishedArticle) error
 	SyncStatus(ctx context.Context, id int64, authorId int64, status uint8) error
+	// Withdraw 把线上库里处于 published 状态的文章改为 private
+	Withdraw(ctx context.Context, id int64, authorId int64, published uint8, private uint8) error
+	// GetByAuthor 按照更新时间倒序，分页查询作者的文章
+	GetByAuthor(ctx context.Context, authorId int64, offset int, limit int) ([]Article, error)
+	// GetByAuthorCursor 使用 (utime, id) 作为游标，查询排在游标之后的文章
+	GetByAuthorCursor(ctx context.Context, authorId int64, utime int64, id int64, limit int) ([]Article, error)
+	GetById(ctx context.Context, id int64) (Article, error)
+	// GetPubById 从线上库查询处于 status 状态的文章
+	GetPubById(ctx context.Context, id int64, status uint8) (PublishedArticle, error)
+	// ListPub 从线上库分页查询处于 status 状态的文章，按照更新时间倒序
+	ListPub(ctx context.Context, status uint8, offset int, limit int) ([]PublishedArticle, error)
+	// ListPubSince 从线上库分页查询 since 之后发表的、处于 status 状态的文章，按照发表时间倒序
+	ListPubSince(ctx context.Context, status uint8, since int64, offset int, limit int) ([]PublishedArticle, error)
+	// ListPubByIds 从线上库批量查询处于 status 状态的文章，不保证顺序
+	ListPubByIds(ctx context.Context, ids []int64, status uint8) ([]PublishedArticle, error)
+	// ListPubByAuthors 从线上库查询这些作者在 before 之前发表的文章，按照发表时间倒序
+	ListPubByAuthors(ctx context.Context, authorIds []int64, status uint8, before int64, limit int) ([]PublishedArticle, error)
+	// SoftDelete 把文章放进回收站，制作库和线上库一起标记
+	SoftDelete(ctx context.Context, id int64, authorId int64) error
+	// ListDeleted 按照删除时间倒序，查询作者在 after 之后删除的文章
+	ListDeleted(ctx context.Context, authorId int64, after int64, offset int, limit int) ([]Article, error)
+	// RestoreDeleted 恢复在 after 之后删除的文章
+	RestoreDeleted(ctx context.Context, id int64, authorId int64, after int64) error
+	// PurgeDeleted 彻底删除在 before 之前删除的文章，一次最多 limit 篇，返回删除的文章 ID
+	// 评论、收藏夹、关注流、互动计数这些挂在文章上面的数据一起删除，biz 是互动数据里面文章的业务标识
+	PurgeDeleted(ctx context.Context, biz string, before int64, limit int) ([]int64, error)
+	// ListByStatus 查询制作库里处于 status 状态的文章，不包括回收站里的
+	ListByStatus(ctx context.Context, status uint8) ([]Article, error)
+	// UpdateSchedule 只修改制作库里的状态和定时发表的时间，要求当前状态是 from
+	UpdateSchedule(ctx context.Context, id int64, authorId int64, from uint8, to uint8, publishAt int64) error
+	// ListByStatusAsc 按照更新时间正序，分页查询制作库里处于 status 状态的文章
+	ListByStatusAsc(ctx context.Context, status uint8, offset int, limit int) ([]Article, error)
+	// ListPubByTag 从线上库查询带有这个标签的文章，按照更新时间倒序
+	ListPubByTag(ctx context.Context, tag string, status uint8, offset int, limit int) ([]PublishedArticle, error)
+	// ListPubTags 线上库里文章最多的 limit 个标签，以及文章数量
+	ListPubTags(ctx context.Context, status uint8, limit int) ([]TagCount, error)
+}
+
+type ArticleReviewDAO interface {
+	Insert(ctx context.Context, r ArticleReview) (int64, error)
+	// FindLatest 文章最近一次的审核记录
+	FindLatest(ctx context.Context, artId int64) (ArticleReview, error)
+}
+
+type ArticleRevisionDAO interface {
+	// List 按照时间倒序查询文章的历史版本
+	List(ctx context.Context, artId int64, offset int, limit int) ([]ArticleRevision, error)
+	GetById(ctx context.Context, id int64) (ArticleRevision, error)
+	// Prune 清理历史版本，只保留最近的 keep 个，并且删除 before 之前的版本
+	// keep 或者 before 为 0 表示不按照这个条件清理，最新的版本无论如何都会保留
+	Prune(ctx context.Context, artId int64, keep int, before int64) error
+}
+
+type InteractiveDAO interface {
+	IncrReadCnt(ctx context.Context, biz string, bizId int64) error
+	// InsertLikeInfo 点赞，并且增加点赞数，已经点过赞了返回 ErrLikeDuplicate
+	InsertLikeInfo(ctx context.Context, biz string, bizId int64, uid int64) error
+	// DeleteLikeInfo 取消点赞，并且减少点赞数，没有点过赞返回 ErrLikeNotFound
+	DeleteLikeInfo(ctx context.Context, biz string, bizId int64, uid int64) error
+	// InsertCollectionInfo 收藏，并且增加收藏数，已经收藏过了返回 ErrCollectionDuplicate
+	InsertCollectionInfo(ctx context.Context, biz string, bizId int64, uid int64) error
+	// DeleteCollectionInfo 取消收藏，并且减少收藏数，没有收藏过返回 ErrCollectionNotFound
+	DeleteCollectionInfo(ctx context.Context, biz string, bizId int64, uid int64) error
+	Get(ctx context.Context, biz string, bizId int64) (Interactive, error)
+	// GetByIds 批量查询，没有互动过的资源不会返回
+	GetByIds(ctx context.Context, biz string, bizIds []int64) ([]Interactive, error)
+	// GetLikeInfo 查询有效的点赞记录
+	GetLikeInfo(ctx context.Context, biz string, bizId int64, uid int64) (UserLikeBiz, error)
+	GetCollectionInfo(ctx context.Context, biz string, bizId int64, uid int64) (UserCollectionBiz, error)
 }
 
 type ArticleAuthorDAO interface {
@@ -33,3 +102,107 @@ type ArticleReaderDAO interface {
 	Upsert(ctx context.Context, art Article) error
 	UpsertV2(ctx context.Context, art PublishedArticle) error
 }
+
+type CollectionFolderDAO interface {
+	// Insert 创建收藏夹，重名返回 ErrFolderNameDuplicate
+	Insert(ctx context.Context, folder CollectionFolder) (int64, error)
+	// UpdateName 重命名，收藏夹不存在或者不是 uid 的返回 ErrFolderNotFound
+	UpdateName(ctx context.Context, id int64, uid int64, name string) error
+	// Delete 删除收藏夹以及里面的文章
+	Delete(ctx context.Context, id int64, uid int64) error
+	GetById(ctx context.Context, id int64) (CollectionFolder, error)
+	// ListByUid 按照创建时间倒序查询用户的收藏夹
+	ListByUid(ctx context.Context, uid int64, offset int, limit int) ([]CollectionFolder, error)
+	// InsertItem 把文章放进收藏夹，已经在里面了返回 ErrFolderItemDuplicate
+	InsertItem(ctx context.Context, item CollectionFolderItem) error
+	// DeleteItem 把文章从收藏夹里面移除，本来就不在里面也算成功
+	DeleteItem(ctx context.Context, fid int64, artId int64) error
+	// ListItems 按照放进收藏夹的时间倒序分页查询
+	ListItems(ctx context.Context, fid int64, offset int, limit int) ([]CollectionFolderItem, error)
+}
+
+type CommentDAO interface {
+	// Insert 插入评论，同时增加文章的评论数，回复还会增加根评论的回复数
+	Insert(ctx context.Context, c Comment) (int64, error)
+	// Delete 删除评论，根评论会连同下面的回复一起删除，同时扣减评论数
+	Delete(ctx context.Context, c Comment) error
+	GetById(ctx context.Context, id int64) (Comment, error)
+	// FindRoots 按照 id 倒序查询文章的根评论，只返回 id 小于 minId 的，minId 为 0 表示从最新的开始
+	FindRoots(ctx context.Context, artId int64, minId int64, limit int) ([]Comment, error)
+	// FindReplies 按照 id 正序查询根评论下面的回复，只返回 id 大于 maxId 的
+	FindReplies(ctx context.Context, rootId int64, maxId int64, limit int) ([]Comment, error)
+	// GetCount 文章的评论数，还没有人评论过返回 0
+	GetCount(ctx context.Context, artId int64) (int64, error)
+}
+
+type CronJobDAO interface {
+	// Preempt 抢占一个到时间的任务，续约时间早于 staleBefore 的运行中任务也可以抢
+	// 没有可以抢的任务返回 ErrNoCronJob
+	Preempt(ctx context.Context, staleBefore int64) (CronJob, error)
+	// UpdateUtime 续约，任务已经被别人抢走了返回 ErrCronJobNotHold
+	UpdateUtime(ctx context.Context, id int64, version int64) error
+	// Release 释放任务，设置下一次运行的时间
+	Release(ctx context.Context, id int64, version int64, nextTime int64) error
+	// Pause 暂停任务，比如 cron 表达式有问题
+	Pause(ctx context.Context, id int64, version int64) error
+}
+
+type AsyncSmsDAO interface {
+	Insert(ctx context.Context, s AsyncSms) (int64, error)
+	// Preempt 抢占一条到时间的短信，发送中的短信 utime 早于 staleBefore 的说明 worker 崩溃了，也可以抢
+	// 没有可以发送的短信返回 ErrNoAsyncSms
+	Preempt(ctx context.Context, staleBefore int64) (AsyncSms, error)
+	MarkSuccess(ctx context.Context, id int64, version int64) error
+	// MarkRetry 放回去等待 nextTime 再发，numbers 是还没有发出去的号码
+	MarkRetry(ctx context.Context, id int64, version int64, numbers string,
+		retryCnt int, nextTime int64, lastErr string) error
+	// MarkSuccess、MarkFailed 和 MarkExpired 都会清空参数
+	MarkFailed(ctx context.Context, id int64, version int64, retryCnt int, lastErr string) error
+	MarkExpired(ctx context.Context, id int64, version int64, lastErr string) error
+}
+
+type UploadDAO interface {
+	// FindByHash 没有返回 ErrUploadNotFound
+	FindByHash(ctx context.Context, hash string) (UploadObject, error)
+	// Insert 同样的内容已经有了不会报错，以先写入的为准
+	Insert(ctx context.Context, obj UploadObject) error
+}
+
+type RBACDAO interface {
+	// FindRoleByName 角色不存在返回 ErrRoleNotFound
+	FindRoleByName(ctx context.Context, name string) (Role, error)
+	FindRolesByUid(ctx context.Context, uid int64) ([]Role, error)
+	// FindPermissionsByUid 用户所有角色的权限，去重
+	FindPermissionsByUid(ctx context.Context, uid int64) ([]string, error)
+	// InsertUserRole 授予角色，已经有了也不会报错
+	InsertUserRole(ctx context.Context, uid int64, roleId int64) error
+	// DeleteUserRole 撤销角色，没有这个角色也不会报错
+	DeleteUserRole(ctx context.Context, uid int64, roleId int64) error
+}
+
+type FollowDAO interface {
+	// Insert 关注，同时更新双方的计数，已经关注过了返回 ErrFollowDuplicate
+	Insert(ctx context.Context, follower int64, followee int64) error
+	// Delete 取消关注，同时更新双方的计数，没有关注过返回 ErrFollowNotFound
+	Delete(ctx context.Context, follower int64, followee int64) error
+	Get(ctx context.Context, follower int64, followee int64) (FollowRelation, error)
+	// ListFollowers 按照关注时间倒序查询粉丝
+	ListFollowers(ctx context.Context, followee int64, offset int, limit int) ([]FollowRelation, error)
+	// ListFollowees 按照关注时间倒序查询关注的人
+	ListFollowees(ctx context.Context, follower int64, offset int, limit int) ([]FollowRelation, error)
+	// FolloweeIds 最近关注的 limit 个人
+	FolloweeIds(ctx context.Context, follower int64, limit int) ([]int64, error)
+	// FollowerIds 所有的粉丝
+	FollowerIds(ctx context.Context, followee int64) ([]int64, error)
+	// GetStatics 查询计数，没有数据的时候返回全 0
+	GetStatics(ctx context.Context, uid int64) (FollowStatics, error)
+	// GetStaticsByUids 批量查询计数，没有数据的用户不会返回
+	GetStaticsByUids(ctx context.Context, uids []int64) ([]FollowStatics, error)
+}
+
+type FeedDAO interface {
+	// InsertInbox 推送到粉丝的收件箱，已经推送过的文章只更新时间
+	InsertInbox(ctx context.Context, items []FeedInbox) error
+	// ListInbox 按照发表时间倒序查询 before 之前的
+	ListInbox(ctx context.Context, uid int64, before int64, limit int) ([]FeedInbox, error)
+}
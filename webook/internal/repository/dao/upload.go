@@ -0,0 +1,30 @@
+package dao
+
+import (
+	"context"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"time"
+)
+
+var ErrUploadNotFound = gorm.ErrRecordNotFound
+
+type GORMUploadDAO struct {
+	db *gorm.DB
+}
+
+func NewGORMUploadDAO(db *gorm.DB) UploadDAO {
+	return &GORMUploadDAO{db: db}
+}
+
+func (dao *GORMUploadDAO) FindByHash(ctx context.Context, hash string) (UploadObject, error) {
+	var obj UploadObject
+	err := dao.db.WithContext(ctx).Where("hash = ?", hash).First(&obj).Error
+	return obj, err
+}
+
+func (dao *GORMUploadDAO) Insert(ctx context.Context, obj UploadObject) error {
+	obj.Ctime = time.Now().UnixMilli()
+	// 两个人同时上传同样的内容，key 也是一样的，谁先写入都可以
+	return dao.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(&obj).Error
+}
@@ -0,0 +1,36 @@
+package repository
+
+import (
+	"context"
+	"github.com/ecodeclub/ekit/slice"
+	"time"
+	"webook/webook/internal/repository/dao"
+)
+
+type CacheFeedRepository struct {
+	dao dao.FeedDAO
+}
+
+func NewCacheFeedRepository(dao dao.FeedDAO) FeedRepository {
+	return &CacheFeedRepository{dao: dao}
+}
+
+func (r *CacheFeedRepository) AddInbox(ctx context.Context, artId int64, uids []int64, ctime time.Time) error {
+	return r.dao.InsertInbox(ctx, slice.Map[int64, dao.FeedInbox](uids, func(idx int, src int64) dao.FeedInbox {
+		return dao.FeedInbox{
+			Uid:   src,
+			ArtId: artId,
+			Ctime: ctime.UnixMilli(),
+		}
+	}))
+}
+
+func (r *CacheFeedRepository) ListInbox(ctx context.Context, uid int64, before time.Time, limit int) ([]int64, error) {
+	items, err := r.dao.ListInbox(ctx, uid, before.UnixMilli(), limit)
+	if err != nil {
+		return nil, err
+	}
+	return slice.Map[dao.FeedInbox, int64](items, func(idx int, src dao.FeedInbox) int64 {
+		return src.ArtId
+	}), nil
+}
@@ -0,0 +1,149 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"github.com/ecodeclub/ekit/slice"
+	"time"
+	"webook/webook/internal/domain"
+	"webook/webook/internal/repository/cache"
+	"webook/webook/internal/repository/dao"
+)
+
+var ErrFollowDuplicate = dao.ErrFollowDuplicate
+
+type CachedFollowRepository struct {
+	dao      dao.FollowDAO
+	cache    cache.FollowCache
+	userRepo UserRepository
+}
+
+func NewCachedFollowRepository(dao dao.FollowDAO, cache cache.FollowCache, userRepo UserRepository) FollowRepository {
+	return &CachedFollowRepository{dao: dao, cache: cache, userRepo: userRepo}
+}
+
+func (r *CachedFollowRepository) Follow(ctx context.Context, follower int64, followee int64) error {
+	err := r.dao.Insert(ctx, follower, followee)
+	if err != nil {
+		return err
+	}
+	_ = r.cache.Follow(ctx, follower, followee)
+	return nil
+}
+
+func (r *CachedFollowRepository) CancelFollow(ctx context.Context, follower int64, followee int64) error {
+	err := r.dao.Delete(ctx, follower, followee)
+	if errors.Is(err, dao.ErrFollowNotFound) {
+		// 本来就没有关注，计数也不需要变化
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	_ = r.cache.CancelFollow(ctx, follower, followee)
+	return nil
+}
+
+func (r *CachedFollowRepository) Followed(ctx context.Context, follower int64, followee int64) (bool, error) {
+	_, err := r.dao.Get(ctx, follower, followee)
+	switch {
+	case err == nil:
+		return true, nil
+	case errors.Is(err, dao.ErrFollowNotFound):
+		return false, nil
+	default:
+		return false, err
+	}
+}
+
+func (r *CachedFollowRepository) ListFollowers(ctx context.Context, uid int64, offset int, limit int) ([]domain.FollowRelation, error) {
+	rs, err := r.dao.ListFollowers(ctx, uid, offset, limit)
+	if err != nil {
+		return nil, err
+	}
+	res := r.toDomains(rs)
+	for i := range res {
+		res[i].Follower.Name, err = r.nickname(ctx, res[i].Follower.Id)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return res, nil
+}
+
+func (r *CachedFollowRepository) ListFollowees(ctx context.Context, uid int64, offset int, limit int) ([]domain.FollowRelation, error) {
+	rs, err := r.dao.ListFollowees(ctx, uid, offset, limit)
+	if err != nil {
+		return nil, err
+	}
+	res := r.toDomains(rs)
+	for i := range res {
+		res[i].Followee.Name, err = r.nickname(ctx, res[i].Followee.Id)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return res, nil
+}
+
+func (r *CachedFollowRepository) FolloweeIds(ctx context.Context, uid int64, limit int) ([]int64, error) {
+	return r.dao.FolloweeIds(ctx, uid, limit)
+}
+
+func (r *CachedFollowRepository) FollowerIds(ctx context.Context, uid int64) ([]int64, error) {
+	return r.dao.FollowerIds(ctx, uid)
+}
+
+func (r *CachedFollowRepository) GetStatics(ctx context.Context, uid int64) (domain.FollowStatics, error) {
+	s, err := r.cache.GetStatics(ctx, uid)
+	if err == nil {
+		return s, nil
+	}
+	se, err := r.dao.GetStatics(ctx, uid)
+	if err != nil {
+		return domain.FollowStatics{}, err
+	}
+	s = r.staticsToDomain(se)
+	_ = r.cache.SetStatics(ctx, s)
+	return s, nil
+}
+
+func (r *CachedFollowRepository) GetStaticsByUids(ctx context.Context, uids []int64) (map[int64]domain.FollowStatics, error) {
+	ss, err := r.dao.GetStaticsByUids(ctx, uids)
+	if err != nil {
+		return nil, err
+	}
+	res := make(map[int64]domain.FollowStatics, len(uids))
+	for _, se := range ss {
+		res[se.Uid] = r.staticsToDomain(se)
+	}
+	return res, nil
+}
+
+// nickname 列表里面每个人都不一样，没有必要去重
+func (r *CachedFollowRepository) nickname(ctx context.Context, uid int64) (string, error) {
+	u, err := r.userRepo.FindById(ctx, uid)
+	if err != nil {
+		return "", err
+	}
+	return u.NickName, nil
+}
+
+func (r *CachedFollowRepository) toDomains(rs []dao.FollowRelation) []domain.FollowRelation {
+	return slice.Map[dao.FollowRelation, domain.FollowRelation](rs, func(idx int, src dao.FollowRelation) domain.FollowRelation {
+		return domain.FollowRelation{
+			Id:       src.Id,
+			Follower: domain.Author{Id: src.Follower},
+			Followee: domain.Author{Id: src.Followee},
+			Ctime:    time.UnixMilli(src.Ctime),
+		}
+	})
+}
+
+func (r *CachedFollowRepository) staticsToDomain(se dao.FollowStatics) domain.FollowStatics {
+	return domain.FollowStatics{
+		Uid:       se.Uid,
+		Followers: se.Followers,
+		Followees: se.Followees,
+	}
+}
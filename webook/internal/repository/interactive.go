@@ -0,0 +1,145 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"webook/webook/internal/domain"
+	"webook/webook/internal/repository/cache"
+	"webook/webook/internal/repository/dao"
+)
+
+var (
+	ErrLikeDuplicate       = dao.ErrLikeDuplicate
+	ErrCollectionDuplicate = dao.ErrCollectionDuplicate
+)
+
+type CachedInteractiveRepository struct {
+	dao   dao.InteractiveDAO
+	cache cache.InteractiveCache
+}
+
+func NewCachedInteractiveRepository(dao dao.InteractiveDAO, cache cache.InteractiveCache) InteractiveRepository {
+	return &CachedInteractiveRepository{dao: dao, cache: cache}
+}
+
+// IncrReadCnt 先更新数据库，再更新缓存
+// 缓存更新失败最多是计数不准，过期之后就会从数据库重新加载，所以不返回缓存的错误
+func (r *CachedInteractiveRepository) IncrReadCnt(ctx context.Context, biz string, bizId int64) error {
+	err := r.dao.IncrReadCnt(ctx, biz, bizId)
+	if err != nil {
+		return err
+	}
+	_ = r.cache.IncrReadCntIfPresent(ctx, biz, bizId)
+	return nil
+}
+
+func (r *CachedInteractiveRepository) IncrLike(ctx context.Context, biz string, bizId int64, uid int64) error {
+	err := r.dao.InsertLikeInfo(ctx, biz, bizId, uid)
+	if err != nil {
+		return err
+	}
+	_ = r.cache.IncrLikeCntIfPresent(ctx, biz, bizId)
+	return nil
+}
+
+func (r *CachedInteractiveRepository) DecrLike(ctx context.Context, biz string, bizId int64, uid int64) error {
+	err := r.dao.DeleteLikeInfo(ctx, biz, bizId, uid)
+	if errors.Is(err, dao.ErrLikeNotFound) {
+		// 本来就没有点赞，计数也不需要变化
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	_ = r.cache.DecrLikeCntIfPresent(ctx, biz, bizId)
+	return nil
+}
+
+func (r *CachedInteractiveRepository) AddCollectionItem(ctx context.Context, biz string, bizId int64, uid int64) error {
+	err := r.dao.InsertCollectionInfo(ctx, biz, bizId, uid)
+	if err != nil {
+		return err
+	}
+	_ = r.cache.IncrCollectCntIfPresent(ctx, biz, bizId)
+	return nil
+}
+
+func (r *CachedInteractiveRepository) DeleteCollectionItem(ctx context.Context, biz string, bizId int64, uid int64) error {
+	err := r.dao.DeleteCollectionInfo(ctx, biz, bizId, uid)
+	if errors.Is(err, dao.ErrCollectionNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	_ = r.cache.DecrCollectCntIfPresent(ctx, biz, bizId)
+	return nil
+}
+
+func (r *CachedInteractiveRepository) Get(ctx context.Context, biz string, bizId int64) (domain.Interactive, error) {
+	intr, err := r.cache.Get(ctx, biz, bizId)
+	if err == nil {
+		return intr, nil
+	}
+	// 缓存没有数据，或者 Redis 出问题了，都去查数据库
+	ie, err := r.dao.Get(ctx, biz, bizId)
+	switch {
+	case err == nil:
+		intr = r.toDomain(ie)
+	case errors.Is(err, dao.ErrInteractiveNotFound):
+		// 还没有人互动过
+		intr = domain.Interactive{Biz: biz, BizId: bizId}
+	default:
+		return domain.Interactive{}, err
+	}
+	// 全 0 的也缓存起来，避免新文章一直打到数据库
+	_ = r.cache.Set(ctx, intr)
+	return intr, nil
+}
+
+func (r *CachedInteractiveRepository) GetByIds(ctx context.Context, biz string,
+	bizIds []int64) (map[int64]domain.Interactive, error) {
+	intrs, err := r.dao.GetByIds(ctx, biz, bizIds)
+	if err != nil {
+		return nil, err
+	}
+	res := make(map[int64]domain.Interactive, len(bizIds))
+	for _, ie := range intrs {
+		res[ie.BizId] = r.toDomain(ie)
+	}
+	return res, nil
+}
+
+func (r *CachedInteractiveRepository) Liked(ctx context.Context, biz string, bizId int64, uid int64) (bool, error) {
+	_, err := r.dao.GetLikeInfo(ctx, biz, bizId, uid)
+	switch {
+	case err == nil:
+		return true, nil
+	case errors.Is(err, dao.ErrInteractiveNotFound):
+		return false, nil
+	default:
+		return false, err
+	}
+}
+
+func (r *CachedInteractiveRepository) Collected(ctx context.Context, biz string, bizId int64, uid int64) (bool, error) {
+	_, err := r.dao.GetCollectionInfo(ctx, biz, bizId, uid)
+	switch {
+	case err == nil:
+		return true, nil
+	case errors.Is(err, dao.ErrInteractiveNotFound):
+		return false, nil
+	default:
+		return false, err
+	}
+}
+
+func (r *CachedInteractiveRepository) toDomain(ie dao.Interactive) domain.Interactive {
+	return domain.Interactive{
+		Biz:        ie.Biz,
+		BizId:      ie.BizId,
+		ReadCnt:    ie.ReadCnt,
+		LikeCnt:    ie.LikeCnt,
+		CollectCnt: ie.CollectCnt,
+	}
+}
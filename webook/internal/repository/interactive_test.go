@@ -0,0 +1,124 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+	"testing"
+	"webook/webook/internal/domain"
+	"webook/webook/internal/repository/cache"
+	cache2 "webook/webook/internal/repository/cache/Redis"
+	cachemocks "webook/webook/internal/repository/cache/mocks"
+	"webook/webook/internal/repository/dao"
+	daomocks "webook/webook/internal/repository/dao/mocks"
+)
+
+func TestCachedInteractiveRepository_Get(t *testing.T) {
+	testCases := []struct {
+		name     string
+		mock     func(ctrl *gomock.Controller) (dao.InteractiveDAO, cache.InteractiveCache)
+		wantIntr domain.Interactive
+		wantErr  error
+	}{
+		{
+			name: "命中缓存",
+			mock: func(ctrl *gomock.Controller) (dao.InteractiveDAO, cache.InteractiveCache) {
+				c := cachemocks.NewMockInteractiveCache(ctrl)
+				c.EXPECT().Get(gomock.Any(), "article", int64(1)).Return(domain.Interactive{
+					Biz: "article", BizId: 1, ReadCnt: 10, LikeCnt: 2,
+				}, nil)
+				return daomocks.NewMockInteractiveDAO(ctrl), c
+			},
+			wantIntr: domain.Interactive{Biz: "article", BizId: 1, ReadCnt: 10, LikeCnt: 2},
+		},
+		{
+			name: "未命中缓存，查询数据库成功",
+			mock: func(ctrl *gomock.Controller) (dao.InteractiveDAO, cache.InteractiveCache) {
+				c := cachemocks.NewMockInteractiveCache(ctrl)
+				c.EXPECT().Get(gomock.Any(), "article", int64(1)).Return(domain.Interactive{}, cache2.ErrKeyNotExist)
+				d := daomocks.NewMockInteractiveDAO(ctrl)
+				d.EXPECT().Get(gomock.Any(), "article", int64(1)).Return(dao.Interactive{
+					Biz: "article", BizId: 1, ReadCnt: 10, LikeCnt: 2, CollectCnt: 3,
+				}, nil)
+				c.EXPECT().Set(gomock.Any(), domain.Interactive{
+					Biz: "article", BizId: 1, ReadCnt: 10, LikeCnt: 2, CollectCnt: 3,
+				}).Return(nil)
+				return d, c
+			},
+			wantIntr: domain.Interactive{Biz: "article", BizId: 1, ReadCnt: 10, LikeCnt: 2, CollectCnt: 3},
+		},
+		{
+			name: "还没有人互动过，缓存全 0",
+			mock: func(ctrl *gomock.Controller) (dao.InteractiveDAO, cache.InteractiveCache) {
+				c := cachemocks.NewMockInteractiveCache(ctrl)
+				c.EXPECT().Get(gomock.Any(), "article", int64(1)).Return(domain.Interactive{}, cache2.ErrKeyNotExist)
+				d := daomocks.NewMockInteractiveDAO(ctrl)
+				d.EXPECT().Get(gomock.Any(), "article", int64(1)).Return(dao.Interactive{}, dao.ErrInteractiveNotFound)
+				c.EXPECT().Set(gomock.Any(), domain.Interactive{Biz: "article", BizId: 1}).Return(nil)
+				return d, c
+			},
+			wantIntr: domain.Interactive{Biz: "article", BizId: 1},
+		},
+		{
+			name: "查询数据库失败",
+			mock: func(ctrl *gomock.Controller) (dao.InteractiveDAO, cache.InteractiveCache) {
+				c := cachemocks.NewMockInteractiveCache(ctrl)
+				c.EXPECT().Get(gomock.Any(), "article", int64(1)).Return(domain.Interactive{}, cache2.ErrKeyNotExist)
+				d := daomocks.NewMockInteractiveDAO(ctrl)
+				d.EXPECT().Get(gomock.Any(), "article", int64(1)).Return(dao.Interactive{}, errors.New("mock db error"))
+				return d, c
+			},
+			wantErr: errors.New("mock db error"),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			d, c := tc.mock(ctrl)
+			repo := NewCachedInteractiveRepository(d, c)
+			intr, err := repo.Get(context.Background(), "article", 1)
+			assert.Equal(t, tc.wantErr, err)
+			assert.Equal(t, tc.wantIntr, intr)
+		})
+	}
+}
+
+func TestCachedInteractiveRepository_IncrLike(t *testing.T) {
+	testCases := []struct {
+		name    string
+		mock    func(ctrl *gomock.Controller) (dao.InteractiveDAO, cache.InteractiveCache)
+		wantErr error
+	}{
+		{
+			name: "点赞成功，更新缓存",
+			mock: func(ctrl *gomock.Controller) (dao.InteractiveDAO, cache.InteractiveCache) {
+				d := daomocks.NewMockInteractiveDAO(ctrl)
+				d.EXPECT().InsertLikeInfo(gomock.Any(), "article", int64(1), int64(123)).Return(nil)
+				c := cachemocks.NewMockInteractiveCache(ctrl)
+				c.EXPECT().IncrLikeCntIfPresent(gomock.Any(), "article", int64(1)).Return(nil)
+				return d, c
+			},
+		},
+		{
+			name: "重复点赞，不更新缓存",
+			mock: func(ctrl *gomock.Controller) (dao.InteractiveDAO, cache.InteractiveCache) {
+				d := daomocks.NewMockInteractiveDAO(ctrl)
+				d.EXPECT().InsertLikeInfo(gomock.Any(), "article", int64(1), int64(123)).Return(dao.ErrLikeDuplicate)
+				return d, cachemocks.NewMockInteractiveCache(ctrl)
+			},
+			wantErr: ErrLikeDuplicate,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			d, c := tc.mock(ctrl)
+			repo := NewCachedInteractiveRepository(d, c)
+			err := repo.IncrLike(context.Background(), "article", 1, 123)
+			assert.Equal(t, tc.wantErr, err)
+		})
+	}
+}
@@ -0,0 +1,46 @@
+package repository
+
+import (
+	"context"
+	"webook/webook/internal/domain"
+	"webook/webook/internal/repository/cache"
+)
+
+// CachedRankingRepository 热榜只存在缓存里面
+// 先查本地缓存，再查 Redis，Redis 出问题了就用本地缓存里过期的数据兜底
+type CachedRankingRepository struct {
+	redis cache.RankingCache
+	local cache.RankingLocalCache
+}
+
+func NewCachedRankingRepository(redis cache.RankingCache, local cache.RankingLocalCache) RankingRepository {
+	return &CachedRankingRepository{redis: redis, local: local}
+}
+
+func (r *CachedRankingRepository) ReplaceTopN(ctx context.Context, arts []domain.Article) error {
+	// 本地缓存不会失败
+	_ = r.local.Set(ctx, arts)
+	return r.redis.Set(ctx, arts)
+}
+
+func (r *CachedRankingRepository) GetTopN(ctx context.Context) ([]domain.Article, error) {
+	arts, err := r.local.Get(ctx)
+	if err == nil {
+		return arts, nil
+	}
+	arts, err = r.redis.Get(ctx)
+	if err != nil {
+		if res, er := r.local.ForceGet(ctx); er == nil {
+			return res, nil
+		}
+		return nil, err
+	}
+	_ = r.local.Set(ctx, arts)
+	return arts, nil
+}
+
+func (r *CachedRankingRepository) RemoveFromTopN(ctx context.Context, artId int64) error {
+	// 只能清掉当前实例的本地缓存，别的实例最多等本地缓存过期
+	_ = r.local.Remove(ctx, artId)
+	return r.redis.Remove(ctx, artId)
+}
@@ -0,0 +1,98 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+	"testing"
+	"webook/webook/internal/domain"
+	"webook/webook/internal/repository/cache"
+	"webook/webook/internal/repository/cache/local"
+	cachemocks "webook/webook/internal/repository/cache/mocks"
+)
+
+func TestCachedRankingRepository_GetTopN(t *testing.T) {
+	arts := []domain.Article{{Id: 2}, {Id: 1}}
+	testCases := []struct {
+		name     string
+		mock     func(ctrl *gomock.Controller) (cache.RankingCache, cache.RankingLocalCache)
+		wantArts []domain.Article
+		wantErr  error
+	}{
+		{
+			name: "命中本地缓存",
+			mock: func(ctrl *gomock.Controller) (cache.RankingCache, cache.RankingLocalCache) {
+				lc := cachemocks.NewMockRankingLocalCache(ctrl)
+				lc.EXPECT().Get(gomock.Any()).Return(arts, nil)
+				return cachemocks.NewMockRankingCache(ctrl), lc
+			},
+			wantArts: arts,
+		},
+		{
+			name: "本地缓存过期，从 Redis 拿并且回写",
+			mock: func(ctrl *gomock.Controller) (cache.RankingCache, cache.RankingLocalCache) {
+				lc := cachemocks.NewMockRankingLocalCache(ctrl)
+				lc.EXPECT().Get(gomock.Any()).Return(nil, local.ErrLocalCacheMiss)
+				rc := cachemocks.NewMockRankingCache(ctrl)
+				rc.EXPECT().Get(gomock.Any()).Return(arts, nil)
+				lc.EXPECT().Set(gomock.Any(), arts).Return(nil)
+				return rc, lc
+			},
+			wantArts: arts,
+		},
+		{
+			name: "Redis 出错，用本地过期的数据兜底",
+			mock: func(ctrl *gomock.Controller) (cache.RankingCache, cache.RankingLocalCache) {
+				lc := cachemocks.NewMockRankingLocalCache(ctrl)
+				lc.EXPECT().Get(gomock.Any()).Return(nil, local.ErrLocalCacheMiss)
+				rc := cachemocks.NewMockRankingCache(ctrl)
+				rc.EXPECT().Get(gomock.Any()).Return(nil, errors.New("mock redis error"))
+				lc.EXPECT().ForceGet(gomock.Any()).Return(arts, nil)
+				return rc, lc
+			},
+			wantArts: arts,
+		},
+		{
+			name: "Redis 出错，本地也没有数据",
+			mock: func(ctrl *gomock.Controller) (cache.RankingCache, cache.RankingLocalCache) {
+				lc := cachemocks.NewMockRankingLocalCache(ctrl)
+				lc.EXPECT().Get(gomock.Any()).Return(nil, local.ErrLocalCacheMiss)
+				rc := cachemocks.NewMockRankingCache(ctrl)
+				rc.EXPECT().Get(gomock.Any()).Return(nil, errors.New("mock redis error"))
+				lc.EXPECT().ForceGet(gomock.Any()).Return(nil, local.ErrLocalCacheMiss)
+				return rc, lc
+			},
+			wantErr: errors.New("mock redis error"),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			rc, lc := tc.mock(ctrl)
+			repo := NewCachedRankingRepository(rc, lc)
+			res, err := repo.GetTopN(context.Background())
+			assert.Equal(t, tc.wantErr, err)
+			assert.Equal(t, tc.wantArts, res)
+		})
+	}
+}
+
+func TestCachedRankingRepository_RemoveFromTopN(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	rc := cachemocks.NewMockRankingCache(ctrl)
+	rc.EXPECT().Remove(gomock.Any(), int64(2)).Return(nil)
+	lc := local.NewRankingLocalCache()
+	arts := []domain.Article{{Id: 3}, {Id: 2}, {Id: 1}}
+	_ = lc.Set(context.Background(), arts)
+	repo := NewCachedRankingRepository(rc, lc)
+	err := repo.RemoveFromTopN(context.Background(), 2)
+	assert.NoError(t, err)
+	res, err := lc.Get(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, []domain.Article{{Id: 3}, {Id: 1}}, res)
+	// 之前拿到的切片不受影响
+	assert.Equal(t, []domain.Article{{Id: 3}, {Id: 2}, {Id: 1}}, arts)
+}
@@ -0,0 +1,72 @@
+package repository
+
+import (
+	"context"
+	"github.com/ecodeclub/ekit/slice"
+	"webook/webook/internal/domain"
+	"webook/webook/internal/repository/cache"
+	"webook/webook/internal/repository/dao"
+)
+
+var ErrRoleNotFound = dao.ErrRoleNotFound
+
+type CachedRBACRepository struct {
+	dao   dao.RBACDAO
+	cache cache.RBACCache
+}
+
+func NewCachedRBACRepository(dao dao.RBACDAO, cache cache.RBACCache) RBACRepository {
+	return &CachedRBACRepository{dao: dao, cache: cache}
+}
+
+func (r *CachedRBACRepository) GetPermissions(ctx context.Context, uid int64) ([]domain.Permission, error) {
+	perms, err := r.cache.GetPermissions(ctx, uid)
+	if err == nil {
+		return perms, nil
+	}
+	// 缓存没有或者出错了都查数据库
+	res, err := r.dao.FindPermissionsByUid(ctx, uid)
+	if err != nil {
+		return nil, err
+	}
+	perms = slice.Map[string, domain.Permission](res, func(idx int, src string) domain.Permission {
+		return domain.Permission(src)
+	})
+	_ = r.cache.SetPermissions(ctx, uid, perms)
+	return perms, nil
+}
+
+func (r *CachedRBACRepository) GetRoles(ctx context.Context, uid int64) ([]domain.Role, error) {
+	roles, err := r.dao.FindRolesByUid(ctx, uid)
+	if err != nil {
+		return nil, err
+	}
+	return slice.Map[dao.Role, domain.Role](roles, func(idx int, src dao.Role) domain.Role {
+		return domain.Role{Id: src.Id, Name: src.Name}
+	}), nil
+}
+
+func (r *CachedRBACRepository) Grant(ctx context.Context, uid int64, role string) error {
+	ro, err := r.dao.FindRoleByName(ctx, role)
+	if err != nil {
+		return err
+	}
+	err = r.dao.InsertUserRole(ctx, uid, ro.Id)
+	if err != nil {
+		return err
+	}
+	return r.cache.DelPermissions(ctx, uid)
+}
+
+func (r *CachedRBACRepository) Revoke(ctx context.Context, uid int64, role string) error {
+	ro, err := r.dao.FindRoleByName(ctx, role)
+	if err != nil {
+		return err
+	}
+	err = r.dao.DeleteUserRole(ctx, uid, ro.Id)
+	if err != nil {
+		return err
+	}
+	// 撤销之后要马上生效，删除缓存失败要告诉调用者
+	return r.cache.DelPermissions(ctx, uid)
+}
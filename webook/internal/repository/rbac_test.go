@@ -0,0 +1,114 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+	"testing"
+	"webook/webook/internal/domain"
+	"webook/webook/internal/repository/cache"
+	cache2 "webook/webook/internal/repository/cache/Redis"
+	cachemocks "webook/webook/internal/repository/cache/mocks"
+	"webook/webook/internal/repository/dao"
+	daomocks "webook/webook/internal/repository/dao/mocks"
+)
+
+func TestCachedRBACRepository_GetPermissions(t *testing.T) {
+	testCases := []struct {
+		name      string
+		mock      func(ctrl *gomock.Controller) (dao.RBACDAO, cache.RBACCache)
+		wantPerms []domain.Permission
+		wantErr   error
+	}{
+		{
+			name: "命中缓存",
+			mock: func(ctrl *gomock.Controller) (dao.RBACDAO, cache.RBACCache) {
+				c := cachemocks.NewMockRBACCache(ctrl)
+				c.EXPECT().GetPermissions(gomock.Any(), int64(1)).
+					Return([]domain.Permission{domain.PermissionArticleReview}, nil)
+				return daomocks.NewMockRBACDAO(ctrl), c
+			},
+			wantPerms: []domain.Permission{domain.PermissionArticleReview},
+		},
+		{
+			name: "未命中缓存，查询数据库之后回写",
+			mock: func(ctrl *gomock.Controller) (dao.RBACDAO, cache.RBACCache) {
+				c := cachemocks.NewMockRBACCache(ctrl)
+				c.EXPECT().GetPermissions(gomock.Any(), int64(1)).Return(nil, cache2.ErrKeyNotExist)
+				d := daomocks.NewMockRBACDAO(ctrl)
+				d.EXPECT().FindPermissionsByUid(gomock.Any(), int64(1)).
+					Return([]string{"article:review", "role:grant"}, nil)
+				c.EXPECT().SetPermissions(gomock.Any(), int64(1), []domain.Permission{
+					domain.PermissionArticleReview, domain.PermissionRoleGrant,
+				}).Return(nil)
+				return d, c
+			},
+			wantPerms: []domain.Permission{domain.PermissionArticleReview, domain.PermissionRoleGrant},
+		},
+		{
+			name: "查询数据库失败",
+			mock: func(ctrl *gomock.Controller) (dao.RBACDAO, cache.RBACCache) {
+				c := cachemocks.NewMockRBACCache(ctrl)
+				c.EXPECT().GetPermissions(gomock.Any(), int64(1)).Return(nil, cache2.ErrKeyNotExist)
+				d := daomocks.NewMockRBACDAO(ctrl)
+				d.EXPECT().FindPermissionsByUid(gomock.Any(), int64(1)).
+					Return(nil, errors.New("mock db error"))
+				return d, c
+			},
+			wantErr: errors.New("mock db error"),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			repo := NewCachedRBACRepository(tc.mock(ctrl))
+			perms, err := repo.GetPermissions(context.Background(), 1)
+			assert.Equal(t, tc.wantErr, err)
+			assert.Equal(t, tc.wantPerms, perms)
+		})
+	}
+}
+
+func TestCachedRBACRepository_Revoke(t *testing.T) {
+	testCases := []struct {
+		name    string
+		mock    func(ctrl *gomock.Controller) (dao.RBACDAO, cache.RBACCache)
+		wantErr error
+	}{
+		{
+			name: "撤销之后删除缓存",
+			mock: func(ctrl *gomock.Controller) (dao.RBACDAO, cache.RBACCache) {
+				d := daomocks.NewMockRBACDAO(ctrl)
+				d.EXPECT().FindRoleByName(gomock.Any(), domain.RoleModerator).
+					Return(dao.Role{Id: 2, Name: domain.RoleModerator}, nil)
+				d.EXPECT().DeleteUserRole(gomock.Any(), int64(1), int64(2)).Return(nil)
+				c := cachemocks.NewMockRBACCache(ctrl)
+				c.EXPECT().DelPermissions(gomock.Any(), int64(1)).Return(nil)
+				return d, c
+			},
+		},
+		{
+			name: "角色不存在",
+			mock: func(ctrl *gomock.Controller) (dao.RBACDAO, cache.RBACCache) {
+				d := daomocks.NewMockRBACDAO(ctrl)
+				d.EXPECT().FindRoleByName(gomock.Any(), domain.RoleModerator).
+					Return(dao.Role{}, dao.ErrRoleNotFound)
+				return d, cachemocks.NewMockRBACCache(ctrl)
+			},
+			wantErr: ErrRoleNotFound,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			repo := NewCachedRBACRepository(tc.mock(ctrl))
+			err := repo.Revoke(context.Background(), 1, domain.RoleModerator)
+			assert.Equal(t, tc.wantErr, err)
+		})
+	}
+}
@@ -0,0 +1,107 @@
+package repository
+
+import (
+	"context"
+	"github.com/ecodeclub/ekit/slice"
+	"strconv"
+	"time"
+	"webook/webook/internal/domain"
+	"webook/webook/pkg/search"
+)
+
+const (
+	searchFieldTitle      = "title"
+	searchFieldContent    = "content"
+	searchFieldAuthorName = "author_name"
+	searchFieldAuthorId   = "author_id"
+	searchFieldUtime      = "utime"
+)
+
+// articleSearchFields 标题的权重最高，其次是作者昵称
+var articleSearchFields = map[string]float64{
+	searchFieldTitle:      3,
+	searchFieldAuthorName: 2,
+	searchFieldContent:    1,
+}
+
+// LocalSearchRepository 索引保存在本地磁盘上
+type LocalSearchRepository struct {
+	idx *search.Index
+}
+
+func NewLocalSearchRepository(idx *search.Index) SearchRepository {
+	return &LocalSearchRepository{idx: idx}
+}
+
+func (r *LocalSearchRepository) InputArticle(ctx context.Context, art domain.Article) error {
+	return r.idx.Index(toSearchDocument(art))
+}
+
+func (r *LocalSearchRepository) DeleteArticle(ctx context.Context, id int64) error {
+	return r.idx.Delete(strconv.FormatInt(id, 10))
+}
+
+func (r *LocalSearchRepository) ReplaceArticles(ctx context.Context,
+	next func(ctx context.Context) ([]domain.Article, error)) error {
+	b := r.idx.NewBuilder()
+	for {
+		arts, err := next(ctx)
+		if err != nil {
+			return err
+		}
+		if len(arts) == 0 {
+			return b.Commit()
+		}
+		for _, art := range arts {
+			b.Add(toSearchDocument(art))
+		}
+	}
+}
+
+func (r *LocalSearchRepository) SearchArticle(ctx context.Context, query string,
+	offset int, limit int) (domain.ArticleSearchResult, error) {
+	res := r.idx.Search(search.Request{
+		Query:     query,
+		Fields:    articleSearchFields,
+		Offset:    offset,
+		Limit:     limit,
+		Highlight: []string{searchFieldTitle, searchFieldContent},
+	})
+	return domain.ArticleSearchResult{
+		Total: res.Total,
+		Hits: slice.Map[search.Hit, domain.ArticleHit](res.Hits, func(idx int, src search.Hit) domain.ArticleHit {
+			// 都是自己写进去的，解析失败就当作 0
+			id, _ := strconv.ParseInt(src.Id, 10, 64)
+			aid, _ := strconv.ParseInt(src.Stored[searchFieldAuthorId], 10, 64)
+			utime, _ := strconv.ParseInt(src.Stored[searchFieldUtime], 10, 64)
+			return domain.ArticleHit{
+				Article: domain.Article{
+					Id:    id,
+					Title: src.Fields[searchFieldTitle],
+					Author: domain.Author{
+						Id:   aid,
+						Name: src.Fields[searchFieldAuthorName],
+					},
+					Utime: time.UnixMilli(utime),
+				},
+				TitleHighlight:   src.Fragments[searchFieldTitle],
+				ContentHighlight: src.Fragments[searchFieldContent],
+			}
+		}),
+	}, nil
+}
+
+func toSearchDocument(art domain.Article) search.Document {
+	return search.Document{
+		Id: strconv.FormatInt(art.Id, 10),
+		Fields: map[string]string{
+			searchFieldTitle:      art.Title,
+			searchFieldContent:    art.Content,
+			searchFieldAuthorName: art.Author.Name,
+		},
+		Stored: map[string]string{
+			searchFieldAuthorId: strconv.FormatInt(art.Author.Id, 10),
+			searchFieldUtime:    strconv.FormatInt(art.Utime.UnixMilli(), 10),
+		},
+	}
+}
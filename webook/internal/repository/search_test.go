@@ -0,0 +1,68 @@
+package repository
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"path/filepath"
+	"testing"
+	"time"
+	"webook/webook/internal/domain"
+	"webook/webook/pkg/search"
+)
+
+func TestLocalSearchRepository(t *testing.T) {
+	idx, err := search.Open(filepath.Join(t.TempDir(), "article.gob"))
+	require.NoError(t, err)
+	defer idx.Close()
+	repo := NewLocalSearchRepository(idx)
+	ctx := context.Background()
+	utime := time.UnixMilli(time.Now().UnixMilli())
+	batches := [][]domain.Article{
+		{
+			{Id: 1, Title: "Go 并发", Content: "goroutine 和 channel",
+				Author: domain.Author{Id: 123, Name: "大明"}, Utime: utime},
+		},
+		{
+			{Id: 2, Title: "Redis 入门", Content: "大明写的 Redis",
+				Author: domain.Author{Id: 234, Name: "小明"}, Utime: utime},
+		},
+	}
+	err = repo.ReplaceArticles(ctx, func(ctx context.Context) ([]domain.Article, error) {
+		if len(batches) == 0 {
+			return nil, nil
+		}
+		batch := batches[0]
+		batches = batches[1:]
+		return batch, nil
+	})
+	require.NoError(t, err)
+
+	// 作者昵称也能搜到，标题里命中的排在前面
+	res, err := repo.SearchArticle(ctx, "redis", 0, 10)
+	require.NoError(t, err)
+	assert.Equal(t, domain.ArticleSearchResult{
+		Total: 1,
+		Hits: []domain.ArticleHit{
+			{
+				Article: domain.Article{
+					Id:     2,
+					Title:  "Redis 入门",
+					Author: domain.Author{Id: 234, Name: "小明"},
+					Utime:  utime,
+				},
+				TitleHighlight:   "<em>Redis</em> 入门",
+				ContentHighlight: "大明写的 <em>Redis</em>",
+			},
+		},
+	}, res)
+	res, err = repo.SearchArticle(ctx, "大明", 0, 10)
+	require.NoError(t, err)
+	assert.Equal(t, 2, res.Total)
+	assert.Equal(t, int64(1), res.Hits[0].Article.Id)
+
+	require.NoError(t, repo.DeleteArticle(ctx, 2))
+	res, err = repo.SearchArticle(ctx, "redis", 0, 10)
+	require.NoError(t, err)
+	assert.Equal(t, 0, res.Total)
+}
@@ -2,6 +2,7 @@ package repository
 
 import (
 	"context"
+	"time"
 	"webook/webook/internal/domain"
 )
 
@@ -30,6 +31,68 @@ type ArticleRepository interface {
 	SyncV2(ctx context.Context, art domain.Article) (int64, error)
 	Sync(ctx context.Context, art domain.Article) (int64, error)
 	SyncStatus(ctx context.Context, id int64, authorId int64, status domain.ArticleStatus) error
+	// Withdraw 撤回线上正在展示的版本，线上没有发表的版本时返回 ErrArticleNotPublished
+	Withdraw(ctx context.Context, id int64, authorId int64) error
+	// List 按照更新时间倒序分页查询作者的文章
+	List(ctx context.Context, uid int64, offset int, limit int) ([]domain.Article, error)
+	// ListByCursor 查询排在 (utime, id) 之后的文章
+	ListByCursor(ctx context.Context, uid int64, utime time.Time, id int64, limit int) ([]domain.Article, error)
+	GetById(ctx context.Context, id int64) (domain.Article, error)
+	// GetPublishedById 读者查看已发表的文章，会带上作者的昵称
+	GetPublishedById(ctx context.Context, id int64) (domain.Article, error)
+	ListPub(ctx context.Context, offset int, limit int) ([]domain.Article, error)
+	// ListPubSince since 之后发表的文章，按照发表时间倒序，发表时间在 Ctime 里面
+	ListPubSince(ctx context.Context, since time.Time, offset int, limit int) ([]domain.Article, error)
+	// ListPubByIds 批量查询已经发表的文章，没有发表的会被跳过，不保证顺序
+	ListPubByIds(ctx context.Context, ids []int64) ([]domain.Article, error)
+	// ListPubByAuthors 这些作者在 before 之前发表的文章，按照发表时间倒序
+	ListPubByAuthors(ctx context.Context, authorIds []int64, before time.Time, limit int) ([]domain.Article, error)
+	// ListPubByTag 带有这个标签的已发表文章，按照更新时间倒序
+	ListPubByTag(ctx context.Context, tag string, offset int, limit int) ([]domain.Article, error)
+	// ListPubTags 已发表文章最多的 limit 个标签
+	ListPubTags(ctx context.Context, limit int) ([]domain.Tag, error)
+	// Delete 把文章放进回收站
+	Delete(ctx context.Context, id int64, uid int64) error
+	// ListDeleted 查询回收站里在 after 之后删除的文章
+	ListDeleted(ctx context.Context, uid int64, after time.Time, offset int, limit int) ([]domain.Article, error)
+	// RestoreDeleted 从回收站恢复在 after 之后删除的文章
+	RestoreDeleted(ctx context.Context, id int64, uid int64, after time.Time) error
+	// PurgeDeleted 彻底删除一批在 before 之前删除的文章，连同数据库里挂在文章上面的数据，返回删除的文章 ID
+	PurgeDeleted(ctx context.Context, before time.Time, limit int) ([]int64, error)
+	// ListScheduled 查询所有等待定时发表的文章
+	ListScheduled(ctx context.Context) ([]domain.Article, error)
+	// UpdateSchedule 取消或者修改定时发表，只修改制作库
+	UpdateSchedule(ctx context.Context, id int64, uid int64, from domain.ArticleStatus,
+		to domain.ArticleStatus, publishAt time.Time) error
+	// ListPendingReview 等待人工审核的文章，先提交的排在前面
+	ListPendingReview(ctx context.Context, offset int, limit int) ([]domain.Article, error)
+}
+
+type ArticleReviewRepository interface {
+	Create(ctx context.Context, r domain.ArticleReview) (int64, error)
+	// FindLatest 文章最近一次的审核结果
+	FindLatest(ctx context.Context, artId int64) (domain.ArticleReview, error)
+}
+
+type ArticleRevisionRepository interface {
+	List(ctx context.Context, artId int64, offset int, limit int) ([]domain.ArticleRevision, error)
+	GetById(ctx context.Context, id int64) (domain.ArticleRevision, error)
+	// Prune 按照保留策略清理历史版本
+	Prune(ctx context.Context, artId int64, retention domain.RevisionRetention) error
+}
+
+type InteractiveRepository interface {
+	IncrReadCnt(ctx context.Context, biz string, bizId int64) error
+	IncrLike(ctx context.Context, biz string, bizId int64, uid int64) error
+	DecrLike(ctx context.Context, biz string, bizId int64, uid int64) error
+	AddCollectionItem(ctx context.Context, biz string, bizId int64, uid int64) error
+	DeleteCollectionItem(ctx context.Context, biz string, bizId int64, uid int64) error
+	// Get 查询计数，没有互动过的资源返回全 0
+	Get(ctx context.Context, biz string, bizId int64) (domain.Interactive, error)
+	// GetByIds 批量查询计数，直接查数据库，没有互动过的资源计数都是 0
+	GetByIds(ctx context.Context, biz string, bizIds []int64) (map[int64]domain.Interactive, error)
+	Liked(ctx context.Context, biz string, bizId int64, uid int64) (bool, error)
+	Collected(ctx context.Context, biz string, bizId int64, uid int64) (bool, error)
 }
 
 type ArticleAuthorRepository interface {
@@ -41,3 +104,110 @@ type ArticleReaderRepository interface {
 	// Save 有就更新，没有就创建
 	Save(ctx context.Context, art domain.Article) (int64, error)
 }
+
+type CollectionFolderRepository interface {
+	Create(ctx context.Context, folder domain.CollectionFolder) (int64, error)
+	Rename(ctx context.Context, id int64, uid int64, name string) error
+	Delete(ctx context.Context, id int64, uid int64) error
+	GetById(ctx context.Context, id int64) (domain.CollectionFolder, error)
+	List(ctx context.Context, uid int64, offset int, limit int) ([]domain.CollectionFolder, error)
+	AddItem(ctx context.Context, id int64, artId int64) error
+	RemoveItem(ctx context.Context, id int64, artId int64) error
+	// ListItems 按照放进收藏夹的时间倒序，返回文章 ID
+	ListItems(ctx context.Context, id int64, offset int, limit int) ([]int64, error)
+}
+
+type CommentRepository interface {
+	Create(ctx context.Context, c domain.Comment) (int64, error)
+	Delete(ctx context.Context, c domain.Comment) error
+	GetById(ctx context.Context, id int64) (domain.Comment, error)
+	// FindRoots 按照时间倒序查询根评论，带上评论者的昵称
+	FindRoots(ctx context.Context, artId int64, minId int64, limit int) ([]domain.Comment, error)
+	// FindReplies 按照时间正序查询回复，带上评论者的昵称
+	FindReplies(ctx context.Context, rootId int64, maxId int64, limit int) ([]domain.Comment, error)
+	GetCount(ctx context.Context, artId int64) (int64, error)
+}
+
+// RankingRepository 热榜，保存的是计算好的结果
+type RankingRepository interface {
+	ReplaceTopN(ctx context.Context, arts []domain.Article) error
+	GetTopN(ctx context.Context) ([]domain.Article, error)
+	// RemoveFromTopN 文章撤回或者删除之后从热榜里去掉，不用等下一次计算
+	RemoveFromTopN(ctx context.Context, artId int64) error
+}
+
+type CronJobRepository interface {
+	Preempt(ctx context.Context, staleBefore time.Time) (domain.CronJob, error)
+	UpdateUtime(ctx context.Context, id int64, version int64) error
+	Release(ctx context.Context, id int64, version int64, nextTime time.Time) error
+	Pause(ctx context.Context, id int64, version int64) error
+}
+
+// AsyncSmsRepository 异步短信的持久化队列
+type AsyncSmsRepository interface {
+	Add(ctx context.Context, s domain.AsyncSms) (int64, error)
+	// Preempt 没有可以发送的短信返回 ErrNoAsyncSms
+	Preempt(ctx context.Context, staleBefore time.Time) (domain.AsyncSms, error)
+	MarkSuccess(ctx context.Context, s domain.AsyncSms) error
+	// MarkRetry 按照 s.RetryCnt、s.LastErr 和 s.Numbers 更新，在 nextTime 之后重试
+	// 部分号码已经发出去的话，s.Numbers 只剩下失败的号码
+	MarkRetry(ctx context.Context, s domain.AsyncSms, nextTime time.Time) error
+	// MarkFailed 不再重试
+	MarkFailed(ctx context.Context, s domain.AsyncSms) error
+	// MarkExpired 过期了，不再发送
+	MarkExpired(ctx context.Context, s domain.AsyncSms) error
+}
+
+type UploadRepository interface {
+	// FindByHash 没有上传过返回 ErrUploadNotFound
+	FindByHash(ctx context.Context, hash string) (domain.UploadObject, error)
+	Create(ctx context.Context, obj domain.UploadObject) error
+}
+
+// RBACRepository 角色和权限，用户的权限有缓存
+type RBACRepository interface {
+	GetPermissions(ctx context.Context, uid int64) ([]domain.Permission, error)
+	GetRoles(ctx context.Context, uid int64) ([]domain.Role, error)
+	// Grant 授予角色，角色不存在返回 ErrRoleNotFound
+	Grant(ctx context.Context, uid int64, role string) error
+	// Revoke 撤销角色，角色不存在返回 ErrRoleNotFound
+	Revoke(ctx context.Context, uid int64, role string) error
+}
+
+type FollowRepository interface {
+	// Follow 关注，已经关注过了返回 ErrFollowDuplicate
+	Follow(ctx context.Context, follower int64, followee int64) error
+	// CancelFollow 取消关注，没有关注过也不会报错
+	CancelFollow(ctx context.Context, follower int64, followee int64) error
+	Followed(ctx context.Context, follower int64, followee int64) (bool, error)
+	// ListFollowers 粉丝列表，带上粉丝的昵称
+	ListFollowers(ctx context.Context, uid int64, offset int, limit int) ([]domain.FollowRelation, error)
+	// ListFollowees 关注列表，带上被关注者的昵称
+	ListFollowees(ctx context.Context, uid int64, offset int, limit int) ([]domain.FollowRelation, error)
+	// FolloweeIds 最近关注的 limit 个人
+	FolloweeIds(ctx context.Context, uid int64, limit int) ([]int64, error)
+	FollowerIds(ctx context.Context, uid int64) ([]int64, error)
+	GetStatics(ctx context.Context, uid int64) (domain.FollowStatics, error)
+	// GetStaticsByUids 批量查询计数，直接查数据库，没有数据的用户不会返回
+	GetStaticsByUids(ctx context.Context, uids []int64) (map[int64]domain.FollowStatics, error)
+}
+
+// FeedRepository 关注流的收件箱
+type FeedRepository interface {
+	// AddInbox 把文章推送给这些用户，ctime 是文章的发表时间
+	AddInbox(ctx context.Context, artId int64, uids []int64, ctime time.Time) error
+	// ListInbox 按照发表时间倒序查询 before 之前发表的文章 ID
+	ListInbox(ctx context.Context, uid int64, before time.Time, limit int) ([]int64, error)
+}
+
+// SearchRepository 文章的全文索引，只有已经发表的文章
+type SearchRepository interface {
+	// InputArticle 新增或者更新文章的索引，Author.Name 也会被索引
+	InputArticle(ctx context.Context, art domain.Article) error
+	DeleteArticle(ctx context.Context, id int64) error
+	// ReplaceArticles 不断调用 next 分批获取文章，直到返回空的一批，然后一次性替换掉所有的索引。
+	// next 返回 error 的时候原来的索引不受影响
+	ReplaceArticles(ctx context.Context, next func(ctx context.Context) ([]domain.Article, error)) error
+	// SearchArticle 按照相关度排序
+	SearchArticle(ctx context.Context, query string, offset int, limit int) (domain.ArticleSearchResult, error)
+}
@@ -0,0 +1,44 @@
+package repository
+
+import (
+	"context"
+	"time"
+	"webook/webook/internal/domain"
+	"webook/webook/internal/repository/dao"
+)
+
+var ErrUploadNotFound = dao.ErrUploadNotFound
+
+type CacheUploadRepository struct {
+	dao dao.UploadDAO
+}
+
+func NewCacheUploadRepository(dao dao.UploadDAO) UploadRepository {
+	return &CacheUploadRepository{dao: dao}
+}
+
+func (r *CacheUploadRepository) FindByHash(ctx context.Context, hash string) (domain.UploadObject, error) {
+	obj, err := r.dao.FindByHash(ctx, hash)
+	if err != nil {
+		return domain.UploadObject{}, err
+	}
+	return domain.UploadObject{
+		Id:          obj.Id,
+		Hash:        obj.Hash,
+		Key:         obj.Key,
+		Size:        obj.Size,
+		ContentType: obj.ContentType,
+		Uid:         obj.Uid,
+		Ctime:       time.UnixMilli(obj.Ctime),
+	}, nil
+}
+
+func (r *CacheUploadRepository) Create(ctx context.Context, obj domain.UploadObject) error {
+	return r.dao.Insert(ctx, dao.UploadObject{
+		Hash:        obj.Hash,
+		Key:         obj.Key,
+		Size:        obj.Size,
+		ContentType: obj.ContentType,
+		Uid:         obj.Uid,
+	})
+}
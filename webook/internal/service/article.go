@@ -2,13 +2,36 @@ package service
 
 import (
 	"context"
+	"errors"
+	"strings"
+	"time"
 	"webook/webook/internal/domain"
 	"webook/webook/internal/repository"
+	"webook/webook/pkg/diff"
 	"webook/webook/pkg/logger"
+	"webook/webook/pkg/markdown"
+)
+
+var (
+	ErrArticleNotFound  = repository.ErrArticleNotFound
+	ErrRevisionNotFound = repository.ErrRevisionNotFound
+	// ErrInvalidStatusTransition 文章当前的状态不允许执行这个操作，比如撤回一篇还没发表的文章
+	ErrInvalidStatusTransition = errors.New("文章状态不允许该操作")
 )
 
 type articleService struct {
 	repo repository.ArticleRepository
+	// 历史版本
+	revisionRepo repository.ArticleRevisionRepository
+	retention    domain.RevisionRetention
+	// 回收站
+	recycleBin domain.RecycleBinRetention
+	// 定时发表
+	scheduler ArticleScheduler
+	// 发表、撤回之后通知其它业务
+	listeners []ArticleListener
+	// 发表之前检查敏感词
+	moderation ModerationService
 
 	// V1 与上面互斥
 	author repository.ArticleAuthorRepository
@@ -22,30 +45,424 @@ func NewArticleServiceV1(author repository.ArticleAuthorRepository,
 	return &articleService{author: author, reader: reader, l: l}
 }
 
+func (a *articleService) List(ctx context.Context, uid int64, offset int, limit int) ([]domain.Article, error) {
+	return a.repo.List(ctx, uid, offset, limit)
+}
+
+func (a *articleService) ListByCursor(ctx context.Context, uid int64,
+	utime time.Time, id int64, limit int) ([]domain.Article, error) {
+	return a.repo.ListByCursor(ctx, uid, utime, id, limit)
+}
+
+func (a *articleService) GetById(ctx context.Context, id int64) (domain.Article, error) {
+	return a.repo.GetById(ctx, id)
+}
+
+func (a *articleService) GetPublishedById(ctx context.Context, id int64) (domain.Article, error) {
+	art, err := a.repo.GetPublishedById(ctx, id)
+	if err != nil || art.HTML != "" {
+		return art, err
+	}
+	// 早期发表的文章没有保存渲染之后的内容，读的时候再渲染
+	return a.render(art)
+}
+
+func (a *articleService) ListPub(ctx context.Context, offset int, limit int) ([]domain.Article, error) {
+	return a.repo.ListPub(ctx, offset, limit)
+}
+
+func (a *articleService) ListPubByTag(ctx context.Context, tag string, offset int, limit int) ([]domain.Article, error) {
+	tag = domain.NormalizeTag(tag)
+	if tag == "" {
+		return []domain.Article{}, nil
+	}
+	return a.repo.ListPubByTag(ctx, tag, offset, limit)
+}
+
+func (a *articleService) ListTags(ctx context.Context, limit int) ([]domain.Tag, error) {
+	return a.repo.ListPubTags(ctx, limit)
+}
+
 func (a *articleService) Withdraw(ctx context.Context, art domain.Article) error {
-	return a.repo.SyncStatus(ctx, art.Id, art.Author.Id, domain.ArticleStatusPrivate)
+	_, err := a.getByAuthor(ctx, art.Id, art.Author.Id)
+	if err != nil {
+		return err
+	}
+	// 制作库里的状态可能是有未发表修改的草稿，能不能撤回要看线上库
+	err = a.repo.Withdraw(ctx, art.Id, art.Author.Id)
+	if errors.Is(err, repository.ErrArticleNotPublished) {
+		return ErrInvalidStatusTransition
+	}
+	if err != nil {
+		return err
+	}
+	a.notifyWithdrawn(ctx, art)
+	return nil
+}
+
+func NewArticleService(repo repository.ArticleRepository, revisionRepo repository.ArticleRevisionRepository,
+	retention domain.RevisionRetention, recycleBin domain.RecycleBinRetention,
+	scheduler ArticleScheduler, moderation ModerationService, listeners []ArticleListener,
+	l logger.Logger) ArticleService {
+	return &articleService{repo: repo, revisionRepo: revisionRepo, retention: retention,
+		recycleBin: recycleBin, scheduler: scheduler, moderation: moderation, listeners: listeners, l: l}
 }
 
-func NewArticleService(repo repository.ArticleRepository) ArticleService {
-	return &articleService{repo: repo}
+func (a *articleService) Delete(ctx context.Context, id int64, uid int64) error {
+	err := a.repo.Delete(ctx, id, uid)
+	if err != nil {
+		return err
+	}
+	a.notifyWithdrawn(ctx, domain.Article{Id: id, Author: domain.Author{Id: uid}})
+	return nil
+}
+
+func (a *articleService) ListRecycleBin(ctx context.Context, uid int64, offset int, limit int) ([]domain.Article, error) {
+	// 过期但是还没来得及清理的文章也不展示
+	return a.repo.ListDeleted(ctx, uid, a.recycleBin.ExpiredBefore(time.Now()), offset, limit)
+}
+
+func (a *articleService) RestoreFromRecycleBin(ctx context.Context, id int64, uid int64) error {
+	err := a.repo.RestoreDeleted(ctx, id, uid, a.recycleBin.ExpiredBefore(time.Now()))
+	if err != nil {
+		return err
+	}
+	art, err := a.repo.GetById(ctx, id)
+	if err != nil {
+		// 恢复已经成功了，定时发表会在重启的时候重新加载
+		a.l.Error("恢复文章之后查询文章失败", logger.Int64("art_id", id), logger.Error(err))
+		return nil
+	}
+	switch art.Status {
+	case domain.ArticleStatusScheduled:
+		// 删除的时候定时器已经失效了，要重新加上
+		a.scheduler.Schedule(art.Id, art.PublishAt)
+	case domain.ArticleStatusPublished:
+		a.notifyPublished(ctx, art)
+	}
+	return nil
+}
+
+func (a *articleService) PurgeRecycleBin(ctx context.Context, limit int) (int, error) {
+	ids, err := a.repo.PurgeDeleted(ctx, a.recycleBin.ExpiredBefore(time.Now()), limit)
+	if err != nil {
+		return 0, err
+	}
+	// 数据库里的已经一起删掉了，搜索索引、热榜这些不在数据库里的再通知一次，
+	// 放进回收站的时候通知失败了也能在这里清理掉
+	for _, id := range ids {
+		a.notifyWithdrawn(ctx, domain.Article{Id: id})
+	}
+	return len(ids), nil
 }
 
 func (a *articleService) Save(ctx context.Context, art domain.Article) (int64, error) {
+	err := a.checkTransition(ctx, art, domain.ArticleStatusUnpublished)
+	if err != nil {
+		return 0, err
+	}
 	art.Status = domain.ArticleStatusUnpublished
+	return a.save(ctx, art)
+}
+
+func (a *articleService) SchedulePublish(ctx context.Context, art domain.Article) (int64, error) {
+	err := a.checkTransition(ctx, art, domain.ArticleStatusScheduled)
+	if err != nil {
+		return 0, err
+	}
+	id, err := a.moderate(ctx, art)
+	if err != nil {
+		return id, err
+	}
+	// 先存到制作库，到时间之后再同步到线上库
+	art.Status = domain.ArticleStatusScheduled
+	id, err = a.save(ctx, art)
+	if err != nil {
+		return 0, err
+	}
+	a.scheduler.Schedule(id, art.PublishAt)
+	return id, nil
+}
+
+func (a *articleService) CancelSchedule(ctx context.Context, id int64, uid int64) error {
+	err := a.checkScheduled(ctx, id, uid)
+	if err != nil {
+		return err
+	}
+	err = a.repo.UpdateSchedule(ctx, id, uid, domain.ArticleStatusScheduled,
+		domain.ArticleStatusUnpublished, time.Time{})
+	if err != nil {
+		return err
+	}
+	a.scheduler.Cancel(id)
+	return nil
+}
+
+func (a *articleService) Reschedule(ctx context.Context, id int64, uid int64, publishAt time.Time) error {
+	err := a.checkScheduled(ctx, id, uid)
+	if err != nil {
+		return err
+	}
+	err = a.repo.UpdateSchedule(ctx, id, uid, domain.ArticleStatusScheduled,
+		domain.ArticleStatusScheduled, publishAt)
+	if err != nil {
+		return err
+	}
+	a.scheduler.Schedule(id, publishAt)
+	return nil
+}
+
+// checkScheduled 取消和改期都要求文章正在等待发表
+func (a *articleService) checkScheduled(ctx context.Context, id int64, uid int64) error {
+	art, err := a.getByAuthor(ctx, id, uid)
+	if err != nil {
+		return err
+	}
+	if art.Status != domain.ArticleStatusScheduled {
+		return ErrInvalidStatusTransition
+	}
+	return nil
+}
+
+// save 按照 art.Status 保存到制作库，调用者负责校验状态迁移
+func (a *articleService) save(ctx context.Context, art domain.Article) (int64, error) {
+	art, err := a.render(art)
+	if err != nil {
+		return 0, err
+	}
+	id := art.Id
 	if art.Id > 0 {
 		// id > 0，说明不是新建，是编辑
-		return art.Id, a.repo.Update(ctx, art)
+		err = a.repo.Update(ctx, art)
+	} else {
+		id, err = a.repo.Create(ctx, art)
+	}
+	if err != nil {
+		return 0, err
 	}
-	return a.repo.Create(ctx, art)
+	a.pruneRevisions(ctx, id)
+	return id, nil
 }
 
 func (a *articleService) Publish(ctx context.Context, art domain.Article) (int64, error) {
+	err := a.checkTransition(ctx, art, domain.ArticleStatusPublished)
+	if err != nil {
+		return 0, err
+	}
+	id, err := a.moderate(ctx, art)
+	if err != nil {
+		return id, err
+	}
 	art.Status = domain.ArticleStatusPublished
+	art, err = a.render(art)
+	if err != nil {
+		return 0, err
+	}
 	//// 制作库
 	//a.repo.Create(ctx, art)
 	//// 同步到制作库
 	//a.repo.SyncToLiveDB(ctx, art)
-	return a.repo.Sync(ctx, art)
+	id, err = a.repo.Sync(ctx, art)
+	if err != nil {
+		return 0, err
+	}
+	a.pruneRevisions(ctx, id)
+	art.Id = id
+	a.notifyPublished(ctx, art)
+	return id, nil
+}
+
+// moderate 发表之前检查标题和内容
+// 违禁的内容直接拒绝；需要人工审核的内容保存为等待审核，返回文章 ID 和 ErrPendingReview
+// 没有问题的时候返回 nil，调用者继续发表
+func (a *articleService) moderate(ctx context.Context, art domain.Article) (int64, error) {
+	res, err := a.moderation.Check(ctx, art.Title, art.Content)
+	if err != nil {
+		return 0, err
+	}
+	switch res.Level {
+	case domain.ModerationBlock:
+		return 0, &SensitiveWordsError{Words: res.Words}
+	case domain.ModerationReview:
+		art.Status = domain.ArticleStatusPendingReview
+		art.PublishAt = time.Time{}
+		id, err := a.save(ctx, art)
+		if err != nil {
+			return 0, err
+		}
+		a.l.Info("文章需要人工审核", logger.Int64("art_id", id), logger.String("words", strings.Join(res.Words, ",")))
+		return id, ErrPendingReview
+	default:
+		return 0, nil
+	}
+}
+
+func (a *articleService) ApproveReview(ctx context.Context, id int64) (domain.Article, error) {
+	art, err := a.getPendingReview(ctx, id)
+	if err != nil {
+		return domain.Article{}, err
+	}
+	// 制作库里的内容在提交审核的时候已经渲染过了
+	art.Status = domain.ArticleStatusPublished
+	id, err = a.repo.Sync(ctx, art)
+	if err != nil {
+		return domain.Article{}, err
+	}
+	a.pruneRevisions(ctx, id)
+	art.Id = id
+	a.notifyPublished(ctx, art)
+	return art, nil
+}
+
+func (a *articleService) RejectReview(ctx context.Context, id int64) (domain.Article, error) {
+	art, err := a.getPendingReview(ctx, id)
+	if err != nil {
+		return domain.Article{}, err
+	}
+	// 带着状态条件更新，防止查询之后作者又改了文章的状态
+	err = a.repo.UpdateSchedule(ctx, id, art.Author.Id, domain.ArticleStatusPendingReview,
+		domain.ArticleStatusUnpublished, time.Time{})
+	if err != nil {
+		return domain.Article{}, err
+	}
+	return art, nil
+}
+
+// getPendingReview 管理员处理的文章必须正在等待审核
+func (a *articleService) getPendingReview(ctx context.Context, id int64) (domain.Article, error) {
+	art, err := a.repo.GetById(ctx, id)
+	if err != nil {
+		return domain.Article{}, err
+	}
+	if art.Status != domain.ArticleStatusPendingReview {
+		return domain.Article{}, ErrInvalidStatusTransition
+	}
+	return art, nil
+}
+
+// render 把 Markdown 渲染成过滤过的 HTML，并且生成纯文本摘要
+// 草稿也要渲染，作者预览和定时发表用的都是制作库里的内容
+func (a *articleService) render(art domain.Article) (domain.Article, error) {
+	h, err := markdown.Render(art.Content)
+	if err != nil {
+		return domain.Article{}, err
+	}
+	art.HTML = h
+	art.Summary = domain.Article{Content: markdown.PlainText(h)}.Abstract()
+	return art, nil
+}
+
+// checkTransition 校验文章能不能从当前状态迁移到 to
+// 新建的文章（Id 为 0）还没有状态，保存、发表、定时发表都可以
+func (a *articleService) checkTransition(ctx context.Context, art domain.Article, to domain.ArticleStatus) error {
+	if art.Id == 0 {
+		return nil
+	}
+	old, err := a.getByAuthor(ctx, art.Id, art.Author.Id)
+	if err != nil {
+		return err
+	}
+	if !old.Status.CanTransitTo(to) {
+		return ErrInvalidStatusTransition
+	}
+	return nil
+}
+
+func (a *articleService) notifyPublished(ctx context.Context, art domain.Article) {
+	for _, listener := range a.listeners {
+		listener.OnPublished(ctx, art)
+	}
+}
+
+func (a *articleService) notifyWithdrawn(ctx context.Context, art domain.Article) {
+	for _, listener := range a.listeners {
+		listener.OnWithdrawn(ctx, art)
+	}
+}
+
+// pruneRevisions 保存成功之后清理多余的历史版本
+// 清理失败不影响保存的结果，下一次保存的时候还会再清理
+func (a *articleService) pruneRevisions(ctx context.Context, artId int64) {
+	err := a.revisionRepo.Prune(ctx, artId, a.retention)
+	if err != nil {
+		a.l.Error("清理文章历史版本失败",
+			logger.Int64("art_id", artId), logger.Error(err))
+	}
+}
+
+func (a *articleService) ListRevisions(ctx context.Context, artId int64, uid int64,
+	offset int, limit int) ([]domain.ArticleRevision, error) {
+	_, err := a.getByAuthor(ctx, artId, uid)
+	if err != nil {
+		return nil, err
+	}
+	return a.revisionRepo.List(ctx, artId, offset, limit)
+}
+
+func (a *articleService) DiffRevisions(ctx context.Context, artId int64, uid int64,
+	from int64, to int64) ([]diff.Line, error) {
+	_, err := a.getByAuthor(ctx, artId, uid)
+	if err != nil {
+		return nil, err
+	}
+	fromRev, err := a.getRevision(ctx, artId, from)
+	if err != nil {
+		return nil, err
+	}
+	toRev, err := a.getRevision(ctx, artId, to)
+	if err != nil {
+		return nil, err
+	}
+	// 标题也参与比较，放在第一行
+	return diff.Lines(fromRev.Title+"\n"+fromRev.Content, toRev.Title+"\n"+toRev.Content), nil
+}
+
+func (a *articleService) RestoreRevision(ctx context.Context, artId int64, uid int64, revId int64) error {
+	art, err := a.getByAuthor(ctx, artId, uid)
+	if err != nil {
+		return err
+	}
+	rev, err := a.getRevision(ctx, artId, revId)
+	if err != nil {
+		return err
+	}
+	// 恢复就是把旧版本的内容重新保存为草稿，这样恢复本身也会产生一个新版本，可以反悔
+	if !art.Status.CanTransitTo(domain.ArticleStatusUnpublished) {
+		return ErrInvalidStatusTransition
+	}
+	art.Title = rev.Title
+	art.Content = rev.Content
+	art.Status = domain.ArticleStatusUnpublished
+	art.PublishAt = time.Time{}
+	_, err = a.save(ctx, art)
+	return err
+}
+
+// getByAuthor 查询文章，并且校验是不是作者本人
+func (a *articleService) getByAuthor(ctx context.Context, artId int64, uid int64) (domain.Article, error) {
+	art, err := a.repo.GetById(ctx, artId)
+	if err != nil {
+		return domain.Article{}, err
+	}
+	if art.Author.Id != uid {
+		// 不告诉调用者文章是存在的
+		a.l.Error("非法访问文章，创作者ID不匹配",
+			logger.Int64("uid", uid), logger.Int64("art_id", artId))
+		return domain.Article{}, ErrArticleNotFound
+	}
+	return art, nil
+}
+
+// getRevision 查询历史版本，并且确认是这篇文章的版本
+func (a *articleService) getRevision(ctx context.Context, artId int64, revId int64) (domain.ArticleRevision, error) {
+	rev, err := a.revisionRepo.GetById(ctx, revId)
+	if err != nil {
+		return domain.ArticleRevision{}, err
+	}
+	if rev.ArticleId != artId {
+		return domain.ArticleRevision{}, ErrRevisionNotFound
+	}
+	return rev, nil
 }
 func (a *articleService) PublishV1(ctx context.Context, art domain.Article) (int64, error) {
 	var (
@@ -0,0 +1,92 @@
+package service
+
+import (
+	"context"
+	"webook/webook/internal/domain"
+	"webook/webook/internal/repository"
+	"webook/webook/pkg/logger"
+)
+
+var ErrReviewNotFound = repository.ErrReviewNotFound
+
+type articleReviewService struct {
+	artSvc     ArticleService
+	artRepo    repository.ArticleRepository
+	reviewRepo repository.ArticleReviewRepository
+	l          logger.Logger
+}
+
+func NewArticleReviewService(artSvc ArticleService, artRepo repository.ArticleRepository,
+	reviewRepo repository.ArticleReviewRepository, l logger.Logger) ArticleReviewService {
+	return &articleReviewService{
+		artSvc:     artSvc,
+		artRepo:    artRepo,
+		reviewRepo: reviewRepo,
+		l:          l,
+	}
+}
+
+func (s *articleReviewService) ListPending(ctx context.Context, offset int, limit int) ([]domain.Article, error) {
+	return s.artRepo.ListPendingReview(ctx, offset, limit)
+}
+
+func (s *articleReviewService) Approve(ctx context.Context, artId int64, reviewer int64) error {
+	art, err := s.artSvc.ApproveReview(ctx, artId)
+	if err != nil {
+		return err
+	}
+	s.record(ctx, domain.ArticleReview{
+		ArtId:    artId,
+		AuthorId: art.Author.Id,
+		Reviewer: reviewer,
+		Result:   domain.ReviewResultApproved,
+	})
+	return nil
+}
+
+func (s *articleReviewService) Reject(ctx context.Context, artId int64, reviewer int64, reason string) error {
+	art, err := s.artRepo.GetById(ctx, artId)
+	if err != nil {
+		return err
+	}
+	if art.Status != domain.ArticleStatusPendingReview {
+		return ErrInvalidStatusTransition
+	}
+	// 驳回的理由作者要看，先写记录，写成功了再改状态
+	// 反过来的话记录写失败，文章已经不在等待审核了，管理员没办法重试
+	_, err = s.reviewRepo.Create(ctx, domain.ArticleReview{
+		ArtId:    artId,
+		AuthorId: art.Author.Id,
+		Reviewer: reviewer,
+		Result:   domain.ReviewResultRejected,
+		Reason:   reason,
+	})
+	if err != nil {
+		return err
+	}
+	_, err = s.artSvc.RejectReview(ctx, artId)
+	return err
+}
+
+func (s *articleReviewService) GetLatest(ctx context.Context, artId int64, uid int64) (domain.ArticleReview, error) {
+	review, err := s.reviewRepo.FindLatest(ctx, artId)
+	if err != nil {
+		return domain.ArticleReview{}, err
+	}
+	if review.AuthorId != uid {
+		// 和文章一样，不告诉调用者这篇文章被审核过
+		s.l.Error("非法访问审核结果，创作者ID不匹配",
+			logger.Int64("uid", uid), logger.Int64("art_id", artId))
+		return domain.ArticleReview{}, ErrReviewNotFound
+	}
+	return review, nil
+}
+
+// record 文章已经发表了，审核记录写失败只记录日志
+func (s *articleReviewService) record(ctx context.Context, review domain.ArticleReview) {
+	_, err := s.reviewRepo.Create(ctx, review)
+	if err != nil {
+		s.l.Error("记录审核结果失败",
+			logger.Int64("art_id", review.ArtId), logger.Error(err))
+	}
+}
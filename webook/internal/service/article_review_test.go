@@ -0,0 +1,151 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+	"testing"
+	"webook/webook/internal/domain"
+	"webook/webook/internal/repository"
+	repomocks "webook/webook/internal/repository/mocks"
+	svcmocks "webook/webook/internal/service/mocks"
+	"webook/webook/pkg/logger"
+)
+
+func Test_articleReviewService_Reject(t *testing.T) {
+	pending := domain.Article{
+		Id:     1,
+		Author: domain.Author{Id: 123},
+		Status: domain.ArticleStatusPendingReview,
+	}
+	testCases := []struct {
+		name    string
+		mock    func(ctrl *gomock.Controller) (ArticleService, repository.ArticleRepository, repository.ArticleReviewRepository)
+		wantErr error
+	}{
+		{
+			name: "驳回，记录理由",
+			mock: func(ctrl *gomock.Controller) (ArticleService, repository.ArticleRepository, repository.ArticleReviewRepository) {
+				artRepo := repomocks.NewMockArticleRepository(ctrl)
+				artRepo.EXPECT().GetById(gomock.Any(), int64(1)).Return(pending, nil)
+				reviewRepo := repomocks.NewMockArticleReviewRepository(ctrl)
+				artSvc := svcmocks.NewMockArticleService(ctrl)
+				gomock.InOrder(
+					reviewRepo.EXPECT().Create(gomock.Any(), domain.ArticleReview{
+						ArtId:    1,
+						AuthorId: 123,
+						Reviewer: 9,
+						Result:   domain.ReviewResultRejected,
+						Reason:   "标题党",
+					}).Return(int64(1), nil),
+					artSvc.EXPECT().RejectReview(gomock.Any(), int64(1)).Return(pending, nil),
+				)
+				return artSvc, artRepo, reviewRepo
+			},
+		},
+		{
+			name: "文章不在等待审核，不记录",
+			mock: func(ctrl *gomock.Controller) (ArticleService, repository.ArticleRepository, repository.ArticleReviewRepository) {
+				artRepo := repomocks.NewMockArticleRepository(ctrl)
+				artRepo.EXPECT().GetById(gomock.Any(), int64(1)).Return(domain.Article{
+					Id:     1,
+					Author: domain.Author{Id: 123},
+					Status: domain.ArticleStatusPublished,
+				}, nil)
+				return svcmocks.NewMockArticleService(ctrl), artRepo, repomocks.NewMockArticleReviewRepository(ctrl)
+			},
+			wantErr: ErrInvalidStatusTransition,
+		},
+		{
+			name: "文章不存在",
+			mock: func(ctrl *gomock.Controller) (ArticleService, repository.ArticleRepository, repository.ArticleReviewRepository) {
+				artRepo := repomocks.NewMockArticleRepository(ctrl)
+				artRepo.EXPECT().GetById(gomock.Any(), int64(1)).Return(domain.Article{}, ErrArticleNotFound)
+				return svcmocks.NewMockArticleService(ctrl), artRepo, repomocks.NewMockArticleReviewRepository(ctrl)
+			},
+			wantErr: ErrArticleNotFound,
+		},
+		{
+			name: "记录失败，不改状态，管理员可以重试",
+			mock: func(ctrl *gomock.Controller) (ArticleService, repository.ArticleRepository, repository.ArticleReviewRepository) {
+				artRepo := repomocks.NewMockArticleRepository(ctrl)
+				artRepo.EXPECT().GetById(gomock.Any(), int64(1)).Return(pending, nil)
+				reviewRepo := repomocks.NewMockArticleReviewRepository(ctrl)
+				reviewRepo.EXPECT().Create(gomock.Any(), gomock.Any()).
+					Return(int64(0), errors.New("mock db error"))
+				return svcmocks.NewMockArticleService(ctrl), artRepo, reviewRepo
+			},
+			wantErr: errors.New("mock db error"),
+		},
+		{
+			name: "记录之后作者改了状态",
+			mock: func(ctrl *gomock.Controller) (ArticleService, repository.ArticleRepository, repository.ArticleReviewRepository) {
+				artRepo := repomocks.NewMockArticleRepository(ctrl)
+				artRepo.EXPECT().GetById(gomock.Any(), int64(1)).Return(pending, nil)
+				reviewRepo := repomocks.NewMockArticleReviewRepository(ctrl)
+				reviewRepo.EXPECT().Create(gomock.Any(), gomock.Any()).Return(int64(1), nil)
+				artSvc := svcmocks.NewMockArticleService(ctrl)
+				artSvc.EXPECT().RejectReview(gomock.Any(), int64(1)).
+					Return(domain.Article{}, ErrInvalidStatusTransition)
+				return artSvc, artRepo, reviewRepo
+			},
+			wantErr: ErrInvalidStatusTransition,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			artSvc, artRepo, reviewRepo := tc.mock(ctrl)
+			svc := NewArticleReviewService(artSvc, artRepo, reviewRepo, logger.NewNoOpLogger())
+			err := svc.Reject(context.Background(), 1, 9, "标题党")
+			assert.Equal(t, tc.wantErr, err)
+		})
+	}
+}
+
+func Test_articleReviewService_GetLatest(t *testing.T) {
+	testCases := []struct {
+		name       string
+		uid        int64
+		wantReview domain.ArticleReview
+		wantErr    error
+	}{
+		{
+			name: "作者本人",
+			uid:  123,
+			wantReview: domain.ArticleReview{
+				ArtId:    1,
+				AuthorId: 123,
+				Result:   domain.ReviewResultRejected,
+				Reason:   "标题党",
+			},
+		},
+		{
+			name:    "不是作者",
+			uid:     456,
+			wantErr: ErrReviewNotFound,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			reviewRepo := repomocks.NewMockArticleReviewRepository(ctrl)
+			reviewRepo.EXPECT().FindLatest(gomock.Any(), int64(1)).Return(domain.ArticleReview{
+				ArtId:    1,
+				AuthorId: 123,
+				Result:   domain.ReviewResultRejected,
+				Reason:   "标题党",
+			}, nil)
+			svc := NewArticleReviewService(svcmocks.NewMockArticleService(ctrl),
+				repomocks.NewMockArticleRepository(ctrl), reviewRepo, logger.NewNoOpLogger())
+			review, err := svc.GetLatest(context.Background(), 1, tc.uid)
+			assert.Equal(t, tc.wantErr, err)
+			assert.Equal(t, tc.wantReview, review)
+		})
+	}
+}
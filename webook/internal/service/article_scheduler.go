@@ -0,0 +1,157 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+	"webook/webook/internal/domain"
+	"webook/webook/internal/repository"
+	"webook/webook/pkg/logger"
+	"webook/webook/pkg/redislock"
+)
+
+// LocalArticleScheduler 在进程内用定时器实现定时发表
+// 定时任务的数据都在 MySQL 里面，启动的时候和之后每隔一段时间都会调用 Load 重新加载，
+// 这样别的实例创建的定时任务，在那个实例挂了之后最多晚一个加载周期也会发表
+// 到时间之后会重新查一遍文章，确认还在等待发表并且时间没有改过，
+// 所以取消、改期、删除之后即使定时器没有停掉也不会误发。
+// 每个实例都会加载所有的定时任务，到时间之后用分布式锁保证只有一个实例发表
+type LocalArticleScheduler struct {
+	repo       repository.ArticleRepository
+	listeners  []ArticleListener
+	lockClient *redislock.Client
+	l          logger.Logger
+	// 发表失败，或者别的实例正在发表的时候，多久之后再确认一次
+	retryInterval  time.Duration
+	lockExpiration time.Duration
+
+	mutex  sync.Mutex
+	timers map[int64]*time.Timer
+}
+
+func NewLocalArticleScheduler(repo repository.ArticleRepository, listeners []ArticleListener,
+	lockClient *redislock.Client, l logger.Logger) *LocalArticleScheduler {
+	return &LocalArticleScheduler{
+		repo:           repo,
+		listeners:      listeners,
+		lockClient:     lockClient,
+		l:              l,
+		retryInterval:  time.Minute,
+		lockExpiration: time.Second * 30,
+		timers:         make(map[int64]*time.Timer),
+	}
+}
+
+// Load 从数据库加载所有等待发表的文章，已经过了时间的会马上发表
+// 已经有定时器的文章会重新设置定时器，到时间之后会重新确认，不会重复发表
+func (s *LocalArticleScheduler) Load(ctx context.Context) error {
+	arts, err := s.repo.ListScheduled(ctx)
+	if err != nil {
+		return err
+	}
+	for _, art := range arts {
+		s.Schedule(art.Id, art.PublishAt)
+	}
+	return nil
+}
+
+func (s *LocalArticleScheduler) Schedule(artId int64, publishAt time.Time) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	// time.Until 是负数的时候定时器会马上触发
+	s.startLocked(artId, time.Until(publishAt), publishAt)
+}
+
+func (s *LocalArticleScheduler) Cancel(artId int64) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.stopLocked(artId)
+}
+
+func (s *LocalArticleScheduler) stopLocked(artId int64) {
+	if timer, ok := s.timers[artId]; ok {
+		timer.Stop()
+		delete(s.timers, artId)
+	}
+}
+
+func (s *LocalArticleScheduler) publish(artId int64, publishAt time.Time) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
+	defer cancel()
+	lock, err := s.lockClient.TryLock(ctx, fmt.Sprintf("article:schedule:%d", artId), s.lockExpiration)
+	if errors.Is(err, redislock.ErrFailedToPreemptLock) {
+		// 别的实例正在发表，过一会儿再确认一下，万一它失败了还能接着发
+		s.retry(artId, publishAt)
+		return
+	}
+	if err != nil {
+		s.l.Error("定时发表抢锁失败", logger.Int64("art_id", artId), logger.Error(err))
+		s.retry(artId, publishAt)
+		return
+	}
+	defer func() {
+		unlockCtx, unlockCancel := context.WithTimeout(context.Background(), time.Second)
+		defer unlockCancel()
+		// 释放之后别的实例再查就是已经发表了，不会重复发表
+		er := lock.Unlock(unlockCtx)
+		if er != nil {
+			s.l.Error("释放定时发表的锁失败", logger.Int64("art_id", artId), logger.Error(er))
+		}
+	}()
+	art, err := s.repo.GetById(ctx, artId)
+	if errors.Is(err, ErrArticleNotFound) {
+		// 文章已经被删除了
+		return
+	}
+	if err != nil {
+		s.l.Error("定时发表查询文章失败", logger.Int64("art_id", artId), logger.Error(err))
+		s.retry(artId, publishAt)
+		return
+	}
+	if art.Status != domain.ArticleStatusScheduled || art.PublishAt.UnixMilli() != publishAt.UnixMilli() {
+		// 已经取消或者改期了
+		return
+	}
+	art.Status = domain.ArticleStatusPublished
+	art.PublishAt = time.Time{}
+	_, err = s.repo.Sync(ctx, art)
+	if err != nil {
+		s.l.Error("定时发表文章失败", logger.Int64("art_id", artId), logger.Error(err))
+		s.retry(artId, publishAt)
+		return
+	}
+	s.l.Info("定时发表文章成功", logger.Int64("art_id", artId))
+	for _, listener := range s.listeners {
+		listener.OnPublished(ctx, art)
+	}
+}
+
+// retry 稍后重试，期间作者改期或者取消会覆盖掉这次重试
+func (s *LocalArticleScheduler) retry(artId int64, publishAt time.Time) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if _, ok := s.timers[artId]; ok {
+		return
+	}
+	s.startLocked(artId, s.retryInterval, publishAt)
+}
+
+// startLocked 启动定时器，会替换掉这篇文章之前的定时器
+func (s *LocalArticleScheduler) startLocked(artId int64, delay time.Duration, publishAt time.Time) {
+	s.stopLocked(artId)
+	var timer *time.Timer
+	timer = time.AfterFunc(delay, func() {
+		s.mutex.Lock()
+		// 已经被新的定时器替换掉了
+		if s.timers[artId] != timer {
+			s.mutex.Unlock()
+			return
+		}
+		delete(s.timers, artId)
+		s.mutex.Unlock()
+		s.publish(artId, publishAt)
+	})
+	s.timers[artId] = timer
+}
@@ -0,0 +1,177 @@
+package service
+
+import (
+	"context"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+	"testing"
+	"time"
+	"webook/webook/internal/domain"
+	repomocks "webook/webook/internal/repository/mocks"
+	redismock "webook/webook/mock/redis"
+	"webook/webook/pkg/logger"
+	"webook/webook/pkg/redislock"
+)
+
+func TestLocalArticleScheduler_Schedule(t *testing.T) {
+	testCases := []struct {
+		name string
+		// 返回的 channel 在发表流程结束的时候关闭
+		mock func(ctrl *gomock.Controller, repo *repomocks.MockArticleRepository,
+			client *redismock.MockCmdable, publishAt time.Time) chan struct{}
+		cancel      bool
+		wantTrigger bool
+		// 别的实例在发表，稍后再确认一次
+		wantRetry bool
+	}{
+		{
+			name: "到时间发表",
+			mock: func(ctrl *gomock.Controller, repo *repomocks.MockArticleRepository,
+				client *redismock.MockCmdable, publishAt time.Time) chan struct{} {
+				done := make(chan struct{})
+				client.EXPECT().SetNX(gomock.Any(), "article:schedule:1", gomock.Any(), time.Second*30).
+					Return(redis.NewBoolResult(true, nil))
+				repo.EXPECT().GetById(gomock.Any(), int64(1)).Return(domain.Article{
+					Id:        1,
+					Title:     "我的标题",
+					Author:    domain.Author{Id: 123},
+					Status:    domain.ArticleStatusScheduled,
+					PublishAt: publishAt,
+				}, nil)
+				repo.EXPECT().Sync(gomock.Any(), domain.Article{
+					Id:     1,
+					Title:  "我的标题",
+					Author: domain.Author{Id: 123},
+					Status: domain.ArticleStatusPublished,
+				}).Return(int64(1), nil)
+				// 发表完释放锁
+				client.EXPECT().Eval(gomock.Any(), gomock.Any(), []string{"article:schedule:1"}, gomock.Any()).
+					DoAndReturn(func(ctx context.Context, script string, keys []string, args ...any) *redis.Cmd {
+						close(done)
+						return redis.NewCmdResult(int64(1), nil)
+					})
+				return done
+			},
+			wantTrigger: true,
+		},
+		{
+			name: "已经改期了，不发表",
+			mock: func(ctrl *gomock.Controller, repo *repomocks.MockArticleRepository,
+				client *redismock.MockCmdable, publishAt time.Time) chan struct{} {
+				done := make(chan struct{})
+				client.EXPECT().SetNX(gomock.Any(), "article:schedule:1", gomock.Any(), time.Second*30).
+					Return(redis.NewBoolResult(true, nil))
+				repo.EXPECT().GetById(gomock.Any(), int64(1)).Return(domain.Article{
+					Id:        1,
+					Status:    domain.ArticleStatusScheduled,
+					PublishAt: publishAt.Add(time.Hour),
+				}, nil)
+				client.EXPECT().Eval(gomock.Any(), gomock.Any(), []string{"article:schedule:1"}, gomock.Any()).
+					DoAndReturn(func(ctx context.Context, script string, keys []string, args ...any) *redis.Cmd {
+						close(done)
+						return redis.NewCmdResult(int64(1), nil)
+					})
+				return done
+			},
+			wantTrigger: true,
+		},
+		{
+			name: "别的实例正在发表",
+			mock: func(ctrl *gomock.Controller, repo *repomocks.MockArticleRepository,
+				client *redismock.MockCmdable, publishAt time.Time) chan struct{} {
+				done := make(chan struct{})
+				// 没抢到锁，不会查文章
+				client.EXPECT().SetNX(gomock.Any(), "article:schedule:1", gomock.Any(), time.Second*30).
+					DoAndReturn(func(ctx context.Context, key string, val any, exp time.Duration) *redis.BoolCmd {
+						close(done)
+						return redis.NewBoolResult(false, nil)
+					})
+				return done
+			},
+			wantTrigger: true,
+			wantRetry:   true,
+		},
+		{
+			name: "取消了，不会触发",
+			mock: func(ctrl *gomock.Controller, repo *repomocks.MockArticleRepository,
+				client *redismock.MockCmdable, publishAt time.Time) chan struct{} {
+				return nil
+			},
+			cancel: true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			repo := repomocks.NewMockArticleRepository(ctrl)
+			client := redismock.NewMockCmdable(ctrl)
+			// 每个用例单独计算时间，保证取消的时候定时器还没有触发
+			publishAt := time.UnixMilli(time.Now().Add(time.Millisecond * 100).UnixMilli())
+			done := tc.mock(ctrl, repo, client, publishAt)
+			s := NewLocalArticleScheduler(repo, nil, redislock.NewClient(client), logger.NewNoOpLogger())
+			// 重试的时候 mock 没有设置预期，测试结束之前不能触发
+			s.retryInterval = time.Hour
+			s.Schedule(1, publishAt)
+			if tc.cancel {
+				s.Cancel(1)
+			}
+			if !tc.wantTrigger {
+				// 等到超过发表时间，mock 没有设置预期，被调用的话会失败
+				time.Sleep(time.Until(publishAt) + time.Millisecond*50)
+				return
+			}
+			select {
+			case <-done:
+			case <-time.After(time.Second):
+				t.Fatal("定时器没有触发")
+			}
+			if tc.wantRetry {
+				assert.Eventually(t, func() bool {
+					s.mutex.Lock()
+					defer s.mutex.Unlock()
+					_, ok := s.timers[1]
+					return ok
+				}, time.Second, time.Millisecond*10)
+			}
+		})
+	}
+}
+
+func TestLocalArticleScheduler_Load(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	repo := repomocks.NewMockArticleRepository(ctrl)
+	client := redismock.NewMockCmdable(ctrl)
+	// 重启期间已经过了发表时间的文章，加载之后马上发表
+	publishAt := time.UnixMilli(time.Now().Add(-time.Minute).UnixMilli())
+	art := domain.Article{
+		Id:        1,
+		Author:    domain.Author{Id: 123},
+		Status:    domain.ArticleStatusScheduled,
+		PublishAt: publishAt,
+	}
+	repo.EXPECT().ListScheduled(gomock.Any()).Return([]domain.Article{art}, nil)
+	client.EXPECT().SetNX(gomock.Any(), "article:schedule:1", gomock.Any(), time.Second*30).
+		Return(redis.NewBoolResult(true, nil))
+	repo.EXPECT().GetById(gomock.Any(), int64(1)).Return(art, nil)
+	done := make(chan struct{})
+	repo.EXPECT().Sync(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, art domain.Article) (int64, error) {
+		assert.Equal(t, domain.ArticleStatusPublished, art.Status)
+		return 1, nil
+	})
+	client.EXPECT().Eval(gomock.Any(), gomock.Any(), []string{"article:schedule:1"}, gomock.Any()).
+		DoAndReturn(func(ctx context.Context, script string, keys []string, args ...any) *redis.Cmd {
+			close(done)
+			return redis.NewCmdResult(int64(1), nil)
+		})
+	s := NewLocalArticleScheduler(repo, nil, redislock.NewClient(client), logger.NewNoOpLogger())
+	err := s.Load(context.Background())
+	assert.NoError(t, err)
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("没有发表过期的文章")
+	}
+}
@@ -6,10 +6,13 @@ import (
 	"github.com/stretchr/testify/assert"
 	"go.uber.org/mock/gomock"
 	"testing"
+	"time"
 	"webook/webook/internal/domain"
 	"webook/webook/internal/repository"
 	repomocks "webook/webook/internal/repository/mocks"
+	svcmocks "webook/webook/internal/service/mocks"
 	"webook/webook/pkg/logger"
+	"webook/webook/pkg/sensitive"
 )
 
 func Test_articleService_Publish(t *testing.T) {
@@ -207,3 +210,739 @@ func Test_articleService_Publish(t *testing.T) {
 		})
 	}
 }
+
+func Test_articleService_RestoreRevision(t *testing.T) {
+	testCases := []struct {
+		name    string
+		mock    func(ctrl *gomock.Controller) (repository.ArticleRepository, repository.ArticleRevisionRepository)
+		artId   int64
+		uid     int64
+		revId   int64
+		wantErr error
+	}{
+		{
+			name: "恢复成功",
+			mock: func(ctrl *gomock.Controller) (repository.ArticleRepository, repository.ArticleRevisionRepository) {
+				repo := repomocks.NewMockArticleRepository(ctrl)
+				revRepo := repomocks.NewMockArticleRevisionRepository(ctrl)
+				repo.EXPECT().GetById(gomock.Any(), int64(1)).Return(domain.Article{
+					Id:      1,
+					Title:   "新的标题",
+					Content: "新的内容",
+					Author:  domain.Author{Id: 123},
+					Status:  domain.ArticleStatusPublished,
+				}, nil)
+				revRepo.EXPECT().GetById(gomock.Any(), int64(10)).Return(domain.ArticleRevision{
+					Id:        10,
+					ArticleId: 1,
+					Title:     "旧的标题",
+					Content:   "旧的内容",
+				}, nil)
+				// 恢复为草稿，内容要重新渲染
+				repo.EXPECT().Update(gomock.Any(), domain.Article{
+					Id:      1,
+					Title:   "旧的标题",
+					Content: "旧的内容",
+					HTML:    "<p>旧的内容</p>\n",
+					Summary: "旧的内容",
+					Author:  domain.Author{Id: 123},
+					Status:  domain.ArticleStatusUnpublished,
+				}).Return(nil)
+				revRepo.EXPECT().Prune(gomock.Any(), int64(1), domain.RevisionRetention{KeepCount: 10}).Return(nil)
+				return repo, revRepo
+			},
+			artId: 1,
+			uid:   123,
+			revId: 10,
+		},
+		{
+			name: "不是作者",
+			mock: func(ctrl *gomock.Controller) (repository.ArticleRepository, repository.ArticleRevisionRepository) {
+				repo := repomocks.NewMockArticleRepository(ctrl)
+				revRepo := repomocks.NewMockArticleRevisionRepository(ctrl)
+				repo.EXPECT().GetById(gomock.Any(), int64(1)).Return(domain.Article{
+					Id:     1,
+					Author: domain.Author{Id: 456},
+				}, nil)
+				return repo, revRepo
+			},
+			artId:   1,
+			uid:     123,
+			revId:   10,
+			wantErr: ErrArticleNotFound,
+		},
+		{
+			name: "不是这篇文章的版本",
+			mock: func(ctrl *gomock.Controller) (repository.ArticleRepository, repository.ArticleRevisionRepository) {
+				repo := repomocks.NewMockArticleRepository(ctrl)
+				revRepo := repomocks.NewMockArticleRevisionRepository(ctrl)
+				repo.EXPECT().GetById(gomock.Any(), int64(1)).Return(domain.Article{
+					Id:     1,
+					Author: domain.Author{Id: 123},
+				}, nil)
+				revRepo.EXPECT().GetById(gomock.Any(), int64(10)).Return(domain.ArticleRevision{
+					Id:        10,
+					ArticleId: 2,
+				}, nil)
+				return repo, revRepo
+			},
+			artId:   1,
+			uid:     123,
+			revId:   10,
+			wantErr: ErrRevisionNotFound,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			repo, revRepo := tc.mock(ctrl)
+			svc := NewArticleService(repo, revRepo, domain.RevisionRetention{KeepCount: 10},
+				domain.RecycleBinRetention{KeepDays: 30}, svcmocks.NewMockArticleScheduler(ctrl), passModeration(ctrl),
+				nil, logger.NewNoOpLogger())
+			err := svc.RestoreRevision(context.Background(), tc.artId, tc.uid, tc.revId)
+			assert.Equal(t, tc.wantErr, err)
+		})
+	}
+}
+
+func Test_articleService_Withdraw(t *testing.T) {
+	testCases := []struct {
+		name    string
+		mock    func(ctrl *gomock.Controller) repository.ArticleRepository
+		art     domain.Article
+		wantErr error
+	}{
+		{
+			name: "撤回成功",
+			mock: func(ctrl *gomock.Controller) repository.ArticleRepository {
+				repo := repomocks.NewMockArticleRepository(ctrl)
+				repo.EXPECT().GetById(gomock.Any(), int64(1)).Return(domain.Article{
+					Id:     1,
+					Author: domain.Author{Id: 123},
+					Status: domain.ArticleStatusPublished,
+				}, nil)
+				repo.EXPECT().Withdraw(gomock.Any(), int64(1), int64(123)).Return(nil)
+				return repo
+			},
+			art: domain.Article{Id: 1, Author: domain.Author{Id: 123}},
+		},
+		{
+			name: "已发表的文章有未发表的修改，撤回成功",
+			mock: func(ctrl *gomock.Controller) repository.ArticleRepository {
+				repo := repomocks.NewMockArticleRepository(ctrl)
+				repo.EXPECT().GetById(gomock.Any(), int64(1)).Return(domain.Article{
+					Id:     1,
+					Author: domain.Author{Id: 123},
+					Status: domain.ArticleStatusUnpublished,
+				}, nil)
+				repo.EXPECT().Withdraw(gomock.Any(), int64(1), int64(123)).Return(nil)
+				return repo
+			},
+			art: domain.Article{Id: 1, Author: domain.Author{Id: 123}},
+		},
+		{
+			name: "线上库没有发表的版本，不能撤回",
+			mock: func(ctrl *gomock.Controller) repository.ArticleRepository {
+				repo := repomocks.NewMockArticleRepository(ctrl)
+				repo.EXPECT().GetById(gomock.Any(), int64(1)).Return(domain.Article{
+					Id:     1,
+					Author: domain.Author{Id: 123},
+					Status: domain.ArticleStatusPrivate,
+				}, nil)
+				repo.EXPECT().Withdraw(gomock.Any(), int64(1), int64(123)).
+					Return(repository.ErrArticleNotPublished)
+				return repo
+			},
+			art:     domain.Article{Id: 1, Author: domain.Author{Id: 123}},
+			wantErr: ErrInvalidStatusTransition,
+		},
+		{
+			name: "撤回失败",
+			mock: func(ctrl *gomock.Controller) repository.ArticleRepository {
+				repo := repomocks.NewMockArticleRepository(ctrl)
+				repo.EXPECT().GetById(gomock.Any(), int64(1)).Return(domain.Article{
+					Id:     1,
+					Author: domain.Author{Id: 123},
+					Status: domain.ArticleStatusPublished,
+				}, nil)
+				repo.EXPECT().Withdraw(gomock.Any(), int64(1), int64(123)).
+					Return(errors.New("mock db error"))
+				return repo
+			},
+			art:     domain.Article{Id: 1, Author: domain.Author{Id: 123}},
+			wantErr: errors.New("mock db error"),
+		},
+		{
+			name: "不是作者",
+			mock: func(ctrl *gomock.Controller) repository.ArticleRepository {
+				repo := repomocks.NewMockArticleRepository(ctrl)
+				repo.EXPECT().GetById(gomock.Any(), int64(1)).Return(domain.Article{
+					Id:     1,
+					Author: domain.Author{Id: 456},
+					Status: domain.ArticleStatusPublished,
+				}, nil)
+				return repo
+			},
+			art:     domain.Article{Id: 1, Author: domain.Author{Id: 123}},
+			wantErr: ErrArticleNotFound,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			svc := NewArticleService(tc.mock(ctrl), repomocks.NewMockArticleRevisionRepository(ctrl),
+				domain.RevisionRetention{}, domain.RecycleBinRetention{}, svcmocks.NewMockArticleScheduler(ctrl),
+				passModeration(ctrl), nil, logger.NewNoOpLogger())
+			err := svc.Withdraw(context.Background(), tc.art)
+			assert.Equal(t, tc.wantErr, err)
+		})
+	}
+}
+
+func Test_articleService_RestoreFromRecycleBin(t *testing.T) {
+	publishAt := time.UnixMilli(time.Now().Add(time.Hour).UnixMilli())
+	testCases := []struct {
+		name    string
+		mock    func(ctrl *gomock.Controller) (repository.ArticleRepository, ArticleScheduler)
+		wantErr error
+	}{
+		{
+			name: "恢复成功",
+			mock: func(ctrl *gomock.Controller) (repository.ArticleRepository, ArticleScheduler) {
+				repo := repomocks.NewMockArticleRepository(ctrl)
+				repo.EXPECT().RestoreDeleted(gomock.Any(), int64(1), int64(123), gomock.Any()).
+					DoAndReturn(func(ctx context.Context, id int64, uid int64, after time.Time) error {
+						// 只能恢复 7 天之内删除的文章
+						expected := time.Now().AddDate(0, 0, -7)
+						assert.WithinDuration(t, expected, after, time.Second)
+						return nil
+					})
+				repo.EXPECT().GetById(gomock.Any(), int64(1)).Return(domain.Article{
+					Id:     1,
+					Status: domain.ArticleStatusPublished,
+				}, nil)
+				return repo, svcmocks.NewMockArticleScheduler(ctrl)
+			},
+		},
+		{
+			name: "恢复等待定时发表的文章，重新加上定时器",
+			mock: func(ctrl *gomock.Controller) (repository.ArticleRepository, ArticleScheduler) {
+				repo := repomocks.NewMockArticleRepository(ctrl)
+				repo.EXPECT().RestoreDeleted(gomock.Any(), int64(1), int64(123), gomock.Any()).Return(nil)
+				repo.EXPECT().GetById(gomock.Any(), int64(1)).Return(domain.Article{
+					Id:        1,
+					Status:    domain.ArticleStatusScheduled,
+					PublishAt: publishAt,
+				}, nil)
+				scheduler := svcmocks.NewMockArticleScheduler(ctrl)
+				scheduler.EXPECT().Schedule(int64(1), publishAt)
+				return repo, scheduler
+			},
+		},
+		{
+			name: "不在回收站里，或者已经过期",
+			mock: func(ctrl *gomock.Controller) (repository.ArticleRepository, ArticleScheduler) {
+				repo := repomocks.NewMockArticleRepository(ctrl)
+				repo.EXPECT().RestoreDeleted(gomock.Any(), int64(1), int64(123), gomock.Any()).
+					Return(ErrArticleNotFound)
+				return repo, svcmocks.NewMockArticleScheduler(ctrl)
+			},
+			wantErr: ErrArticleNotFound,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			repo, scheduler := tc.mock(ctrl)
+			svc := NewArticleService(repo, repomocks.NewMockArticleRevisionRepository(ctrl),
+				domain.RevisionRetention{}, domain.RecycleBinRetention{KeepDays: 7}, scheduler, passModeration(ctrl),
+				nil, logger.NewNoOpLogger())
+			err := svc.RestoreFromRecycleBin(context.Background(), 1, 123)
+			assert.Equal(t, tc.wantErr, err)
+		})
+	}
+}
+
+func Test_articleService_SchedulePublish(t *testing.T) {
+	publishAt := time.UnixMilli(time.Now().Add(time.Hour).UnixMilli())
+	testCases := []struct {
+		name    string
+		mock    func(ctrl *gomock.Controller) (repository.ArticleRepository, ArticleScheduler)
+		art     domain.Article
+		wantId  int64
+		wantErr error
+	}{
+		{
+			name: "新建并定时发表",
+			mock: func(ctrl *gomock.Controller) (repository.ArticleRepository, ArticleScheduler) {
+				repo := repomocks.NewMockArticleRepository(ctrl)
+				repo.EXPECT().Create(gomock.Any(), domain.Article{
+					Title:     "我的标题",
+					Author:    domain.Author{Id: 123},
+					Status:    domain.ArticleStatusScheduled,
+					PublishAt: publishAt,
+				}).Return(int64(1), nil)
+				scheduler := svcmocks.NewMockArticleScheduler(ctrl)
+				scheduler.EXPECT().Schedule(int64(1), publishAt)
+				return repo, scheduler
+			},
+			art: domain.Article{
+				Title:     "我的标题",
+				Author:    domain.Author{Id: 123},
+				PublishAt: publishAt,
+			},
+			wantId: 1,
+		},
+		{
+			name: "撤回的文章也可以定时发表",
+			mock: func(ctrl *gomock.Controller) (repository.ArticleRepository, ArticleScheduler) {
+				repo := repomocks.NewMockArticleRepository(ctrl)
+				repo.EXPECT().GetById(gomock.Any(), int64(2)).Return(domain.Article{
+					Id:     2,
+					Author: domain.Author{Id: 123},
+					Status: domain.ArticleStatusPrivate,
+				}, nil)
+				repo.EXPECT().Update(gomock.Any(), domain.Article{
+					Id:        2,
+					Title:     "我的标题",
+					Author:    domain.Author{Id: 123},
+					Status:    domain.ArticleStatusScheduled,
+					PublishAt: publishAt,
+				}).Return(nil)
+				scheduler := svcmocks.NewMockArticleScheduler(ctrl)
+				scheduler.EXPECT().Schedule(int64(2), publishAt)
+				return repo, scheduler
+			},
+			art: domain.Article{
+				Id:        2,
+				Title:     "我的标题",
+				Author:    domain.Author{Id: 123},
+				PublishAt: publishAt,
+			},
+			wantId: 2,
+		},
+		{
+			name: "保存失败，不会加上定时器",
+			mock: func(ctrl *gomock.Controller) (repository.ArticleRepository, ArticleScheduler) {
+				repo := repomocks.NewMockArticleRepository(ctrl)
+				repo.EXPECT().Create(gomock.Any(), gomock.Any()).Return(int64(0), errors.New("mock db error"))
+				return repo, svcmocks.NewMockArticleScheduler(ctrl)
+			},
+			art: domain.Article{
+				Title:     "我的标题",
+				Author:    domain.Author{Id: 123},
+				PublishAt: publishAt,
+			},
+			wantErr: errors.New("mock db error"),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			repo, scheduler := tc.mock(ctrl)
+			revRepo := repomocks.NewMockArticleRevisionRepository(ctrl)
+			revRepo.EXPECT().Prune(gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes().Return(nil)
+			svc := NewArticleService(repo, revRepo, domain.RevisionRetention{}, domain.RecycleBinRetention{},
+				scheduler, passModeration(ctrl), nil, logger.NewNoOpLogger())
+			id, err := svc.SchedulePublish(context.Background(), tc.art)
+			assert.Equal(t, tc.wantErr, err)
+			assert.Equal(t, tc.wantId, id)
+		})
+	}
+}
+
+func Test_articleService_Reschedule(t *testing.T) {
+	publishAt := time.UnixMilli(time.Now().Add(time.Hour).UnixMilli())
+	testCases := []struct {
+		name    string
+		mock    func(ctrl *gomock.Controller) (repository.ArticleRepository, ArticleScheduler)
+		wantErr error
+	}{
+		{
+			name: "改期成功",
+			mock: func(ctrl *gomock.Controller) (repository.ArticleRepository, ArticleScheduler) {
+				repo := repomocks.NewMockArticleRepository(ctrl)
+				repo.EXPECT().GetById(gomock.Any(), int64(1)).Return(domain.Article{
+					Id:     1,
+					Author: domain.Author{Id: 123},
+					Status: domain.ArticleStatusScheduled,
+				}, nil)
+				repo.EXPECT().UpdateSchedule(gomock.Any(), int64(1), int64(123),
+					domain.ArticleStatusScheduled, domain.ArticleStatusScheduled, publishAt).Return(nil)
+				scheduler := svcmocks.NewMockArticleScheduler(ctrl)
+				scheduler.EXPECT().Schedule(int64(1), publishAt)
+				return repo, scheduler
+			},
+		},
+		{
+			name: "没有在等待发表",
+			mock: func(ctrl *gomock.Controller) (repository.ArticleRepository, ArticleScheduler) {
+				repo := repomocks.NewMockArticleRepository(ctrl)
+				repo.EXPECT().GetById(gomock.Any(), int64(1)).Return(domain.Article{
+					Id:     1,
+					Author: domain.Author{Id: 123},
+					Status: domain.ArticleStatusPublished,
+				}, nil)
+				return repo, svcmocks.NewMockArticleScheduler(ctrl)
+			},
+			wantErr: ErrInvalidStatusTransition,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			repo, scheduler := tc.mock(ctrl)
+			svc := NewArticleService(repo, repomocks.NewMockArticleRevisionRepository(ctrl),
+				domain.RevisionRetention{}, domain.RecycleBinRetention{}, scheduler, passModeration(ctrl),
+				nil, logger.NewNoOpLogger())
+			err := svc.Reschedule(context.Background(), 1, 123, publishAt)
+			assert.Equal(t, tc.wantErr, err)
+		})
+	}
+}
+
+func Test_articleService_PublishNotifyListeners(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	repo := repomocks.NewMockArticleRepository(ctrl)
+	revRepo := repomocks.NewMockArticleRevisionRepository(ctrl)
+	listener := svcmocks.NewMockArticleListener(ctrl)
+	repo.EXPECT().Sync(gomock.Any(), gomock.Any()).Return(int64(1), nil)
+	revRepo.EXPECT().Prune(gomock.Any(), int64(1), gomock.Any()).Return(nil)
+	// 新建的文章，通知的时候要带上 ID
+	listener.EXPECT().OnPublished(gomock.Any(), domain.Article{
+		Id:     1,
+		Title:  "我的标题",
+		Author: domain.Author{Id: 123},
+		Status: domain.ArticleStatusPublished,
+	})
+	svc := NewArticleService(repo, revRepo, domain.RevisionRetention{}, domain.RecycleBinRetention{},
+		svcmocks.NewMockArticleScheduler(ctrl), passModeration(ctrl), []ArticleListener{listener}, logger.NewNoOpLogger())
+	id, err := svc.Publish(context.Background(), domain.Article{
+		Title:  "我的标题",
+		Author: domain.Author{Id: 123},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), id)
+}
+
+func Test_articleService_PurgeRecycleBin(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	repo := repomocks.NewMockArticleRepository(ctrl)
+	listener := svcmocks.NewMockArticleListener(ctrl)
+	repo.EXPECT().PurgeDeleted(gomock.Any(), gomock.Any(), 10).Return([]int64{1, 2}, nil)
+	// 搜索索引、热榜这些不在数据库里的，按照撤回通知一遍
+	listener.EXPECT().OnWithdrawn(gomock.Any(), domain.Article{Id: 1})
+	listener.EXPECT().OnWithdrawn(gomock.Any(), domain.Article{Id: 2})
+	svc := NewArticleService(repo, repomocks.NewMockArticleRevisionRepository(ctrl),
+		domain.RevisionRetention{}, domain.RecycleBinRetention{KeepDays: 30},
+		svcmocks.NewMockArticleScheduler(ctrl), passModeration(ctrl), []ArticleListener{listener}, logger.NewNoOpLogger())
+	n, err := svc.PurgeRecycleBin(context.Background(), 10)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, n)
+}
+
+func Test_articleService_ListPubByTag(t *testing.T) {
+	testCases := []struct {
+		name     string
+		tag      string
+		mock     func(ctrl *gomock.Controller) repository.ArticleRepository
+		wantArts []domain.Article
+		wantErr  error
+	}{
+		{
+			name: "按照规范化之后的标签查询",
+			tag:  "#Micro Service",
+			mock: func(ctrl *gomock.Controller) repository.ArticleRepository {
+				repo := repomocks.NewMockArticleRepository(ctrl)
+				repo.EXPECT().ListPubByTag(gomock.Any(), "micro-service", 0, 10).
+					Return([]domain.Article{{Id: 1}}, nil)
+				return repo
+			},
+			wantArts: []domain.Article{{Id: 1}},
+		},
+		{
+			name: "规范化之后是空的，不查数据库",
+			tag:  " # ",
+			mock: func(ctrl *gomock.Controller) repository.ArticleRepository {
+				return repomocks.NewMockArticleRepository(ctrl)
+			},
+			wantArts: []domain.Article{},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			svc := NewArticleService(tc.mock(ctrl), repomocks.NewMockArticleRevisionRepository(ctrl),
+				domain.RevisionRetention{}, domain.RecycleBinRetention{},
+				svcmocks.NewMockArticleScheduler(ctrl), passModeration(ctrl), nil, logger.NewNoOpLogger())
+			arts, err := svc.ListPubByTag(context.Background(), tc.tag, 0, 10)
+			assert.Equal(t, tc.wantErr, err)
+			assert.Equal(t, tc.wantArts, arts)
+		})
+	}
+}
+
+func Test_articleService_PublishRender(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	repo := repomocks.NewMockArticleRepository(ctrl)
+	revRepo := repomocks.NewMockArticleRevisionRepository(ctrl)
+	// 线上库和制作库保存的都是过滤之后的 HTML
+	repo.EXPECT().Sync(gomock.Any(), domain.Article{
+		Title:   "我的标题",
+		Content: "**你好**<script>alert(1)</script>",
+		HTML:    "<p><strong>你好</strong></p>\n",
+		Summary: "你好",
+		Author:  domain.Author{Id: 123},
+		Status:  domain.ArticleStatusPublished,
+	}).Return(int64(1), nil)
+	revRepo.EXPECT().Prune(gomock.Any(), int64(1), gomock.Any()).Return(nil)
+	svc := NewArticleService(repo, revRepo, domain.RevisionRetention{}, domain.RecycleBinRetention{},
+		svcmocks.NewMockArticleScheduler(ctrl), passModeration(ctrl), nil, logger.NewNoOpLogger())
+	_, err := svc.Publish(context.Background(), domain.Article{
+		Title:   "我的标题",
+		Content: "**你好**<script>alert(1)</script>",
+		Author:  domain.Author{Id: 123},
+	})
+	assert.NoError(t, err)
+}
+
+// passModeration 审核总是通过
+func passModeration(ctrl *gomock.Controller) ModerationService {
+	moderation := svcmocks.NewMockModerationService(ctrl)
+	moderation.EXPECT().Check(gomock.Any(), gomock.Any()).
+		Return(domain.ModerationResult{Level: domain.ModerationPass}, nil).AnyTimes()
+	return moderation
+}
+
+func Test_articleService_PublishModeration(t *testing.T) {
+	testCases := []struct {
+		name    string
+		cfg     domain.ModerationConfig
+		mock    func(ctrl *gomock.Controller) repository.ArticleRepository
+		art     domain.Article
+		wantId  int64
+		wantErr error
+	}{
+		{
+			name: "包含违禁词，不保存",
+			mock: func(ctrl *gomock.Controller) repository.ArticleRepository {
+				return repomocks.NewMockArticleRepository(ctrl)
+			},
+			art: domain.Article{
+				Title:   "违禁标题",
+				Content: "内容",
+				Author:  domain.Author{Id: 123},
+			},
+			wantErr: &SensitiveWordsError{Words: []string{"违禁"}},
+		},
+		{
+			name: "需要人工审核，保存为等待审核",
+			cfg:  domain.ModerationConfig{Review: true},
+			mock: func(ctrl *gomock.Controller) repository.ArticleRepository {
+				repo := repomocks.NewMockArticleRepository(ctrl)
+				repo.EXPECT().Create(gomock.Any(), domain.Article{
+					Title:   "标题",
+					Content: "擦边",
+					HTML:    "<p>擦边</p>\n",
+					Summary: "擦边",
+					Author:  domain.Author{Id: 123},
+					Status:  domain.ArticleStatusPendingReview,
+				}).Return(int64(1), nil)
+				return repo
+			},
+			art: domain.Article{
+				Title:   "标题",
+				Content: "擦边",
+				Author:  domain.Author{Id: 123},
+			},
+			wantId:  1,
+			wantErr: ErrPendingReview,
+		},
+		{
+			name: "所有文章都要人工审核",
+			cfg:  domain.ModerationConfig{ReviewAll: true},
+			mock: func(ctrl *gomock.Controller) repository.ArticleRepository {
+				repo := repomocks.NewMockArticleRepository(ctrl)
+				repo.EXPECT().Create(gomock.Any(), domain.Article{
+					Title:   "标题",
+					Content: "内容",
+					HTML:    "<p>内容</p>\n",
+					Summary: "内容",
+					Author:  domain.Author{Id: 123},
+					Status:  domain.ArticleStatusPendingReview,
+				}).Return(int64(1), nil)
+				return repo
+			},
+			art: domain.Article{
+				Title:   "标题",
+				Content: "内容",
+				Author:  domain.Author{Id: 123},
+			},
+			wantId:  1,
+			wantErr: ErrPendingReview,
+		},
+		{
+			name: "没有开启人工审核，直接发表",
+			mock: func(ctrl *gomock.Controller) repository.ArticleRepository {
+				repo := repomocks.NewMockArticleRepository(ctrl)
+				repo.EXPECT().Sync(gomock.Any(), gomock.Any()).Return(int64(1), nil)
+				return repo
+			},
+			art: domain.Article{
+				Title:   "标题",
+				Content: "擦边",
+				Author:  domain.Author{Id: 123},
+			},
+			wantId: 1,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			revRepo := repomocks.NewMockArticleRevisionRepository(ctrl)
+			revRepo.EXPECT().Prune(gomock.Any(), int64(1), gomock.Any()).Return(nil).AnyTimes()
+			moderation := NewDictModerationService(sensitive.NewDictionary([]string{"违禁"}, []string{"擦边"}), tc.cfg)
+			svc := NewArticleService(tc.mock(ctrl), revRepo, domain.RevisionRetention{}, domain.RecycleBinRetention{},
+				svcmocks.NewMockArticleScheduler(ctrl), moderation, nil, logger.NewNoOpLogger())
+			id, err := svc.Publish(context.Background(), tc.art)
+			assert.Equal(t, tc.wantErr, err)
+			assert.Equal(t, tc.wantId, id)
+		})
+	}
+}
+
+func Test_articleService_ApproveReview(t *testing.T) {
+	pending := domain.Article{
+		Id:      1,
+		Title:   "标题",
+		Content: "内容",
+		HTML:    "<p>内容</p>\n",
+		Author:  domain.Author{Id: 123},
+		Status:  domain.ArticleStatusPendingReview,
+	}
+	testCases := []struct {
+		name    string
+		mock    func(ctrl *gomock.Controller) repository.ArticleRepository
+		wantArt domain.Article
+		wantErr error
+	}{
+		{
+			name: "审核通过，同步到线上库",
+			mock: func(ctrl *gomock.Controller) repository.ArticleRepository {
+				repo := repomocks.NewMockArticleRepository(ctrl)
+				repo.EXPECT().GetById(gomock.Any(), int64(1)).Return(pending, nil)
+				published := pending
+				published.Status = domain.ArticleStatusPublished
+				repo.EXPECT().Sync(gomock.Any(), published).Return(int64(1), nil)
+				return repo
+			},
+			wantArt: domain.Article{
+				Id:      1,
+				Title:   "标题",
+				Content: "内容",
+				HTML:    "<p>内容</p>\n",
+				Author:  domain.Author{Id: 123},
+				Status:  domain.ArticleStatusPublished,
+			},
+		},
+		{
+			name: "文章不在等待审核",
+			mock: func(ctrl *gomock.Controller) repository.ArticleRepository {
+				repo := repomocks.NewMockArticleRepository(ctrl)
+				art := pending
+				art.Status = domain.ArticleStatusUnpublished
+				repo.EXPECT().GetById(gomock.Any(), int64(1)).Return(art, nil)
+				return repo
+			},
+			wantErr: ErrInvalidStatusTransition,
+		},
+		{
+			name: "同步失败",
+			mock: func(ctrl *gomock.Controller) repository.ArticleRepository {
+				repo := repomocks.NewMockArticleRepository(ctrl)
+				repo.EXPECT().GetById(gomock.Any(), int64(1)).Return(pending, nil)
+				repo.EXPECT().Sync(gomock.Any(), gomock.Any()).Return(int64(0), errors.New("mock db error"))
+				return repo
+			},
+			wantErr: errors.New("mock db error"),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			revRepo := repomocks.NewMockArticleRevisionRepository(ctrl)
+			revRepo.EXPECT().Prune(gomock.Any(), int64(1), gomock.Any()).Return(nil).AnyTimes()
+			svc := NewArticleService(tc.mock(ctrl), revRepo, domain.RevisionRetention{}, domain.RecycleBinRetention{},
+				svcmocks.NewMockArticleScheduler(ctrl), passModeration(ctrl), nil, logger.NewNoOpLogger())
+			art, err := svc.ApproveReview(context.Background(), 1)
+			assert.Equal(t, tc.wantErr, err)
+			assert.Equal(t, tc.wantArt, art)
+		})
+	}
+}
+
+func Test_articleService_RejectReview(t *testing.T) {
+	testCases := []struct {
+		name    string
+		mock    func(ctrl *gomock.Controller) repository.ArticleRepository
+		wantErr error
+	}{
+		{
+			name: "驳回，回到未发表",
+			mock: func(ctrl *gomock.Controller) repository.ArticleRepository {
+				repo := repomocks.NewMockArticleRepository(ctrl)
+				repo.EXPECT().GetById(gomock.Any(), int64(1)).Return(domain.Article{
+					Id:     1,
+					Author: domain.Author{Id: 123},
+					Status: domain.ArticleStatusPendingReview,
+				}, nil)
+				repo.EXPECT().UpdateSchedule(gomock.Any(), int64(1), int64(123),
+					domain.ArticleStatusPendingReview, domain.ArticleStatusUnpublished, time.Time{}).Return(nil)
+				return repo
+			},
+		},
+		{
+			name: "已经发表了",
+			mock: func(ctrl *gomock.Controller) repository.ArticleRepository {
+				repo := repomocks.NewMockArticleRepository(ctrl)
+				repo.EXPECT().GetById(gomock.Any(), int64(1)).Return(domain.Article{
+					Id:     1,
+					Author: domain.Author{Id: 123},
+					Status: domain.ArticleStatusPublished,
+				}, nil)
+				return repo
+			},
+			wantErr: ErrInvalidStatusTransition,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			svc := NewArticleService(tc.mock(ctrl), repomocks.NewMockArticleRevisionRepository(ctrl),
+				domain.RevisionRetention{}, domain.RecycleBinRetention{},
+				svcmocks.NewMockArticleScheduler(ctrl), passModeration(ctrl), nil, logger.NewNoOpLogger())
+			_, err := svc.RejectReview(context.Background(), 1)
+			assert.Equal(t, tc.wantErr, err)
+		})
+	}
+}
@@ -4,12 +4,19 @@ import (
 	"context"
 	"fmt"
 	"math/rand"
+	"time"
 	"webook/webook/internal/repository"
 	"webook/webook/internal/service/sms"
 )
 
-// 我的短信验证码模板Id
-const codeTplId string = "1921139"
+// codeTpls 不同业务的验证码使用不同的短信模板，没有配置的用登录验证码的模板
+var codeTpls = map[string]string{
+	"login":          sms.TplLoginCode,
+	"password_reset": sms.TplPasswordReset,
+}
+
+// codeExpiration 和 set_code.lua 里面验证码的过期时间保持一致，过期之后短信也没有必要再发了
+const codeExpiration = 10 * time.Minute
 
 var (
 	ErrCodeSendTooMany   = repository.ErrCodeSendTooMany
@@ -41,7 +48,12 @@ func (s *SmsCodeService) Send(ctx context.Context, biz string, phone string) err
 		return err
 	}
 	// 只有个redis存储验证码通过后才能发送短信
-	return s.smsSvc.Send(ctx, codeTplId, []string{code}, phone)
+	tpl, ok := codeTpls[biz]
+	if !ok {
+		tpl = sms.TplLoginCode
+	}
+	ctx = sms.WithExpireAt(ctx, time.Now().Add(codeExpiration))
+	return s.smsSvc.Send(ctx, tpl, []string{code}, phone)
 }
 
 func (s *SmsCodeService) Verify(ctx context.Context, biz string, phone string, inputCode string) (bool, error) {
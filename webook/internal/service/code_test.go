@@ -1,12 +1,93 @@
 package service
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
 	"math/rand"
 	"testing"
+	"time"
+	"webook/webook/internal/repository"
+	repomocks "webook/webook/internal/repository/mocks"
+	"webook/webook/internal/service/sms"
+	smsmocks "webook/webook/internal/service/sms/mocks"
 )
 
 func TestSmsCodeService_generateCode(t *testing.T) {
 	nums := rand.Intn(1000000)
 	fmt.Printf("%06d", nums)
 }
+
+func TestSmsCodeService_Send(t *testing.T) {
+	testCases := []struct {
+		name    string
+		biz     string
+		mock    func(ctrl *gomock.Controller) (repository.CodeRepository, sms.Service)
+		wantErr error
+	}{
+		{
+			name: "登录验证码",
+			biz:  "login",
+			mock: func(ctrl *gomock.Controller) (repository.CodeRepository, sms.Service) {
+				repo := repomocks.NewMockCodeRepository(ctrl)
+				repo.EXPECT().Store(gomock.Any(), "login", "13800000000", gomock.Any()).Return(nil)
+				smsSvc := smsmocks.NewMockService(ctrl)
+				smsSvc.EXPECT().Send(gomock.Any(), sms.TplLoginCode, gomock.Len(1), "13800000000").
+					DoAndReturn(func(ctx context.Context, tplId string, args []string, numbers ...string) error {
+						// 验证码过期之后短信也不用再发了
+						expireAt, ok := sms.ExpireAt(ctx)
+						assert.True(t, ok)
+						assert.WithinDuration(t, time.Now().Add(codeExpiration), expireAt, time.Second)
+						return nil
+					})
+				return repo, smsSvc
+			},
+		},
+		{
+			name: "重置密码验证码",
+			biz:  "password_reset",
+			mock: func(ctrl *gomock.Controller) (repository.CodeRepository, sms.Service) {
+				repo := repomocks.NewMockCodeRepository(ctrl)
+				repo.EXPECT().Store(gomock.Any(), "password_reset", "13800000000", gomock.Any()).Return(nil)
+				smsSvc := smsmocks.NewMockService(ctrl)
+				smsSvc.EXPECT().Send(gomock.Any(), sms.TplPasswordReset, gomock.Len(1), "13800000000").Return(nil)
+				return repo, smsSvc
+			},
+		},
+		{
+			name: "发送太频繁，不发短信",
+			biz:  "login",
+			mock: func(ctrl *gomock.Controller) (repository.CodeRepository, sms.Service) {
+				repo := repomocks.NewMockCodeRepository(ctrl)
+				repo.EXPECT().Store(gomock.Any(), "login", "13800000000", gomock.Any()).Return(ErrCodeSendTooMany)
+				return repo, smsmocks.NewMockService(ctrl)
+			},
+			wantErr: ErrCodeSendTooMany,
+		},
+		{
+			name: "短信发送失败",
+			biz:  "login",
+			mock: func(ctrl *gomock.Controller) (repository.CodeRepository, sms.Service) {
+				repo := repomocks.NewMockCodeRepository(ctrl)
+				repo.EXPECT().Store(gomock.Any(), "login", "13800000000", gomock.Any()).Return(nil)
+				smsSvc := smsmocks.NewMockService(ctrl)
+				smsSvc.EXPECT().Send(gomock.Any(), sms.TplLoginCode, gomock.Any(), "13800000000").
+					Return(errors.New("服务商出错"))
+				return repo, smsSvc
+			},
+			wantErr: errors.New("服务商出错"),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			repo, smsSvc := tc.mock(ctrl)
+			svc := NewSmsCodeService(repo, smsSvc)
+			err := svc.Send(context.Background(), tc.biz, "13800000000")
+			assert.Equal(t, tc.wantErr, err)
+		})
+	}
+}
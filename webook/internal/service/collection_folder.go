@@ -0,0 +1,121 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"webook/webook/internal/domain"
+	"webook/webook/internal/repository"
+	"webook/webook/pkg/logger"
+)
+
+var (
+	ErrFolderNotFound      = repository.ErrFolderNotFound
+	ErrFolderNameDuplicate = repository.ErrFolderNameDuplicate
+	ErrFolderItemDuplicate = repository.ErrFolderItemDuplicate
+)
+
+type collectionFolderService struct {
+	repo    repository.CollectionFolderRepository
+	artRepo repository.ArticleRepository
+	// 放进收藏夹的同时记一次收藏，收藏数和“是否收藏”才对得上
+	intrRepo repository.InteractiveRepository
+	l        logger.Logger
+}
+
+func NewCollectionFolderService(repo repository.CollectionFolderRepository, artRepo repository.ArticleRepository,
+	intrRepo repository.InteractiveRepository, l logger.Logger) CollectionFolderService {
+	return &collectionFolderService{repo: repo, artRepo: artRepo, intrRepo: intrRepo, l: l}
+}
+
+func (c *collectionFolderService) Create(ctx context.Context, folder domain.CollectionFolder) (int64, error) {
+	return c.repo.Create(ctx, folder)
+}
+
+func (c *collectionFolderService) Rename(ctx context.Context, id int64, uid int64, name string) error {
+	return c.repo.Rename(ctx, id, uid, name)
+}
+
+func (c *collectionFolderService) Delete(ctx context.Context, id int64, uid int64) error {
+	return c.repo.Delete(ctx, id, uid)
+}
+
+func (c *collectionFolderService) List(ctx context.Context, uid int64, offset int, limit int) ([]domain.CollectionFolder, error) {
+	return c.repo.List(ctx, uid, offset, limit)
+}
+
+func (c *collectionFolderService) AddArticle(ctx context.Context, id int64, uid int64, artId int64) error {
+	_, err := c.getByOwner(ctx, id, uid)
+	if err != nil {
+		return err
+	}
+	// 只能收藏已经发表的文章
+	_, err = c.artRepo.GetPublishedById(ctx, artId)
+	if err != nil {
+		return err
+	}
+	err = c.repo.AddItem(ctx, id, artId)
+	if err != nil {
+		return err
+	}
+	// 已经收藏过，只是再放进另外一个收藏夹，收藏数不变
+	err = c.intrRepo.AddCollectionItem(ctx, domain.BizArticle, artId, uid)
+	if err != nil && !errors.Is(err, repository.ErrCollectionDuplicate) {
+		// 文章已经放进收藏夹了，计数没有加上不影响用户
+		c.l.Error("放进收藏夹之后记录收藏失败", logger.Error(err),
+			logger.Int64("uid", uid), logger.Int64("art_id", artId))
+	}
+	return nil
+}
+
+func (c *collectionFolderService) RemoveArticle(ctx context.Context, id int64, uid int64, artId int64) error {
+	// 从收藏夹移除不会取消收藏，取消收藏还是走原来的接口
+	_, err := c.getByOwner(ctx, id, uid)
+	if err != nil {
+		return err
+	}
+	return c.repo.RemoveItem(ctx, id, artId)
+}
+
+func (c *collectionFolderService) ListArticles(ctx context.Context, id int64, uid int64,
+	offset int, limit int) ([]domain.Article, error) {
+	_, err := c.getByOwner(ctx, id, uid)
+	if err != nil {
+		return nil, err
+	}
+	artIds, err := c.repo.ListItems(ctx, id, offset, limit)
+	if err != nil {
+		return nil, err
+	}
+	arts, err := c.artRepo.ListPubByIds(ctx, artIds)
+	if err != nil {
+		return nil, err
+	}
+	// 按照放进收藏夹的顺序排列，撤回或者删除了的文章查不出来，直接跳过
+	// 所以一页的数量可能比 limit 少
+	artMap := make(map[int64]domain.Article, len(arts))
+	for _, art := range arts {
+		artMap[art.Id] = art
+	}
+	res := make([]domain.Article, 0, len(arts))
+	for _, artId := range artIds {
+		if art, ok := artMap[artId]; ok {
+			res = append(res, art)
+		}
+	}
+	return res, nil
+}
+
+// getByOwner 查询收藏夹，并且校验是不是 uid 的
+func (c *collectionFolderService) getByOwner(ctx context.Context, id int64, uid int64) (domain.CollectionFolder, error) {
+	folder, err := c.repo.GetById(ctx, id)
+	if err != nil {
+		return domain.CollectionFolder{}, err
+	}
+	if folder.Uid != uid {
+		// 不告诉调用者收藏夹是存在的
+		c.l.Error("非法访问收藏夹，用户ID不匹配",
+			logger.Int64("uid", uid), logger.Int64("folder_id", id))
+		return domain.CollectionFolder{}, ErrFolderNotFound
+	}
+	return folder, nil
+}
@@ -0,0 +1,158 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+	"testing"
+	"webook/webook/internal/domain"
+	"webook/webook/internal/repository"
+	repomocks "webook/webook/internal/repository/mocks"
+	"webook/webook/pkg/logger"
+)
+
+func Test_collectionFolderService_AddArticle(t *testing.T) {
+	testCases := []struct {
+		name string
+		mock func(ctrl *gomock.Controller) (repository.CollectionFolderRepository,
+			repository.ArticleRepository, repository.InteractiveRepository)
+		wantErr error
+	}{
+		{
+			name: "放进收藏夹，同时记一次收藏",
+			mock: func(ctrl *gomock.Controller) (repository.CollectionFolderRepository,
+				repository.ArticleRepository, repository.InteractiveRepository) {
+				repo := repomocks.NewMockCollectionFolderRepository(ctrl)
+				repo.EXPECT().GetById(gomock.Any(), int64(1)).Return(domain.CollectionFolder{Id: 1, Uid: 123}, nil)
+				repo.EXPECT().AddItem(gomock.Any(), int64(1), int64(2)).Return(nil)
+				artRepo := repomocks.NewMockArticleRepository(ctrl)
+				artRepo.EXPECT().GetPublishedById(gomock.Any(), int64(2)).Return(domain.Article{Id: 2}, nil)
+				intrRepo := repomocks.NewMockInteractiveRepository(ctrl)
+				intrRepo.EXPECT().AddCollectionItem(gomock.Any(), domain.BizArticle, int64(2), int64(123)).Return(nil)
+				return repo, artRepo, intrRepo
+			},
+		},
+		{
+			name: "已经收藏过，放进另一个收藏夹",
+			mock: func(ctrl *gomock.Controller) (repository.CollectionFolderRepository,
+				repository.ArticleRepository, repository.InteractiveRepository) {
+				repo := repomocks.NewMockCollectionFolderRepository(ctrl)
+				repo.EXPECT().GetById(gomock.Any(), int64(1)).Return(domain.CollectionFolder{Id: 1, Uid: 123}, nil)
+				repo.EXPECT().AddItem(gomock.Any(), int64(1), int64(2)).Return(nil)
+				artRepo := repomocks.NewMockArticleRepository(ctrl)
+				artRepo.EXPECT().GetPublishedById(gomock.Any(), int64(2)).Return(domain.Article{Id: 2}, nil)
+				intrRepo := repomocks.NewMockInteractiveRepository(ctrl)
+				intrRepo.EXPECT().AddCollectionItem(gomock.Any(), domain.BizArticle, int64(2), int64(123)).
+					Return(repository.ErrCollectionDuplicate)
+				return repo, artRepo, intrRepo
+			},
+		},
+		{
+			name: "别人的收藏夹",
+			mock: func(ctrl *gomock.Controller) (repository.CollectionFolderRepository,
+				repository.ArticleRepository, repository.InteractiveRepository) {
+				repo := repomocks.NewMockCollectionFolderRepository(ctrl)
+				repo.EXPECT().GetById(gomock.Any(), int64(1)).Return(domain.CollectionFolder{Id: 1, Uid: 456}, nil)
+				return repo, repomocks.NewMockArticleRepository(ctrl), repomocks.NewMockInteractiveRepository(ctrl)
+			},
+			wantErr: ErrFolderNotFound,
+		},
+		{
+			name: "文章没有发表",
+			mock: func(ctrl *gomock.Controller) (repository.CollectionFolderRepository,
+				repository.ArticleRepository, repository.InteractiveRepository) {
+				repo := repomocks.NewMockCollectionFolderRepository(ctrl)
+				repo.EXPECT().GetById(gomock.Any(), int64(1)).Return(domain.CollectionFolder{Id: 1, Uid: 123}, nil)
+				artRepo := repomocks.NewMockArticleRepository(ctrl)
+				artRepo.EXPECT().GetPublishedById(gomock.Any(), int64(2)).Return(domain.Article{}, ErrArticleNotFound)
+				return repo, artRepo, repomocks.NewMockInteractiveRepository(ctrl)
+			},
+			wantErr: ErrArticleNotFound,
+		},
+		{
+			name: "已经在收藏夹里了",
+			mock: func(ctrl *gomock.Controller) (repository.CollectionFolderRepository,
+				repository.ArticleRepository, repository.InteractiveRepository) {
+				repo := repomocks.NewMockCollectionFolderRepository(ctrl)
+				repo.EXPECT().GetById(gomock.Any(), int64(1)).Return(domain.CollectionFolder{Id: 1, Uid: 123}, nil)
+				repo.EXPECT().AddItem(gomock.Any(), int64(1), int64(2)).Return(repository.ErrFolderItemDuplicate)
+				artRepo := repomocks.NewMockArticleRepository(ctrl)
+				artRepo.EXPECT().GetPublishedById(gomock.Any(), int64(2)).Return(domain.Article{Id: 2}, nil)
+				return repo, artRepo, repomocks.NewMockInteractiveRepository(ctrl)
+			},
+			wantErr: ErrFolderItemDuplicate,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			repo, artRepo, intrRepo := tc.mock(ctrl)
+			svc := NewCollectionFolderService(repo, artRepo, intrRepo, logger.NewNoOpLogger())
+			err := svc.AddArticle(context.Background(), 1, 123, 2)
+			assert.Equal(t, tc.wantErr, err)
+		})
+	}
+}
+
+func Test_collectionFolderService_ListArticles(t *testing.T) {
+	testCases := []struct {
+		name     string
+		mock     func(ctrl *gomock.Controller) (repository.CollectionFolderRepository, repository.ArticleRepository)
+		wantArts []domain.Article
+		wantErr  error
+	}{
+		{
+			name: "按照放进收藏夹的顺序，跳过撤回了的文章",
+			mock: func(ctrl *gomock.Controller) (repository.CollectionFolderRepository, repository.ArticleRepository) {
+				repo := repomocks.NewMockCollectionFolderRepository(ctrl)
+				repo.EXPECT().GetById(gomock.Any(), int64(1)).Return(domain.CollectionFolder{Id: 1, Uid: 123}, nil)
+				repo.EXPECT().ListItems(gomock.Any(), int64(1), 0, 10).Return([]int64{3, 2, 1}, nil)
+				artRepo := repomocks.NewMockArticleRepository(ctrl)
+				artRepo.EXPECT().ListPubByIds(gomock.Any(), []int64{3, 2, 1}).Return([]domain.Article{
+					{Id: 1, Title: "标题1"},
+					{Id: 3, Title: "标题3"},
+				}, nil)
+				return repo, artRepo
+			},
+			wantArts: []domain.Article{
+				{Id: 3, Title: "标题3"},
+				{Id: 1, Title: "标题1"},
+			},
+		},
+		{
+			name: "别人的收藏夹",
+			mock: func(ctrl *gomock.Controller) (repository.CollectionFolderRepository, repository.ArticleRepository) {
+				repo := repomocks.NewMockCollectionFolderRepository(ctrl)
+				repo.EXPECT().GetById(gomock.Any(), int64(1)).Return(domain.CollectionFolder{Id: 1, Uid: 456}, nil)
+				return repo, repomocks.NewMockArticleRepository(ctrl)
+			},
+			wantErr: ErrFolderNotFound,
+		},
+		{
+			name: "查询文章失败",
+			mock: func(ctrl *gomock.Controller) (repository.CollectionFolderRepository, repository.ArticleRepository) {
+				repo := repomocks.NewMockCollectionFolderRepository(ctrl)
+				repo.EXPECT().GetById(gomock.Any(), int64(1)).Return(domain.CollectionFolder{Id: 1, Uid: 123}, nil)
+				repo.EXPECT().ListItems(gomock.Any(), int64(1), 0, 10).Return([]int64{1}, nil)
+				artRepo := repomocks.NewMockArticleRepository(ctrl)
+				artRepo.EXPECT().ListPubByIds(gomock.Any(), []int64{1}).Return(nil, errors.New("mock db error"))
+				return repo, artRepo
+			},
+			wantErr: errors.New("mock db error"),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			repo, artRepo := tc.mock(ctrl)
+			svc := NewCollectionFolderService(repo, artRepo,
+				repomocks.NewMockInteractiveRepository(ctrl), logger.NewNoOpLogger())
+			arts, err := svc.ListArticles(context.Background(), 1, 123, 0, 10)
+			assert.Equal(t, tc.wantErr, err)
+			assert.Equal(t, tc.wantArts, arts)
+		})
+	}
+}
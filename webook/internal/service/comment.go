@@ -0,0 +1,105 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"webook/webook/internal/domain"
+	"webook/webook/internal/repository"
+	"webook/webook/pkg/logger"
+)
+
+var ErrCommentNotFound = repository.ErrCommentNotFound
+
+type commentService struct {
+	repo       repository.CommentRepository
+	artRepo    repository.ArticleRepository
+	moderation ModerationService
+	l          logger.Logger
+}
+
+func NewCommentService(repo repository.CommentRepository, artRepo repository.ArticleRepository,
+	moderation ModerationService, l logger.Logger) CommentService {
+	return &commentService{repo: repo, artRepo: artRepo, moderation: moderation, l: l}
+}
+
+func (s *commentService) Create(ctx context.Context, c domain.Comment) (int64, error) {
+	// 只能评论已经发表的文章
+	_, err := s.artRepo.GetPublishedById(ctx, c.ArtId)
+	if err != nil {
+		return 0, err
+	}
+	res, err := s.moderation.Check(ctx, c.Content)
+	if err != nil {
+		return 0, err
+	}
+	// 评论没有人工审核，只拦截违禁的内容
+	if res.Level == domain.ModerationBlock {
+		return 0, &SensitiveWordsError{Words: res.Words}
+	}
+	c.RootId = 0
+	if c.ParentId > 0 {
+		parent, err := s.repo.GetById(ctx, c.ParentId)
+		if err != nil {
+			return 0, err
+		}
+		if parent.ArtId != c.ArtId {
+			return 0, ErrCommentNotFound
+		}
+		// 回复的回复也挂在根评论下面
+		c.RootId = parent.RootId
+		if parent.IsRoot() {
+			c.RootId = parent.Id
+		}
+	}
+	return s.repo.Create(ctx, c)
+}
+
+func (s *commentService) Delete(ctx context.Context, id int64, uid int64) error {
+	c, err := s.repo.GetById(ctx, id)
+	if err != nil {
+		return err
+	}
+	if c.Commentator.Id != uid {
+		// 不是自己的评论，那就要是文章的作者
+		art, err := s.artRepo.GetById(ctx, c.ArtId)
+		if err != nil && !errors.Is(err, ErrArticleNotFound) {
+			return err
+		}
+		if err != nil || art.Author.Id != uid {
+			s.l.Error("非法删除评论，既不是评论者也不是文章作者",
+				logger.Int64("uid", uid), logger.Int64("comment_id", id))
+			return ErrCommentNotFound
+		}
+	}
+	return s.repo.Delete(ctx, c)
+}
+
+// ListRoots 不需要登录就能查看，所以要确认文章还是已发表的
+func (s *commentService) ListRoots(ctx context.Context, artId int64, minId int64, limit int) ([]domain.Comment, error) {
+	_, err := s.artRepo.GetPublishedById(ctx, artId)
+	if err != nil {
+		return nil, err
+	}
+	return s.repo.FindRoots(ctx, artId, minId, limit)
+}
+
+func (s *commentService) ListReplies(ctx context.Context, rootId int64, maxId int64, limit int) ([]domain.Comment, error) {
+	root, err := s.repo.GetById(ctx, rootId)
+	if err != nil {
+		return nil, err
+	}
+	_, err = s.artRepo.GetPublishedById(ctx, root.ArtId)
+	if err != nil {
+		return nil, err
+	}
+	return s.repo.FindReplies(ctx, rootId, maxId, limit)
+}
+
+// Count 和 ListRoots 一样不需要登录，没有发表的文章不返回评论数
+func (s *commentService) Count(ctx context.Context, artId int64) (int64, error) {
+	_, err := s.artRepo.GetPublishedById(ctx, artId)
+	if err != nil {
+		return 0, err
+	}
+	return s.repo.GetCount(ctx, artId)
+}
@@ -0,0 +1,337 @@
+package service
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+	"testing"
+	"webook/webook/internal/domain"
+	"webook/webook/internal/repository"
+	repomocks "webook/webook/internal/repository/mocks"
+	svcmocks "webook/webook/internal/service/mocks"
+	"webook/webook/pkg/logger"
+	"webook/webook/pkg/sensitive"
+)
+
+func Test_commentService_Create(t *testing.T) {
+	testCases := []struct {
+		name    string
+		mock    func(ctrl *gomock.Controller) (repository.CommentRepository, repository.ArticleRepository)
+		c       domain.Comment
+		wantId  int64
+		wantErr error
+	}{
+		{
+			name: "根评论",
+			mock: func(ctrl *gomock.Controller) (repository.CommentRepository, repository.ArticleRepository) {
+				artRepo := repomocks.NewMockArticleRepository(ctrl)
+				artRepo.EXPECT().GetPublishedById(gomock.Any(), int64(1)).Return(domain.Article{Id: 1}, nil)
+				repo := repomocks.NewMockCommentRepository(ctrl)
+				repo.EXPECT().Create(gomock.Any(), domain.Comment{
+					ArtId:       1,
+					Commentator: domain.Author{Id: 123},
+					Content:     "写得好",
+				}).Return(int64(10), nil)
+				return repo, artRepo
+			},
+			c: domain.Comment{
+				ArtId:       1,
+				Commentator: domain.Author{Id: 123},
+				Content:     "写得好",
+			},
+			wantId: 10,
+		},
+		{
+			name: "回复根评论",
+			mock: func(ctrl *gomock.Controller) (repository.CommentRepository, repository.ArticleRepository) {
+				artRepo := repomocks.NewMockArticleRepository(ctrl)
+				artRepo.EXPECT().GetPublishedById(gomock.Any(), int64(1)).Return(domain.Article{Id: 1}, nil)
+				repo := repomocks.NewMockCommentRepository(ctrl)
+				repo.EXPECT().GetById(gomock.Any(), int64(10)).Return(domain.Comment{Id: 10, ArtId: 1}, nil)
+				repo.EXPECT().Create(gomock.Any(), domain.Comment{
+					ArtId:       1,
+					Commentator: domain.Author{Id: 123},
+					Content:     "同意",
+					RootId:      10,
+					ParentId:    10,
+				}).Return(int64(11), nil)
+				return repo, artRepo
+			},
+			c: domain.Comment{
+				ArtId:       1,
+				Commentator: domain.Author{Id: 123},
+				Content:     "同意",
+				ParentId:    10,
+			},
+			wantId: 11,
+		},
+		{
+			name: "回复的回复，挂在根评论下面",
+			mock: func(ctrl *gomock.Controller) (repository.CommentRepository, repository.ArticleRepository) {
+				artRepo := repomocks.NewMockArticleRepository(ctrl)
+				artRepo.EXPECT().GetPublishedById(gomock.Any(), int64(1)).Return(domain.Article{Id: 1}, nil)
+				repo := repomocks.NewMockCommentRepository(ctrl)
+				repo.EXPECT().GetById(gomock.Any(), int64(11)).Return(domain.Comment{
+					Id: 11, ArtId: 1, RootId: 10, ParentId: 10,
+				}, nil)
+				repo.EXPECT().Create(gomock.Any(), domain.Comment{
+					ArtId:       1,
+					Commentator: domain.Author{Id: 123},
+					Content:     "不同意",
+					RootId:      10,
+					ParentId:    11,
+				}).Return(int64(12), nil)
+				return repo, artRepo
+			},
+			c: domain.Comment{
+				ArtId:       1,
+				Commentator: domain.Author{Id: 123},
+				Content:     "不同意",
+				ParentId:    11,
+			},
+			wantId: 12,
+		},
+		{
+			name: "回复别的文章下面的评论",
+			mock: func(ctrl *gomock.Controller) (repository.CommentRepository, repository.ArticleRepository) {
+				artRepo := repomocks.NewMockArticleRepository(ctrl)
+				artRepo.EXPECT().GetPublishedById(gomock.Any(), int64(1)).Return(domain.Article{Id: 1}, nil)
+				repo := repomocks.NewMockCommentRepository(ctrl)
+				repo.EXPECT().GetById(gomock.Any(), int64(10)).Return(domain.Comment{Id: 10, ArtId: 2}, nil)
+				return repo, artRepo
+			},
+			c: domain.Comment{
+				ArtId:       1,
+				Commentator: domain.Author{Id: 123},
+				Content:     "同意",
+				ParentId:    10,
+			},
+			wantErr: ErrCommentNotFound,
+		},
+		{
+			name: "文章没有发表",
+			mock: func(ctrl *gomock.Controller) (repository.CommentRepository, repository.ArticleRepository) {
+				artRepo := repomocks.NewMockArticleRepository(ctrl)
+				artRepo.EXPECT().GetPublishedById(gomock.Any(), int64(1)).Return(domain.Article{}, ErrArticleNotFound)
+				return repomocks.NewMockCommentRepository(ctrl), artRepo
+			},
+			c: domain.Comment{
+				ArtId:       1,
+				Commentator: domain.Author{Id: 123},
+				Content:     "写得好",
+			},
+			wantErr: ErrArticleNotFound,
+		},
+		{
+			name: "包含违禁词",
+			mock: func(ctrl *gomock.Controller) (repository.CommentRepository, repository.ArticleRepository) {
+				artRepo := repomocks.NewMockArticleRepository(ctrl)
+				artRepo.EXPECT().GetPublishedById(gomock.Any(), int64(1)).Return(domain.Article{Id: 1}, nil)
+				return repomocks.NewMockCommentRepository(ctrl), artRepo
+			},
+			c: domain.Comment{
+				ArtId:       1,
+				Commentator: domain.Author{Id: 123},
+				Content:     "这是违 禁的内容",
+			},
+			wantErr: &SensitiveWordsError{Words: []string{"违禁"}},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			repo, artRepo := tc.mock(ctrl)
+			moderation := NewDictModerationService(sensitive.NewDictionary([]string{"违禁"}, nil),
+				domain.ModerationConfig{})
+			svc := NewCommentService(repo, artRepo, moderation, logger.NewNoOpLogger())
+			id, err := svc.Create(context.Background(), tc.c)
+			assert.Equal(t, tc.wantErr, err)
+			assert.Equal(t, tc.wantId, id)
+		})
+	}
+}
+
+func Test_commentService_Delete(t *testing.T) {
+	testCases := []struct {
+		name    string
+		mock    func(ctrl *gomock.Controller) (repository.CommentRepository, repository.ArticleRepository)
+		uid     int64
+		wantErr error
+	}{
+		{
+			name: "删除自己的评论",
+			mock: func(ctrl *gomock.Controller) (repository.CommentRepository, repository.ArticleRepository) {
+				c := domain.Comment{Id: 10, ArtId: 1, Commentator: domain.Author{Id: 123}}
+				repo := repomocks.NewMockCommentRepository(ctrl)
+				repo.EXPECT().GetById(gomock.Any(), int64(10)).Return(c, nil)
+				repo.EXPECT().Delete(gomock.Any(), c).Return(nil)
+				return repo, repomocks.NewMockArticleRepository(ctrl)
+			},
+			uid: 123,
+		},
+		{
+			name: "文章作者删除别人的评论",
+			mock: func(ctrl *gomock.Controller) (repository.CommentRepository, repository.ArticleRepository) {
+				c := domain.Comment{Id: 10, ArtId: 1, Commentator: domain.Author{Id: 456}}
+				repo := repomocks.NewMockCommentRepository(ctrl)
+				repo.EXPECT().GetById(gomock.Any(), int64(10)).Return(c, nil)
+				repo.EXPECT().Delete(gomock.Any(), c).Return(nil)
+				artRepo := repomocks.NewMockArticleRepository(ctrl)
+				artRepo.EXPECT().GetById(gomock.Any(), int64(1)).Return(domain.Article{
+					Id: 1, Author: domain.Author{Id: 123},
+				}, nil)
+				return repo, artRepo
+			},
+			uid: 123,
+		},
+		{
+			name: "既不是评论者也不是文章作者",
+			mock: func(ctrl *gomock.Controller) (repository.CommentRepository, repository.ArticleRepository) {
+				repo := repomocks.NewMockCommentRepository(ctrl)
+				repo.EXPECT().GetById(gomock.Any(), int64(10)).Return(domain.Comment{
+					Id: 10, ArtId: 1, Commentator: domain.Author{Id: 456},
+				}, nil)
+				artRepo := repomocks.NewMockArticleRepository(ctrl)
+				artRepo.EXPECT().GetById(gomock.Any(), int64(1)).Return(domain.Article{
+					Id: 1, Author: domain.Author{Id: 789},
+				}, nil)
+				return repo, artRepo
+			},
+			uid:     123,
+			wantErr: ErrCommentNotFound,
+		},
+		{
+			name: "文章已经删除了，只有评论者自己能删",
+			mock: func(ctrl *gomock.Controller) (repository.CommentRepository, repository.ArticleRepository) {
+				repo := repomocks.NewMockCommentRepository(ctrl)
+				repo.EXPECT().GetById(gomock.Any(), int64(10)).Return(domain.Comment{
+					Id: 10, ArtId: 1, Commentator: domain.Author{Id: 456},
+				}, nil)
+				artRepo := repomocks.NewMockArticleRepository(ctrl)
+				artRepo.EXPECT().GetById(gomock.Any(), int64(1)).Return(domain.Article{}, ErrArticleNotFound)
+				return repo, artRepo
+			},
+			uid:     123,
+			wantErr: ErrCommentNotFound,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			repo, artRepo := tc.mock(ctrl)
+			svc := NewCommentService(repo, artRepo, svcmocks.NewMockModerationService(ctrl), logger.NewNoOpLogger())
+			err := svc.Delete(context.Background(), 10, tc.uid)
+			assert.Equal(t, tc.wantErr, err)
+		})
+	}
+}
+
+func Test_commentService_ListReplies(t *testing.T) {
+	testCases := []struct {
+		name    string
+		mock    func(ctrl *gomock.Controller) (repository.CommentRepository, repository.ArticleRepository)
+		wantRes []domain.Comment
+		wantErr error
+	}{
+		{
+			name: "文章已发表",
+			mock: func(ctrl *gomock.Controller) (repository.CommentRepository, repository.ArticleRepository) {
+				repo := repomocks.NewMockCommentRepository(ctrl)
+				repo.EXPECT().GetById(gomock.Any(), int64(10)).Return(domain.Comment{Id: 10, ArtId: 1}, nil)
+				repo.EXPECT().FindReplies(gomock.Any(), int64(10), int64(0), 10).
+					Return([]domain.Comment{{Id: 11, ArtId: 1, RootId: 10}}, nil)
+				artRepo := repomocks.NewMockArticleRepository(ctrl)
+				artRepo.EXPECT().GetPublishedById(gomock.Any(), int64(1)).Return(domain.Article{Id: 1}, nil)
+				return repo, artRepo
+			},
+			wantRes: []domain.Comment{{Id: 11, ArtId: 1, RootId: 10}},
+		},
+		{
+			name: "文章已经撤回了",
+			mock: func(ctrl *gomock.Controller) (repository.CommentRepository, repository.ArticleRepository) {
+				repo := repomocks.NewMockCommentRepository(ctrl)
+				repo.EXPECT().GetById(gomock.Any(), int64(10)).Return(domain.Comment{Id: 10, ArtId: 1}, nil)
+				artRepo := repomocks.NewMockArticleRepository(ctrl)
+				artRepo.EXPECT().GetPublishedById(gomock.Any(), int64(1)).Return(domain.Article{}, ErrArticleNotFound)
+				return repo, artRepo
+			},
+			wantErr: ErrArticleNotFound,
+		},
+		{
+			name: "根评论不存在",
+			mock: func(ctrl *gomock.Controller) (repository.CommentRepository, repository.ArticleRepository) {
+				repo := repomocks.NewMockCommentRepository(ctrl)
+				repo.EXPECT().GetById(gomock.Any(), int64(10)).Return(domain.Comment{}, ErrCommentNotFound)
+				return repo, repomocks.NewMockArticleRepository(ctrl)
+			},
+			wantErr: ErrCommentNotFound,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			repo, artRepo := tc.mock(ctrl)
+			svc := NewCommentService(repo, artRepo, svcmocks.NewMockModerationService(ctrl), logger.NewNoOpLogger())
+			res, err := svc.ListReplies(context.Background(), 10, 0, 10)
+			assert.Equal(t, tc.wantErr, err)
+			assert.Equal(t, tc.wantRes, res)
+		})
+	}
+}
+
+func Test_commentService_ListRoots(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	// 撤回之后不需要登录的接口也看不到评论
+	artRepo := repomocks.NewMockArticleRepository(ctrl)
+	artRepo.EXPECT().GetPublishedById(gomock.Any(), int64(1)).Return(domain.Article{}, ErrArticleNotFound)
+	svc := NewCommentService(repomocks.NewMockCommentRepository(ctrl), artRepo,
+		svcmocks.NewMockModerationService(ctrl), logger.NewNoOpLogger())
+	res, err := svc.ListRoots(context.Background(), 1, 0, 10)
+	assert.Equal(t, ErrArticleNotFound, err)
+	assert.Nil(t, res)
+}
+
+func Test_commentService_Count(t *testing.T) {
+	testCases := []struct {
+		name    string
+		mock    func(ctrl *gomock.Controller) (repository.CommentRepository, repository.ArticleRepository)
+		wantCnt int64
+		wantErr error
+	}{
+		{
+			name: "已发表的文章",
+			mock: func(ctrl *gomock.Controller) (repository.CommentRepository, repository.ArticleRepository) {
+				artRepo := repomocks.NewMockArticleRepository(ctrl)
+				artRepo.EXPECT().GetPublishedById(gomock.Any(), int64(1)).Return(domain.Article{Id: 1}, nil)
+				repo := repomocks.NewMockCommentRepository(ctrl)
+				repo.EXPECT().GetCount(gomock.Any(), int64(1)).Return(int64(12), nil)
+				return repo, artRepo
+			},
+			wantCnt: 12,
+		},
+		{
+			name: "没有发表的文章，不返回评论数",
+			mock: func(ctrl *gomock.Controller) (repository.CommentRepository, repository.ArticleRepository) {
+				artRepo := repomocks.NewMockArticleRepository(ctrl)
+				artRepo.EXPECT().GetPublishedById(gomock.Any(), int64(1)).Return(domain.Article{}, ErrArticleNotFound)
+				return repomocks.NewMockCommentRepository(ctrl), artRepo
+			},
+			wantErr: ErrArticleNotFound,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			repo, artRepo := tc.mock(ctrl)
+			svc := NewCommentService(repo, artRepo, svcmocks.NewMockModerationService(ctrl), logger.NewNoOpLogger())
+			cnt, err := svc.Count(context.Background(), 1)
+			assert.Equal(t, tc.wantErr, err)
+			assert.Equal(t, tc.wantCnt, cnt)
+		})
+	}
+}
@@ -0,0 +1,90 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+	"webook/webook/internal/domain"
+	"webook/webook/internal/repository"
+	"webook/webook/pkg/logger"
+)
+
+var ErrNoCronJob = repository.ErrNoCronJob
+
+type cronJobService struct {
+	repo repository.CronJobRepository
+	l    logger.Logger
+	// 多久续约一次
+	refreshInterval time.Duration
+	// 运行中的任务超过这个时间没有续约，就认为抢到的实例已经挂了
+	staleTimeout time.Duration
+}
+
+func NewCronJobService(repo repository.CronJobRepository, l logger.Logger) CronJobService {
+	return &cronJobService{
+		repo:            repo,
+		l:               l,
+		refreshInterval: time.Second * 10,
+		staleTimeout:    time.Minute,
+	}
+}
+
+func (s *cronJobService) Preempt(ctx context.Context) (domain.CronJob, error) {
+	j, err := s.repo.Preempt(ctx, time.Now().Add(-s.staleTimeout))
+	if err != nil {
+		return domain.CronJob{}, err
+	}
+	jobCtx, cancel := context.WithCancel(context.Background())
+	stop := make(chan struct{})
+	go s.refresh(j, cancel, stop)
+	j.Ctx = jobCtx
+	j.CancelFunc = func() {
+		close(stop)
+		cancel()
+		s.release(j)
+	}
+	return j, nil
+}
+
+// refresh 定时续约，任务被别人抢走了就取消 context
+func (s *cronJobService) refresh(j domain.CronJob, cancel context.CancelFunc, stop chan struct{}) {
+	ticker := time.NewTicker(s.refreshInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			ctx, cancelRefresh := context.WithTimeout(context.Background(), time.Second)
+			err := s.repo.UpdateUtime(ctx, j.Id, j.Version)
+			cancelRefresh()
+			if errors.Is(err, repository.ErrCronJobNotHold) {
+				s.l.Error("任务已经被别的实例抢走了", logger.String("name", j.Name))
+				cancel()
+				return
+			}
+			if err != nil {
+				// 偶尔失败一次没关系，超过 staleTimeout 才会被别人抢走
+				s.l.Error("续约任务失败", logger.String("name", j.Name), logger.Error(err))
+			}
+		case <-stop:
+			return
+		}
+	}
+}
+
+// release 释放任务，并且设置下一次运行的时间
+func (s *cronJobService) release(j domain.CronJob) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	next, err := j.NextTimeAfter(time.Now())
+	if err != nil {
+		// cron 表达式有问题，下一次也运行不了，暂停等人来修
+		s.l.Error("任务的 cron 表达式有误，暂停任务", logger.String("name", j.Name), logger.Error(err))
+		err = s.repo.Pause(ctx, j.Id, j.Version)
+	} else {
+		err = s.repo.Release(ctx, j.Id, j.Version, next)
+	}
+	if err != nil {
+		// 没释放掉也没关系，超时之后会被重新抢占
+		s.l.Error("释放任务失败", logger.String("name", j.Name), logger.Error(err))
+	}
+}
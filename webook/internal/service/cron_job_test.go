@@ -0,0 +1,102 @@
+package service
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"testing"
+	"time"
+	"webook/webook/internal/domain"
+	"webook/webook/internal/repository"
+	repomocks "webook/webook/internal/repository/mocks"
+	"webook/webook/pkg/logger"
+)
+
+func TestCronJobService_Preempt(t *testing.T) {
+	testCases := []struct {
+		name string
+		mock func(ctrl *gomock.Controller) repository.CronJobRepository
+		// 抢到之后等多久再释放
+		wait time.Duration
+
+		wantErr      error
+		wantCanceled bool
+	}{
+		{
+			name: "运行完释放，设置下一次的时间",
+			mock: func(ctrl *gomock.Controller) repository.CronJobRepository {
+				repo := repomocks.NewMockCronJobRepository(ctrl)
+				repo.EXPECT().Preempt(gomock.Any(), gomock.Any()).Return(domain.CronJob{
+					Id: 1, Name: "ranking", Expression: "*/3 * * * *", Version: 2,
+				}, nil)
+				repo.EXPECT().Release(gomock.Any(), int64(1), int64(2), gomock.Any()).
+					DoAndReturn(func(ctx context.Context, id, version int64, nextTime time.Time) error {
+						assert.True(t, nextTime.After(time.Now()))
+						return nil
+					})
+				return repo
+			},
+		},
+		{
+			name: "表达式有误，暂停任务",
+			mock: func(ctrl *gomock.Controller) repository.CronJobRepository {
+				repo := repomocks.NewMockCronJobRepository(ctrl)
+				repo.EXPECT().Preempt(gomock.Any(), gomock.Any()).Return(domain.CronJob{
+					Id: 1, Name: "ranking", Expression: "abc", Version: 2,
+				}, nil)
+				repo.EXPECT().Pause(gomock.Any(), int64(1), int64(2)).Return(nil)
+				return repo
+			},
+		},
+		{
+			name: "续约的时候发现被别人抢走了",
+			mock: func(ctrl *gomock.Controller) repository.CronJobRepository {
+				repo := repomocks.NewMockCronJobRepository(ctrl)
+				repo.EXPECT().Preempt(gomock.Any(), gomock.Any()).Return(domain.CronJob{
+					Id: 1, Name: "ranking", Expression: "*/3 * * * *", Version: 2,
+				}, nil)
+				repo.EXPECT().UpdateUtime(gomock.Any(), int64(1), int64(2)).
+					Return(repository.ErrCronJobNotHold)
+				repo.EXPECT().Release(gomock.Any(), int64(1), int64(2), gomock.Any()).
+					Return(repository.ErrCronJobNotHold)
+				return repo
+			},
+			wait:         time.Millisecond * 50,
+			wantCanceled: true,
+		},
+		{
+			name: "没有任务",
+			mock: func(ctrl *gomock.Controller) repository.CronJobRepository {
+				repo := repomocks.NewMockCronJobRepository(ctrl)
+				repo.EXPECT().Preempt(gomock.Any(), gomock.Any()).
+					Return(domain.CronJob{}, repository.ErrNoCronJob)
+				return repo
+			},
+			wantErr: ErrNoCronJob,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			svc := NewCronJobService(tc.mock(ctrl), logger.NewNoOpLogger()).(*cronJobService)
+			svc.refreshInterval = time.Millisecond * 10
+			j, err := svc.Preempt(context.Background())
+			assert.Equal(t, tc.wantErr, err)
+			if err != nil {
+				return
+			}
+			if tc.wantCanceled {
+				select {
+				case <-j.Ctx.Done():
+				case <-time.After(time.Second):
+					t.Fatal("任务被抢走之后 context 没有取消")
+				}
+			}
+			time.Sleep(tc.wait)
+			j.CancelFunc()
+			require.Error(t, j.Ctx.Err())
+		})
+	}
+}
@@ -0,0 +1,126 @@
+package service
+
+import (
+	"context"
+	"sort"
+	"time"
+	"webook/webook/internal/domain"
+	"webook/webook/internal/repository"
+	"webook/webook/pkg/logger"
+)
+
+var _ ArticleListener = (*feedService)(nil)
+
+// feedService 推拉结合的关注流
+// 粉丝数不超过阈值的作者发表文章的时候推送到每个粉丝的收件箱，
+// 粉丝多的作者推送的代价太大，读的时候再去线上库拉
+type feedService struct {
+	followRepo repository.FollowRepository
+	feedRepo   repository.FeedRepository
+	artRepo    repository.ArticleRepository
+	cfg        domain.FeedConfig
+	l          logger.Logger
+}
+
+func NewFeedService(followRepo repository.FollowRepository, feedRepo repository.FeedRepository,
+	artRepo repository.ArticleRepository, cfg domain.FeedConfig, l logger.Logger) FeedService {
+	return &feedService{followRepo: followRepo, feedRepo: feedRepo, artRepo: artRepo, cfg: cfg, l: l}
+}
+
+// OnPublished 粉丝少的作者推送给所有粉丝
+// 推送之后才关注的人看不到之前的文章
+// 收件箱里记录的是线上库的发表时间，和拉取的文章用同一个字段分页
+func (s *feedService) OnPublished(ctx context.Context, art domain.Article) {
+	if s.cfg.PushThreshold <= 0 {
+		return
+	}
+	aid := art.Author.Id
+	statics, err := s.followRepo.GetStatics(ctx, aid)
+	if err != nil {
+		s.l.Error("推送关注流查询粉丝数失败", logger.Int64("art_id", art.Id), logger.Error(err))
+		return
+	}
+	if !s.cfg.UsePush(statics.Followers) {
+		return
+	}
+	pub, err := s.artRepo.GetPublishedById(ctx, art.Id)
+	if err != nil {
+		// 可能刚发表就撤回了
+		s.l.Error("推送关注流查询文章失败", logger.Int64("art_id", art.Id), logger.Error(err))
+		return
+	}
+	uids, err := s.followRepo.FollowerIds(ctx, aid)
+	if err != nil {
+		s.l.Error("推送关注流查询粉丝失败", logger.Int64("art_id", art.Id), logger.Error(err))
+		return
+	}
+	err = s.feedRepo.AddInbox(ctx, art.Id, uids, pub.Ctime)
+	if err != nil {
+		s.l.Error("推送关注流失败", logger.Int64("art_id", art.Id), logger.Error(err))
+	}
+}
+
+// OnWithdrawn 收件箱里面的文章读的时候会过滤掉没有发表的，这里不需要处理
+func (s *feedService) OnWithdrawn(ctx context.Context, art domain.Article) {
+}
+
+// Following 拉取粉丝多的作者的文章，再和收件箱里面的合并，两边都按照发表时间分页
+// 收件箱里面会过滤掉已经取消关注的作者，所以返回的数量可能少于 limit
+// 只看最近关注的 MaxFollowees 个作者，更早关注的作者推送过来的文章也会被过滤掉
+func (s *feedService) Following(ctx context.Context, uid int64, before time.Time, limit int) ([]domain.Article, error) {
+	followees, err := s.followRepo.FolloweeIds(ctx, uid, s.cfg.MaxFollowees)
+	if err != nil || len(followees) == 0 {
+		return nil, err
+	}
+	if s.cfg.PushThreshold <= 0 {
+		return s.artRepo.ListPubByAuthors(ctx, followees, before, limit)
+	}
+	statics, err := s.followRepo.GetStaticsByUids(ctx, followees)
+	if err != nil {
+		return nil, err
+	}
+	followed := make(map[int64]struct{}, len(followees))
+	pullIds := make([]int64, 0, len(followees))
+	for _, id := range followees {
+		followed[id] = struct{}{}
+		if !s.cfg.UsePush(statics[id].Followers) {
+			pullIds = append(pullIds, id)
+		}
+	}
+	arts, err := s.artRepo.ListPubByAuthors(ctx, pullIds, before, limit)
+	if err != nil {
+		return nil, err
+	}
+	artIds, err := s.feedRepo.ListInbox(ctx, uid, before, limit)
+	if err != nil {
+		return nil, err
+	}
+	pushed, err := s.artRepo.ListPubByIds(ctx, artIds)
+	if err != nil {
+		return nil, err
+	}
+	// 作者的粉丝数跨过阈值之后，同一篇文章可能既被推送过又被拉到了
+	seen := make(map[int64]struct{}, len(arts))
+	for _, art := range arts {
+		seen[art.Id] = struct{}{}
+	}
+	for _, art := range pushed {
+		_, ok := followed[art.Author.Id]
+		_, dup := seen[art.Id]
+		if !ok || dup || !art.Ctime.Before(before) {
+			continue
+		}
+		seen[art.Id] = struct{}{}
+		arts = append(arts, art)
+	}
+	sort.Slice(arts, func(i, j int) bool {
+		if arts[i].Ctime.Equal(arts[j].Ctime) {
+			return arts[i].Id > arts[j].Id
+		}
+		return arts[i].Ctime.After(arts[j].Ctime)
+	})
+	if len(arts) > limit {
+		arts = arts[:limit]
+	}
+	return arts, nil
+}
@@ -0,0 +1,162 @@
+package service
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+	"testing"
+	"time"
+	"webook/webook/internal/domain"
+	repomocks "webook/webook/internal/repository/mocks"
+	"webook/webook/pkg/logger"
+)
+
+func TestFeedService_OnPublished(t *testing.T) {
+	ptime := time.UnixMilli(time.Now().Add(-time.Hour).UnixMilli())
+	testCases := []struct {
+		name string
+		mock func(ctrl *gomock.Controller) (*repomocks.MockFollowRepository,
+			*repomocks.MockFeedRepository, *repomocks.MockArticleRepository)
+		cfg domain.FeedConfig
+	}{
+		{
+			name: "粉丝少，推送给所有粉丝",
+			mock: func(ctrl *gomock.Controller) (*repomocks.MockFollowRepository,
+				*repomocks.MockFeedRepository, *repomocks.MockArticleRepository) {
+				followRepo := repomocks.NewMockFollowRepository(ctrl)
+				feedRepo := repomocks.NewMockFeedRepository(ctrl)
+				followRepo.EXPECT().GetStatics(gomock.Any(), int64(123)).
+					Return(domain.FollowStatics{Uid: 123, Followers: 2}, nil)
+				artRepo := repomocks.NewMockArticleRepository(ctrl)
+				// 收件箱里记录线上库的发表时间
+				artRepo.EXPECT().GetPublishedById(gomock.Any(), int64(10)).
+					Return(domain.Article{Id: 10, Ctime: ptime}, nil)
+				followRepo.EXPECT().FollowerIds(gomock.Any(), int64(123)).Return([]int64{1, 2}, nil)
+				feedRepo.EXPECT().AddInbox(gomock.Any(), int64(10), []int64{1, 2}, ptime).Return(nil)
+				return followRepo, feedRepo, artRepo
+			},
+			cfg: domain.FeedConfig{PushThreshold: 100},
+		},
+		{
+			name: "粉丝多，读的时候再拉",
+			mock: func(ctrl *gomock.Controller) (*repomocks.MockFollowRepository,
+				*repomocks.MockFeedRepository, *repomocks.MockArticleRepository) {
+				followRepo := repomocks.NewMockFollowRepository(ctrl)
+				followRepo.EXPECT().GetStatics(gomock.Any(), int64(123)).
+					Return(domain.FollowStatics{Uid: 123, Followers: 101}, nil)
+				return followRepo, repomocks.NewMockFeedRepository(ctrl), repomocks.NewMockArticleRepository(ctrl)
+			},
+			cfg: domain.FeedConfig{PushThreshold: 100},
+		},
+		{
+			name: "没有开启推送",
+			mock: func(ctrl *gomock.Controller) (*repomocks.MockFollowRepository,
+				*repomocks.MockFeedRepository, *repomocks.MockArticleRepository) {
+				return repomocks.NewMockFollowRepository(ctrl), repomocks.NewMockFeedRepository(ctrl),
+					repomocks.NewMockArticleRepository(ctrl)
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			followRepo, feedRepo, artRepo := tc.mock(ctrl)
+			svc := NewFeedService(followRepo, feedRepo, artRepo, tc.cfg, logger.NewNoOpLogger())
+			svc.OnPublished(context.Background(), domain.Article{Id: 10, Author: domain.Author{Id: 123}})
+		})
+	}
+}
+
+func TestFeedService_Following(t *testing.T) {
+	now := time.UnixMilli(time.Now().UnixMilli())
+	before := now.Add(time.Minute)
+	testCases := []struct {
+		name string
+		mock func(ctrl *gomock.Controller) (*repomocks.MockFollowRepository,
+			*repomocks.MockFeedRepository, *repomocks.MockArticleRepository)
+		cfg   domain.FeedConfig
+		limit int
+
+		wantIds []int64
+		wantErr error
+	}{
+		{
+			name: "只拉",
+			mock: func(ctrl *gomock.Controller) (*repomocks.MockFollowRepository,
+				*repomocks.MockFeedRepository, *repomocks.MockArticleRepository) {
+				followRepo := repomocks.NewMockFollowRepository(ctrl)
+				artRepo := repomocks.NewMockArticleRepository(ctrl)
+				followRepo.EXPECT().FolloweeIds(gomock.Any(), int64(1), 1000).Return([]int64{2, 3}, nil)
+				artRepo.EXPECT().ListPubByAuthors(gomock.Any(), []int64{2, 3}, before, 10).
+					Return([]domain.Article{{Id: 5, Ctime: now}, {Id: 4, Ctime: now.Add(-time.Second)}}, nil)
+				return followRepo, repomocks.NewMockFeedRepository(ctrl), artRepo
+			},
+			cfg:     domain.FeedConfig{MaxFollowees: 1000},
+			limit:   10,
+			wantIds: []int64{5, 4},
+		},
+		{
+			name: "推拉结合，去掉重复的和取消关注的",
+			mock: func(ctrl *gomock.Controller) (*repomocks.MockFollowRepository,
+				*repomocks.MockFeedRepository, *repomocks.MockArticleRepository) {
+				followRepo := repomocks.NewMockFollowRepository(ctrl)
+				feedRepo := repomocks.NewMockFeedRepository(ctrl)
+				artRepo := repomocks.NewMockArticleRepository(ctrl)
+				followRepo.EXPECT().FolloweeIds(gomock.Any(), int64(1), 1000).Return([]int64{2, 3}, nil)
+				// 2 是大 V，3 的粉丝少
+				followRepo.EXPECT().GetStaticsByUids(gomock.Any(), []int64{2, 3}).
+					Return(map[int64]domain.FollowStatics{
+						2: {Uid: 2, Followers: 1000},
+						3: {Uid: 3, Followers: 10},
+					}, nil)
+				artRepo.EXPECT().ListPubByAuthors(gomock.Any(), []int64{2}, before, 3).
+					Return([]domain.Article{
+						{Id: 6, Author: domain.Author{Id: 2}, Ctime: now.Add(-time.Second)},
+						{Id: 1, Author: domain.Author{Id: 2}, Ctime: now.Add(-time.Hour)},
+					}, nil)
+				feedRepo.EXPECT().ListInbox(gomock.Any(), int64(1), before, 3).Return([]int64{7, 6, 8, 3}, nil)
+				artRepo.EXPECT().ListPubByIds(gomock.Any(), []int64{7, 6, 8, 3}).
+					Return([]domain.Article{
+						{Id: 7, Author: domain.Author{Id: 3}, Ctime: now},
+						// 2 变成大 V 之前推送过的
+						{Id: 6, Author: domain.Author{Id: 2}, Ctime: now.Add(-time.Second)},
+						// 已经取消关注了
+						{Id: 8, Author: domain.Author{Id: 4}, Ctime: now.Add(-time.Second * 2)},
+						// 刚刚修改过，还是按照发表时间排在后面
+						{Id: 3, Author: domain.Author{Id: 3}, Ctime: now.Add(-time.Second * 3), Utime: before},
+					}, nil)
+				return followRepo, feedRepo, artRepo
+			},
+			cfg:     domain.FeedConfig{PushThreshold: 100, MaxFollowees: 1000},
+			limit:   3,
+			wantIds: []int64{7, 6, 3},
+		},
+		{
+			name: "没有关注任何人",
+			mock: func(ctrl *gomock.Controller) (*repomocks.MockFollowRepository,
+				*repomocks.MockFeedRepository, *repomocks.MockArticleRepository) {
+				followRepo := repomocks.NewMockFollowRepository(ctrl)
+				followRepo.EXPECT().FolloweeIds(gomock.Any(), int64(1), 1000).Return(nil, nil)
+				return followRepo, repomocks.NewMockFeedRepository(ctrl), repomocks.NewMockArticleRepository(ctrl)
+			},
+			cfg:   domain.FeedConfig{PushThreshold: 100, MaxFollowees: 1000},
+			limit: 10,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			followRepo, feedRepo, artRepo := tc.mock(ctrl)
+			svc := NewFeedService(followRepo, feedRepo, artRepo, tc.cfg, logger.NewNoOpLogger())
+			arts, err := svc.Following(context.Background(), 1, before, tc.limit)
+			assert.Equal(t, tc.wantErr, err)
+			var ids []int64
+			for _, art := range arts {
+				ids = append(ids, art.Id)
+			}
+			assert.Equal(t, tc.wantIds, ids)
+		})
+	}
+}
@@ -0,0 +1,59 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"webook/webook/internal/domain"
+	"webook/webook/internal/repository"
+)
+
+var (
+	ErrFollowDuplicate = repository.ErrFollowDuplicate
+	ErrFollowSelf      = errors.New("不能关注自己")
+	ErrUserNotFound    = repository.ErrUserNotFound
+)
+
+type followService struct {
+	repo     repository.FollowRepository
+	userRepo repository.UserRepository
+}
+
+func NewFollowService(repo repository.FollowRepository, userRepo repository.UserRepository) FollowService {
+	return &followService{repo: repo, userRepo: userRepo}
+}
+
+func (s *followService) Follow(ctx context.Context, follower int64, followee int64) error {
+	if follower == followee {
+		return ErrFollowSelf
+	}
+	// 确认被关注的人是存在的
+	_, err := s.userRepo.FindById(ctx, followee)
+	if err != nil {
+		return err
+	}
+	return s.repo.Follow(ctx, follower, followee)
+}
+
+func (s *followService) CancelFollow(ctx context.Context, follower int64, followee int64) error {
+	return s.repo.CancelFollow(ctx, follower, followee)
+}
+
+func (s *followService) ListFollowers(ctx context.Context, uid int64, offset int, limit int) ([]domain.FollowRelation, error) {
+	return s.repo.ListFollowers(ctx, uid, offset, limit)
+}
+
+func (s *followService) ListFollowees(ctx context.Context, uid int64, offset int, limit int) ([]domain.FollowRelation, error) {
+	return s.repo.ListFollowees(ctx, uid, offset, limit)
+}
+
+func (s *followService) GetStatics(ctx context.Context, uid int64, viewer int64) (domain.FollowStatics, error) {
+	statics, err := s.repo.GetStatics(ctx, uid)
+	if err != nil {
+		return domain.FollowStatics{}, err
+	}
+	if viewer <= 0 || viewer == uid {
+		return statics, nil
+	}
+	statics.Followed, err = s.repo.Followed(ctx, viewer, uid)
+	return statics, err
+}
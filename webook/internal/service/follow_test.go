@@ -0,0 +1,137 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+	"testing"
+	"webook/webook/internal/domain"
+	"webook/webook/internal/repository"
+	repomocks "webook/webook/internal/repository/mocks"
+)
+
+func TestFollowService_Follow(t *testing.T) {
+	testCases := []struct {
+		name     string
+		mock     func(ctrl *gomock.Controller) (repository.FollowRepository, repository.UserRepository)
+		follower int64
+		followee int64
+		wantErr  error
+	}{
+		{
+			name: "关注成功",
+			mock: func(ctrl *gomock.Controller) (repository.FollowRepository, repository.UserRepository) {
+				repo := repomocks.NewMockFollowRepository(ctrl)
+				userRepo := repomocks.NewMockUserRepository(ctrl)
+				userRepo.EXPECT().FindById(gomock.Any(), int64(2)).Return(domain.User{Id: 2}, nil)
+				repo.EXPECT().Follow(gomock.Any(), int64(1), int64(2)).Return(nil)
+				return repo, userRepo
+			},
+			follower: 1,
+			followee: 2,
+		},
+		{
+			name: "不能关注自己",
+			mock: func(ctrl *gomock.Controller) (repository.FollowRepository, repository.UserRepository) {
+				return repomocks.NewMockFollowRepository(ctrl), repomocks.NewMockUserRepository(ctrl)
+			},
+			follower: 1,
+			followee: 1,
+			wantErr:  ErrFollowSelf,
+		},
+		{
+			name: "被关注的人不存在",
+			mock: func(ctrl *gomock.Controller) (repository.FollowRepository, repository.UserRepository) {
+				userRepo := repomocks.NewMockUserRepository(ctrl)
+				userRepo.EXPECT().FindById(gomock.Any(), int64(2)).Return(domain.User{}, repository.ErrUserNotFound)
+				return repomocks.NewMockFollowRepository(ctrl), userRepo
+			},
+			follower: 1,
+			followee: 2,
+			wantErr:  ErrUserNotFound,
+		},
+		{
+			name: "重复关注",
+			mock: func(ctrl *gomock.Controller) (repository.FollowRepository, repository.UserRepository) {
+				repo := repomocks.NewMockFollowRepository(ctrl)
+				userRepo := repomocks.NewMockUserRepository(ctrl)
+				userRepo.EXPECT().FindById(gomock.Any(), int64(2)).Return(domain.User{Id: 2}, nil)
+				repo.EXPECT().Follow(gomock.Any(), int64(1), int64(2)).Return(repository.ErrFollowDuplicate)
+				return repo, userRepo
+			},
+			follower: 1,
+			followee: 2,
+			wantErr:  ErrFollowDuplicate,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			svc := NewFollowService(tc.mock(ctrl))
+			err := svc.Follow(context.Background(), tc.follower, tc.followee)
+			assert.Equal(t, tc.wantErr, err)
+		})
+	}
+}
+
+func TestFollowService_GetStatics(t *testing.T) {
+	testCases := []struct {
+		name   string
+		mock   func(ctrl *gomock.Controller) repository.FollowRepository
+		uid    int64
+		viewer int64
+
+		wantStatics domain.FollowStatics
+		wantErr     error
+	}{
+		{
+			name: "查看别人，带上有没有关注",
+			mock: func(ctrl *gomock.Controller) repository.FollowRepository {
+				repo := repomocks.NewMockFollowRepository(ctrl)
+				repo.EXPECT().GetStatics(gomock.Any(), int64(2)).
+					Return(domain.FollowStatics{Uid: 2, Followers: 10, Followees: 3}, nil)
+				repo.EXPECT().Followed(gomock.Any(), int64(1), int64(2)).Return(true, nil)
+				return repo
+			},
+			uid:         2,
+			viewer:      1,
+			wantStatics: domain.FollowStatics{Uid: 2, Followers: 10, Followees: 3, Followed: true},
+		},
+		{
+			name: "查看自己，不查有没有关注",
+			mock: func(ctrl *gomock.Controller) repository.FollowRepository {
+				repo := repomocks.NewMockFollowRepository(ctrl)
+				repo.EXPECT().GetStatics(gomock.Any(), int64(1)).
+					Return(domain.FollowStatics{Uid: 1, Followers: 10}, nil)
+				return repo
+			},
+			uid:         1,
+			viewer:      1,
+			wantStatics: domain.FollowStatics{Uid: 1, Followers: 10},
+		},
+		{
+			name: "查询计数失败",
+			mock: func(ctrl *gomock.Controller) repository.FollowRepository {
+				repo := repomocks.NewMockFollowRepository(ctrl)
+				repo.EXPECT().GetStatics(gomock.Any(), int64(2)).
+					Return(domain.FollowStatics{}, errors.New("mock db error"))
+				return repo
+			},
+			uid:     2,
+			viewer:  1,
+			wantErr: errors.New("mock db error"),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			svc := NewFollowService(tc.mock(ctrl), repomocks.NewMockUserRepository(ctrl))
+			statics, err := svc.GetStatics(context.Background(), tc.uid, tc.viewer)
+			assert.Equal(t, tc.wantErr, err)
+			assert.Equal(t, tc.wantStatics, statics)
+		})
+	}
+}
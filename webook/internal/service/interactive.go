@@ -0,0 +1,60 @@
+package service
+
+import (
+	"context"
+	"webook/webook/internal/domain"
+	"webook/webook/internal/repository"
+)
+
+var (
+	ErrLikeDuplicate       = repository.ErrLikeDuplicate
+	ErrCollectionDuplicate = repository.ErrCollectionDuplicate
+)
+
+type interactiveService struct {
+	repo repository.InteractiveRepository
+}
+
+func NewInteractiveService(repo repository.InteractiveRepository) InteractiveService {
+	return &interactiveService{repo: repo}
+}
+
+func (i *interactiveService) IncrReadCnt(ctx context.Context, biz string, bizId int64) error {
+	return i.repo.IncrReadCnt(ctx, biz, bizId)
+}
+
+func (i *interactiveService) Like(ctx context.Context, biz string, bizId int64, uid int64) error {
+	return i.repo.IncrLike(ctx, biz, bizId, uid)
+}
+
+func (i *interactiveService) CancelLike(ctx context.Context, biz string, bizId int64, uid int64) error {
+	return i.repo.DecrLike(ctx, biz, bizId, uid)
+}
+
+func (i *interactiveService) Collect(ctx context.Context, biz string, bizId int64, uid int64) error {
+	return i.repo.AddCollectionItem(ctx, biz, bizId, uid)
+}
+
+func (i *interactiveService) CancelCollect(ctx context.Context, biz string, bizId int64, uid int64) error {
+	return i.repo.DeleteCollectionItem(ctx, biz, bizId, uid)
+}
+
+func (i *interactiveService) Get(ctx context.Context, biz string, bizId int64, uid int64) (domain.Interactive, error) {
+	intr, err := i.repo.Get(ctx, biz, bizId)
+	if err != nil {
+		return domain.Interactive{}, err
+	}
+	if uid <= 0 {
+		// 没有登录
+		return intr, nil
+	}
+	intr.Liked, err = i.repo.Liked(ctx, biz, bizId, uid)
+	if err != nil {
+		return domain.Interactive{}, err
+	}
+	intr.Collected, err = i.repo.Collected(ctx, biz, bizId, uid)
+	if err != nil {
+		return domain.Interactive{}, err
+	}
+	return intr, nil
+}
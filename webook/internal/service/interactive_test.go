@@ -0,0 +1,72 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+	"testing"
+	"webook/webook/internal/domain"
+	"webook/webook/internal/repository"
+	repomocks "webook/webook/internal/repository/mocks"
+)
+
+func Test_interactiveService_Get(t *testing.T) {
+	testCases := []struct {
+		name     string
+		mock     func(ctrl *gomock.Controller) repository.InteractiveRepository
+		uid      int64
+		wantIntr domain.Interactive
+		wantErr  error
+	}{
+		{
+			name: "没有登录，只查计数",
+			mock: func(ctrl *gomock.Controller) repository.InteractiveRepository {
+				repo := repomocks.NewMockInteractiveRepository(ctrl)
+				repo.EXPECT().Get(gomock.Any(), "article", int64(1)).Return(domain.Interactive{
+					Biz: "article", BizId: 1, ReadCnt: 10, LikeCnt: 2,
+				}, nil)
+				return repo
+			},
+			wantIntr: domain.Interactive{Biz: "article", BizId: 1, ReadCnt: 10, LikeCnt: 2},
+		},
+		{
+			name: "登录了，带上是否点赞、收藏",
+			mock: func(ctrl *gomock.Controller) repository.InteractiveRepository {
+				repo := repomocks.NewMockInteractiveRepository(ctrl)
+				repo.EXPECT().Get(gomock.Any(), "article", int64(1)).Return(domain.Interactive{
+					Biz: "article", BizId: 1, ReadCnt: 10, LikeCnt: 2,
+				}, nil)
+				repo.EXPECT().Liked(gomock.Any(), "article", int64(1), int64(123)).Return(true, nil)
+				repo.EXPECT().Collected(gomock.Any(), "article", int64(1), int64(123)).Return(false, nil)
+				return repo
+			},
+			uid:      123,
+			wantIntr: domain.Interactive{Biz: "article", BizId: 1, ReadCnt: 10, LikeCnt: 2, Liked: true},
+		},
+		{
+			name: "查询点赞信息失败",
+			mock: func(ctrl *gomock.Controller) repository.InteractiveRepository {
+				repo := repomocks.NewMockInteractiveRepository(ctrl)
+				repo.EXPECT().Get(gomock.Any(), "article", int64(1)).Return(domain.Interactive{
+					Biz: "article", BizId: 1,
+				}, nil)
+				repo.EXPECT().Liked(gomock.Any(), "article", int64(1), int64(123)).
+					Return(false, errors.New("mock db error"))
+				return repo
+			},
+			uid:     123,
+			wantErr: errors.New("mock db error"),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			svc := NewInteractiveService(tc.mock(ctrl))
+			intr, err := svc.Get(context.Background(), "article", 1, tc.uid)
+			assert.Equal(t, tc.wantErr, err)
+			assert.Equal(t, tc.wantIntr, intr)
+		})
+	}
+}
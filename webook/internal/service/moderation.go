@@ -0,0 +1,75 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"webook/webook/internal/domain"
+	"webook/webook/pkg/sensitive"
+)
+
+var (
+	// ErrSensitiveContent 用 errors.Is 判断是不是因为敏感词被拦截了
+	ErrSensitiveContent = errors.New("内容包含敏感词")
+	// ErrPendingReview 文章需要人工审核，已经保存了，但是还没有发表
+	ErrPendingReview = errors.New("文章需要人工审核")
+)
+
+// SensitiveWordsError 内容包含违禁的词，Words 要告诉作者
+type SensitiveWordsError struct {
+	Words []string
+}
+
+func (e *SensitiveWordsError) Error() string {
+	return ErrSensitiveContent.Error() + ": " + strings.Join(e.Words, ", ")
+}
+
+func (e *SensitiveWordsError) Is(target error) bool {
+	return target == ErrSensitiveContent
+}
+
+// dictModerationService 用本地的敏感词词库审核
+type dictModerationService struct {
+	dict *sensitive.Dictionary
+	cfg  domain.ModerationConfig
+}
+
+func NewDictModerationService(dict *sensitive.Dictionary, cfg domain.ModerationConfig) ModerationService {
+	return &dictModerationService{dict: dict, cfg: cfg}
+}
+
+func (s *dictModerationService) Check(ctx context.Context, texts ...string) (domain.ModerationResult, error) {
+	var block, review []string
+	// 分开检查，避免标题的结尾和正文的开头拼出一个词
+	for _, text := range texts {
+		b, r := s.dict.Match(text)
+		block = appendUnique(block, b)
+		review = appendUnique(review, r)
+	}
+	switch {
+	case len(block) > 0:
+		return domain.ModerationResult{Level: domain.ModerationBlock, Words: block}, nil
+	case len(review) > 0 && (s.cfg.Review || s.cfg.ReviewAll):
+		return domain.ModerationResult{Level: domain.ModerationReview, Words: review}, nil
+	case s.cfg.ReviewAll:
+		return domain.ModerationResult{Level: domain.ModerationReview}, nil
+	default:
+		return domain.ModerationResult{Level: domain.ModerationPass}, nil
+	}
+}
+
+func appendUnique(dst []string, src []string) []string {
+	for _, w := range src {
+		found := false
+		for _, d := range dst {
+			if d == w {
+				found = true
+				break
+			}
+		}
+		if !found {
+			dst = append(dst, w)
+		}
+	}
+	return dst
+}
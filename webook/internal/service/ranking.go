@@ -0,0 +1,140 @@
+package service
+
+import (
+	"container/heap"
+	"context"
+	"math"
+	"time"
+	"webook/webook/internal/domain"
+	"webook/webook/internal/repository"
+	"webook/webook/pkg/logger"
+)
+
+var _ ArticleListener = (*BatchRankingService)(nil)
+
+// BatchRankingService 分批查询最近发表的文章，按照点赞数和发表时间打分，取前 N 篇
+type BatchRankingService struct {
+	artRepo  repository.ArticleRepository
+	intrRepo repository.InteractiveRepository
+	repo     repository.RankingRepository
+	l        logger.Logger
+	// 每一批查询多少篇
+	batchSize int
+	// 热榜保留多少篇
+	n int
+	// 只考虑这段时间之内发表的文章，再早的分数已经很低了
+	window time.Duration
+	// 为了测试可以替换
+	scoreFunc func(likeCnt int64, ptime time.Time) float64
+}
+
+func NewBatchRankingService(artRepo repository.ArticleRepository, intrRepo repository.InteractiveRepository,
+	repo repository.RankingRepository, l logger.Logger) RankingService {
+	return &BatchRankingService{
+		artRepo:   artRepo,
+		intrRepo:  intrRepo,
+		repo:      repo,
+		l:         l,
+		batchSize: 100,
+		n:         100,
+		window:    time.Hour * 24 * 7,
+		scoreFunc: hackerNewsScore,
+	}
+}
+
+// hackerNewsScore 点赞数除以时间的衰减，越新的文章衰减越小
+// 参考 Hacker News：score = P / (T + 2) ^ G，T 是发表了多少个小时，G 取 1.5
+func hackerNewsScore(likeCnt int64, ptime time.Time) float64 {
+	hours := time.Since(ptime).Hours()
+	return float64(likeCnt) / math.Pow(hours+2, 1.5)
+}
+
+// OnPublished 新发表的文章等下一次计算热榜
+func (s *BatchRankingService) OnPublished(ctx context.Context, art domain.Article) {}
+
+// OnWithdrawn 撤回和删除的文章马上从热榜里去掉
+func (s *BatchRankingService) OnWithdrawn(ctx context.Context, art domain.Article) {
+	err := s.repo.RemoveFromTopN(ctx, art.Id)
+	if err != nil {
+		s.l.Error("从热榜里删除文章失败", logger.Int64("art_id", art.Id), logger.Error(err))
+	}
+}
+
+func (s *BatchRankingService) GetTopN(ctx context.Context) ([]domain.Article, error) {
+	return s.repo.GetTopN(ctx)
+}
+
+func (s *BatchRankingService) RankTopN(ctx context.Context) error {
+	arts, err := s.topN(ctx)
+	if err != nil {
+		return err
+	}
+	return s.repo.ReplaceTopN(ctx, arts)
+}
+
+func (s *BatchRankingService) topN(ctx context.Context) ([]domain.Article, error) {
+	start := time.Now().Add(-s.window)
+	h := &rankingHeap{}
+	for offset := 0; ; offset += s.batchSize {
+		// 按照发表时间倒序，修改文章不会让它重新变成新文章
+		arts, err := s.artRepo.ListPubSince(ctx, start, offset, s.batchSize)
+		if err != nil {
+			return nil, err
+		}
+		ids := make([]int64, 0, len(arts))
+		for _, art := range arts {
+			ids = append(ids, art.Id)
+		}
+		intrs, err := s.intrRepo.GetByIds(ctx, domain.BizArticle, ids)
+		if err != nil {
+			return nil, err
+		}
+		for _, art := range arts {
+			// 热榜只展示摘要，不需要缓存整篇文章
+			art.Content = art.Abstract()
+			item := rankingItem{art: art, score: s.scoreFunc(intrs[art.Id].LikeCnt, art.Ctime)}
+			if h.Len() < s.n {
+				heap.Push(h, item)
+				continue
+			}
+			if item.score > (*h)[0].score {
+				(*h)[0] = item
+				heap.Fix(h, 0)
+			}
+		}
+		// 不满一批，时间范围之内的都查完了
+		if len(arts) < s.batchSize {
+			break
+		}
+	}
+	// 堆顶是分数最低的，倒着放
+	res := make([]domain.Article, h.Len())
+	for i := len(res) - 1; i >= 0; i-- {
+		res[i] = heap.Pop(h).(rankingItem).art
+	}
+	return res, nil
+}
+
+type rankingItem struct {
+	art   domain.Article
+	score float64
+}
+
+// rankingHeap 小顶堆，堆顶是目前前 N 篇里面分数最低的
+type rankingHeap []rankingItem
+
+func (h rankingHeap) Len() int           { return len(h) }
+func (h rankingHeap) Less(i, j int) bool { return h[i].score < h[j].score }
+func (h rankingHeap) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
+
+func (h *rankingHeap) Push(x any) {
+	*h = append(*h, x.(rankingItem))
+}
+
+func (h *rankingHeap) Pop() any {
+	old := *h
+	n := len(old)
+	x := old[n-1]
+	*h = old[:n-1]
+	return x
+}
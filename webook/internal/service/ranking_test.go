@@ -0,0 +1,96 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+	"testing"
+	"time"
+	"webook/webook/internal/domain"
+	"webook/webook/internal/repository"
+	repomocks "webook/webook/internal/repository/mocks"
+	"webook/webook/pkg/logger"
+)
+
+func TestBatchRankingService_RankTopN(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	now := time.Now()
+	artRepo := repomocks.NewMockArticleRepository(ctrl)
+	intrRepo := repomocks.NewMockInteractiveRepository(ctrl)
+	repo := repomocks.NewMockRankingRepository(ctrl)
+	// 第一批满了，第二批不满一批，不再往下查
+	// 按照发表时间打分，刚修改过的旧文章不会排到前面
+	artRepo.EXPECT().ListPubSince(gomock.Any(), gomock.Any(), 0, 2).Return([]domain.Article{
+		{Id: 5, Ctime: now, Utime: now},
+		{Id: 4, Ctime: now, Utime: now},
+	}, nil)
+	artRepo.EXPECT().ListPubSince(gomock.Any(), gomock.Any(), 2, 2).Return([]domain.Article{
+		{Id: 2, Ctime: now.Add(-time.Hour * 24 * 6), Utime: now},
+	}, nil)
+	intrRepo.EXPECT().GetByIds(gomock.Any(), domain.BizArticle, []int64{5, 4}).
+		Return(map[int64]domain.Interactive{
+			5: {LikeCnt: 1},
+			4: {LikeCnt: 10},
+		}, nil)
+	intrRepo.EXPECT().GetByIds(gomock.Any(), domain.BizArticle, []int64{2}).
+		Return(map[int64]domain.Interactive{
+			2: {LikeCnt: 20},
+		}, nil)
+	repo.EXPECT().ReplaceTopN(gomock.Any(), []domain.Article{
+		{Id: 4, Ctime: now, Utime: now},
+		{Id: 5, Ctime: now, Utime: now},
+	}).Return(nil)
+	svc := &BatchRankingService{
+		artRepo:   artRepo,
+		intrRepo:  intrRepo,
+		repo:      repo,
+		batchSize: 2,
+		n:         2,
+		window:    time.Hour * 24 * 7,
+		scoreFunc: hackerNewsScore,
+	}
+	err := svc.RankTopN(context.Background())
+	assert.NoError(t, err)
+}
+
+func TestBatchRankingService_OnWithdrawn(t *testing.T) {
+	testCases := []struct {
+		name string
+		mock func(ctrl *gomock.Controller) repository.RankingRepository
+	}{
+		{
+			name: "从热榜里删除",
+			mock: func(ctrl *gomock.Controller) repository.RankingRepository {
+				repo := repomocks.NewMockRankingRepository(ctrl)
+				repo.EXPECT().RemoveFromTopN(gomock.Any(), int64(1)).Return(nil)
+				return repo
+			},
+		},
+		{
+			name: "删除失败，只记录日志",
+			mock: func(ctrl *gomock.Controller) repository.RankingRepository {
+				repo := repomocks.NewMockRankingRepository(ctrl)
+				repo.EXPECT().RemoveFromTopN(gomock.Any(), int64(1)).Return(errors.New("mock redis error"))
+				return repo
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			svc := NewBatchRankingService(nil, nil, tc.mock(ctrl), logger.NewNoOpLogger())
+			svc.OnWithdrawn(context.Background(), domain.Article{Id: 1})
+		})
+	}
+}
+
+func TestHackerNewsScore(t *testing.T) {
+	now := time.Now()
+	// 点赞数一样，越新分数越高
+	assert.Greater(t, hackerNewsScore(10, now), hackerNewsScore(10, now.Add(-time.Hour)))
+	// 时间一样，点赞越多分数越高
+	assert.Greater(t, hackerNewsScore(10, now), hackerNewsScore(5, now))
+}
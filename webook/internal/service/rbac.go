@@ -0,0 +1,69 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"webook/webook/internal/domain"
+	"webook/webook/internal/repository"
+	"webook/webook/pkg/logger"
+)
+
+var (
+	ErrRoleNotFound = repository.ErrRoleNotFound
+	ErrRevokeSelf   = errors.New("不能撤销自己的超级管理员")
+)
+
+type rbacService struct {
+	repo     repository.RBACRepository
+	userRepo repository.UserRepository
+	l        logger.Logger
+}
+
+func NewRBACService(repo repository.RBACRepository, userRepo repository.UserRepository, l logger.Logger) RBACService {
+	return &rbacService{repo: repo, userRepo: userRepo, l: l}
+}
+
+func (s *rbacService) HasPermission(ctx context.Context, uid int64, perm domain.Permission) (bool, error) {
+	perms, err := s.repo.GetPermissions(ctx, uid)
+	if err != nil {
+		return false, err
+	}
+	for _, p := range perms {
+		if p == perm {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (s *rbacService) ListRoles(ctx context.Context, uid int64) ([]domain.Role, error) {
+	return s.repo.GetRoles(ctx, uid)
+}
+
+func (s *rbacService) Grant(ctx context.Context, operator int64, uid int64, role string) error {
+	// 确认用户是存在的
+	_, err := s.userRepo.FindById(ctx, uid)
+	if err != nil {
+		return err
+	}
+	err = s.repo.Grant(ctx, uid, role)
+	if err != nil {
+		return err
+	}
+	s.l.Info("授予角色", logger.Int64("operator", operator),
+		logger.Int64("uid", uid), logger.String("role", role))
+	return nil
+}
+
+func (s *rbacService) Revoke(ctx context.Context, operator int64, uid int64, role string) error {
+	if operator == uid && role == domain.RoleSuperAdmin {
+		return ErrRevokeSelf
+	}
+	err := s.repo.Revoke(ctx, uid, role)
+	if err != nil {
+		return err
+	}
+	s.l.Info("撤销角色", logger.Int64("operator", operator),
+		logger.Int64("uid", uid), logger.String("role", role))
+	return nil
+}
@@ -0,0 +1,66 @@
+package service
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+	"testing"
+	"webook/webook/internal/domain"
+	"webook/webook/internal/repository"
+	repomocks "webook/webook/internal/repository/mocks"
+	"webook/webook/pkg/logger"
+)
+
+func Test_rbacService_Revoke(t *testing.T) {
+	testCases := []struct {
+		name     string
+		mock     func(ctrl *gomock.Controller) repository.RBACRepository
+		operator int64
+		uid      int64
+		role     string
+		wantErr  error
+	}{
+		{
+			name: "撤销别人的角色",
+			mock: func(ctrl *gomock.Controller) repository.RBACRepository {
+				repo := repomocks.NewMockRBACRepository(ctrl)
+				repo.EXPECT().Revoke(gomock.Any(), int64(2), domain.RoleSuperAdmin).Return(nil)
+				return repo
+			},
+			operator: 1,
+			uid:      2,
+			role:     domain.RoleSuperAdmin,
+		},
+		{
+			name: "撤销自己的审核员",
+			mock: func(ctrl *gomock.Controller) repository.RBACRepository {
+				repo := repomocks.NewMockRBACRepository(ctrl)
+				repo.EXPECT().Revoke(gomock.Any(), int64(1), domain.RoleModerator).Return(nil)
+				return repo
+			},
+			operator: 1,
+			uid:      1,
+			role:     domain.RoleModerator,
+		},
+		{
+			name: "不能撤销自己的超级管理员",
+			mock: func(ctrl *gomock.Controller) repository.RBACRepository {
+				return repomocks.NewMockRBACRepository(ctrl)
+			},
+			operator: 1,
+			uid:      1,
+			role:     domain.RoleSuperAdmin,
+			wantErr:  ErrRevokeSelf,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			svc := NewRBACService(tc.mock(ctrl), repomocks.NewMockUserRepository(ctrl), logger.NewNoOpLogger())
+			err := svc.Revoke(context.Background(), tc.operator, tc.uid, tc.role)
+			assert.Equal(t, tc.wantErr, err)
+		})
+	}
+}
@@ -0,0 +1,75 @@
+package service
+
+import (
+	"context"
+	"time"
+	"webook/webook/internal/domain"
+	"webook/webook/internal/repository"
+	"webook/webook/pkg/logger"
+)
+
+var _ ArticleListener = (*searchService)(nil)
+
+type searchService struct {
+	repo     repository.SearchRepository
+	artRepo  repository.ArticleRepository
+	userRepo repository.UserRepository
+	l        logger.Logger
+	// 重建索引的时候每一批查多少篇
+	batchSize int
+}
+
+func NewSearchService(repo repository.SearchRepository, artRepo repository.ArticleRepository,
+	userRepo repository.UserRepository, l logger.Logger) SearchService {
+	return &searchService{repo: repo, artRepo: artRepo, userRepo: userRepo, l: l, batchSize: 100}
+}
+
+// OnPublished 发表的时候只有作者 ID，还要查一下昵称
+func (s *searchService) OnPublished(ctx context.Context, art domain.Article) {
+	u, err := s.userRepo.FindById(ctx, art.Author.Id)
+	if err != nil {
+		// 没有昵称也能按照标题和内容搜到，重建索引的时候会补上
+		s.l.Error("更新搜索索引查询作者失败", logger.Int64("art_id", art.Id), logger.Error(err))
+	}
+	art.Author.Name = u.NickName
+	if art.Utime.IsZero() {
+		art.Utime = time.Now()
+	}
+	err = s.repo.InputArticle(ctx, art)
+	if err != nil {
+		s.l.Error("更新搜索索引失败", logger.Int64("art_id", art.Id), logger.Error(err))
+	}
+}
+
+func (s *searchService) OnWithdrawn(ctx context.Context, art domain.Article) {
+	err := s.repo.DeleteArticle(ctx, art.Id)
+	if err != nil {
+		s.l.Error("删除搜索索引失败", logger.Int64("art_id", art.Id), logger.Error(err))
+	}
+}
+
+func (s *searchService) SearchArticle(ctx context.Context, query string,
+	offset int, limit int) (domain.ArticleSearchResult, error) {
+	return s.repo.SearchArticle(ctx, query, offset, limit)
+}
+
+// Rebuild 分批从线上库查询，一批一批写进索引，不会把所有文章都放在内存里
+func (s *searchService) Rebuild(ctx context.Context) (int, error) {
+	offset, done := 0, false
+	err := s.repo.ReplaceArticles(ctx, func(ctx context.Context) ([]domain.Article, error) {
+		if done {
+			return nil, nil
+		}
+		batch, err := s.artRepo.ListPub(ctx, offset, s.batchSize)
+		if err != nil {
+			return nil, err
+		}
+		offset += len(batch)
+		done = len(batch) < s.batchSize
+		return batch, nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return offset, nil
+}
@@ -0,0 +1,138 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+	"testing"
+	"time"
+	"webook/webook/internal/domain"
+	"webook/webook/internal/repository"
+	repomocks "webook/webook/internal/repository/mocks"
+	"webook/webook/pkg/logger"
+)
+
+func TestSearchService_OnPublished(t *testing.T) {
+	utime := time.UnixMilli(123)
+	testCases := []struct {
+		name string
+		mock func(ctrl *gomock.Controller) (repository.SearchRepository, repository.UserRepository)
+	}{
+		{
+			name: "带上作者昵称",
+			mock: func(ctrl *gomock.Controller) (repository.SearchRepository, repository.UserRepository) {
+				repo := repomocks.NewMockSearchRepository(ctrl)
+				userRepo := repomocks.NewMockUserRepository(ctrl)
+				userRepo.EXPECT().FindById(gomock.Any(), int64(123)).
+					Return(domain.User{Id: 123, UserInfo: domain.UserInfo{NickName: "大明"}}, nil)
+				repo.EXPECT().InputArticle(gomock.Any(), domain.Article{
+					Id:      1,
+					Title:   "我的标题",
+					Content: "我的内容",
+					Author:  domain.Author{Id: 123, Name: "大明"},
+					Utime:   utime,
+				}).Return(nil)
+				return repo, userRepo
+			},
+		},
+		{
+			name: "查不到作者也要索引",
+			mock: func(ctrl *gomock.Controller) (repository.SearchRepository, repository.UserRepository) {
+				repo := repomocks.NewMockSearchRepository(ctrl)
+				userRepo := repomocks.NewMockUserRepository(ctrl)
+				userRepo.EXPECT().FindById(gomock.Any(), int64(123)).
+					Return(domain.User{}, errors.New("mock db error"))
+				repo.EXPECT().InputArticle(gomock.Any(), domain.Article{
+					Id:      1,
+					Title:   "我的标题",
+					Content: "我的内容",
+					Author:  domain.Author{Id: 123},
+					Utime:   utime,
+				}).Return(nil)
+				return repo, userRepo
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			repo, userRepo := tc.mock(ctrl)
+			svc := NewSearchService(repo, repomocks.NewMockArticleRepository(ctrl), userRepo, logger.NewNoOpLogger())
+			svc.OnPublished(context.Background(), domain.Article{
+				Id:      1,
+				Title:   "我的标题",
+				Content: "我的内容",
+				Author:  domain.Author{Id: 123},
+				Utime:   utime,
+			})
+		})
+	}
+}
+
+func TestSearchService_Rebuild(t *testing.T) {
+	testCases := []struct {
+		name    string
+		mock    func(ctrl *gomock.Controller) (repository.SearchRepository, repository.ArticleRepository)
+		wantCnt int
+		wantErr error
+	}{
+		{
+			name: "分批写入之后一次替换",
+			mock: func(ctrl *gomock.Controller) (repository.SearchRepository, repository.ArticleRepository) {
+				repo := repomocks.NewMockSearchRepository(ctrl)
+				artRepo := repomocks.NewMockArticleRepository(ctrl)
+				artRepo.EXPECT().ListPub(gomock.Any(), 0, 2).
+					Return([]domain.Article{{Id: 1}, {Id: 2}}, nil)
+				artRepo.EXPECT().ListPub(gomock.Any(), 2, 2).
+					Return([]domain.Article{{Id: 3}}, nil)
+				repo.EXPECT().ReplaceArticles(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(ctx context.Context,
+						next func(ctx context.Context) ([]domain.Article, error)) error {
+						var batches [][]domain.Article
+						for {
+							batch, err := next(ctx)
+							if err != nil || len(batch) == 0 {
+								assert.Equal(t, [][]domain.Article{{{Id: 1}, {Id: 2}}, {{Id: 3}}}, batches)
+								return err
+							}
+							batches = append(batches, batch)
+						}
+					})
+				return repo, artRepo
+			},
+			wantCnt: 3,
+		},
+		{
+			name: "查询失败，不替换",
+			mock: func(ctrl *gomock.Controller) (repository.SearchRepository, repository.ArticleRepository) {
+				repo := repomocks.NewMockSearchRepository(ctrl)
+				artRepo := repomocks.NewMockArticleRepository(ctrl)
+				artRepo.EXPECT().ListPub(gomock.Any(), 0, 2).
+					Return(nil, errors.New("mock db error"))
+				repo.EXPECT().ReplaceArticles(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(ctx context.Context,
+						next func(ctx context.Context) ([]domain.Article, error)) error {
+						_, err := next(ctx)
+						return err
+					})
+				return repo, artRepo
+			},
+			wantErr: errors.New("mock db error"),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			repo, artRepo := tc.mock(ctrl)
+			svc := NewSearchService(repo, artRepo, repomocks.NewMockUserRepository(ctrl),
+				logger.NewNoOpLogger()).(*searchService)
+			svc.batchSize = 2
+			cnt, err := svc.Rebuild(context.Background())
+			assert.Equal(t, tc.wantErr, err)
+			assert.Equal(t, tc.wantCnt, cnt)
+		})
+	}
+}
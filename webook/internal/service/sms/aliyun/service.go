@@ -0,0 +1,146 @@
+package aliyun
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"github.com/google/uuid"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+	"webook/webook/internal/service/sms"
+)
+
+type Config struct {
+	// Endpoint 一般是 https://dysmsapi.aliyuncs.com
+	Endpoint        string
+	RegionId        string
+	AccessKeyId     string
+	AccessKeySecret string
+	// SignName 签名，说明是谁发送的
+	SignName string
+}
+
+// provider 在 TemplateRegistry 里面的名字
+const provider = "aliyun"
+
+// codeMobileNumberIllegal 手机号格式不对
+const codeMobileNumberIllegal = "isv.MOBILE_NUMBER_ILLEGAL"
+
+// SmsService 阿里云短信服务
+// 直接调用 SendSms 接口，签名按照阿里云 RPC 风格的规则计算
+type SmsService struct {
+	client *http.Client
+	cfg    Config
+	tpls   *sms.TemplateRegistry
+	now    func() time.Time
+	nonce  func() string
+}
+
+func NewSMSService(client *http.Client, cfg Config, tpls *sms.TemplateRegistry) *SmsService {
+	return &SmsService{
+		client: client,
+		cfg:    cfg,
+		tpls:   tpls,
+		now:    time.Now,
+		nonce:  uuid.NewString,
+	}
+}
+
+type response struct {
+	// Code 成功的时候是 OK
+	Code      string `json:"Code"`
+	Message   string `json:"Message"`
+	BizId     string `json:"BizId"`
+	RequestId string `json:"RequestId"`
+}
+
+// Send 阿里云发送短信
+// 阿里云的模板变量是有名字的，变量名就是业务模板的参数名
+func (s *SmsService) Send(ctx context.Context, tplId string, args []string, numbers ...string) error {
+	tpl, vals, err := s.tpls.Resolve(provider, tplId, args)
+	if err != nil {
+		return err
+	}
+	params := make(map[string]string, len(vals))
+	for i, name := range tpl.Params {
+		params[name] = vals[i]
+	}
+	tplParam, err := json.Marshal(params)
+	if err != nil {
+		return err
+	}
+	form := url.Values{}
+	form.Set("Action", "SendSms")
+	form.Set("Version", "2017-05-25")
+	form.Set("Format", "JSON")
+	form.Set("RegionId", s.cfg.RegionId)
+	form.Set("AccessKeyId", s.cfg.AccessKeyId)
+	form.Set("SignatureMethod", "HMAC-SHA1")
+	form.Set("SignatureVersion", "1.0")
+	form.Set("SignatureNonce", s.nonce())
+	form.Set("Timestamp", s.now().UTC().Format("2006-01-02T15:04:05Z"))
+	// 多个号码用逗号分隔，一次最多 1000 个
+	form.Set("PhoneNumbers", strings.Join(numbers, ","))
+	form.Set("SignName", s.cfg.SignName)
+	form.Set("TemplateCode", tpl.Id)
+	form.Set("TemplateParam", string(tplParam))
+	form.Set("Signature", s.sign(http.MethodPost, form))
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.cfg.Endpoint,
+		strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	var res response
+	err = json.NewDecoder(resp.Body).Decode(&res)
+	if err != nil {
+		return fmt.Errorf("解析阿里云短信响应失败 %d %w", resp.StatusCode, err)
+	}
+	if res.Code == codeMobileNumberIllegal {
+		return fmt.Errorf("发送短信失败 %s %w", res.Message, sms.ErrInvalidNumber)
+	}
+	if res.Code != "OK" {
+		return fmt.Errorf("发送短信失败 %s %s", res.Code, res.Message)
+	}
+	return nil
+}
+
+// sign 参数按照名字排序之后拼接起来，用 AccessKeySecret 计算 HMAC-SHA1
+func (s *SmsService) sign(method string, form url.Values) string {
+	keys := make([]string, 0, len(form))
+	for k := range form {
+		if k == "Signature" {
+			continue
+		}
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	pairs := make([]string, 0, len(keys))
+	for _, k := range keys {
+		pairs = append(pairs, percentEncode(k)+"="+percentEncode(form.Get(k)))
+	}
+	str := method + "&" + percentEncode("/") + "&" + percentEncode(strings.Join(pairs, "&"))
+	mac := hmac.New(sha1.New, []byte(s.cfg.AccessKeySecret+"&"))
+	mac.Write([]byte(str))
+	return base64.StdEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// percentEncode 阿里云要求的编码方式，和 url.QueryEscape 有几个字符不一样
+func percentEncode(s string) string {
+	res := url.QueryEscape(s)
+	res = strings.ReplaceAll(res, "+", "%20")
+	res = strings.ReplaceAll(res, "*", "%2A")
+	return strings.ReplaceAll(res, "%7E", "~")
+}
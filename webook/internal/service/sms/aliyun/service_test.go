@@ -0,0 +1,140 @@
+package aliyun
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+	"webook/webook/internal/service/sms"
+)
+
+func TestSmsService_Send(t *testing.T) {
+	testCases := []struct {
+		name string
+		// handler 模拟阿里云的 SendSms 接口
+		handler func(t *testing.T, svc *SmsService) http.HandlerFunc
+		tplId   string
+		args    []string
+		wantErr error
+	}{
+		{
+			name: "发送成功",
+			handler: func(t *testing.T, svc *SmsService) http.HandlerFunc {
+				return func(w http.ResponseWriter, r *http.Request) {
+					require.NoError(t, r.ParseForm())
+					assert.Equal(t, http.MethodPost, r.Method)
+					assert.Equal(t, "SendSms", r.PostForm.Get("Action"))
+					assert.Equal(t, "2017-05-25", r.PostForm.Get("Version"))
+					assert.Equal(t, "cn-hangzhou", r.PostForm.Get("RegionId"))
+					assert.Equal(t, "key-id", r.PostForm.Get("AccessKeyId"))
+					assert.Equal(t, "2023-10-01T04:00:00Z", r.PostForm.Get("Timestamp"))
+					assert.Equal(t, "nonce", r.PostForm.Get("SignatureNonce"))
+					assert.Equal(t, "13800000000,13900000000", r.PostForm.Get("PhoneNumbers"))
+					assert.Equal(t, "小微书", r.PostForm.Get("SignName"))
+					assert.Equal(t, "SMS_1", r.PostForm.Get("TemplateCode"))
+					// 阿里云模板变量名就是业务模板的参数名
+					assert.JSONEq(t, `{"code":"123456","minutes":"5"}`, r.PostForm.Get("TemplateParam"))
+					assert.Equal(t, svc.sign(http.MethodPost, r.PostForm), r.PostForm.Get("Signature"))
+					_, _ = w.Write([]byte(`{"Code":"OK","Message":"OK","BizId":"1","RequestId":"r"}`))
+				}
+			},
+			tplId: "login",
+			args:  []string{"123456", "5"},
+		},
+		{
+			name: "服务商返回错误",
+			handler: func(t *testing.T, svc *SmsService) http.HandlerFunc {
+				return func(w http.ResponseWriter, r *http.Request) {
+					_, _ = w.Write([]byte(`{"Code":"isv.BUSINESS_LIMIT_CONTROL","Message":"业务限流"}`))
+				}
+			},
+			tplId:   "login",
+			args:    []string{"123456", "5"},
+			wantErr: errors.New("发送短信失败 isv.BUSINESS_LIMIT_CONTROL 业务限流"),
+		},
+		{
+			name: "号码不对",
+			handler: func(t *testing.T, svc *SmsService) http.HandlerFunc {
+				return func(w http.ResponseWriter, r *http.Request) {
+					_, _ = w.Write([]byte(`{"Code":"isv.MOBILE_NUMBER_ILLEGAL","Message":"非法手机号"}`))
+				}
+			},
+			tplId:   "login",
+			args:    []string{"123456", "5"},
+			wantErr: sms.ErrInvalidNumber,
+		},
+		{
+			name: "阿里云没有配置这个模板",
+			handler: func(t *testing.T, svc *SmsService) http.HandlerFunc {
+				return func(w http.ResponseWriter, r *http.Request) {
+					t.Fatal("不应该调用阿里云")
+				}
+			},
+			tplId:   "reset",
+			args:    []string{"123456"},
+			wantErr: sms.ErrUnknownTemplate,
+		},
+		{
+			name: "参数个数不对",
+			handler: func(t *testing.T, svc *SmsService) http.HandlerFunc {
+				return func(w http.ResponseWriter, r *http.Request) {
+					t.Fatal("不应该调用阿里云")
+				}
+			},
+			tplId:   "login",
+			args:    []string{"123456"},
+			wantErr: sms.ErrTemplateArgs,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tpls, err := sms.NewTemplateRegistry([]sms.TemplateDef{
+				{
+					Name: "login",
+					Args: []string{"code", "minutes"},
+					Providers: map[string]sms.Template{
+						"aliyun": {Id: "SMS_1", Params: []string{"code", "minutes"}},
+					},
+				},
+				{
+					Name: "reset",
+					Args: []string{"code"},
+					Providers: map[string]sms.Template{
+						"tencent": {Id: "1", Params: []string{"code"}},
+					},
+				},
+			})
+			require.NoError(t, err)
+			svc := NewSMSService(http.DefaultClient, Config{
+				RegionId:        "cn-hangzhou",
+				AccessKeyId:     "key-id",
+				AccessKeySecret: "key-secret",
+				SignName:        "小微书",
+			}, tpls)
+			svc.now = func() time.Time {
+				return time.Date(2023, 10, 1, 12, 0, 0, 0, time.FixedZone("CST", 8*3600))
+			}
+			svc.nonce = func() string {
+				return "nonce"
+			}
+			server := httptest.NewServer(tc.handler(t, svc))
+			defer server.Close()
+			svc.cfg.Endpoint = server.URL
+			err = svc.Send(context.Background(), tc.tplId, tc.args, "13800000000", "13900000000")
+			if errors.Is(tc.wantErr, sms.ErrUnknownTemplate) || errors.Is(tc.wantErr, sms.ErrTemplateArgs) ||
+				errors.Is(tc.wantErr, sms.ErrInvalidNumber) {
+				assert.ErrorIs(t, err, tc.wantErr)
+				return
+			}
+			assert.Equal(t, tc.wantErr, err)
+		})
+	}
+}
+
+func TestPercentEncode(t *testing.T) {
+	assert.Equal(t, "a%20b%2Ac~d%2F", percentEncode("a b*c~d/"))
+}
@@ -0,0 +1,213 @@
+package async
+
+import (
+	"context"
+	"errors"
+	"time"
+	"webook/webook/internal/domain"
+	"webook/webook/internal/repository"
+	"webook/webook/internal/service/sms"
+	"webook/webook/internal/service/sms/ratelimit"
+	"webook/webook/pkg/logger"
+)
+
+// ErrExpired 短信还没发出去就过期了，不会再存到数据库里
+var ErrExpired = errors.New("短信已经过期")
+
+type Config struct {
+	// MaxRetry 最多发送几次，用完了标记为失败
+	MaxRetry int
+	// InitBackoff 第一次重试的间隔，之后每次翻倍，最多 MaxBackoff
+	InitBackoff time.Duration
+	MaxBackoff  time.Duration
+	// Window 统计最近多少次发送的结果，失败的比例超过 ErrRateThreshold 就认为服务商不健康，直接走异步
+	Window           int
+	ErrRateThreshold float64
+	// SendTimeout worker 每次发送的超时时间
+	SendTimeout time.Duration
+	// PollInterval 没有短信要发的时候，worker 隔多久再查
+	PollInterval time.Duration
+	// StaleTimeout 发送中的短信超过这个时间还没有结果，认为 worker 崩溃了，别的 worker 可以重新发
+	StaleTimeout time.Duration
+}
+
+// Service 服务商限流或者不健康的时候，把短信存到数据库里，立刻返回，由后台的 worker 重试
+// 同步发送失败也会转成异步，调用者拿到 nil 只说明短信最终会尝试发送。
+// 部分号码发送失败的时候，只有失败的号码会转成异步；
+// 调用者可以用 sms.WithExpireAt 设置过期时间，过期的短信不会再发送；
+// 模板、参数、号码不对这种重试也不会成功的错误直接返回，不会转成异步
+type Service struct {
+	svc   sms.Service
+	repo  repository.AsyncSmsRepository
+	cfg   Config
+	stats *errStats
+	l     logger.Logger
+}
+
+// NewService svc 一般是 ratelimit.Service，触发限流的时候转成异步
+func NewService(svc sms.Service, repo repository.AsyncSmsRepository, cfg Config, l logger.Logger) *Service {
+	return &Service{
+		svc:   svc,
+		repo:  repo,
+		cfg:   cfg,
+		stats: newErrStats(cfg.Window, cfg.ErrRateThreshold),
+		l:     l,
+	}
+}
+
+func (s *Service) Send(ctx context.Context, tplId string, args []string, numbers ...string) error {
+	if s.stats.unhealthy() {
+		return s.enqueue(ctx, tplId, args, numbers)
+	}
+	err := s.svc.Send(ctx, tplId, args, numbers...)
+	var pe *sms.PartialError
+	if errors.As(err, &pe) {
+		// 已经发出去的号码不能再发
+		numbers, err = pe.Numbers, pe.Err
+	}
+	switch {
+	case err == nil:
+		s.stats.record(false)
+		return nil
+	case isPermanent(err):
+		// 不是服务商的问题，也不计入失败率
+		return err
+	case errors.Is(err, ratelimit.ErrLimited):
+		// 限流不是服务商的问题，不计入失败率
+		return s.enqueue(ctx, tplId, args, numbers)
+	case ctx.Err() != nil:
+		// 调用者已经不等了，存数据库也会失败
+		return err
+	default:
+		s.stats.record(true)
+		s.l.Warn("同步发送短信失败，转为异步发送", logger.Error(err), logger.String("tpl", tplId))
+		return s.enqueue(ctx, tplId, args, numbers)
+	}
+}
+
+func (s *Service) enqueue(ctx context.Context, tplId string, args []string, numbers []string) error {
+	expireAt, _ := sms.ExpireAt(ctx)
+	if !expireAt.IsZero() && !time.Now().Before(expireAt) {
+		return ErrExpired
+	}
+	_, err := s.repo.Add(ctx, domain.AsyncSms{
+		TplId:    tplId,
+		Args:     args,
+		Numbers:  numbers,
+		MaxRetry: s.cfg.MaxRetry,
+		ExpireAt: expireAt,
+	})
+	return err
+}
+
+// isPermanent 重试也不会成功的错误
+func isPermanent(err error) bool {
+	return errors.Is(err, sms.ErrUnknownTemplate) ||
+		errors.Is(err, sms.ErrTemplateArgs) ||
+		errors.Is(err, sms.ErrInvalidNumber)
+}
+
+// Start 启动 n 个 worker，ctx 取消之后退出
+func (s *Service) Start(ctx context.Context, n int) {
+	for i := 0; i < n; i++ {
+		go s.loop(ctx)
+	}
+}
+
+func (s *Service) loop(ctx context.Context) {
+	for ctx.Err() == nil {
+		if s.sendOne(ctx) {
+			continue
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(s.cfg.PollInterval):
+		}
+	}
+}
+
+// sendOne 抢一条短信发送，没有抢到返回 false
+func (s *Service) sendOne(ctx context.Context) bool {
+	msg, err := s.repo.Preempt(ctx, time.Now().Add(-s.cfg.StaleTimeout))
+	if errors.Is(err, repository.ErrNoAsyncSms) {
+		return false
+	}
+	if err != nil {
+		s.l.Error("抢占异步短信失败", logger.Error(err))
+		return false
+	}
+	if s.expired(msg, time.Now()) {
+		msg.LastErr = ErrExpired.Error()
+		err = s.repo.MarkExpired(ctx, msg)
+		if err != nil {
+			s.l.Error("更新异步短信的状态失败", logger.Int64("id", msg.Id), logger.Error(err))
+		}
+		return true
+	}
+	sendCtx, cancel := context.WithTimeout(ctx, s.cfg.SendTimeout)
+	err = s.svc.Send(sendCtx, msg.TplId, msg.Args, msg.Numbers...)
+	cancel()
+	var pe *sms.PartialError
+	if errors.As(err, &pe) {
+		// 重试的时候只发失败的号码
+		msg.Numbers, err = pe.Numbers, pe.Err
+	}
+	switch {
+	case err == nil:
+		s.stats.record(false)
+		err = s.repo.MarkSuccess(ctx, msg)
+	case isPermanent(err):
+		msg.RetryCnt++
+		msg.LastErr = err.Error()
+		s.l.Error("异步短信重试也不会成功，不再发送",
+			logger.Int64("id", msg.Id), logger.Error(err))
+		err = s.repo.MarkFailed(ctx, msg)
+	case errors.Is(err, ratelimit.ErrLimited):
+		// 限流了不算一次失败，等一会儿再发
+		err = s.retry(ctx, msg, time.Now().Add(s.cfg.InitBackoff))
+	default:
+		s.stats.record(true)
+		msg.RetryCnt++
+		msg.LastErr = err.Error()
+		if msg.RetryCnt >= msg.MaxRetry {
+			s.l.Error("异步短信重试次数用完了，不再发送",
+				logger.Int64("id", msg.Id), logger.Error(err))
+			err = s.repo.MarkFailed(ctx, msg)
+			break
+		}
+		err = s.retry(ctx, msg, time.Now().Add(s.backoff(msg.RetryCnt)))
+	}
+	if err != nil {
+		// 更新失败的话，过了 StaleTimeout 会被重新发送
+		s.l.Error("更新异步短信的状态失败", logger.Int64("id", msg.Id), logger.Error(err))
+	}
+	return true
+}
+
+// retry 下次重试的时候已经过期了，就直接标记为过期，不要等到验证码失效之后才发出去
+func (s *Service) retry(ctx context.Context, msg domain.AsyncSms, nextTime time.Time) error {
+	if s.expired(msg, nextTime) {
+		if msg.LastErr == "" {
+			msg.LastErr = ErrExpired.Error()
+		}
+		return s.repo.MarkExpired(ctx, msg)
+	}
+	return s.repo.MarkRetry(ctx, msg, nextTime)
+}
+
+func (s *Service) expired(msg domain.AsyncSms, t time.Time) bool {
+	return !msg.ExpireAt.IsZero() && !t.Before(msg.ExpireAt)
+}
+
+// backoff 第 retryCnt 次失败之后要等多久，指数退避
+func (s *Service) backoff(retryCnt int) time.Duration {
+	d := s.cfg.InitBackoff
+	for i := 1; i < retryCnt; i++ {
+		d *= 2
+		if d >= s.cfg.MaxBackoff {
+			return s.cfg.MaxBackoff
+		}
+	}
+	return d
+}
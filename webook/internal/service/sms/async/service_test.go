@@ -0,0 +1,376 @@
+package async
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+	"testing"
+	"time"
+	"webook/webook/internal/domain"
+	"webook/webook/internal/repository"
+	repomocks "webook/webook/internal/repository/mocks"
+	"webook/webook/internal/service/sms"
+	smsmocks "webook/webook/internal/service/sms/mocks"
+	"webook/webook/internal/service/sms/ratelimit"
+	"webook/webook/pkg/logger"
+)
+
+var testCfg = Config{
+	MaxRetry:         3,
+	InitBackoff:      time.Second,
+	MaxBackoff:       time.Second * 3,
+	Window:           2,
+	ErrRateThreshold: 0.5,
+	SendTimeout:      time.Second,
+	PollInterval:     time.Millisecond * 10,
+	StaleTimeout:     time.Minute,
+}
+
+func TestService_Send(t *testing.T) {
+	expireAt := time.Now().Add(time.Minute)
+	testCases := []struct {
+		name string
+		mock func(ctrl *gomock.Controller) (sms.Service, repository.AsyncSmsRepository)
+		// failures 发送之前最近失败了几次
+		failures int
+		ctx      func() context.Context
+		wantErr  error
+	}{
+		{
+			name: "同步发送成功",
+			mock: func(ctrl *gomock.Controller) (sms.Service, repository.AsyncSmsRepository) {
+				svc := smsmocks.NewMockService(ctrl)
+				svc.EXPECT().Send(gomock.Any(), "tpl", []string{"123456"}, "13800000000").Return(nil)
+				return svc, repomocks.NewMockAsyncSmsRepository(ctrl)
+			},
+			ctx: context.Background,
+		},
+		{
+			name: "触发限流，转为异步",
+			mock: func(ctrl *gomock.Controller) (sms.Service, repository.AsyncSmsRepository) {
+				svc := smsmocks.NewMockService(ctrl)
+				svc.EXPECT().Send(gomock.Any(), "tpl", []string{"123456"}, "13800000000").
+					Return(ratelimit.ErrLimited)
+				repo := repomocks.NewMockAsyncSmsRepository(ctrl)
+				repo.EXPECT().Add(gomock.Any(), domain.AsyncSms{
+					TplId:    "tpl",
+					Args:     []string{"123456"},
+					Numbers:  []string{"13800000000"},
+					MaxRetry: 3,
+				}).Return(int64(1), nil)
+				return svc, repo
+			},
+			ctx: context.Background,
+		},
+		{
+			name: "同步发送失败，转为异步",
+			mock: func(ctrl *gomock.Controller) (sms.Service, repository.AsyncSmsRepository) {
+				svc := smsmocks.NewMockService(ctrl)
+				svc.EXPECT().Send(gomock.Any(), "tpl", []string{"123456"}, "13800000000").
+					Return(errors.New("服务商出错"))
+				repo := repomocks.NewMockAsyncSmsRepository(ctrl)
+				repo.EXPECT().Add(gomock.Any(), gomock.Any()).Return(int64(1), nil)
+				return svc, repo
+			},
+			ctx: context.Background,
+		},
+		{
+			name: "最近失败率太高，直接异步",
+			mock: func(ctrl *gomock.Controller) (sms.Service, repository.AsyncSmsRepository) {
+				repo := repomocks.NewMockAsyncSmsRepository(ctrl)
+				repo.EXPECT().Add(gomock.Any(), gomock.Any()).Return(int64(1), nil)
+				return smsmocks.NewMockService(ctrl), repo
+			},
+			failures: 2,
+			ctx:      context.Background,
+		},
+		{
+			name: "转为异步的时候保存失败",
+			mock: func(ctrl *gomock.Controller) (sms.Service, repository.AsyncSmsRepository) {
+				svc := smsmocks.NewMockService(ctrl)
+				svc.EXPECT().Send(gomock.Any(), "tpl", []string{"123456"}, "13800000000").
+					Return(ratelimit.ErrLimited)
+				repo := repomocks.NewMockAsyncSmsRepository(ctrl)
+				repo.EXPECT().Add(gomock.Any(), gomock.Any()).Return(int64(0), errors.New("mock db error"))
+				return svc, repo
+			},
+			ctx:     context.Background,
+			wantErr: errors.New("mock db error"),
+		},
+		{
+			name: "带上过期时间转为异步",
+			mock: func(ctrl *gomock.Controller) (sms.Service, repository.AsyncSmsRepository) {
+				svc := smsmocks.NewMockService(ctrl)
+				svc.EXPECT().Send(gomock.Any(), "tpl", []string{"123456"}, "13800000000").
+					Return(ratelimit.ErrLimited)
+				repo := repomocks.NewMockAsyncSmsRepository(ctrl)
+				repo.EXPECT().Add(gomock.Any(), domain.AsyncSms{
+					TplId:    "tpl",
+					Args:     []string{"123456"},
+					Numbers:  []string{"13800000000"},
+					MaxRetry: 3,
+					ExpireAt: expireAt,
+				}).Return(int64(1), nil)
+				return svc, repo
+			},
+			ctx: func() context.Context {
+				return sms.WithExpireAt(context.Background(), expireAt)
+			},
+		},
+		{
+			name: "已经过期了，不转异步",
+			mock: func(ctrl *gomock.Controller) (sms.Service, repository.AsyncSmsRepository) {
+				svc := smsmocks.NewMockService(ctrl)
+				svc.EXPECT().Send(gomock.Any(), "tpl", []string{"123456"}, "13800000000").
+					Return(errors.New("服务商出错"))
+				return svc, repomocks.NewMockAsyncSmsRepository(ctrl)
+			},
+			ctx: func() context.Context {
+				return sms.WithExpireAt(context.Background(), time.Now().Add(-time.Second))
+			},
+			wantErr: ErrExpired,
+		},
+		{
+			name: "号码不对，重试也没用",
+			mock: func(ctrl *gomock.Controller) (sms.Service, repository.AsyncSmsRepository) {
+				svc := smsmocks.NewMockService(ctrl)
+				svc.EXPECT().Send(gomock.Any(), "tpl", []string{"123456"}, "13800000000").
+					Return(sms.ErrInvalidNumber)
+				return svc, repomocks.NewMockAsyncSmsRepository(ctrl)
+			},
+			ctx:     context.Background,
+			wantErr: sms.ErrInvalidNumber,
+		},
+		{
+			name: "模板没有配置，重试也没用",
+			mock: func(ctrl *gomock.Controller) (sms.Service, repository.AsyncSmsRepository) {
+				svc := smsmocks.NewMockService(ctrl)
+				svc.EXPECT().Send(gomock.Any(), "tpl", []string{"123456"}, "13800000000").
+					Return(sms.ErrUnknownTemplate)
+				return svc, repomocks.NewMockAsyncSmsRepository(ctrl)
+			},
+			ctx:     context.Background,
+			wantErr: sms.ErrUnknownTemplate,
+		},
+		{
+			name: "调用者已经取消，不转异步",
+			mock: func(ctrl *gomock.Controller) (sms.Service, repository.AsyncSmsRepository) {
+				svc := smsmocks.NewMockService(ctrl)
+				svc.EXPECT().Send(gomock.Any(), "tpl", []string{"123456"}, "13800000000").
+					Return(context.Canceled)
+				return svc, repomocks.NewMockAsyncSmsRepository(ctrl)
+			},
+			ctx: func() context.Context {
+				ctx, cancel := context.WithCancel(context.Background())
+				cancel()
+				return ctx
+			},
+			wantErr: context.Canceled,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			svc, repo := tc.mock(ctrl)
+			s := NewService(svc, repo, testCfg, logger.NewNoOpLogger())
+			for i := 0; i < tc.failures; i++ {
+				s.stats.record(true)
+			}
+			err := s.Send(tc.ctx(), "tpl", []string{"123456"}, "13800000000")
+			assert.Equal(t, tc.wantErr, err)
+		})
+	}
+}
+
+func TestService_sendOne(t *testing.T) {
+	msg := domain.AsyncSms{
+		Id:       1,
+		TplId:    "tpl",
+		Args:     []string{"123456"},
+		Numbers:  []string{"13800000000"},
+		Status:   domain.AsyncSmsStatusSending,
+		RetryCnt: 1,
+		MaxRetry: 3,
+		Version:  2,
+	}
+	testCases := []struct {
+		name     string
+		mock     func(ctrl *gomock.Controller) (sms.Service, repository.AsyncSmsRepository)
+		wantSent bool
+	}{
+		{
+			name: "没有要发送的短信",
+			mock: func(ctrl *gomock.Controller) (sms.Service, repository.AsyncSmsRepository) {
+				repo := repomocks.NewMockAsyncSmsRepository(ctrl)
+				repo.EXPECT().Preempt(gomock.Any(), gomock.Any()).Return(domain.AsyncSms{}, repository.ErrNoAsyncSms)
+				return smsmocks.NewMockService(ctrl), repo
+			},
+		},
+		{
+			name: "发送成功",
+			mock: func(ctrl *gomock.Controller) (sms.Service, repository.AsyncSmsRepository) {
+				repo := repomocks.NewMockAsyncSmsRepository(ctrl)
+				repo.EXPECT().Preempt(gomock.Any(), gomock.Any()).Return(msg, nil)
+				svc := smsmocks.NewMockService(ctrl)
+				svc.EXPECT().Send(gomock.Any(), "tpl", []string{"123456"}, "13800000000").Return(nil)
+				repo.EXPECT().MarkSuccess(gomock.Any(), msg).Return(nil)
+				return svc, repo
+			},
+			wantSent: true,
+		},
+		{
+			name: "触发限流，不计入重试次数",
+			mock: func(ctrl *gomock.Controller) (sms.Service, repository.AsyncSmsRepository) {
+				repo := repomocks.NewMockAsyncSmsRepository(ctrl)
+				repo.EXPECT().Preempt(gomock.Any(), gomock.Any()).Return(msg, nil)
+				svc := smsmocks.NewMockService(ctrl)
+				svc.EXPECT().Send(gomock.Any(), "tpl", []string{"123456"}, "13800000000").
+					Return(ratelimit.ErrLimited)
+				repo.EXPECT().MarkRetry(gomock.Any(), msg, gomock.Any()).Return(nil)
+				return svc, repo
+			},
+			wantSent: true,
+		},
+		{
+			name: "发送失败，稍后重试",
+			mock: func(ctrl *gomock.Controller) (sms.Service, repository.AsyncSmsRepository) {
+				repo := repomocks.NewMockAsyncSmsRepository(ctrl)
+				repo.EXPECT().Preempt(gomock.Any(), gomock.Any()).Return(msg, nil)
+				svc := smsmocks.NewMockService(ctrl)
+				svc.EXPECT().Send(gomock.Any(), "tpl", []string{"123456"}, "13800000000").
+					Return(errors.New("服务商出错"))
+				retry := msg
+				retry.RetryCnt = 2
+				retry.LastErr = "服务商出错"
+				repo.EXPECT().MarkRetry(gomock.Any(), retry, gomock.Any()).
+					DoAndReturn(func(ctx context.Context, s domain.AsyncSms, nextTime time.Time) error {
+						// 第二次失败，退避 2 秒
+						assert.WithinDuration(t, time.Now().Add(time.Second*2), nextTime, time.Second)
+						return nil
+					})
+				return svc, repo
+			},
+			wantSent: true,
+		},
+		{
+			name: "部分号码失败，只重试失败的号码",
+			mock: func(ctrl *gomock.Controller) (sms.Service, repository.AsyncSmsRepository) {
+				multi := msg
+				multi.Numbers = []string{"13800000000", "13900000000"}
+				repo := repomocks.NewMockAsyncSmsRepository(ctrl)
+				repo.EXPECT().Preempt(gomock.Any(), gomock.Any()).Return(multi, nil)
+				svc := smsmocks.NewMockService(ctrl)
+				svc.EXPECT().Send(gomock.Any(), "tpl", []string{"123456"}, "13800000000", "13900000000").
+					Return(&sms.PartialError{Numbers: []string{"13900000000"}, Err: errors.New("服务商出错")})
+				retry := multi
+				retry.Numbers = []string{"13900000000"}
+				retry.RetryCnt = 2
+				retry.LastErr = "服务商出错"
+				repo.EXPECT().MarkRetry(gomock.Any(), retry, gomock.Any()).Return(nil)
+				return svc, repo
+			},
+			wantSent: true,
+		},
+		{
+			name: "已经过期了，不再发送",
+			mock: func(ctrl *gomock.Controller) (sms.Service, repository.AsyncSmsRepository) {
+				expired := msg
+				expired.ExpireAt = time.Now().Add(-time.Second)
+				repo := repomocks.NewMockAsyncSmsRepository(ctrl)
+				repo.EXPECT().Preempt(gomock.Any(), gomock.Any()).Return(expired, nil)
+				marked := expired
+				marked.LastErr = ErrExpired.Error()
+				repo.EXPECT().MarkExpired(gomock.Any(), marked).Return(nil)
+				return smsmocks.NewMockService(ctrl), repo
+			},
+			wantSent: true,
+		},
+		{
+			name: "等到下次重试的时候已经过期了，不再重试",
+			mock: func(ctrl *gomock.Controller) (sms.Service, repository.AsyncSmsRepository) {
+				expiring := msg
+				// 下次重试要等 2 秒
+				expiring.ExpireAt = time.Now().Add(time.Second)
+				repo := repomocks.NewMockAsyncSmsRepository(ctrl)
+				repo.EXPECT().Preempt(gomock.Any(), gomock.Any()).Return(expiring, nil)
+				svc := smsmocks.NewMockService(ctrl)
+				svc.EXPECT().Send(gomock.Any(), "tpl", []string{"123456"}, "13800000000").
+					Return(errors.New("服务商出错"))
+				marked := expiring
+				marked.RetryCnt = 2
+				marked.LastErr = "服务商出错"
+				repo.EXPECT().MarkExpired(gomock.Any(), marked).Return(nil)
+				return svc, repo
+			},
+			wantSent: true,
+		},
+		{
+			name: "模板参数不对，直接标记为失败",
+			mock: func(ctrl *gomock.Controller) (sms.Service, repository.AsyncSmsRepository) {
+				repo := repomocks.NewMockAsyncSmsRepository(ctrl)
+				repo.EXPECT().Preempt(gomock.Any(), gomock.Any()).Return(msg, nil)
+				svc := smsmocks.NewMockService(ctrl)
+				svc.EXPECT().Send(gomock.Any(), "tpl", []string{"123456"}, "13800000000").
+					Return(sms.ErrTemplateArgs)
+				failed := msg
+				failed.RetryCnt = 2
+				failed.LastErr = sms.ErrTemplateArgs.Error()
+				repo.EXPECT().MarkFailed(gomock.Any(), failed).Return(nil)
+				return svc, repo
+			},
+			wantSent: true,
+		},
+		{
+			name: "重试次数用完，标记为失败",
+			mock: func(ctrl *gomock.Controller) (sms.Service, repository.AsyncSmsRepository) {
+				last := msg
+				last.RetryCnt = 2
+				repo := repomocks.NewMockAsyncSmsRepository(ctrl)
+				repo.EXPECT().Preempt(gomock.Any(), gomock.Any()).Return(last, nil)
+				svc := smsmocks.NewMockService(ctrl)
+				svc.EXPECT().Send(gomock.Any(), "tpl", []string{"123456"}, "13800000000").
+					Return(errors.New("服务商出错"))
+				failed := last
+				failed.RetryCnt = 3
+				failed.LastErr = "服务商出错"
+				repo.EXPECT().MarkFailed(gomock.Any(), failed).Return(nil)
+				return svc, repo
+			},
+			wantSent: true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			svc, repo := tc.mock(ctrl)
+			s := NewService(svc, repo, testCfg, logger.NewNoOpLogger())
+			sent := s.sendOne(context.Background())
+			assert.Equal(t, tc.wantSent, sent)
+		})
+	}
+}
+
+func TestService_backoff(t *testing.T) {
+	s := NewService(nil, nil, testCfg, logger.NewNoOpLogger())
+	assert.Equal(t, time.Second, s.backoff(1))
+	assert.Equal(t, time.Second*2, s.backoff(2))
+	// 超过上限
+	assert.Equal(t, time.Second*3, s.backoff(3))
+	assert.Equal(t, time.Second*3, s.backoff(10))
+}
+
+func TestErrStats(t *testing.T) {
+	e := newErrStats(3, 0.5)
+	e.record(true)
+	e.record(true)
+	// 窗口还没满
+	assert.False(t, e.unhealthy())
+	e.record(false)
+	assert.True(t, e.unhealthy())
+	// 最早的一次失败被挤掉了，1/3
+	e.record(false)
+	assert.False(t, e.unhealthy())
+}
@@ -0,0 +1,49 @@
+package async
+
+import "sync"
+
+// errStats 最近 window 次发送的结果，同步发送和 worker 发送都算
+// 不健康的时候只有 worker 在发，worker 发送成功了失败率才会降下来
+type errStats struct {
+	mu        sync.Mutex
+	results   []bool
+	idx       int
+	cnt       int
+	failed    int
+	threshold float64
+}
+
+func newErrStats(window int, threshold float64) *errStats {
+	return &errStats{results: make([]bool, window), threshold: threshold}
+}
+
+func (e *errStats) record(failed bool) {
+	if len(e.results) == 0 {
+		return
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.cnt == len(e.results) {
+		// 窗口满了，挤掉最早的一次
+		if e.results[e.idx] {
+			e.failed--
+		}
+	} else {
+		e.cnt++
+	}
+	e.results[e.idx] = failed
+	if failed {
+		e.failed++
+	}
+	e.idx = (e.idx + 1) % len(e.results)
+}
+
+// unhealthy 窗口满了之后才判断，刚启动的时候几次失败不说明问题
+func (e *errStats) unhealthy() bool {
+	if len(e.results) == 0 {
+		return false
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.cnt == len(e.results) && float64(e.failed)/float64(e.cnt) > e.threshold
+}
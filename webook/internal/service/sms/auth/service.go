@@ -0,0 +1,128 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/ecodeclub/ekit/slice"
+	"github.com/golang-jwt/jwt/v5"
+	"time"
+	"webook/webook/internal/service/sms"
+	"webook/webook/pkg/ginx/ratelimit"
+)
+
+var (
+	ErrNoToken       = errors.New("没有短信服务的 token")
+	ErrInvalidToken  = errors.New("短信服务的 token 无效")
+	ErrTplNotAllowed = errors.New("调用方没有使用这个短信模板的权限")
+	ErrQuotaExceeded = errors.New("调用方发送短信的次数超过了配额")
+)
+
+// AuthError 调用方被拒绝，Err 是上面几个错误中的一个
+type AuthError struct {
+	// Caller 调用方，token 无效的时候为空
+	Caller string
+	TplId  string
+	Err    error
+}
+
+func (e *AuthError) Error() string {
+	return fmt.Sprintf("短信服务拒绝了调用方 %q 使用模板 %s: %v", e.Caller, e.TplId, e.Err)
+}
+
+func (e *AuthError) Unwrap() error {
+	return e.Err
+}
+
+// Claims 短信服务的 token 里的数据
+type Claims struct {
+	jwt.RegisteredClaims
+	// Caller 调用方，每个调用方有自己的配额
+	Caller string
+	// Tpls 允许使用的业务模板
+	Tpls []string
+}
+
+// token 的签发方和接收方，别的地方签发的 token 就算密钥一样也不能用来发短信
+const (
+	tokenIssuer   = "webook"
+	tokenAudience = "sms"
+)
+
+type tokenKey struct{}
+
+// WithToken 调用方把 token 放进 context 里面
+func WithToken(ctx context.Context, token string) context.Context {
+	return context.WithValue(ctx, tokenKey{}, token)
+}
+
+// GenerateToken 给调用方签发 token
+func GenerateToken(key []byte, caller string, tpls []string, expire time.Duration) (string, error) {
+	claims := Claims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    tokenIssuer,
+			Audience:  jwt.ClaimStrings{tokenAudience},
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(expire)),
+		},
+		Caller: caller,
+		Tpls:   tpls,
+	}
+	return jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(key)
+}
+
+// Service 校验调用方的 token 之后再发送短信
+// token 里面说明了调用方是谁、能用哪些模板，每个调用方的发送次数由 limiter 限制，
+// 一次发给多个号码的时候每个号码算一次
+type Service struct {
+	svc     sms.Service
+	key     []byte
+	limiter ratelimit.Limiter
+}
+
+func NewService(svc sms.Service, key []byte, limiter ratelimit.Limiter) *Service {
+	return &Service{
+		svc:     svc,
+		key:     key,
+		limiter: limiter,
+	}
+}
+
+func (s *Service) Send(ctx context.Context, tplId string, args []string, numbers ...string) error {
+	token, _ := ctx.Value(tokenKey{}).(string)
+	if token == "" {
+		return &AuthError{TplId: tplId, Err: ErrNoToken}
+	}
+	var c Claims
+	// 只接受 HS256，没有签名（alg 是 none）或者换了算法的 token 都不行
+	// 必须有过期时间，签发方和接收方也要对得上
+	t, err := jwt.ParseWithClaims(token, &c, func(token *jwt.Token) (interface{}, error) {
+		return s.key, nil
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}),
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuer(tokenIssuer),
+		jwt.WithAudience(tokenAudience))
+	if err != nil {
+		return &AuthError{TplId: tplId, Err: fmt.Errorf("%w, %w", ErrInvalidToken, err)}
+	}
+	if !t.Valid || c.Caller == "" {
+		return &AuthError{TplId: tplId, Err: ErrInvalidToken}
+	}
+	if !slice.Contains(c.Tpls, tplId) {
+		return &AuthError{Caller: c.Caller, TplId: tplId, Err: ErrTplNotAllowed}
+	}
+	// 配额按照号码算，不然一次调用塞很多号码就绕过去了；没有号码也算一次
+	cnt := len(numbers)
+	if cnt == 0 {
+		cnt = 1
+	}
+	for i := 0; i < cnt; i++ {
+		limited, err := s.limiter.Limit(ctx, "sms:caller:"+c.Caller)
+		if err != nil {
+			return fmt.Errorf("短信服务判断调用方的配额出现问题, %w", err)
+		}
+		if limited {
+			return &AuthError{Caller: c.Caller, TplId: tplId, Err: ErrQuotaExceeded}
+		}
+	}
+	return s.svc.Send(ctx, tplId, args, numbers...)
+}
@@ -0,0 +1,267 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"testing"
+	"time"
+	"webook/webook/internal/service/sms"
+	smsmocks "webook/webook/internal/service/sms/mocks"
+	"webook/webook/pkg/ginx/ratelimit"
+	ratelimitmocks "webook/webook/pkg/ginx/ratelimit/mocks"
+)
+
+var testKey = []byte("k6CswdUm75WKcbM68UQUuxVsHSpTCwgK")
+
+func TestService_Send(t *testing.T) {
+	testCases := []struct {
+		name  string
+		mock  func(ctrl *gomock.Controller) (sms.Service, ratelimit.Limiter)
+		token func(t *testing.T) string
+		// wantCaller 被拒绝的时候 AuthError 里的调用方
+		wantCaller string
+		wantErr    error
+	}{
+		{
+			name: "校验通过，发送短信",
+			mock: func(ctrl *gomock.Controller) (sms.Service, ratelimit.Limiter) {
+				limiter := ratelimitmocks.NewMockLimiter(ctrl)
+				limiter.EXPECT().Limit(gomock.Any(), "sms:caller:user-service").Return(false, nil)
+				svc := smsmocks.NewMockService(ctrl)
+				svc.EXPECT().Send(gomock.Any(), sms.TplLoginCode, []string{"123456"}, "13800000000").Return(nil)
+				return svc, limiter
+			},
+			token: func(t *testing.T) string {
+				token, err := GenerateToken(testKey, "user-service",
+					[]string{sms.TplLoginCode, sms.TplPasswordReset}, time.Minute)
+				require.NoError(t, err)
+				return token
+			},
+		},
+		{
+			name: "没有 token",
+			mock: func(ctrl *gomock.Controller) (sms.Service, ratelimit.Limiter) {
+				return smsmocks.NewMockService(ctrl), ratelimitmocks.NewMockLimiter(ctrl)
+			},
+			token: func(t *testing.T) string {
+				return ""
+			},
+			wantErr: ErrNoToken,
+		},
+		{
+			name: "没有签名的 token",
+			mock: func(ctrl *gomock.Controller) (sms.Service, ratelimit.Limiter) {
+				return smsmocks.NewMockService(ctrl), ratelimitmocks.NewMockLimiter(ctrl)
+			},
+			token: func(t *testing.T) string {
+				token, err := jwt.NewWithClaims(jwt.SigningMethodNone, Claims{
+					Caller: "user-service",
+					Tpls:   []string{sms.TplLoginCode},
+				}).SignedString(jwt.UnsafeAllowNoneSignatureType)
+				require.NoError(t, err)
+				return token
+			},
+			wantErr: ErrInvalidToken,
+		},
+		{
+			name: "别的密钥签名的 token",
+			mock: func(ctrl *gomock.Controller) (sms.Service, ratelimit.Limiter) {
+				return smsmocks.NewMockService(ctrl), ratelimitmocks.NewMockLimiter(ctrl)
+			},
+			token: func(t *testing.T) string {
+				token, err := GenerateToken([]byte("another key"), "user-service",
+					[]string{sms.TplLoginCode}, time.Minute)
+				require.NoError(t, err)
+				return token
+			},
+			wantErr: ErrInvalidToken,
+		},
+		{
+			name: "token 过期了",
+			mock: func(ctrl *gomock.Controller) (sms.Service, ratelimit.Limiter) {
+				return smsmocks.NewMockService(ctrl), ratelimitmocks.NewMockLimiter(ctrl)
+			},
+			token: func(t *testing.T) string {
+				token, err := GenerateToken(testKey, "user-service", []string{sms.TplLoginCode}, -time.Minute)
+				require.NoError(t, err)
+				return token
+			},
+			wantErr: ErrInvalidToken,
+		},
+		{
+			name: "token 没有过期时间",
+			mock: func(ctrl *gomock.Controller) (sms.Service, ratelimit.Limiter) {
+				return smsmocks.NewMockService(ctrl), ratelimitmocks.NewMockLimiter(ctrl)
+			},
+			token: func(t *testing.T) string {
+				return signToken(t, jwt.RegisteredClaims{
+					Issuer:   tokenIssuer,
+					Audience: jwt.ClaimStrings{tokenAudience},
+				})
+			},
+			wantErr: ErrInvalidToken,
+		},
+		{
+			name: "别人签发的 token",
+			mock: func(ctrl *gomock.Controller) (sms.Service, ratelimit.Limiter) {
+				return smsmocks.NewMockService(ctrl), ratelimitmocks.NewMockLimiter(ctrl)
+			},
+			token: func(t *testing.T) string {
+				return signToken(t, jwt.RegisteredClaims{
+					Issuer:    "another",
+					Audience:  jwt.ClaimStrings{tokenAudience},
+					ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Minute)),
+				})
+			},
+			wantErr: ErrInvalidToken,
+		},
+		{
+			name: "签给别的服务的 token",
+			mock: func(ctrl *gomock.Controller) (sms.Service, ratelimit.Limiter) {
+				return smsmocks.NewMockService(ctrl), ratelimitmocks.NewMockLimiter(ctrl)
+			},
+			token: func(t *testing.T) string {
+				return signToken(t, jwt.RegisteredClaims{
+					Issuer:    tokenIssuer,
+					Audience:  jwt.ClaimStrings{"user"},
+					ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Minute)),
+				})
+			},
+			wantErr: ErrInvalidToken,
+		},
+		{
+			name: "token 里没有调用方",
+			mock: func(ctrl *gomock.Controller) (sms.Service, ratelimit.Limiter) {
+				return smsmocks.NewMockService(ctrl), ratelimitmocks.NewMockLimiter(ctrl)
+			},
+			token: func(t *testing.T) string {
+				token, err := GenerateToken(testKey, "", []string{sms.TplLoginCode}, time.Minute)
+				require.NoError(t, err)
+				return token
+			},
+			wantErr: ErrInvalidToken,
+		},
+		{
+			name: "不能使用这个模板",
+			mock: func(ctrl *gomock.Controller) (sms.Service, ratelimit.Limiter) {
+				return smsmocks.NewMockService(ctrl), ratelimitmocks.NewMockLimiter(ctrl)
+			},
+			token: func(t *testing.T) string {
+				token, err := GenerateToken(testKey, "alert-service", []string{sms.TplSecurityAlert}, time.Minute)
+				require.NoError(t, err)
+				return token
+			},
+			wantCaller: "alert-service",
+			wantErr:    ErrTplNotAllowed,
+		},
+		{
+			name: "超过了配额",
+			mock: func(ctrl *gomock.Controller) (sms.Service, ratelimit.Limiter) {
+				limiter := ratelimitmocks.NewMockLimiter(ctrl)
+				limiter.EXPECT().Limit(gomock.Any(), "sms:caller:user-service").Return(true, nil)
+				return smsmocks.NewMockService(ctrl), limiter
+			},
+			token: func(t *testing.T) string {
+				token, err := GenerateToken(testKey, "user-service", []string{sms.TplLoginCode}, time.Minute)
+				require.NoError(t, err)
+				return token
+			},
+			wantCaller: "user-service",
+			wantErr:    ErrQuotaExceeded,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			svc, limiter := tc.mock(ctrl)
+			s := NewService(svc, testKey, limiter)
+			ctx := WithToken(context.Background(), tc.token(t))
+			err := s.Send(ctx, sms.TplLoginCode, []string{"123456"}, "13800000000")
+			if tc.wantErr == nil {
+				assert.NoError(t, err)
+				return
+			}
+			var authErr *AuthError
+			require.True(t, errors.As(err, &authErr))
+			assert.ErrorIs(t, err, tc.wantErr)
+			assert.Equal(t, tc.wantCaller, authErr.Caller)
+			assert.Equal(t, sms.TplLoginCode, authErr.TplId)
+		})
+	}
+}
+
+// signToken 用 testKey 签发可以使用登录验证码模板的 token
+func signToken(t *testing.T, rc jwt.RegisteredClaims) string {
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, Claims{
+		RegisteredClaims: rc,
+		Caller:           "user-service",
+		Tpls:             []string{sms.TplLoginCode},
+	}).SignedString(testKey)
+	require.NoError(t, err)
+	return token
+}
+
+func TestService_SendLimiterError(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	limiter := ratelimitmocks.NewMockLimiter(ctrl)
+	limiter.EXPECT().Limit(gomock.Any(), "sms:caller:user-service").Return(false, errors.New("redis 崩了"))
+	s := NewService(smsmocks.NewMockService(ctrl), testKey, limiter)
+	token, err := GenerateToken(testKey, "user-service", []string{sms.TplLoginCode}, time.Minute)
+	require.NoError(t, err)
+	err = s.Send(WithToken(context.Background(), token), sms.TplLoginCode, []string{"123456"}, "13800000000")
+	// 限流器出错不是调用方的问题，不返回 AuthError
+	var authErr *AuthError
+	assert.False(t, errors.As(err, &authErr))
+	assert.EqualError(t, err, "短信服务判断调用方的配额出现问题, redis 崩了")
+}
+
+func TestService_SendQuotaPerNumber(t *testing.T) {
+	testCases := []struct {
+		name string
+		// allowed 配额还剩几次
+		allowed int
+		wantErr error
+	}{
+		{
+			name:    "每个号码扣一次配额",
+			allowed: 3,
+		},
+		{
+			name:    "配额不够发给所有号码",
+			allowed: 2,
+			wantErr: ErrQuotaExceeded,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			limiter := ratelimitmocks.NewMockLimiter(ctrl)
+			limiter.EXPECT().Limit(gomock.Any(), "sms:caller:user-service").
+				Times(tc.allowed).Return(false, nil)
+			svc := smsmocks.NewMockService(ctrl)
+			if tc.wantErr == nil {
+				svc.EXPECT().Send(gomock.Any(), sms.TplLoginCode, []string{"123456"},
+					"13800000000", "13900000000", "13700000000").Return(nil)
+			} else {
+				limiter.EXPECT().Limit(gomock.Any(), "sms:caller:user-service").Return(true, nil)
+			}
+			s := NewService(svc, testKey, limiter)
+			token, err := GenerateToken(testKey, "user-service", []string{sms.TplLoginCode}, time.Minute)
+			require.NoError(t, err)
+			err = s.Send(WithToken(context.Background(), token), sms.TplLoginCode, []string{"123456"},
+				"13800000000", "13900000000", "13700000000")
+			if tc.wantErr == nil {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorIs(t, err, tc.wantErr)
+		})
+	}
+}
@@ -1,9 +1,61 @@
 package failover
 
-import "webook/webook/internal/service/sms"
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"webook/webook/internal/service/sms"
+)
 
+var errAllFailed = errors.New("所有短信服务商都发送失败")
+
+// SMSService 轮询，每次从下一个服务商开始，失败了就换下一个
+// 起点是轮换的，所以负载会均匀分到每一个服务商
+// 部分号码发送成功的时候，下一个服务商只发失败的号码
 type SMSService struct {
 	// svcs 多个短信服务商，这里可以是使用了限流的短信服务
 	svcs []sms.Service
 	idx  uint64
 }
+
+func NewSMSService(svcs []sms.Service) *SMSService {
+	return &SMSService{svcs: svcs}
+}
+
+func (s *SMSService) Send(ctx context.Context, tplId string, args []string, numbers ...string) error {
+	idx := atomic.AddUint64(&s.idx, 1)
+	length := uint64(len(s.svcs))
+	// sent 已经有号码发出去了
+	sent := false
+	// invalid 服务商说不对的号码，不会再发
+	var invalid []string
+	for i := uint64(0); i < length; i++ {
+		err := s.svcs[(idx+i)%length].Send(ctx, tplId, args, numbers...)
+		if err == nil {
+			return nil
+		}
+		var pe *sms.PartialError
+		if errors.As(err, &pe) {
+			invalid = append(invalid, pe.Invalid...)
+			sent = true
+			if len(pe.Numbers) == 0 {
+				// 剩下的号码都不对，换服务商也没用
+				return s.wrap(sent, nil, invalid, pe.Err)
+			}
+			numbers = pe.Numbers
+		}
+		// 调用者已经超时或者取消了，换谁都没用
+		if ctx.Err() != nil {
+			return s.wrap(sent, numbers, invalid, ctx.Err())
+		}
+	}
+	return s.wrap(sent, numbers, invalid, errAllFailed)
+}
+
+// wrap 已经有号码发出去了，告诉调用者只有 numbers 失败了
+func (s *SMSService) wrap(sent bool, numbers []string, invalid []string, err error) error {
+	if sent {
+		return &sms.PartialError{Numbers: numbers, Invalid: invalid, Err: err}
+	}
+	return err
+}
@@ -0,0 +1,146 @@
+package failover
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+	"testing"
+	"webook/webook/internal/service/sms"
+	smsmocks "webook/webook/internal/service/sms/mocks"
+)
+
+func TestSMSService_Send(t *testing.T) {
+	testCases := []struct {
+		name string
+		mock func(ctrl *gomock.Controller) []sms.Service
+		// idx 发送之前的轮询位置
+		idx uint64
+		ctx func() context.Context
+		// numbers 不设置的话只发给 13800000000
+		numbers []string
+		wantErr error
+	}{
+		{
+			name: "从下一个服务商开始，一次成功",
+			mock: func(ctrl *gomock.Controller) []sms.Service {
+				svc0 := smsmocks.NewMockService(ctrl)
+				svc1 := smsmocks.NewMockService(ctrl)
+				svc1.EXPECT().Send(gomock.Any(), "tpl", []string{"123456"}, "13800000000").Return(nil)
+				return []sms.Service{svc0, svc1}
+			},
+			ctx: context.Background,
+		},
+		{
+			name: "失败之后换下一个",
+			mock: func(ctrl *gomock.Controller) []sms.Service {
+				svc0 := smsmocks.NewMockService(ctrl)
+				svc1 := smsmocks.NewMockService(ctrl)
+				svc1.EXPECT().Send(gomock.Any(), "tpl", []string{"123456"}, "13800000000").
+					Return(errors.New("服务商出错"))
+				svc0.EXPECT().Send(gomock.Any(), "tpl", []string{"123456"}, "13800000000").Return(nil)
+				return []sms.Service{svc0, svc1}
+			},
+			ctx: context.Background,
+		},
+		{
+			name: "轮询位置到了最大值，也会尝试所有服务商",
+			mock: func(ctrl *gomock.Controller) []sms.Service {
+				svc0 := smsmocks.NewMockService(ctrl)
+				svc1 := smsmocks.NewMockService(ctrl)
+				svc1.EXPECT().Send(gomock.Any(), "tpl", []string{"123456"}, "13800000000").
+					Return(errors.New("服务商出错"))
+				svc0.EXPECT().Send(gomock.Any(), "tpl", []string{"123456"}, "13800000000").Return(nil)
+				return []sms.Service{svc0, svc1}
+			},
+			idx: ^uint64(0) - 1,
+			ctx: context.Background,
+		},
+		{
+			name: "全部失败",
+			mock: func(ctrl *gomock.Controller) []sms.Service {
+				svc0 := smsmocks.NewMockService(ctrl)
+				svc1 := smsmocks.NewMockService(ctrl)
+				svc0.EXPECT().Send(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+					Return(errors.New("服务商出错"))
+				svc1.EXPECT().Send(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+					Return(errors.New("服务商出错"))
+				return []sms.Service{svc0, svc1}
+			},
+			ctx:     context.Background,
+			wantErr: errAllFailed,
+		},
+		{
+			name: "调用者已经取消了，不再尝试",
+			mock: func(ctrl *gomock.Controller) []sms.Service {
+				svc0 := smsmocks.NewMockService(ctrl)
+				svc1 := smsmocks.NewMockService(ctrl)
+				svc1.EXPECT().Send(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+					Return(context.Canceled)
+				return []sms.Service{svc0, svc1}
+			},
+			ctx: func() context.Context {
+				ctx, cancel := context.WithCancel(context.Background())
+				cancel()
+				return ctx
+			},
+			wantErr: context.Canceled,
+		},
+		{
+			name: "部分号码失败，下一个服务商只发失败的号码",
+			mock: func(ctrl *gomock.Controller) []sms.Service {
+				svc0 := smsmocks.NewMockService(ctrl)
+				svc1 := smsmocks.NewMockService(ctrl)
+				svc1.EXPECT().Send(gomock.Any(), "tpl", []string{"123456"}, "13800000000", "13900000000").
+					Return(&sms.PartialError{Numbers: []string{"13900000000"}, Err: errors.New("服务商出错")})
+				svc0.EXPECT().Send(gomock.Any(), "tpl", []string{"123456"}, "13900000000").Return(nil)
+				return []sms.Service{svc0, svc1}
+			},
+			ctx:     context.Background,
+			numbers: []string{"13800000000", "13900000000"},
+		},
+		{
+			name: "部分号码失败，剩下的全部失败",
+			mock: func(ctrl *gomock.Controller) []sms.Service {
+				svc0 := smsmocks.NewMockService(ctrl)
+				svc1 := smsmocks.NewMockService(ctrl)
+				svc1.EXPECT().Send(gomock.Any(), "tpl", []string{"123456"}, "13800000000", "13900000000").
+					Return(&sms.PartialError{Numbers: []string{"13900000000"}, Err: errors.New("服务商出错")})
+				svc0.EXPECT().Send(gomock.Any(), "tpl", []string{"123456"}, "13900000000").
+					Return(errors.New("服务商出错"))
+				return []sms.Service{svc0, svc1}
+			},
+			ctx:     context.Background,
+			numbers: []string{"13800000000", "13900000000"},
+			wantErr: &sms.PartialError{Numbers: []string{"13900000000"}, Err: errAllFailed},
+		},
+		{
+			name: "部分号码失败，剩下的号码都不对，不再换服务商",
+			mock: func(ctrl *gomock.Controller) []sms.Service {
+				svc0 := smsmocks.NewMockService(ctrl)
+				svc1 := smsmocks.NewMockService(ctrl)
+				svc1.EXPECT().Send(gomock.Any(), "tpl", []string{"123456"}, "13800000000", "13900000000").
+					Return(&sms.PartialError{Invalid: []string{"13900000000"}, Err: sms.ErrInvalidNumber})
+				return []sms.Service{svc0, svc1}
+			},
+			ctx:     context.Background,
+			numbers: []string{"13800000000", "13900000000"},
+			wantErr: &sms.PartialError{Invalid: []string{"13900000000"}, Err: sms.ErrInvalidNumber},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			svc := NewSMSService(tc.mock(ctrl))
+			svc.idx = tc.idx
+			numbers := tc.numbers
+			if numbers == nil {
+				numbers = []string{"13800000000"}
+			}
+			err := svc.Send(tc.ctx(), "tpl", []string{"123456"}, numbers...)
+			assert.Equal(t, tc.wantErr, err)
+		})
+	}
+}
@@ -0,0 +1,44 @@
+package failover
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"webook/webook/internal/service/sms"
+)
+
+// TimeoutFailoverSMSService 一直用同一个服务商，连续超时 threshold 次之后切换到下一个
+// 成功一次就重新计数；其它错误可能是号码之类的问题，不算服务商不可用
+type TimeoutFailoverSMSService struct {
+	svcs []sms.Service
+	// idx 当前使用的服务商
+	idx int32
+	// cnt 当前服务商连续超时的次数
+	cnt       int32
+	threshold int32
+}
+
+func NewTimeoutFailoverSMSService(svcs []sms.Service, threshold int32) *TimeoutFailoverSMSService {
+	return &TimeoutFailoverSMSService{svcs: svcs, threshold: threshold}
+}
+
+func (s *TimeoutFailoverSMSService) Send(ctx context.Context, tplId string, args []string, numbers ...string) error {
+	idx := atomic.LoadInt32(&s.idx)
+	cnt := atomic.LoadInt32(&s.cnt)
+	if cnt >= s.threshold {
+		next := (idx + 1) % int32(len(s.svcs))
+		// 并发的时候只有一个人能切换成功，其他人直接用切换之后的
+		if atomic.CompareAndSwapInt32(&s.idx, idx, next) {
+			atomic.StoreInt32(&s.cnt, 0)
+		}
+		idx = atomic.LoadInt32(&s.idx)
+	}
+	err := s.svcs[idx].Send(ctx, tplId, args, numbers...)
+	switch {
+	case err == nil:
+		atomic.StoreInt32(&s.cnt, 0)
+	case errors.Is(err, context.DeadlineExceeded):
+		atomic.AddInt32(&s.cnt, 1)
+	}
+	return err
+}
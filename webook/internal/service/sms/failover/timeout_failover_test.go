@@ -0,0 +1,102 @@
+package failover
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+	"testing"
+	"webook/webook/internal/service/sms"
+	smsmocks "webook/webook/internal/service/sms/mocks"
+)
+
+func TestTimeoutFailoverSMSService_Send(t *testing.T) {
+	testCases := []struct {
+		name string
+		mock func(ctrl *gomock.Controller) []sms.Service
+		// 发送之前的状态
+		idx int32
+		cnt int32
+		// 发送之后的状态
+		wantIdx int32
+		wantCnt int32
+		wantErr error
+	}{
+		{
+			name: "没有达到阈值，成功之后重新计数",
+			mock: func(ctrl *gomock.Controller) []sms.Service {
+				svc0 := smsmocks.NewMockService(ctrl)
+				svc0.EXPECT().Send(gomock.Any(), "tpl", []string{"123456"}, "13800000000").Return(nil)
+				return []sms.Service{svc0, smsmocks.NewMockService(ctrl)}
+			},
+			cnt:     2,
+			wantIdx: 0,
+			wantCnt: 0,
+		},
+		{
+			name: "超时，计数加一",
+			mock: func(ctrl *gomock.Controller) []sms.Service {
+				svc0 := smsmocks.NewMockService(ctrl)
+				svc0.EXPECT().Send(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+					Return(context.DeadlineExceeded)
+				return []sms.Service{svc0, smsmocks.NewMockService(ctrl)}
+			},
+			cnt:     1,
+			wantIdx: 0,
+			wantCnt: 2,
+			wantErr: context.DeadlineExceeded,
+		},
+		{
+			name: "其它错误不计数",
+			mock: func(ctrl *gomock.Controller) []sms.Service {
+				svc0 := smsmocks.NewMockService(ctrl)
+				svc0.EXPECT().Send(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+					Return(errors.New("号码有误"))
+				return []sms.Service{svc0, smsmocks.NewMockService(ctrl)}
+			},
+			cnt:     1,
+			wantIdx: 0,
+			wantCnt: 1,
+			wantErr: errors.New("号码有误"),
+		},
+		{
+			name: "达到阈值，切换到下一个",
+			mock: func(ctrl *gomock.Controller) []sms.Service {
+				svc1 := smsmocks.NewMockService(ctrl)
+				svc1.EXPECT().Send(gomock.Any(), "tpl", []string{"123456"}, "13800000000").Return(nil)
+				return []sms.Service{smsmocks.NewMockService(ctrl), svc1}
+			},
+			cnt:     3,
+			wantIdx: 1,
+			wantCnt: 0,
+		},
+		{
+			name: "最后一个达到阈值，回到第一个，切换之后又超时",
+			mock: func(ctrl *gomock.Controller) []sms.Service {
+				svc0 := smsmocks.NewMockService(ctrl)
+				svc0.EXPECT().Send(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+					Return(context.DeadlineExceeded)
+				return []sms.Service{svc0, smsmocks.NewMockService(ctrl)}
+			},
+			idx:     1,
+			cnt:     3,
+			wantIdx: 0,
+			wantCnt: 1,
+			wantErr: context.DeadlineExceeded,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			svc := NewTimeoutFailoverSMSService(tc.mock(ctrl), 3)
+			svc.idx = tc.idx
+			svc.cnt = tc.cnt
+			err := svc.Send(context.Background(), "tpl", []string{"123456"}, "13800000000")
+			assert.Equal(t, tc.wantErr, err)
+			assert.Equal(t, tc.wantIdx, svc.idx)
+			assert.Equal(t, tc.wantCnt, svc.cnt)
+		})
+	}
+}
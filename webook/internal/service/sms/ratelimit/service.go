@@ -7,7 +7,8 @@ import (
 	"webook/webook/pkg/ginx/ratelimit"
 )
 
-var errLimited = fmt.Errorf("触发了限流")
+// ErrLimited 触发了限流，服务商本身没有问题，调用者可以稍后再试
+var ErrLimited = fmt.Errorf("触发了限流")
 
 type Service struct {
 	svc     sms.Service
@@ -30,7 +31,7 @@ func (s *Service) Send(ctx context.Context, templateID string, args []string, nu
 		return fmt.Errorf("短信服务判断是否限流出现问题, %w", err)
 	}
 	if limited {
-		return errLimited
+		return ErrLimited
 	}
 	return s.svc.Send(ctx, templateID, args, numbers...)
 }
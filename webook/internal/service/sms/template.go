@@ -0,0 +1,95 @@
+package sms
+
+import (
+	"errors"
+	"fmt"
+)
+
+// 业务上的短信模板，和服务商无关
+const (
+	// TplLoginCode 登录验证码，参数 code
+	TplLoginCode = "login_code"
+	// TplPasswordReset 重置密码验证码，参数 code
+	TplPasswordReset = "password_reset"
+	// TplSecurityAlert 安全提醒，例如异地登录，参数 time、location
+	TplSecurityAlert = "security_alert"
+)
+
+var (
+	// ErrUnknownTemplate 没有这个业务模板，或者服务商没有配置这个模板
+	ErrUnknownTemplate = errors.New("短信模板没有配置")
+	ErrTemplateArgs    = errors.New("短信模板的参数个数不对")
+)
+
+// Template 服务商的模板
+type Template struct {
+	// Id 服务商的模板 Id
+	Id string
+	// Params 服务商模板的参数，按照服务商模板里的顺序，值是业务模板的参数名
+	// 阿里云的模板变量是有名字的，变量名就是业务模板的参数名
+	Params []string
+	// Body 短信内容，只有没有模板功能的服务商才需要，{1}、{2} 按照 Params 的顺序替换
+	Body string
+}
+
+// TemplateDef 业务模板
+type TemplateDef struct {
+	Name string
+	// Args 参数名，调用方按照这个顺序传参数
+	Args []string
+	// Providers 服务商的名字对应服务商的模板
+	Providers map[string]Template
+}
+
+// TemplateRegistry 业务模板到各家服务商模板的映射，换服务商的时候业务代码不需要修改
+type TemplateRegistry struct {
+	defs map[string]TemplateDef
+}
+
+// NewTemplateRegistry 服务商模板用到了业务模板没有的参数会返回错误
+func NewTemplateRegistry(defs []TemplateDef) (*TemplateRegistry, error) {
+	res := &TemplateRegistry{defs: make(map[string]TemplateDef, len(defs))}
+	for _, def := range defs {
+		if _, ok := res.defs[def.Name]; ok {
+			return nil, fmt.Errorf("短信模板 %s 重复定义", def.Name)
+		}
+		args := make(map[string]struct{}, len(def.Args))
+		for _, arg := range def.Args {
+			args[arg] = struct{}{}
+		}
+		for provider, tpl := range def.Providers {
+			for _, param := range tpl.Params {
+				if _, ok := args[param]; !ok {
+					return nil, fmt.Errorf("短信模板 %s 在 %s 的参数 %s 没有定义", def.Name, provider, param)
+				}
+			}
+		}
+		res.defs[def.Name] = def
+	}
+	return res, nil
+}
+
+// Resolve 找到服务商的模板，参数按照服务商模板的顺序排列
+func (r *TemplateRegistry) Resolve(provider string, name string, args []string) (Template, []string, error) {
+	def, ok := r.defs[name]
+	if !ok {
+		return Template{}, nil, fmt.Errorf("%w %s", ErrUnknownTemplate, name)
+	}
+	if len(args) != len(def.Args) {
+		return Template{}, nil, fmt.Errorf("%w %s 需要 %d 个，传入了 %d 个",
+			ErrTemplateArgs, name, len(def.Args), len(args))
+	}
+	tpl, ok := def.Providers[provider]
+	if !ok {
+		return Template{}, nil, fmt.Errorf("%w %s %s", ErrUnknownTemplate, provider, name)
+	}
+	values := make(map[string]string, len(args))
+	for i, arg := range def.Args {
+		values[arg] = args[i]
+	}
+	res := make([]string, 0, len(tpl.Params))
+	for _, param := range tpl.Params {
+		res = append(res, values[param])
+	}
+	return tpl, res, nil
+}
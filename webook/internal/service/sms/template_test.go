@@ -0,0 +1,125 @@
+package sms
+
+import (
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestNewTemplateRegistry(t *testing.T) {
+	testCases := []struct {
+		name    string
+		defs    []TemplateDef
+		wantErr error
+	}{
+		{
+			name: "服务商模板的参数都定义了",
+			defs: []TemplateDef{
+				{
+					Name: TplSecurityAlert,
+					Args: []string{"time", "location"},
+					Providers: map[string]Template{
+						"tencent": {Id: "1", Params: []string{"location", "time"}},
+					},
+				},
+			},
+		},
+		{
+			name: "重复定义",
+			defs: []TemplateDef{
+				{Name: TplLoginCode, Args: []string{"code"}},
+				{Name: TplLoginCode, Args: []string{"code"}},
+			},
+			wantErr: errors.New("短信模板 login_code 重复定义"),
+		},
+		{
+			name: "服务商模板用了没有定义的参数",
+			defs: []TemplateDef{
+				{
+					Name: TplLoginCode,
+					Args: []string{"code"},
+					Providers: map[string]Template{
+						"aliyun": {Id: "SMS_1", Params: []string{"code", "minutes"}},
+					},
+				},
+			},
+			wantErr: errors.New("短信模板 login_code 在 aliyun 的参数 minutes 没有定义"),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := NewTemplateRegistry(tc.defs)
+			assert.Equal(t, tc.wantErr, err)
+		})
+	}
+}
+
+func TestTemplateRegistry_Resolve(t *testing.T) {
+	r, err := NewTemplateRegistry([]TemplateDef{
+		{
+			Name: TplSecurityAlert,
+			Args: []string{"time", "location"},
+			Providers: map[string]Template{
+				"tencent": {Id: "1921141", Params: []string{"time", "location"}},
+				"twilio":  {Id: "alert", Params: []string{"location", "time"}, Body: "{1} {2}"},
+			},
+		},
+	})
+	require.NoError(t, err)
+	testCases := []struct {
+		name     string
+		provider string
+		tplName  string
+		args     []string
+		wantTpl  Template
+		wantArgs []string
+		wantErr  error
+	}{
+		{
+			name:     "参数顺序一样",
+			provider: "tencent",
+			tplName:  TplSecurityAlert,
+			args:     []string{"12:00", "北京"},
+			wantTpl:  Template{Id: "1921141", Params: []string{"time", "location"}},
+			wantArgs: []string{"12:00", "北京"},
+		},
+		{
+			name:     "按照服务商模板的顺序排列参数",
+			provider: "twilio",
+			tplName:  TplSecurityAlert,
+			args:     []string{"12:00", "北京"},
+			wantTpl:  Template{Id: "alert", Params: []string{"location", "time"}, Body: "{1} {2}"},
+			wantArgs: []string{"北京", "12:00"},
+		},
+		{
+			name:     "没有这个业务模板",
+			provider: "tencent",
+			tplName:  TplLoginCode,
+			args:     []string{"123456"},
+			wantErr:  ErrUnknownTemplate,
+		},
+		{
+			name:     "服务商没有配置",
+			provider: "aliyun",
+			tplName:  TplSecurityAlert,
+			args:     []string{"12:00", "北京"},
+			wantErr:  ErrUnknownTemplate,
+		},
+		{
+			name:     "参数个数不对",
+			provider: "tencent",
+			tplName:  TplSecurityAlert,
+			args:     []string{"12:00"},
+			wantErr:  ErrTemplateArgs,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tpl, args, err := r.Resolve(tc.provider, tc.tplName, tc.args)
+			assert.ErrorIs(t, err, tc.wantErr)
+			assert.Equal(t, tc.wantTpl, tpl)
+			assert.Equal(t, tc.wantArgs, args)
+		})
+	}
+}
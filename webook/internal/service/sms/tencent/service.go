@@ -5,8 +5,15 @@ import (
 	"fmt"
 	"github.com/ecodeclub/ekit/slice"
 	sms "github.com/tencentcloud/tencentcloud-sdk-go/tencentcloud/sms/v20210111"
+	smssvc "webook/webook/internal/service/sms"
 )
 
+// provider 在 TemplateRegistry 里面的名字
+const provider = "tencent"
+
+// codeIncorrectPhoneNumber 手机号格式不对
+const codeIncorrectPhoneNumber = "InvalidParameterValue.IncorrectPhoneNumber"
+
 // SmsService 短信服务
 type SmsService struct {
 	// 应用ID
@@ -15,11 +22,14 @@ type SmsService struct {
 	signName *string
 	// 短信服务商
 	sp *sms.Client
+	// 业务模板对应的腾讯云模板
+	tpls *smssvc.TemplateRegistry
 }
 
-func NewSMSService(sp *sms.Client, appId string, signName string) *SmsService {
+func NewSMSService(sp *sms.Client, appId string, signName string, tpls *smssvc.TemplateRegistry) *SmsService {
 	return &SmsService{
-		sp: sp,
+		sp:   sp,
+		tpls: tpls,
 		// 转换为对应类型的指针类型
 		// 因为腾讯的API要求传的就是指针
 		appId:    &appId,
@@ -29,32 +39,59 @@ func NewSMSService(sp *sms.Client, appId string, signName string) *SmsService {
 }
 
 // Send 腾讯云发送短信
-// templateId 模板Id
+// templateId 业务模板的名字
 func (s *SmsService) Send(ctx context.Context, templateId string, args []string, numbers ...string) error {
+	tpl, vals, err := s.tpls.Resolve(provider, templateId, args)
+	if err != nil {
+		return err
+	}
 	req := sms.NewSendSmsRequest()
 	// 设置要求传入的参数
 	req.SmsSdkAppId = s.appId
 	req.SignName = s.signName
 	// 短信模板Id
-	req.TemplateId = &templateId
+	req.TemplateId = &tpl.Id
 	// 这下面也要转换成对应的指针
 	// 这个短信API设计的真够恶心的
 	req.PhoneNumberSet = s.toStringPtrSlice(numbers)
 	// 传给模板中参数的值，数量少要对应
-	req.TemplateParamSet = s.toStringPtrSlice(args)
+	req.TemplateParamSet = s.toStringPtrSlice(vals)
 	// 发送短信
 	resp, err := s.sp.SendSms(req)
 	if err != nil {
 		return err
 	}
-	// 一条短信一个number，有些手机发成功了，有些可能没有成功，需要逐个解析是否全部成功
-	for _, status := range resp.Response.SendStatusSet {
-		if status.Code == nil || *(status.Code) != "Ok" {
-			return fmt.Errorf("发送短信失败 %s %s ", *status.Code, *status.Message)
+	return s.result(numbers, resp.Response.SendStatusSet)
+}
+
+// result 一条短信一个number，有些手机发成功了，有些可能没有成功，需要逐个解析
+// 部分成功的时候返回 *smssvc.PartialError，只重试没有成功的号码
+func (s *SmsService) result(numbers []string, set []*sms.SendStatus) error {
+	failures := smssvc.NewFailures(len(numbers))
+	for i, status := range set {
+		code := s.value(status.Code)
+		if code == "Ok" {
+			continue
+		}
+		// 腾讯云返回的号码带国家码，没有返回的话按照顺序对应
+		number := s.value(status.PhoneNumber)
+		if number == "" && i < len(numbers) {
+			number = numbers[i]
+		}
+		if code == codeIncorrectPhoneNumber {
+			failures.Add(number, fmt.Errorf("发送短信失败 %s %w", s.value(status.Message), smssvc.ErrInvalidNumber))
+			continue
 		}
+		failures.Add(number, fmt.Errorf("发送短信失败 %s %s", code, s.value(status.Message)))
 	}
-	return nil
+	return failures.Err()
+}
 
+func (s *SmsService) value(p *string) string {
+	if p == nil {
+		return ""
+	}
+	return *p
 }
 
 func (s *SmsService) toStringPtrSlice(src []string) []*string {
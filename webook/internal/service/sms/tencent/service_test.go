@@ -0,0 +1,87 @@
+package tencent
+
+import (
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	sms "github.com/tencentcloud/tencentcloud-sdk-go/tencentcloud/sms/v20210111"
+	"testing"
+	smssvc "webook/webook/internal/service/sms"
+)
+
+func TestSmsService_result(t *testing.T) {
+	status := func(number string, code *string) *sms.SendStatus {
+		return &sms.SendStatus{PhoneNumber: &number, Code: code}
+	}
+	str := func(s string) *string {
+		return &s
+	}
+	numbers := []string{"+8613800000000", "+8613900000000"}
+	testCases := []struct {
+		name    string
+		set     []*sms.SendStatus
+		wantErr error
+	}{
+		{
+			name: "全部成功",
+			set: []*sms.SendStatus{
+				status("+8613800000000", str("Ok")),
+				status("+8613900000000", str("Ok")),
+			},
+		},
+		{
+			name: "全部失败，整个重试",
+			set: []*sms.SendStatus{
+				status("+8613800000000", str("LimitExceeded.PhoneNumberDailyLimit")),
+				status("+8613900000000", str("LimitExceeded.PhoneNumberDailyLimit")),
+			},
+			wantErr: errors.New("发送短信失败 LimitExceeded.PhoneNumberDailyLimit "),
+		},
+		{
+			name: "部分失败，没有 Code 和 Message 也不会 panic",
+			set: []*sms.SendStatus{
+				status("+8613800000000", str("Ok")),
+				status("+8613900000000", nil),
+			},
+			wantErr: &smssvc.PartialError{
+				Numbers: []string{"+8613900000000"},
+				Err:     errors.Join(errors.New("发送短信失败  ")),
+			},
+		},
+		{
+			name: "号码不对的不会重试",
+			set: []*sms.SendStatus{
+				status("+8613800000000", str(codeIncorrectPhoneNumber)),
+				status("+8613900000000", str("Ok")),
+			},
+			wantErr: &smssvc.PartialError{
+				Invalid: []string{"+8613800000000"},
+				Err:     smssvc.ErrInvalidNumber,
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			s := &SmsService{}
+			err := s.result(numbers, tc.set)
+			if tc.wantErr == nil {
+				assert.NoError(t, err)
+				return
+			}
+			var wantPe *smssvc.PartialError
+			if !errors.As(tc.wantErr, &wantPe) {
+				assert.Equal(t, tc.wantErr, err)
+				return
+			}
+			var pe *smssvc.PartialError
+			require.True(t, errors.As(err, &pe))
+			assert.Equal(t, wantPe.Numbers, pe.Numbers)
+			assert.Equal(t, wantPe.Invalid, pe.Invalid)
+			if errors.Is(wantPe.Err, smssvc.ErrInvalidNumber) {
+				assert.ErrorIs(t, pe.Err, smssvc.ErrInvalidNumber)
+			} else {
+				assert.Equal(t, wantPe.Err, pe.Err)
+			}
+		})
+	}
+}
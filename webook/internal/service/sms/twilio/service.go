@@ -0,0 +1,109 @@
+package twilio
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"webook/webook/internal/service/sms"
+)
+
+type Config struct {
+	// BaseURL 一般是 https://api.twilio.com，也可以是兼容 Twilio 接口的网关
+	BaseURL    string
+	AccountSid string
+	AuthToken  string
+	// From 发送短信的号码
+	From string
+}
+
+// provider 在 TemplateRegistry 里面的名字
+const provider = "twilio"
+
+// 号码不对的错误码，见 https://www.twilio.com/docs/api/errors
+const (
+	codeInvalidToNumber = 21211
+	codeNotMobileNumber = 21614
+)
+
+// SmsService Twilio 风格的 HTTP 短信接口
+type SmsService struct {
+	client *http.Client
+	cfg    Config
+	tpls   *sms.TemplateRegistry
+}
+
+func NewSMSService(client *http.Client, cfg Config, tpls *sms.TemplateRegistry) *SmsService {
+	return &SmsService{
+		client: client,
+		cfg:    cfg,
+		tpls:   tpls,
+	}
+}
+
+type errResponse struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// Send Twilio 一次只能发给一个号码，有号码失败了也会继续发剩下的
+// 失败的号码由 sms.Failures 汇总，部分失败返回 *sms.PartialError
+// Twilio 没有模板，短信内容是模板的 Body，{1}、{2} 按顺序替换成参数
+func (s *SmsService) Send(ctx context.Context, tplId string, args []string, numbers ...string) error {
+	tpl, vals, err := s.tpls.Resolve(provider, tplId, args)
+	if err != nil {
+		return err
+	}
+	body := s.render(tpl.Body, vals)
+	failures := sms.NewFailures(len(numbers))
+	for _, number := range numbers {
+		err := s.sendOne(ctx, number, body)
+		if err != nil {
+			failures.Add(number, err)
+		}
+	}
+	return failures.Err()
+}
+
+func (s *SmsService) sendOne(ctx context.Context, number string, body string) error {
+	form := url.Values{}
+	form.Set("To", number)
+	form.Set("From", s.cfg.From)
+	form.Set("Body", body)
+	api := fmt.Sprintf("%s/2010-04-01/Accounts/%s/Messages.json",
+		strings.TrimSuffix(s.cfg.BaseURL, "/"), url.PathEscape(s.cfg.AccountSid))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, api, strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	req.SetBasicAuth(s.cfg.AccountSid, s.cfg.AuthToken)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return nil
+	}
+	var res errResponse
+	err = json.NewDecoder(resp.Body).Decode(&res)
+	if err != nil {
+		return fmt.Errorf("发送短信失败 %d", resp.StatusCode)
+	}
+	if res.Code == codeInvalidToNumber || res.Code == codeNotMobileNumber {
+		return fmt.Errorf("发送短信失败 %d %s %w", res.Code, res.Message, sms.ErrInvalidNumber)
+	}
+	return fmt.Errorf("发送短信失败 %d %d %s", resp.StatusCode, res.Code, res.Message)
+}
+
+func (s *SmsService) render(tpl string, args []string) string {
+	oldnew := make([]string, 0, len(args)*2)
+	for i, arg := range args {
+		oldnew = append(oldnew, "{"+strconv.Itoa(i+1)+"}", arg)
+	}
+	return strings.NewReplacer(oldnew...).Replace(tpl)
+}
@@ -0,0 +1,195 @@
+package twilio
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+	"webook/webook/internal/service/sms"
+)
+
+func TestSmsService_Send(t *testing.T) {
+	testCases := []struct {
+		name string
+		// handler 模拟 Twilio 的 Messages 接口
+		handler func(t *testing.T, numbers *[]string) http.HandlerFunc
+		tplId   string
+		args    []string
+		// wantNumbers 服务商收到的号码
+		wantNumbers []string
+		wantErr     error
+	}{
+		{
+			name: "每个号码发一次",
+			handler: func(t *testing.T, numbers *[]string) http.HandlerFunc {
+				return func(w http.ResponseWriter, r *http.Request) {
+					assert.Equal(t, http.MethodPost, r.Method)
+					assert.Equal(t, "/2010-04-01/Accounts/AC123/Messages.json", r.URL.Path)
+					user, pwd, ok := r.BasicAuth()
+					assert.True(t, ok)
+					assert.Equal(t, "AC123", user)
+					assert.Equal(t, "token", pwd)
+					require.NoError(t, r.ParseForm())
+					assert.Equal(t, "+15005550006", r.PostForm.Get("From"))
+					assert.Equal(t, "验证码 123456，5 分钟内有效", r.PostForm.Get("Body"))
+					*numbers = append(*numbers, r.PostForm.Get("To"))
+					w.WriteHeader(http.StatusCreated)
+					_, _ = w.Write([]byte(`{"sid":"SM1","status":"queued"}`))
+				}
+			},
+			tplId:       "login",
+			args:        []string{"5", "123456"},
+			wantNumbers: []string{"+8613800000000", "+8613900000000"},
+		},
+		{
+			name: "服务商返回错误",
+			handler: func(t *testing.T, numbers *[]string) http.HandlerFunc {
+				return func(w http.ResponseWriter, r *http.Request) {
+					require.NoError(t, r.ParseForm())
+					*numbers = append(*numbers, r.PostForm.Get("To"))
+					w.WriteHeader(http.StatusUnauthorized)
+					_, _ = w.Write([]byte(`{"code":20003,"message":"Authenticate","status":401}`))
+				}
+			},
+			tplId:       "login",
+			args:        []string{"5", "123456"},
+			wantNumbers: []string{"+8613800000000", "+8613900000000"},
+			wantErr:     errors.New("发送短信失败 401 20003 Authenticate"),
+		},
+		{
+			name: "号码不对",
+			handler: func(t *testing.T, numbers *[]string) http.HandlerFunc {
+				return func(w http.ResponseWriter, r *http.Request) {
+					require.NoError(t, r.ParseForm())
+					*numbers = append(*numbers, r.PostForm.Get("To"))
+					w.WriteHeader(http.StatusBadRequest)
+					_, _ = w.Write([]byte(`{"code":21211,"message":"Invalid 'To' Phone Number","status":400}`))
+				}
+			},
+			tplId:       "login",
+			args:        []string{"5", "123456"},
+			wantNumbers: []string{"+8613800000000", "+8613900000000"},
+			wantErr:     sms.ErrInvalidNumber,
+		},
+		{
+			name: "部分号码失败，继续发剩下的号码",
+			handler: func(t *testing.T, numbers *[]string) http.HandlerFunc {
+				return func(w http.ResponseWriter, r *http.Request) {
+					require.NoError(t, r.ParseForm())
+					to := r.PostForm.Get("To")
+					*numbers = append(*numbers, to)
+					if to == "+8613800000000" {
+						w.WriteHeader(http.StatusServiceUnavailable)
+						_, _ = w.Write([]byte(`{"code":20503,"message":"Service Unavailable","status":503}`))
+						return
+					}
+					w.WriteHeader(http.StatusCreated)
+					_, _ = w.Write([]byte(`{"sid":"SM1","status":"queued"}`))
+				}
+			},
+			tplId:       "login",
+			args:        []string{"5", "123456"},
+			wantNumbers: []string{"+8613800000000", "+8613900000000"},
+			wantErr: &sms.PartialError{
+				Numbers: []string{"+8613800000000"},
+				Err:     errors.Join(errors.New("发送短信失败 503 20503 Service Unavailable")),
+			},
+		},
+		{
+			name: "服务商返回的不是 JSON",
+			handler: func(t *testing.T, numbers *[]string) http.HandlerFunc {
+				return func(w http.ResponseWriter, r *http.Request) {
+					require.NoError(t, r.ParseForm())
+					*numbers = append(*numbers, r.PostForm.Get("To"))
+					w.WriteHeader(http.StatusBadGateway)
+					_, _ = w.Write([]byte(`<html>bad gateway</html>`))
+				}
+			},
+			tplId:       "login",
+			args:        []string{"5", "123456"},
+			wantNumbers: []string{"+8613800000000", "+8613900000000"},
+			wantErr:     errors.New("发送短信失败 502"),
+		},
+		{
+			name: "模板没有配置",
+			handler: func(t *testing.T, numbers *[]string) http.HandlerFunc {
+				return func(w http.ResponseWriter, r *http.Request) {
+					t.Fatal("不应该调用服务商")
+				}
+			},
+			tplId:   "reset",
+			args:    []string{"123456"},
+			wantErr: sms.ErrUnknownTemplate,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var numbers []string
+			server := httptest.NewServer(tc.handler(t, &numbers))
+			defer server.Close()
+			svc := newTestService(t, http.DefaultClient, server.URL+"/")
+			err := svc.Send(context.Background(), tc.tplId, tc.args, "+8613800000000", "+8613900000000")
+			if errors.Is(tc.wantErr, sms.ErrUnknownTemplate) || errors.Is(tc.wantErr, sms.ErrInvalidNumber) {
+				assert.ErrorIs(t, err, tc.wantErr)
+			} else {
+				assert.Equal(t, tc.wantErr, err)
+			}
+			assert.Equal(t, tc.wantNumbers, numbers)
+		})
+	}
+}
+
+func TestSmsService_SendInvalidAndTimeout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, r.ParseForm())
+		switch r.PostForm.Get("To") {
+		case "+8613800000000":
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"code":21211,"message":"Invalid 'To' Phone Number","status":400}`))
+		case "+8613900000000":
+			// 超过客户端的超时时间
+			time.Sleep(time.Millisecond * 200)
+			w.WriteHeader(http.StatusCreated)
+		default:
+			w.WriteHeader(http.StatusCreated)
+		}
+	}))
+	defer server.Close()
+	svc := newTestService(t, &http.Client{Timeout: time.Millisecond * 50}, server.URL)
+	err := svc.Send(context.Background(), "login", []string{"5", "123456"},
+		"+8613800000000", "+8613900000000", "+8613700000000")
+	var pe *sms.PartialError
+	require.True(t, errors.As(err, &pe))
+	// 超时的号码可以重试，号码不对的不能
+	assert.Equal(t, []string{"+8613900000000"}, pe.Numbers)
+	assert.Equal(t, []string{"+8613800000000"}, pe.Invalid)
+	assert.False(t, errors.Is(pe.Err, sms.ErrInvalidNumber))
+}
+
+func newTestService(t *testing.T, client *http.Client, baseURL string) *SmsService {
+	tpls, err := sms.NewTemplateRegistry([]sms.TemplateDef{
+		{
+			Name: "login",
+			// 参数顺序和短信内容里的不一样
+			Args: []string{"minutes", "code"},
+			Providers: map[string]sms.Template{
+				"twilio": {
+					Id:     "login",
+					Params: []string{"code", "minutes"},
+					Body:   "验证码 {1}，{2} 分钟内有效",
+				},
+			},
+		},
+	})
+	require.NoError(t, err)
+	return NewSMSService(client, Config{
+		BaseURL:    baseURL,
+		AccountSid: "AC123",
+		AuthToken:  "token",
+		From:       "+15005550006",
+	}, tpls)
+}
@@ -1,12 +1,99 @@
 package sms
 
-import "context"
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+//go:generate mockgen -source=./types.go -package=smsmocks -destination=./mocks/sms.mock.go
+
+// ErrInvalidNumber 服务商明确说号码不对，重试也不会成功
+var ErrInvalidNumber = errors.New("手机号码不对")
+
+// PartialError 一部分号码已经发出去了
+// 重试的时候只能发给 Numbers，不然已经收到的号码会再收到一次
+// Invalid 是号码不对的号码，重试也不会成功，不在 Numbers 里面
+// Err 是 Numbers 失败的原因，没有可以重试的号码的时候才是 ErrInvalidNumber
+type PartialError struct {
+	Numbers []string
+	Invalid []string
+	Err     error
+}
+
+func (e *PartialError) Error() string {
+	return fmt.Sprintf("部分号码发送失败 %v，号码不对 %v: %v", e.Numbers, e.Invalid, e.Err)
+}
+
+// Failures 服务商按号码返回结果的时候，用来汇总发送失败的号码
+type Failures struct {
+	total      int
+	numbers    []string
+	errs       []error
+	invalid    []string
+	invalidErr error
+}
+
+// NewFailures total 是这次发送的号码数量
+func NewFailures(total int) *Failures {
+	return &Failures{total: total}
+}
+
+// Add number 发送失败了，err 是 ErrInvalidNumber 的话这个号码不会再重试
+func (f *Failures) Add(number string, err error) {
+	if errors.Is(err, ErrInvalidNumber) {
+		f.invalid = append(f.invalid, number)
+		if f.invalidErr == nil {
+			f.invalidErr = err
+		}
+		return
+	}
+	f.numbers = append(f.numbers, number)
+	f.errs = append(f.errs, err)
+}
+
+// Err 没有失败返回 nil；所有号码都因为同一类原因失败，返回第一个错误，
+// 调用者整个重试或者整个放弃都不会重复发送；其它情况返回 *PartialError
+func (f *Failures) Err() error {
+	switch {
+	case len(f.numbers) == 0 && len(f.invalid) == 0:
+		return nil
+	case len(f.numbers) == f.total:
+		return f.errs[0]
+	case len(f.invalid) == f.total:
+		return f.invalidErr
+	case len(f.numbers) == 0:
+		return &PartialError{Invalid: f.invalid, Err: f.invalidErr}
+	default:
+		// 号码不对的错误不放进去，不然调用者会认为 Numbers 重试也不会成功
+		return &PartialError{Numbers: f.numbers, Invalid: f.invalid, Err: errors.Join(f.errs...)}
+	}
+}
+
+func (e *PartialError) Unwrap() error {
+	return e.Err
+}
 
 // Service 短信服务
 type Service interface {
 	// Send
-	// tplId 模板Id
-	// args 模板的占位符参数
+	// tplId 业务模板的名字，例如 TplLoginCode，由实现通过 TemplateRegistry 找到服务商的模板
+	// args 模板的参数，按照业务模板定义的顺序
 	// numbers 发送的号码
+	// 只有一部分号码发送成功的时候返回 *PartialError
 	Send(ctx context.Context, tplId string, args []string, numbers ...string) error
 }
+
+type expireAtKey struct{}
+
+// WithExpireAt 短信在 t 之后就没有意义了，例如验证码过期了，异步发送的时候不会再发
+func WithExpireAt(ctx context.Context, t time.Time) context.Context {
+	return context.WithValue(ctx, expireAtKey{}, t)
+}
+
+// ExpireAt 没有设置过期时间的短信一直有效
+func ExpireAt(ctx context.Context) (time.Time, bool) {
+	t, ok := ctx.Value(expireAtKey{}).(time.Time)
+	return t, ok
+}
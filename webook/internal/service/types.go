@@ -2,7 +2,9 @@ package service
 
 import (
 	"context"
+	"time"
 	"webook/webook/internal/domain"
+	"webook/webook/pkg/diff"
 )
 
 //go:generate mockgen -source=./types.go -package=svcmocks -destination=./mocks/service.mock.go
@@ -25,7 +27,182 @@ type CodeService interface {
 type ArticleService interface {
 	// Save 保存文章，并返回文章ID
 	Save(ctx context.Context, art domain.Article) (int64, error)
+	// Publish 发表文章，包含违禁内容的时候返回 *SensitiveWordsError
+	// 需要人工审核的时候文章会保存到制作库，返回文章 ID 和 ErrPendingReview
 	Publish(ctx context.Context, art domain.Article) (int64, error)
 	PublishV1(ctx context.Context, art domain.Article) (int64, error)
+	// SchedulePublish 保存文章，并且在 art.PublishAt 定时发表，审核的规则和 Publish 一样
+	SchedulePublish(ctx context.Context, art domain.Article) (int64, error)
+	// CancelSchedule 取消定时发表，文章回到未发表的状态
+	CancelSchedule(ctx context.Context, id int64, uid int64) error
+	// Reschedule 修改定时发表的时间
+	Reschedule(ctx context.Context, id int64, uid int64, publishAt time.Time) error
 	Withdraw(ctx context.Context, art domain.Article) error
+	// List 创作者查看自己的文章列表，按照更新时间倒序
+	List(ctx context.Context, uid int64, offset int, limit int) ([]domain.Article, error)
+	// ListByCursor 使用上一页最后一篇文章的 (utime, id) 作为游标
+	ListByCursor(ctx context.Context, uid int64, utime time.Time, id int64, limit int) ([]domain.Article, error)
+	GetById(ctx context.Context, id int64) (domain.Article, error)
+	// GetPublishedById 读者查看已发表的文章
+	GetPublishedById(ctx context.Context, id int64) (domain.Article, error)
+	// ListPub 读者查看已发表的文章列表
+	ListPub(ctx context.Context, offset int, limit int) ([]domain.Article, error)
+	// ListPubByTag 读者查看带有某个标签的文章，标签会先规范化
+	ListPubByTag(ctx context.Context, tag string, offset int, limit int) ([]domain.Article, error)
+	// ListTags 标签云，已发表文章最多的 limit 个标签
+	ListTags(ctx context.Context, limit int) ([]domain.Tag, error)
+	// ListRevisions 作者查看文章的历史版本，按照时间倒序
+	ListRevisions(ctx context.Context, artId int64, uid int64, offset int, limit int) ([]domain.ArticleRevision, error)
+	// DiffRevisions 比较两个历史版本，返回从 from 到 to 的行级别差异
+	DiffRevisions(ctx context.Context, artId int64, uid int64, from int64, to int64) ([]diff.Line, error)
+	// RestoreRevision 把历史版本恢复为草稿
+	RestoreRevision(ctx context.Context, artId int64, uid int64, revId int64) error
+	// Delete 把文章放进回收站，读者和作者都看不到了
+	Delete(ctx context.Context, id int64, uid int64) error
+	// ListRecycleBin 作者查看回收站里还可以恢复的文章，按照删除时间倒序
+	ListRecycleBin(ctx context.Context, uid int64, offset int, limit int) ([]domain.Article, error)
+	// RestoreFromRecycleBin 从回收站恢复文章，恢复之后还是删除之前的状态
+	RestoreFromRecycleBin(ctx context.Context, id int64, uid int64) error
+	// PurgeRecycleBin 彻底删除一批回收站里过期的文章，返回删除的篇数
+	PurgeRecycleBin(ctx context.Context, limit int) (int, error)
+	// ApproveReview 审核通过，把等待审核的文章发表出去，返回发表之后的文章
+	ApproveReview(ctx context.Context, id int64) (domain.Article, error)
+	// RejectReview 驳回，文章回到未发表的状态，返回驳回之前的文章
+	RejectReview(ctx context.Context, id int64) (domain.Article, error)
+}
+
+// ArticleReviewService 文章的人工审核，管理员处理等待审核的文章
+type ArticleReviewService interface {
+	// ListPending 等待审核的文章，先提交的排在前面
+	ListPending(ctx context.Context, offset int, limit int) ([]domain.Article, error)
+	Approve(ctx context.Context, artId int64, reviewer int64) error
+	// Reject 驳回，reason 会展示给作者
+	Reject(ctx context.Context, artId int64, reviewer int64, reason string) error
+	// GetLatest 作者查看自己的文章最近一次的审核结果，没有审核过返回 ErrReviewNotFound
+	GetLatest(ctx context.Context, artId int64, uid int64) (domain.ArticleReview, error)
+}
+
+// InteractiveService 阅读、点赞、收藏
+type InteractiveService interface {
+	IncrReadCnt(ctx context.Context, biz string, bizId int64) error
+	// Like 点赞，已经点过赞了返回 ErrLikeDuplicate
+	Like(ctx context.Context, biz string, bizId int64, uid int64) error
+	// CancelLike 取消点赞，没有点过赞也不会报错
+	CancelLike(ctx context.Context, biz string, bizId int64, uid int64) error
+	// Collect 收藏，已经收藏过了返回 ErrCollectionDuplicate
+	Collect(ctx context.Context, biz string, bizId int64, uid int64) error
+	CancelCollect(ctx context.Context, biz string, bizId int64, uid int64) error
+	// Get 查询计数，uid 大于 0 的时候还会查询这个用户有没有点赞、收藏
+	Get(ctx context.Context, biz string, bizId int64, uid int64) (domain.Interactive, error)
+}
+
+// ArticleListener 关心文章发表、撤回的其它业务，比如关注流、搜索
+// 回调的失败不影响发表本身，实现者自己记录日志
+type ArticleListener interface {
+	OnPublished(ctx context.Context, art domain.Article)
+	// OnWithdrawn 读者看不到这篇文章了，撤回、删除和从回收站彻底删除都会触发，
+	// art 里面可能只有 Id 和作者 Id，彻底删除的时候只有 Id
+	OnWithdrawn(ctx context.Context, art domain.Article)
+}
+
+// ArticleScheduler 定时发表文章
+type ArticleScheduler interface {
+	// Schedule 在 publishAt 发表文章，重复调用会覆盖之前的时间
+	Schedule(artId int64, publishAt time.Time)
+	Cancel(artId int64)
+	// Load 从数据库加载所有等待发表的文章，要定期调用，
+	// 这样别的实例创建或者改期的定时任务，在那个实例挂了之后也能发表
+	Load(ctx context.Context) error
+}
+
+// ModerationService 内容审核，发表文章和评论之前检查有没有敏感词
+type ModerationService interface {
+	// Check 分别检查每一段文本，返回最严重的级别，以及这个级别命中的词
+	Check(ctx context.Context, texts ...string) (domain.ModerationResult, error)
+}
+
+// CollectionFolderService 收藏夹，只有创建者自己能操作和查看
+// 别人的收藏夹一律当作不存在
+type CollectionFolderService interface {
+	Create(ctx context.Context, folder domain.CollectionFolder) (int64, error)
+	Rename(ctx context.Context, id int64, uid int64, name string) error
+	Delete(ctx context.Context, id int64, uid int64) error
+	List(ctx context.Context, uid int64, offset int, limit int) ([]domain.CollectionFolder, error)
+	// AddArticle 把已经发表的文章放进收藏夹，同时算作收藏了这篇文章
+	AddArticle(ctx context.Context, id int64, uid int64, artId int64) error
+	RemoveArticle(ctx context.Context, id int64, uid int64, artId int64) error
+	// ListArticles 按照放进收藏夹的时间倒序，已经不再公开的文章会被跳过
+	ListArticles(ctx context.Context, id int64, uid int64, offset int, limit int) ([]domain.Article, error)
+}
+
+type CommentService interface {
+	// Create 评论已经发表的文章，ParentId 大于 0 的是回复
+	Create(ctx context.Context, c domain.Comment) (int64, error)
+	// Delete 评论者可以删除自己的评论，文章作者可以删除文章下面的任何评论
+	Delete(ctx context.Context, id int64, uid int64) error
+	// ListRoots 文章撤回或者删除了返回 ErrArticleNotFound
+	ListRoots(ctx context.Context, artId int64, minId int64, limit int) ([]domain.Comment, error)
+	// ListReplies 根评论不存在返回 ErrCommentNotFound，文章撤回或者删除了返回 ErrArticleNotFound
+	ListReplies(ctx context.Context, rootId int64, maxId int64, limit int) ([]domain.Comment, error)
+	// Count 文章撤回或者删除了返回 ErrArticleNotFound
+	Count(ctx context.Context, artId int64) (int64, error)
+}
+
+// RankingService 热榜
+type RankingService interface {
+	// ArticleListener 撤回和删除的文章要从热榜里去掉
+	ArticleListener
+	// RankTopN 重新计算热榜并且保存起来，由定时任务调用
+	RankTopN(ctx context.Context) error
+	GetTopN(ctx context.Context) ([]domain.Article, error)
+}
+
+type CronJobService interface {
+	// Preempt 抢占一个到时间的任务，没有任务返回 ErrNoCronJob
+	// 抢到之后在后台续约，运行完之后要调用 CancelFunc 释放
+	Preempt(ctx context.Context) (domain.CronJob, error)
+}
+
+// UploadService 上传图片和附件，内容相同的文件只保存一份
+type UploadService interface {
+	// Upload 按照内容判断类型，不支持的类型返回 ErrUnsupportedUploadType，超过大小返回 ErrUploadTooLarge
+	Upload(ctx context.Context, uid int64, data []byte) (domain.UploadObject, error)
+	// URL 对象的临时下载链接
+	URL(ctx context.Context, key string) (string, error)
+}
+
+// RBACService 基于角色的权限控制
+type RBACService interface {
+	HasPermission(ctx context.Context, uid int64, perm domain.Permission) (bool, error)
+	ListRoles(ctx context.Context, uid int64) ([]domain.Role, error)
+	// Grant operator 给 uid 授予角色，用户不存在返回 ErrUserNotFound，角色不存在返回 ErrRoleNotFound
+	Grant(ctx context.Context, operator int64, uid int64, role string) error
+	// Revoke 撤销角色，超级管理员不能撤销自己的超级管理员，防止没有人能管理角色
+	Revoke(ctx context.Context, operator int64, uid int64, role string) error
+}
+
+type FollowService interface {
+	// Follow 关注，不能关注自己，已经关注过了返回 ErrFollowDuplicate
+	Follow(ctx context.Context, follower int64, followee int64) error
+	// CancelFollow 取消关注，没有关注过也不会报错
+	CancelFollow(ctx context.Context, follower int64, followee int64) error
+	ListFollowers(ctx context.Context, uid int64, offset int, limit int) ([]domain.FollowRelation, error)
+	ListFollowees(ctx context.Context, uid int64, offset int, limit int) ([]domain.FollowRelation, error)
+	// GetStatics 查询关注数和粉丝数，viewer 大于 0 的时候还会查询 viewer 有没有关注 uid
+	GetStatics(ctx context.Context, uid int64, viewer int64) (domain.FollowStatics, error)
+}
+
+// FeedService 关注流，粉丝少的作者发表的时候推送，粉丝多的作者读的时候拉
+type FeedService interface {
+	ArticleListener
+	// Following 关注的作者发表的文章，按照发表时间倒序，before 是上一页最后一篇的发表时间，也就是 Ctime
+	Following(ctx context.Context, uid int64, before time.Time, limit int) ([]domain.Article, error)
+}
+
+// SearchService 搜索已经发表的文章
+type SearchService interface {
+	ArticleListener
+	SearchArticle(ctx context.Context, query string, offset int, limit int) (domain.ArticleSearchResult, error)
+	// Rebuild 从线上库重建索引，返回索引了多少篇文章
+	Rebuild(ctx context.Context) (int, error)
 }
@@ -0,0 +1,108 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+	"webook/webook/internal/domain"
+	"webook/webook/internal/repository"
+	"webook/webook/pkg/logger"
+	"webook/webook/pkg/storage"
+)
+
+var (
+	ErrUploadTooLarge        = errors.New("文件太大")
+	ErrUnsupportedUploadType = errors.New("不支持的文件类型")
+	// ErrUploadNotFound 不是通过上传接口上传的文件
+	ErrUploadNotFound = repository.ErrUploadNotFound
+)
+
+// uploadKeyPrefix 上传的文件都在这个前缀下面，key 是 uploads/{hash 前两位}/{hash}{扩展名}
+const uploadKeyPrefix = "uploads/"
+
+// uploadURLExpiration 下载链接的有效期，文章里面引用的是 key，每次打开都会重新生成
+const uploadURLExpiration = time.Hour
+
+type uploadService struct {
+	repo  repository.UploadRepository
+	store storage.Storage
+	l     logger.Logger
+}
+
+func NewUploadService(repo repository.UploadRepository, store storage.Storage, l logger.Logger) UploadService {
+	return &uploadService{repo: repo, store: store, l: l}
+}
+
+func (s *uploadService) Upload(ctx context.Context, uid int64, data []byte) (domain.UploadObject, error) {
+	if len(data) > domain.MaxUploadSize {
+		return domain.UploadObject{}, ErrUploadTooLarge
+	}
+	// 只看内容，不相信文件名和客户端给的类型
+	contentType := http.DetectContentType(data)
+	ext, ok := domain.UploadContentTypes[contentType]
+	if !ok {
+		return domain.UploadObject{}, ErrUnsupportedUploadType
+	}
+	sum := sha256.Sum256(data)
+	hash := hex.EncodeToString(sum[:])
+	obj, err := s.repo.FindByHash(ctx, hash)
+	if err == nil {
+		// 同样的内容已经有人传过了
+		return obj, nil
+	}
+	if !errors.Is(err, repository.ErrUploadNotFound) {
+		return domain.UploadObject{}, err
+	}
+	obj = domain.UploadObject{
+		Hash:        hash,
+		Key:         uploadKeyPrefix + hash[:2] + "/" + hash + ext,
+		Size:        int64(len(data)),
+		ContentType: contentType,
+		Uid:         uid,
+	}
+	// 先存对象再写记录，写记录失败最多多一个没人引用的对象
+	err = s.store.Put(ctx, obj.Key, bytes.NewReader(data), obj.Size, contentType)
+	if err != nil {
+		return domain.UploadObject{}, err
+	}
+	err = s.repo.Create(ctx, obj)
+	if err != nil {
+		return domain.UploadObject{}, err
+	}
+	s.l.Info("上传文件", logger.Int64("uid", uid), logger.String("key", obj.Key))
+	return obj, nil
+}
+
+// URL 下载链接不需要登录，所以只给上传记录里有的 key 签名，不然可以拿到桶里的任意对象
+func (s *uploadService) URL(ctx context.Context, key string) (string, error) {
+	hash, ok := s.hashOf(key)
+	if !ok {
+		return "", ErrUploadNotFound
+	}
+	obj, err := s.repo.FindByHash(ctx, hash)
+	if err != nil {
+		return "", err
+	}
+	if obj.Key != key {
+		return "", ErrUploadNotFound
+	}
+	return s.store.PresignedURL(ctx, key, uploadURLExpiration)
+}
+
+// hashOf 从 key 里面解析出内容的摘要
+func (s *uploadService) hashOf(key string) (string, bool) {
+	dir, name, ok := strings.Cut(strings.TrimPrefix(key, uploadKeyPrefix), "/")
+	if !ok || !strings.HasPrefix(key, uploadKeyPrefix) || len(name) < sha256.Size*2 {
+		return "", false
+	}
+	hash := name[:sha256.Size*2]
+	if dir != hash[:2] {
+		return "", false
+	}
+	return hash, true
+}
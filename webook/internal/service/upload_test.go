@@ -0,0 +1,189 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"io"
+	"testing"
+	"webook/webook/internal/domain"
+	"webook/webook/internal/repository"
+	repomocks "webook/webook/internal/repository/mocks"
+	"webook/webook/pkg/logger"
+	"webook/webook/pkg/storage"
+)
+
+func Test_uploadService_Upload(t *testing.T) {
+	png := append([]byte("\x89PNG\r\n\x1a\n"), bytes.Repeat([]byte{0}, 16)...)
+	sum := sha256.Sum256(png)
+	hash := hex.EncodeToString(sum[:])
+	key := "uploads/" + hash[:2] + "/" + hash + ".png"
+	testCases := []struct {
+		name    string
+		mock    func(ctrl *gomock.Controller) repository.UploadRepository
+		data    []byte
+		wantObj domain.UploadObject
+		// wantStored 对象存储里面应该有这个文件
+		wantStored bool
+		wantErr    error
+	}{
+		{
+			name: "新文件",
+			mock: func(ctrl *gomock.Controller) repository.UploadRepository {
+				repo := repomocks.NewMockUploadRepository(ctrl)
+				repo.EXPECT().FindByHash(gomock.Any(), hash).
+					Return(domain.UploadObject{}, repository.ErrUploadNotFound)
+				repo.EXPECT().Create(gomock.Any(), domain.UploadObject{
+					Hash:        hash,
+					Key:         key,
+					Size:        int64(len(png)),
+					ContentType: "image/png",
+					Uid:         123,
+				}).Return(nil)
+				return repo
+			},
+			data: png,
+			wantObj: domain.UploadObject{
+				Hash:        hash,
+				Key:         key,
+				Size:        int64(len(png)),
+				ContentType: "image/png",
+				Uid:         123,
+			},
+			wantStored: true,
+		},
+		{
+			name: "同样的内容已经上传过了",
+			mock: func(ctrl *gomock.Controller) repository.UploadRepository {
+				repo := repomocks.NewMockUploadRepository(ctrl)
+				repo.EXPECT().FindByHash(gomock.Any(), hash).Return(domain.UploadObject{
+					Id:  1,
+					Key: key,
+					Uid: 456,
+				}, nil)
+				return repo
+			},
+			data: png,
+			wantObj: domain.UploadObject{
+				Id:  1,
+				Key: key,
+				Uid: 456,
+			},
+		},
+		{
+			name: "不支持的类型",
+			mock: func(ctrl *gomock.Controller) repository.UploadRepository {
+				return repomocks.NewMockUploadRepository(ctrl)
+			},
+			data:    []byte(`<svg xmlns="http://www.w3.org/2000/svg"><script>alert(1)</script></svg>`),
+			wantErr: ErrUnsupportedUploadType,
+		},
+		{
+			name: "太大",
+			mock: func(ctrl *gomock.Controller) repository.UploadRepository {
+				return repomocks.NewMockUploadRepository(ctrl)
+			},
+			data:    append(png, make([]byte, domain.MaxUploadSize)...),
+			wantErr: ErrUploadTooLarge,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			store, err := storage.NewLocalStorage(t.TempDir(), "/storage/local/", []byte("secret"))
+			require.NoError(t, err)
+			svc := NewUploadService(tc.mock(ctrl), store, logger.NewNoOpLogger())
+			obj, err := svc.Upload(context.Background(), 123, tc.data)
+			assert.Equal(t, tc.wantErr, err)
+			assert.Equal(t, tc.wantObj, obj)
+			r, err := store.Get(context.Background(), key)
+			if !tc.wantStored {
+				assert.Equal(t, storage.ErrObjectNotFound, err)
+				return
+			}
+			require.NoError(t, err)
+			defer r.Close()
+			stored, err := io.ReadAll(r)
+			require.NoError(t, err)
+			assert.Equal(t, tc.data, stored)
+		})
+	}
+}
+
+func Test_uploadService_URL(t *testing.T) {
+	hash := hex.EncodeToString(make([]byte, sha256.Size))
+	key := "uploads/" + hash[:2] + "/" + hash + ".png"
+	testCases := []struct {
+		name    string
+		mock    func(ctrl *gomock.Controller) repository.UploadRepository
+		key     string
+		wantErr error
+	}{
+		{
+			name: "上传过的文件",
+			mock: func(ctrl *gomock.Controller) repository.UploadRepository {
+				repo := repomocks.NewMockUploadRepository(ctrl)
+				repo.EXPECT().FindByHash(gomock.Any(), hash).Return(domain.UploadObject{Key: key}, nil)
+				return repo
+			},
+			key: key,
+		},
+		{
+			name: "不是上传的目录",
+			mock: func(ctrl *gomock.Controller) repository.UploadRepository {
+				return repomocks.NewMockUploadRepository(ctrl)
+			},
+			key:     "private/" + hash[:2] + "/" + hash + ".png",
+			wantErr: ErrUploadNotFound,
+		},
+		{
+			name: "目录和摘要对不上",
+			mock: func(ctrl *gomock.Controller) repository.UploadRepository {
+				return repomocks.NewMockUploadRepository(ctrl)
+			},
+			key:     "uploads/ff/" + hash + ".png",
+			wantErr: ErrUploadNotFound,
+		},
+		{
+			name: "没有上传记录",
+			mock: func(ctrl *gomock.Controller) repository.UploadRepository {
+				repo := repomocks.NewMockUploadRepository(ctrl)
+				repo.EXPECT().FindByHash(gomock.Any(), hash).
+					Return(domain.UploadObject{}, repository.ErrUploadNotFound)
+				return repo
+			},
+			key:     key,
+			wantErr: ErrUploadNotFound,
+		},
+		{
+			name: "摘要一样，但是 key 不是上传记录里的",
+			mock: func(ctrl *gomock.Controller) repository.UploadRepository {
+				repo := repomocks.NewMockUploadRepository(ctrl)
+				repo.EXPECT().FindByHash(gomock.Any(), hash).Return(domain.UploadObject{Key: key}, nil)
+				return repo
+			},
+			key:     key + ".bak",
+			wantErr: ErrUploadNotFound,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			store, err := storage.NewLocalStorage(t.TempDir(), "/storage/local/", []byte("secret"))
+			require.NoError(t, err)
+			svc := NewUploadService(tc.mock(ctrl), store, logger.NewNoOpLogger())
+			u, err := svc.URL(context.Background(), tc.key)
+			assert.Equal(t, tc.wantErr, err)
+			if tc.wantErr == nil {
+				assert.Contains(t, u, "/storage/local/"+tc.key)
+			}
+		})
+	}
+}
@@ -1,9 +1,15 @@
 package web
 
 import (
+	"errors"
+	"fmt"
+	"github.com/ecodeclub/ekit/slice"
 	"github.com/gin-gonic/gin"
 	"net/http"
+	"strconv"
+	"time"
 	"webook/webook/internal/domain"
+	"webook/webook/internal/errs"
 	"webook/webook/internal/service"
 	"webook/webook/pkg/logger"
 )
@@ -24,6 +30,304 @@ func (h *ArticleHandler) RegisterRouter(server *gin.Engine) {
 	g.POST("/edit", h.Edit)
 	g.POST("/publish", h.Publish)
 	g.POST("/withdraw", h.Withdraw)
+	// 创作者查看自己的文章
+	g.POST("/list", h.List)
+	g.GET("/detail/:id", h.Detail)
+
+	// 历史版本
+	rg := g.Group("/revisions")
+	rg.POST("/list", h.ListRevisions)
+	rg.POST("/diff", h.DiffRevisions)
+	rg.POST("/restore", h.RestoreRevision)
+
+	// 定时发表
+	sg := g.Group("/schedule")
+	sg.POST("/cancel", h.CancelSchedule)
+	sg.POST("/reschedule", h.Reschedule)
+
+	// 回收站
+	g.POST("/delete", h.Delete)
+	bg := g.Group("/recycle")
+	bg.POST("/list", h.ListRecycleBin)
+	bg.POST("/restore", h.RestoreFromRecycleBin)
+}
+
+// ListRevisions 查看文章的历史版本
+func (h *ArticleHandler) ListRevisions(ctx *gin.Context) {
+	type Req struct {
+		Id     int64 `json:"id"`
+		Offset int   `json:"offset"`
+		Limit  int   `json:"limit"`
+	}
+	var req Req
+	if err := ctx.Bind(&req); err != nil {
+		return
+	}
+	if req.Limit <= 0 || req.Limit > 100 {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 4,
+			Msg:  "输入有误",
+		})
+		return
+	}
+	uid, _ := ctx.Get("userId")
+	userId, ok := uid.(int64)
+	if !ok {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "系统错误",
+		})
+		h.l.Error("未发现用户的session信息")
+		return
+	}
+	revs, err := h.svc.ListRevisions(ctx.Request.Context(), req.Id, userId, req.Offset, req.Limit)
+	if errors.Is(err, service.ErrArticleNotFound) {
+		ctx.JSON(http.StatusOK, Result{
+			Code: errs.ArticleNotFound,
+			Msg:  "文章不存在",
+		})
+		return
+	}
+	if err != nil {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "系统错误",
+		})
+		h.l.Error("查找文章历史版本失败", logger.Error(err), logger.Int64("id", req.Id))
+		return
+	}
+	ctx.JSON(http.StatusOK, Result{
+		Data: slice.Map[domain.ArticleRevision, RevisionVO](revs, func(idx int, src domain.ArticleRevision) RevisionVO {
+			return RevisionVO{
+				Id:       src.Id,
+				Title:    src.Title,
+				Abstract: src.Abstract(),
+				Status:   src.Status.ToUint8(),
+				Ctime:    src.Ctime.UnixMilli(),
+			}
+		}),
+	})
+}
+
+// DiffRevisions 比较两个历史版本
+func (h *ArticleHandler) DiffRevisions(ctx *gin.Context) {
+	type Req struct {
+		Id   int64 `json:"id"`
+		From int64 `json:"from"`
+		To   int64 `json:"to"`
+	}
+	var req Req
+	if err := ctx.Bind(&req); err != nil {
+		return
+	}
+	uid, _ := ctx.Get("userId")
+	userId, ok := uid.(int64)
+	if !ok {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "系统错误",
+		})
+		h.l.Error("未发现用户的session信息")
+		return
+	}
+	lines, err := h.svc.DiffRevisions(ctx.Request.Context(), req.Id, userId, req.From, req.To)
+	switch {
+	case err == nil:
+		ctx.JSON(http.StatusOK, Result{
+			Data: lines,
+		})
+	case errors.Is(err, service.ErrArticleNotFound):
+		ctx.JSON(http.StatusOK, Result{
+			Code: errs.ArticleNotFound,
+			Msg:  "文章不存在",
+		})
+	case errors.Is(err, service.ErrRevisionNotFound):
+		ctx.JSON(http.StatusOK, Result{
+			Code: errs.ArticleRevisionNotFound,
+			Msg:  "历史版本不存在",
+		})
+	default:
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "系统错误",
+		})
+		h.l.Error("比较文章历史版本失败", logger.Error(err), logger.Int64("id", req.Id))
+	}
+}
+
+// RestoreRevision 把历史版本恢复为草稿
+func (h *ArticleHandler) RestoreRevision(ctx *gin.Context) {
+	type Req struct {
+		Id         int64 `json:"id"`
+		RevisionId int64 `json:"revisionId"`
+	}
+	var req Req
+	if err := ctx.Bind(&req); err != nil {
+		return
+	}
+	uid, _ := ctx.Get("userId")
+	userId, ok := uid.(int64)
+	if !ok {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "系统错误",
+		})
+		h.l.Error("未发现用户的session信息")
+		return
+	}
+	err := h.svc.RestoreRevision(ctx.Request.Context(), req.Id, userId, req.RevisionId)
+	switch {
+	case err == nil:
+		ctx.JSON(http.StatusOK, Result{
+			Msg: "OK",
+		})
+	case errors.Is(err, service.ErrArticleNotFound):
+		ctx.JSON(http.StatusOK, Result{
+			Code: errs.ArticleNotFound,
+			Msg:  "文章不存在",
+		})
+	case errors.Is(err, service.ErrRevisionNotFound):
+		ctx.JSON(http.StatusOK, Result{
+			Code: errs.ArticleRevisionNotFound,
+			Msg:  "历史版本不存在",
+		})
+	case errors.Is(err, service.ErrInvalidStatusTransition):
+		ctx.JSON(http.StatusOK, Result{
+			Code: errs.ArticleInvalidStatus,
+			Msg:  "文章当前状态不能编辑",
+		})
+	default:
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "系统错误",
+		})
+		h.l.Error("恢复文章历史版本失败", logger.Error(err),
+			logger.Int64("id", req.Id), logger.Int64("revision_id", req.RevisionId))
+	}
+}
+
+// List 创作者的文章列表，只返回摘要
+func (h *ArticleHandler) List(ctx *gin.Context) {
+	var req ListReq
+	if err := ctx.Bind(&req); err != nil {
+		return
+	}
+	// 防止一次查太多数据
+	if req.Limit <= 0 || req.Limit > 100 {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 4,
+			Msg:  "输入有误",
+		})
+		return
+	}
+	uid, _ := ctx.Get("userId")
+	userId, ok := uid.(int64)
+	if !ok {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "系统错误",
+		})
+		h.l.Error("未发现用户的session信息")
+		return
+	}
+	var (
+		arts []domain.Article
+		err  error
+	)
+	if req.CursorId > 0 {
+		// 有游标的时候，忽略 offset
+		arts, err = h.svc.ListByCursor(ctx.Request.Context(), userId,
+			time.UnixMilli(req.CursorUtime), req.CursorId, req.Limit)
+	} else {
+		arts, err = h.svc.List(ctx.Request.Context(), userId, req.Offset, req.Limit)
+	}
+	if err != nil {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "系统错误",
+		})
+		h.l.Error("查找文章列表失败", logger.Error(err),
+			logger.Int64("uid", userId))
+		return
+	}
+	ctx.JSON(http.StatusOK, Result{
+		Data: slice.Map[domain.Article, ArticleVO](arts, func(idx int, src domain.Article) ArticleVO {
+			return ArticleVO{
+				Id:       src.Id,
+				Title:    src.Title,
+				Abstract: src.Abstract(),
+				Status:   src.Status.ToUint8(),
+				Ctime:    src.Ctime.UnixMilli(),
+				Utime:    src.Utime.UnixMilli(),
+				// 创作者要能看到哪些文章在等待定时发表
+				PublishAt: toMilli(src.PublishAt),
+			}
+		}),
+	})
+}
+
+// Detail 创作者查看自己的文章详情
+func (h *ArticleHandler) Detail(ctx *gin.Context) {
+	id, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 4,
+			Msg:  "参数错误",
+		})
+		return
+	}
+	uid, _ := ctx.Get("userId")
+	userId, ok := uid.(int64)
+	if !ok {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "系统错误",
+		})
+		h.l.Error("未发现用户的session信息")
+		return
+	}
+	art, err := h.svc.GetById(ctx.Request.Context(), id)
+	if errors.Is(err, service.ErrArticleNotFound) {
+		ctx.JSON(http.StatusOK, Result{
+			Code: errs.ArticleNotFound,
+			Msg:  "文章不存在",
+		})
+		return
+	}
+	if err != nil {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "系统错误",
+		})
+		h.l.Error("查找文章失败", logger.Error(err), logger.Int64("id", id))
+		return
+	}
+	// 只有作者本人才能在这里看到文章，包括未发表的草稿
+	if art.Author.Id != userId {
+		// 不告诉前端到底是没有这篇文章还是不是作者
+		ctx.JSON(http.StatusOK, Result{
+			Code: errs.ArticleNotFound,
+			Msg:  "文章不存在",
+		})
+		// 正常用户不会走到这里，可能是有人在攻击系统
+		h.l.Error("非法访问文章，创作者ID不匹配",
+			logger.Int64("uid", userId), logger.Int64("aid", id))
+		return
+	}
+	ctx.JSON(http.StatusOK, Result{
+		Data: ArticleVO{
+			Id:        art.Id,
+			Title:     art.Title,
+			Content:   art.Content,
+			AuthorId:  art.Author.Id,
+			Status:    art.Status.ToUint8(),
+			Category:  art.Category.ToUint8(),
+			Tags:      art.Tags,
+			Ctime:     art.Ctime.UnixMilli(),
+			Utime:     art.Utime.UnixMilli(),
+			PublishAt: toMilli(art.PublishAt),
+		},
+	})
 }
 
 func (h *ArticleHandler) Withdraw(ctx *gin.Context) {
@@ -51,6 +355,13 @@ func (h *ArticleHandler) Withdraw(ctx *gin.Context) {
 			Id: userId,
 		},
 	})
+	if errors.Is(err, service.ErrInvalidStatusTransition) {
+		ctx.JSON(http.StatusOK, Result{
+			Code: errs.ArticleInvalidStatus,
+			Msg:  "文章当前状态不能撤回",
+		})
+		return
+	}
 	if err != nil {
 		ctx.JSON(http.StatusOK, Result{
 			Code: 5,
@@ -79,15 +390,54 @@ func (h *ArticleHandler) Publish(ctx *gin.Context) {
 		return
 	}
 
-	id, err := h.svc.Publish(ctx.Request.Context(), domain.Article{
-		// 新建并发表时是没有ID的，为0
-		Id:      req.Id,
-		Title:   req.Title,
-		Content: req.Content,
-		Author: domain.Author{
-			Id: userId,
-		},
-	})
+	// 新建并发表时是没有ID的，为0
+	art, ok := h.toDomain(ctx, req, userId)
+	if !ok {
+		return
+	}
+	var (
+		id  int64
+		err error
+	)
+	if req.PublishAt > 0 {
+		// 定时发表
+		art.PublishAt = time.UnixMilli(req.PublishAt)
+		if !art.PublishAt.After(time.Now()) {
+			ctx.JSON(http.StatusOK, Result{
+				Code: 4,
+				Msg:  "发表时间有误",
+			})
+			return
+		}
+		id, err = h.svc.SchedulePublish(ctx.Request.Context(), art)
+	} else {
+		id, err = h.svc.Publish(ctx.Request.Context(), art)
+	}
+	var swErr *service.SensitiveWordsError
+	if errors.As(err, &swErr) {
+		// 告诉作者是哪些词，方便修改
+		ctx.JSON(http.StatusOK, Result{
+			Code: errs.ContentSensitive,
+			Msg:  "文章包含敏感词",
+			Data: swErr.Words,
+		})
+		return
+	}
+	if errors.Is(err, service.ErrPendingReview) {
+		// 已经保存了，不算失败
+		ctx.JSON(http.StatusOK, Result{
+			Data: id,
+			Msg:  "文章需要人工审核，审核通过之后会自动发表",
+		})
+		return
+	}
+	if errors.Is(err, service.ErrInvalidStatusTransition) {
+		ctx.JSON(http.StatusOK, Result{
+			Code: errs.ArticleInvalidStatus,
+			Msg:  "文章当前状态不能发表",
+		})
+		return
+	}
 	if err != nil {
 		ctx.JSON(http.StatusOK, Result{
 			Code: 5,
@@ -118,14 +468,18 @@ func (h *ArticleHandler) Edit(ctx *gin.Context) {
 		h.l.Error("未发现用户的session信息")
 		return
 	}
-	id, err := h.svc.Save(ctx, domain.Article{
-		Id:      req.Id,
-		Title:   req.Title,
-		Content: req.Content,
-		Author: domain.Author{
-			Id: userId,
-		},
-	})
+	art, ok := h.toDomain(ctx, req, userId)
+	if !ok {
+		return
+	}
+	id, err := h.svc.Save(ctx, art)
+	if errors.Is(err, service.ErrInvalidStatusTransition) {
+		ctx.JSON(http.StatusOK, Result{
+			Code: errs.ArticleInvalidStatus,
+			Msg:  "文章当前状态不能编辑",
+		})
+		return
+	}
 	if err != nil {
 		ctx.JSON(http.StatusOK, Result{
 			Code: 5,
@@ -140,8 +494,54 @@ func (h *ArticleHandler) Edit(ctx *gin.Context) {
 	})
 }
 
+// toDomain 校验分类和标签，不合法的时候直接返回错误给前端
+func (h *ArticleHandler) toDomain(ctx *gin.Context, req ArticleReq, userId int64) (domain.Article, bool) {
+	category := domain.ArticleCategory(req.Category)
+	if !category.Valid() {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 4,
+			Msg:  "分类有误",
+		})
+		return domain.Article{}, false
+	}
+	tags, err := domain.NormalizeTags(req.Tags)
+	if err != nil {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 4,
+			Msg:  fmt.Sprintf("最多 %d 个标签，每个标签不超过 %d 个字", domain.MaxArticleTags, domain.MaxTagLength),
+		})
+		return domain.Article{}, false
+	}
+	return domain.Article{
+		Id:       req.Id,
+		Title:    req.Title,
+		Content:  req.Content,
+		Category: category,
+		Tags:     tags,
+		Author: domain.Author{
+			Id: userId,
+		},
+	}, true
+}
+
 type ArticleReq struct {
 	Id      int64  `json:"id"`
 	Title   string `json:"title"`
 	Content string `json:"content"`
+	// Category 分类，只能是固定的几个，0 表示未分类
+	Category uint8 `json:"category"`
+	// Tags 标签，会被规范化和去重，每次都是整体替换
+	Tags []string `json:"tags"`
+	// PublishAt 定时发表的时间，毫秒数，只有发表的时候才有用
+	PublishAt int64 `json:"publishAt"`
+}
+
+// ListReq 文章列表分页
+// 支持 offset/limit 分页，也支持使用上一页最后一篇文章的 (utime, id) 作为游标
+type ListReq struct {
+	Offset int `json:"offset"`
+	Limit  int `json:"limit"`
+	// 游标，毫秒数
+	CursorUtime int64 `json:"cursorUtime"`
+	CursorId    int64 `json:"cursorId"`
 }
@@ -0,0 +1,358 @@
+package web
+
+import (
+	"context"
+	"errors"
+	"github.com/ecodeclub/ekit/slice"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"strconv"
+	"time"
+	"webook/webook/internal/domain"
+	"webook/webook/internal/errs"
+	"webook/webook/internal/service"
+	"webook/webook/pkg/logger"
+)
+
+var _ handler = (*ArticleReaderHandler)(nil)
+
+// ArticleReaderHandler 读者查看文章，不需要登录
+// 只能看到已经发表的文章
+type ArticleReaderHandler struct {
+	svc        service.ArticleService
+	intrSvc    service.InteractiveService
+	rankingSvc service.RankingService
+	l          logger.Logger
+}
+
+func NewArticleReaderHandler(svc service.ArticleService, intrSvc service.InteractiveService,
+	rankingSvc service.RankingService, l logger.Logger) *ArticleReaderHandler {
+	return &ArticleReaderHandler{svc: svc, intrSvc: intrSvc, rankingSvc: rankingSvc, l: l}
+}
+
+func (h *ArticleReaderHandler) RegisterRouter(server *gin.Engine) {
+	g := server.Group("/articles/pub")
+	g.GET("/:id", h.PubDetail)
+	g.POST("/list", h.PubList)
+	g.GET("/ranking", h.Ranking)
+	// 标签
+	g.POST("/tag/list", h.PubListByTag)
+	g.GET("/tags", h.TagCloud)
+	// 点赞和收藏要登录，所以不放在 /articles/pub 下面
+	server.POST("/articles/like", h.Like)
+	server.POST("/articles/collect", h.Collect)
+}
+
+// PubDetail 读者查看文章详情
+func (h *ArticleReaderHandler) PubDetail(ctx *gin.Context) {
+	id, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 4,
+			Msg:  "参数错误",
+		})
+		return
+	}
+	art, err := h.svc.GetPublishedById(ctx.Request.Context(), id)
+	if errors.Is(err, service.ErrArticleNotFound) {
+		// 没发表、仅自己可见和撤回的文章都当作不存在
+		ctx.JSON(http.StatusOK, Result{
+			Code: errs.ArticleNotFound,
+			Msg:  "文章不存在",
+		})
+		return
+	}
+	if err != nil {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "系统错误",
+		})
+		h.l.Error("查找已发表的文章失败", logger.Error(err), logger.Int64("id", id))
+		return
+	}
+	// 阅读数不影响读者看文章，异步增加
+	go func() {
+		// 请求结束之后 ctx 就被取消了，不能用请求的 ctx
+		c, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		er := h.intrSvc.IncrReadCnt(c, domain.BizArticle, art.Id)
+		if er != nil {
+			h.l.Error("增加阅读数失败", logger.Error(er), logger.Int64("id", art.Id))
+		}
+	}()
+	// 没有登录的时候 uid 是 0，只查询计数
+	uid, _ := ctx.Get("userId")
+	userId, _ := uid.(int64)
+	vo := ArticleVO{
+		Id:         art.Id,
+		Title:      art.Title,
+		Content:    art.Content,
+		Html:       art.HTML,
+		AuthorId:   art.Author.Id,
+		AuthorName: art.Author.Name,
+		Status:     art.Status.ToUint8(),
+		Category:   art.Category.ToUint8(),
+		Tags:       art.Tags,
+		Ctime:      art.Ctime.UnixMilli(),
+		Utime:      art.Utime.UnixMilli(),
+	}
+	intr, err := h.intrSvc.Get(ctx.Request.Context(), domain.BizArticle, art.Id, userId)
+	if err != nil {
+		// 互动数据查不到也要让读者能看文章
+		h.l.Error("查询互动数据失败", logger.Error(err), logger.Int64("id", art.Id))
+	} else {
+		vo.Interactive = &InteractiveVO{
+			ReadCnt:    intr.ReadCnt,
+			LikeCnt:    intr.LikeCnt,
+			CollectCnt: intr.CollectCnt,
+			Liked:      intr.Liked,
+			Collected:  intr.Collected,
+		}
+	}
+	ctx.JSON(http.StatusOK, Result{
+		Data: vo,
+	})
+}
+
+// Like 点赞或者取消点赞
+func (h *ArticleReaderHandler) Like(ctx *gin.Context) {
+	type Req struct {
+		Id int64 `json:"id"`
+		// true 是点赞，false 是取消点赞
+		Like bool `json:"like"`
+	}
+	var req Req
+	if err := ctx.Bind(&req); err != nil {
+		return
+	}
+	userId, ok := h.checkInteract(ctx, req.Id)
+	if !ok {
+		return
+	}
+	var err error
+	if req.Like {
+		err = h.intrSvc.Like(ctx.Request.Context(), domain.BizArticle, req.Id, userId)
+	} else {
+		err = h.intrSvc.CancelLike(ctx.Request.Context(), domain.BizArticle, req.Id, userId)
+	}
+	switch {
+	case err == nil:
+		ctx.JSON(http.StatusOK, Result{
+			Msg: "OK",
+		})
+	case errors.Is(err, service.ErrLikeDuplicate):
+		ctx.JSON(http.StatusOK, Result{
+			Code: errs.ArticleAlreadyLiked,
+			Msg:  "已经点过赞了",
+		})
+	default:
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "系统错误",
+		})
+		h.l.Error("点赞失败", logger.Error(err),
+			logger.Int64("uid", userId), logger.Int64("id", req.Id))
+	}
+}
+
+// Collect 收藏或者取消收藏
+func (h *ArticleReaderHandler) Collect(ctx *gin.Context) {
+	type Req struct {
+		Id int64 `json:"id"`
+		// true 是收藏，false 是取消收藏
+		Collect bool `json:"collect"`
+	}
+	var req Req
+	if err := ctx.Bind(&req); err != nil {
+		return
+	}
+	userId, ok := h.checkInteract(ctx, req.Id)
+	if !ok {
+		return
+	}
+	var err error
+	if req.Collect {
+		err = h.intrSvc.Collect(ctx.Request.Context(), domain.BizArticle, req.Id, userId)
+	} else {
+		err = h.intrSvc.CancelCollect(ctx.Request.Context(), domain.BizArticle, req.Id, userId)
+	}
+	switch {
+	case err == nil:
+		ctx.JSON(http.StatusOK, Result{
+			Msg: "OK",
+		})
+	case errors.Is(err, service.ErrCollectionDuplicate):
+		ctx.JSON(http.StatusOK, Result{
+			Code: errs.ArticleAlreadyCollected,
+			Msg:  "已经收藏过了",
+		})
+	default:
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "系统错误",
+		})
+		h.l.Error("收藏失败", logger.Error(err),
+			logger.Int64("uid", userId), logger.Int64("id", req.Id))
+	}
+}
+
+// checkInteract 拿到登录用户，并且确认文章是已经发表的
+// 返回 false 的时候已经写好了响应
+func (h *ArticleReaderHandler) checkInteract(ctx *gin.Context, id int64) (int64, bool) {
+	uid, _ := ctx.Get("userId")
+	userId, ok := uid.(int64)
+	if !ok {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "系统错误",
+		})
+		h.l.Error("未发现用户的session信息")
+		return 0, false
+	}
+	_, err := h.svc.GetPublishedById(ctx.Request.Context(), id)
+	if errors.Is(err, service.ErrArticleNotFound) {
+		ctx.JSON(http.StatusOK, Result{
+			Code: errs.ArticleNotFound,
+			Msg:  "文章不存在",
+		})
+		return 0, false
+	}
+	if err != nil {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "系统错误",
+		})
+		h.l.Error("查找已发表的文章失败", logger.Error(err), logger.Int64("id", id))
+		return 0, false
+	}
+	return userId, true
+}
+
+// PubList 读者查看文章列表，只返回摘要
+func (h *ArticleReaderHandler) PubList(ctx *gin.Context) {
+	var req ListReq
+	if err := ctx.Bind(&req); err != nil {
+		return
+	}
+	if req.Limit <= 0 || req.Limit > 100 {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 4,
+			Msg:  "输入有误",
+		})
+		return
+	}
+	arts, err := h.svc.ListPub(ctx.Request.Context(), req.Offset, req.Limit)
+	if err != nil {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "系统错误",
+		})
+		h.l.Error("查找已发表的文章列表失败", logger.Error(err))
+		return
+	}
+	ctx.JSON(http.StatusOK, Result{
+		Data: slice.Map[domain.Article, ArticleVO](arts, func(idx int, src domain.Article) ArticleVO {
+			return h.toPubListVO(src)
+		}),
+	})
+}
+
+// PubListByTag 读者查看带有某个标签的文章，只返回摘要
+func (h *ArticleReaderHandler) PubListByTag(ctx *gin.Context) {
+	type Req struct {
+		Tag    string `json:"tag"`
+		Offset int    `json:"offset"`
+		Limit  int    `json:"limit"`
+	}
+	var req Req
+	if err := ctx.Bind(&req); err != nil {
+		return
+	}
+	if req.Limit <= 0 || req.Limit > 100 {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 4,
+			Msg:  "输入有误",
+		})
+		return
+	}
+	arts, err := h.svc.ListPubByTag(ctx.Request.Context(), req.Tag, req.Offset, req.Limit)
+	if err != nil {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "系统错误",
+		})
+		h.l.Error("按照标签查找文章列表失败", logger.Error(err), logger.String("tag", req.Tag))
+		return
+	}
+	ctx.JSON(http.StatusOK, Result{
+		Data: slice.Map[domain.Article, ArticleVO](arts, func(idx int, src domain.Article) ArticleVO {
+			return h.toPubListVO(src)
+		}),
+	})
+}
+
+// tagCloudSize 标签云最多展示多少个标签
+const tagCloudSize = 100
+
+// TagCloud 标签云，按照文章数量倒序
+func (h *ArticleReaderHandler) TagCloud(ctx *gin.Context) {
+	tags, err := h.svc.ListTags(ctx.Request.Context(), tagCloudSize)
+	if err != nil {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "系统错误",
+		})
+		h.l.Error("查找标签云失败", logger.Error(err))
+		return
+	}
+	ctx.JSON(http.StatusOK, Result{
+		Data: slice.Map[domain.Tag, TagVO](tags, func(idx int, src domain.Tag) TagVO {
+			return TagVO{
+				Name: src.Name,
+				Cnt:  src.Cnt,
+			}
+		}),
+	})
+}
+
+// toPubListVO 读者看到的列表只有摘要
+func (h *ArticleReaderHandler) toPubListVO(art domain.Article) ArticleVO {
+	return ArticleVO{
+		Id:         art.Id,
+		Title:      art.Title,
+		Abstract:   art.Abstract(),
+		AuthorId:   art.Author.Id,
+		AuthorName: art.Author.Name,
+		Status:     art.Status.ToUint8(),
+		Category:   art.Category.ToUint8(),
+		Ctime:      art.Ctime.UnixMilli(),
+		Utime:      art.Utime.UnixMilli(),
+	}
+}
+
+// Ranking 热榜，由定时任务计算好，这里只是读出来
+func (h *ArticleReaderHandler) Ranking(ctx *gin.Context) {
+	arts, err := h.rankingSvc.GetTopN(ctx.Request.Context())
+	if err != nil {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "系统错误",
+		})
+		h.l.Error("查找热榜失败", logger.Error(err))
+		return
+	}
+	ctx.JSON(http.StatusOK, Result{
+		Data: slice.Map[domain.Article, ArticleVO](arts, func(idx int, src domain.Article) ArticleVO {
+			return ArticleVO{
+				Id:         src.Id,
+				Title:      src.Title,
+				Abstract:   src.Abstract(),
+				AuthorId:   src.Author.Id,
+				AuthorName: src.Author.Name,
+				Status:     src.Status.ToUint8(),
+				Ctime:      src.Ctime.UnixMilli(),
+				Utime:      src.Utime.UnixMilli(),
+			}
+		}),
+	})
+}
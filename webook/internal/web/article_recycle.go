@@ -0,0 +1,145 @@
+package web
+
+import (
+	"errors"
+	"github.com/ecodeclub/ekit/slice"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"webook/webook/internal/domain"
+	"webook/webook/internal/errs"
+	"webook/webook/internal/service"
+	"webook/webook/pkg/logger"
+)
+
+// Delete 把文章放进回收站
+func (h *ArticleHandler) Delete(ctx *gin.Context) {
+	type Req struct {
+		Id int64 `json:"id"`
+	}
+	var req Req
+	if err := ctx.Bind(&req); err != nil {
+		return
+	}
+	uid, _ := ctx.Get("userId")
+	userId, ok := uid.(int64)
+	if !ok {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "系统错误",
+		})
+		h.l.Error("未发现用户的session信息")
+		return
+	}
+	err := h.svc.Delete(ctx.Request.Context(), req.Id, userId)
+	switch {
+	case err == nil:
+		ctx.JSON(http.StatusOK, Result{
+			Msg: "OK",
+		})
+	case errors.Is(err, service.ErrArticleNotFound):
+		ctx.JSON(http.StatusOK, Result{
+			Code: errs.ArticleNotFound,
+			Msg:  "文章不存在",
+		})
+	default:
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "系统错误",
+		})
+		h.l.Error("删除文章失败", logger.Error(err),
+			logger.Int64("uid", userId), logger.Int64("id", req.Id))
+	}
+}
+
+// ListRecycleBin 回收站里的文章，只返回摘要
+func (h *ArticleHandler) ListRecycleBin(ctx *gin.Context) {
+	type Req struct {
+		Offset int `json:"offset"`
+		Limit  int `json:"limit"`
+	}
+	var req Req
+	if err := ctx.Bind(&req); err != nil {
+		return
+	}
+	if req.Limit <= 0 || req.Limit > 100 {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 4,
+			Msg:  "输入有误",
+		})
+		return
+	}
+	uid, _ := ctx.Get("userId")
+	userId, ok := uid.(int64)
+	if !ok {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "系统错误",
+		})
+		h.l.Error("未发现用户的session信息")
+		return
+	}
+	arts, err := h.svc.ListRecycleBin(ctx.Request.Context(), userId, req.Offset, req.Limit)
+	if err != nil {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "系统错误",
+		})
+		h.l.Error("查找回收站失败", logger.Error(err),
+			logger.Int64("uid", userId))
+		return
+	}
+	ctx.JSON(http.StatusOK, Result{
+		Data: slice.Map[domain.Article, ArticleVO](arts, func(idx int, src domain.Article) ArticleVO {
+			return ArticleVO{
+				Id:       src.Id,
+				Title:    src.Title,
+				Abstract: src.Abstract(),
+				Status:   src.Status.ToUint8(),
+				Ctime:    src.Ctime.UnixMilli(),
+				Utime:    src.Utime.UnixMilli(),
+				Dtime:    src.Dtime.UnixMilli(),
+			}
+		}),
+	})
+}
+
+// RestoreFromRecycleBin 从回收站恢复文章
+func (h *ArticleHandler) RestoreFromRecycleBin(ctx *gin.Context) {
+	type Req struct {
+		Id int64 `json:"id"`
+	}
+	var req Req
+	if err := ctx.Bind(&req); err != nil {
+		return
+	}
+	uid, _ := ctx.Get("userId")
+	userId, ok := uid.(int64)
+	if !ok {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "系统错误",
+		})
+		h.l.Error("未发现用户的session信息")
+		return
+	}
+	err := h.svc.RestoreFromRecycleBin(ctx.Request.Context(), req.Id, userId)
+	switch {
+	case err == nil:
+		ctx.JSON(http.StatusOK, Result{
+			Msg: "OK",
+		})
+	case errors.Is(err, service.ErrArticleNotFound):
+		// 不在回收站里，或者已经过了可以恢复的时间
+		ctx.JSON(http.StatusOK, Result{
+			Code: errs.ArticleNotFound,
+			Msg:  "文章不存在",
+		})
+	default:
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "系统错误",
+		})
+		h.l.Error("从回收站恢复文章失败", logger.Error(err),
+			logger.Int64("uid", userId), logger.Int64("id", req.Id))
+	}
+}
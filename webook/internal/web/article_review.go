@@ -0,0 +1,201 @@
+package web
+
+import (
+	"errors"
+	"github.com/ecodeclub/ekit/slice"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"strconv"
+	"unicode/utf8"
+	"webook/webook/internal/domain"
+	"webook/webook/internal/errs"
+	"webook/webook/internal/service"
+	"webook/webook/internal/web/middleware"
+	"webook/webook/pkg/logger"
+)
+
+// maxReviewReasonLength 驳回理由的最大字数，和数据库的字段长度一致
+const maxReviewReasonLength = 1024
+
+var _ handler = (*ArticleReviewHandler)(nil)
+
+// ArticleReviewHandler 文章的人工审核，管理员的接口要有审核文章的权限
+type ArticleReviewHandler struct {
+	svc  service.ArticleReviewService
+	perm *middleware.PermissionMiddlewareBuilder
+	l    logger.Logger
+}
+
+func NewArticleReviewHandler(svc service.ArticleReviewService, perm *middleware.PermissionMiddlewareBuilder,
+	l logger.Logger) *ArticleReviewHandler {
+	return &ArticleReviewHandler{svc: svc, perm: perm, l: l}
+}
+
+func (h *ArticleReviewHandler) RegisterRouter(server *gin.Engine) {
+	g := server.Group("/admin/articles/review", h.perm.Build(domain.PermissionArticleReview))
+	g.POST("/list", h.ListPending)
+	g.POST("/approve", h.Approve)
+	g.POST("/reject", h.Reject)
+	// 作者查看自己的文章的审核结果
+	server.GET("/articles/review/:id", h.Latest)
+}
+
+// ListPending 等待审核的文章，审核要看全文，所以带上内容
+func (h *ArticleReviewHandler) ListPending(ctx *gin.Context) {
+	var req ListReq
+	if err := ctx.Bind(&req); err != nil {
+		return
+	}
+	if req.Limit <= 0 || req.Limit > 100 {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 4,
+			Msg:  "输入有误",
+		})
+		return
+	}
+	arts, err := h.svc.ListPending(ctx.Request.Context(), req.Offset, req.Limit)
+	if err != nil {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "系统错误",
+		})
+		h.l.Error("查询等待审核的文章失败", logger.Error(err))
+		return
+	}
+	ctx.JSON(http.StatusOK, Result{
+		Data: slice.Map[domain.Article, ArticleVO](arts, func(idx int, src domain.Article) ArticleVO {
+			return ArticleVO{
+				Id:       src.Id,
+				Title:    src.Title,
+				Content:  src.Content,
+				AuthorId: src.Author.Id,
+				Status:   src.Status.ToUint8(),
+				Category: src.Category.ToUint8(),
+				Tags:     src.Tags,
+				Ctime:    src.Ctime.UnixMilli(),
+				Utime:    src.Utime.UnixMilli(),
+			}
+		}),
+	})
+}
+
+// Approve 审核通过，文章会直接发表
+func (h *ArticleReviewHandler) Approve(ctx *gin.Context) {
+	type Req struct {
+		Id int64 `json:"id"`
+	}
+	var req Req
+	if err := ctx.Bind(&req); err != nil {
+		return
+	}
+	reviewer, ok := h.userId(ctx)
+	if !ok {
+		return
+	}
+	err := h.svc.Approve(ctx.Request.Context(), req.Id, reviewer)
+	h.writeResult(ctx, err, req.Id, "审核通过文章失败")
+}
+
+// Reject 驳回，理由会展示给作者
+func (h *ArticleReviewHandler) Reject(ctx *gin.Context) {
+	type Req struct {
+		Id     int64  `json:"id"`
+		Reason string `json:"reason"`
+	}
+	var req Req
+	if err := ctx.Bind(&req); err != nil {
+		return
+	}
+	if req.Reason == "" || utf8.RuneCountInString(req.Reason) > maxReviewReasonLength {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 4,
+			Msg:  "驳回理由有误",
+		})
+		return
+	}
+	reviewer, ok := h.userId(ctx)
+	if !ok {
+		return
+	}
+	err := h.svc.Reject(ctx.Request.Context(), req.Id, reviewer, req.Reason)
+	h.writeResult(ctx, err, req.Id, "驳回文章失败")
+}
+
+// Latest 作者查看最近一次的审核结果
+func (h *ArticleReviewHandler) Latest(ctx *gin.Context) {
+	id, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 4,
+			Msg:  "参数错误",
+		})
+		return
+	}
+	userId, ok := h.userId(ctx)
+	if !ok {
+		return
+	}
+	review, err := h.svc.GetLatest(ctx.Request.Context(), id, userId)
+	switch {
+	case err == nil:
+		ctx.JSON(http.StatusOK, Result{
+			Data: ArticleReviewVO{
+				ArtId:  review.ArtId,
+				Result: review.Result.ToUint8(),
+				Reason: review.Reason,
+				Ctime:  review.Ctime.UnixMilli(),
+			},
+		})
+	case errors.Is(err, service.ErrReviewNotFound):
+		ctx.JSON(http.StatusOK, Result{
+			Code: errs.ArticleReviewNotFound,
+			Msg:  "没有审核记录",
+		})
+	default:
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "系统错误",
+		})
+		h.l.Error("查询审核结果失败", logger.Error(err),
+			logger.Int64("id", id), logger.Int64("uid", userId))
+	}
+}
+
+func (h *ArticleReviewHandler) writeResult(ctx *gin.Context, err error, id int64, logMsg string) {
+	switch {
+	case err == nil:
+		ctx.JSON(http.StatusOK, Result{
+			Msg: "OK",
+		})
+	case errors.Is(err, service.ErrArticleNotFound):
+		ctx.JSON(http.StatusOK, Result{
+			Code: errs.ArticleNotFound,
+			Msg:  "文章不存在",
+		})
+	case errors.Is(err, service.ErrInvalidStatusTransition):
+		// 可能已经被别的管理员处理了，或者作者又改了文章
+		ctx.JSON(http.StatusOK, Result{
+			Code: errs.ArticleInvalidStatus,
+			Msg:  "文章不在等待审核",
+		})
+	default:
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "系统错误",
+		})
+		h.l.Error(logMsg, logger.Error(err), logger.Int64("id", id))
+	}
+}
+
+func (h *ArticleReviewHandler) userId(ctx *gin.Context) (int64, bool) {
+	uid, _ := ctx.Get("userId")
+	userId, ok := uid.(int64)
+	if !ok {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "系统错误",
+		})
+		h.l.Error("未发现用户的session信息")
+	}
+	return userId, ok
+}
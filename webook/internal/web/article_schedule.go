@@ -0,0 +1,92 @@
+package web
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"time"
+	"webook/webook/internal/errs"
+	"webook/webook/internal/service"
+	"webook/webook/pkg/logger"
+)
+
+// CancelSchedule 取消定时发表
+func (h *ArticleHandler) CancelSchedule(ctx *gin.Context) {
+	type Req struct {
+		Id int64 `json:"id"`
+	}
+	var req Req
+	if err := ctx.Bind(&req); err != nil {
+		return
+	}
+	uid, _ := ctx.Get("userId")
+	userId, ok := uid.(int64)
+	if !ok {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "系统错误",
+		})
+		h.l.Error("未发现用户的session信息")
+		return
+	}
+	err := h.svc.CancelSchedule(ctx.Request.Context(), req.Id, userId)
+	h.writeScheduleResult(ctx, err, req.Id, "取消定时发表失败")
+}
+
+// Reschedule 修改定时发表的时间
+func (h *ArticleHandler) Reschedule(ctx *gin.Context) {
+	type Req struct {
+		Id int64 `json:"id"`
+		// 毫秒数
+		PublishAt int64 `json:"publishAt"`
+	}
+	var req Req
+	if err := ctx.Bind(&req); err != nil {
+		return
+	}
+	publishAt := time.UnixMilli(req.PublishAt)
+	if !publishAt.After(time.Now()) {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 4,
+			Msg:  "发表时间有误",
+		})
+		return
+	}
+	uid, _ := ctx.Get("userId")
+	userId, ok := uid.(int64)
+	if !ok {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "系统错误",
+		})
+		h.l.Error("未发现用户的session信息")
+		return
+	}
+	err := h.svc.Reschedule(ctx.Request.Context(), req.Id, userId, publishAt)
+	h.writeScheduleResult(ctx, err, req.Id, "修改定时发表失败")
+}
+
+func (h *ArticleHandler) writeScheduleResult(ctx *gin.Context, err error, id int64, logMsg string) {
+	switch {
+	case err == nil:
+		ctx.JSON(http.StatusOK, Result{
+			Msg: "OK",
+		})
+	case errors.Is(err, service.ErrArticleNotFound):
+		ctx.JSON(http.StatusOK, Result{
+			Code: errs.ArticleNotFound,
+			Msg:  "文章不存在",
+		})
+	case errors.Is(err, service.ErrInvalidStatusTransition):
+		ctx.JSON(http.StatusOK, Result{
+			Code: errs.ArticleInvalidStatus,
+			Msg:  "文章没有在等待定时发表",
+		})
+	default:
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "系统错误",
+		})
+		h.l.Error(logMsg, logger.Error(err), logger.Int64("id", id))
+	}
+}
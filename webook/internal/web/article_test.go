@@ -11,7 +11,9 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 	"webook/webook/internal/domain"
+	"webook/webook/internal/errs"
 	"webook/webook/internal/service"
 	svcmocks "webook/webook/internal/service/mocks"
 	"webook/webook/pkg/logger"
@@ -76,6 +78,156 @@ func TestArticleHandler_Publish(t *testing.T) {
 				Msg:  "系统错误",
 			},
 		},
+		{
+			name: "标签规范化之后发表",
+			reqBody: `
+	{
+		"title": "我的标题",
+		"content": "我的内容",
+		"category": 1,
+		"tags": ["#Go", " go ", "Micro_Service", "ＧＯ"]
+	}
+`,
+			mock: func(ctrl *gomock.Controller) service.ArticleService {
+				svc := svcmocks.NewMockArticleService(ctrl)
+				svc.EXPECT().Publish(gomock.Any(), domain.Article{
+					Title:    "我的标题",
+					Content:  "我的内容",
+					Category: domain.ArticleCategoryBackend,
+					Tags:     []string{"go", "micro-service"},
+					Author: domain.Author{
+						Id: 789,
+					},
+				}).Return(int64(1), nil)
+				return svc
+			},
+			wantCode: http.StatusOK,
+			wantBody: Result{
+				Data: float64(1),
+				Msg:  "OK",
+			},
+		},
+		{
+			name: "包含敏感词",
+			reqBody: `
+	{
+		"title": "我的标题",
+		"content": "违禁内容"
+	}
+`,
+			mock: func(ctrl *gomock.Controller) service.ArticleService {
+				svc := svcmocks.NewMockArticleService(ctrl)
+				svc.EXPECT().Publish(gomock.Any(), gomock.Any()).
+					Return(int64(0), &service.SensitiveWordsError{Words: []string{"违禁"}})
+				return svc
+			},
+			wantCode: http.StatusOK,
+			wantBody: Result{
+				Code: errs.ContentSensitive,
+				Msg:  "文章包含敏感词",
+				Data: []any{"违禁"},
+			},
+		},
+		{
+			name: "需要人工审核",
+			reqBody: `
+	{
+		"title": "我的标题",
+		"content": "擦边内容"
+	}
+`,
+			mock: func(ctrl *gomock.Controller) service.ArticleService {
+				svc := svcmocks.NewMockArticleService(ctrl)
+				svc.EXPECT().Publish(gomock.Any(), gomock.Any()).Return(int64(1), service.ErrPendingReview)
+				return svc
+			},
+			wantCode: http.StatusOK,
+			wantBody: Result{
+				Data: float64(1),
+				Msg:  "文章需要人工审核，审核通过之后会自动发表",
+			},
+		},
+		{
+			name: "分类不存在",
+			reqBody: `
+	{
+		"title": "我的标题",
+		"content": "我的内容",
+		"category": 100
+	}
+`,
+			mock: func(ctrl *gomock.Controller) service.ArticleService {
+				return svcmocks.NewMockArticleService(ctrl)
+			},
+			wantCode: http.StatusOK,
+			wantBody: Result{
+				Code: 4,
+				Msg:  "分类有误",
+			},
+		},
+		{
+			name: "标签太多",
+			reqBody: `
+	{
+		"title": "我的标题",
+		"content": "我的内容",
+		"tags": ["a", "b", "c", "d", "e", "f"]
+	}
+`,
+			mock: func(ctrl *gomock.Controller) service.ArticleService {
+				return svcmocks.NewMockArticleService(ctrl)
+			},
+			wantCode: http.StatusOK,
+			wantBody: Result{
+				Code: 4,
+				Msg:  "最多 5 个标签，每个标签不超过 20 个字",
+			},
+		},
+		{
+			name: "定时发表的时间已经过去了",
+			reqBody: `
+	{
+		"title": "我的标题",
+		"content": "我的内容",
+		"publishAt": 1000
+	}
+`,
+			mock: func(ctrl *gomock.Controller) service.ArticleService {
+				return svcmocks.NewMockArticleService(ctrl)
+			},
+			wantCode: http.StatusOK,
+			wantBody: Result{
+				Code: 4,
+				Msg:  "发表时间有误",
+			},
+		},
+		{
+			name: "当前状态不能发表",
+			reqBody: `
+	{
+		"id": 2,
+		"title": "我的标题",
+		"content": "我的内容"
+	}
+`,
+			mock: func(ctrl *gomock.Controller) service.ArticleService {
+				svc := svcmocks.NewMockArticleService(ctrl)
+				svc.EXPECT().Publish(gomock.Any(), domain.Article{
+					Id:      2,
+					Title:   "我的标题",
+					Content: "我的内容",
+					Author: domain.Author{
+						Id: 789,
+					},
+				}).Return(int64(0), service.ErrInvalidStatusTransition)
+				return svc
+			},
+			wantCode: http.StatusOK,
+			wantBody: Result{
+				Code: errs.ArticleInvalidStatus,
+				Msg:  "文章当前状态不能发表",
+			},
+		},
 	}
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
@@ -108,3 +260,117 @@ func TestArticleHandler_Publish(t *testing.T) {
 		})
 	}
 }
+
+func TestArticleHandler_Detail(t *testing.T) {
+	testCases := []struct {
+		name     string
+		id       string
+		mock     func(ctrl *gomock.Controller) service.ArticleService
+		wantCode int
+		wantBody Result
+	}{
+		{
+			name: "查询成功",
+			id:   "1",
+			mock: func(ctrl *gomock.Controller) service.ArticleService {
+				svc := svcmocks.NewMockArticleService(ctrl)
+				svc.EXPECT().GetById(gomock.Any(), int64(1)).Return(domain.Article{
+					Id:      1,
+					Title:   "我的标题",
+					Content: "我的内容",
+					Author: domain.Author{
+						Id: 789,
+					},
+					Status: domain.ArticleStatusUnpublished,
+					Ctime:  time.UnixMilli(123),
+					Utime:  time.UnixMilli(456),
+				}, nil)
+				return svc
+			},
+			wantCode: http.StatusOK,
+			wantBody: Result{
+				Data: map[string]any{
+					"id":         float64(1),
+					"title":      "我的标题",
+					"abstract":   "",
+					"content":    "我的内容",
+					"authorId":   float64(789),
+					"authorName": "",
+					"status":     float64(1),
+					"category":   float64(0),
+					"ctime":      float64(123),
+					"utime":      float64(456),
+				},
+			},
+		},
+		{
+			name: "不是作者本人",
+			id:   "2",
+			mock: func(ctrl *gomock.Controller) service.ArticleService {
+				svc := svcmocks.NewMockArticleService(ctrl)
+				svc.EXPECT().GetById(gomock.Any(), int64(2)).Return(domain.Article{
+					Id:      2,
+					Title:   "我的标题",
+					Content: "我的内容",
+					Author: domain.Author{
+						Id: 123,
+					},
+				}, nil)
+				return svc
+			},
+			wantCode: http.StatusOK,
+			wantBody: Result{
+				Code: errs.ArticleNotFound,
+				Msg:  "文章不存在",
+			},
+		},
+		{
+			name: "文章不存在",
+			id:   "3",
+			mock: func(ctrl *gomock.Controller) service.ArticleService {
+				svc := svcmocks.NewMockArticleService(ctrl)
+				svc.EXPECT().GetById(gomock.Any(), int64(3)).
+					Return(domain.Article{}, service.ErrArticleNotFound)
+				return svc
+			},
+			wantCode: http.StatusOK,
+			wantBody: Result{
+				Code: errs.ArticleNotFound,
+				Msg:  "文章不存在",
+			},
+		},
+		{
+			name: "id不合法",
+			id:   "abc",
+			mock: func(ctrl *gomock.Controller) service.ArticleService {
+				return svcmocks.NewMockArticleService(ctrl)
+			},
+			wantCode: http.StatusOK,
+			wantBody: Result{
+				Code: 4,
+				Msg:  "参数错误",
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			server := gin.Default()
+			server.Use(func(ctx *gin.Context) {
+				ctx.Set("userId", int64(789))
+			})
+			h := NewArticleHandler(tc.mock(ctrl), logger.NewNoOpLogger())
+			h.RegisterRouter(server)
+			req, err := http.NewRequest(http.MethodGet, "/articles/detail/"+tc.id, nil)
+			require.NoError(t, err)
+			resp := httptest.NewRecorder()
+			server.ServeHTTP(resp, req)
+			assert.Equal(t, tc.wantCode, resp.Code)
+			var result Result
+			err = json.NewDecoder(resp.Body).Decode(&result)
+			require.NoError(t, err)
+			assert.Equal(t, tc.wantBody, result)
+		})
+	}
+}
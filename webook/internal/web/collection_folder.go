@@ -0,0 +1,365 @@
+package web
+
+import (
+	"errors"
+	"github.com/ecodeclub/ekit/slice"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"strings"
+	"unicode/utf8"
+	"webook/webook/internal/domain"
+	"webook/webook/internal/errs"
+	"webook/webook/internal/service"
+	"webook/webook/pkg/logger"
+)
+
+var _ handler = (*CollectionFolderHandler)(nil)
+
+// folderNameMaxLen 收藏夹名称最多多少个字
+const folderNameMaxLen = 32
+
+// CollectionFolderHandler 收藏夹，都需要登录
+type CollectionFolderHandler struct {
+	svc service.CollectionFolderService
+	l   logger.Logger
+}
+
+func NewCollectionFolderHandler(svc service.CollectionFolderService, l logger.Logger) *CollectionFolderHandler {
+	return &CollectionFolderHandler{svc: svc, l: l}
+}
+
+func (h *CollectionFolderHandler) RegisterRouter(server *gin.Engine) {
+	g := server.Group("/collection/folders")
+	g.POST("/create", h.Create)
+	g.POST("/rename", h.Rename)
+	g.POST("/delete", h.Delete)
+	g.POST("/list", h.List)
+	ag := g.Group("/articles")
+	ag.POST("/add", h.AddArticle)
+	ag.POST("/remove", h.RemoveArticle)
+	ag.POST("/list", h.ListArticles)
+}
+
+// Create 创建收藏夹
+func (h *CollectionFolderHandler) Create(ctx *gin.Context) {
+	type Req struct {
+		Name string `json:"name"`
+	}
+	var req Req
+	if err := ctx.Bind(&req); err != nil {
+		return
+	}
+	name, ok := h.checkName(ctx, req.Name)
+	if !ok {
+		return
+	}
+	userId, ok := h.userId(ctx)
+	if !ok {
+		return
+	}
+	id, err := h.svc.Create(ctx.Request.Context(), domain.CollectionFolder{
+		Uid:  userId,
+		Name: name,
+	})
+	switch {
+	case err == nil:
+		ctx.JSON(http.StatusOK, Result{
+			Data: id,
+		})
+	case errors.Is(err, service.ErrFolderNameDuplicate):
+		ctx.JSON(http.StatusOK, Result{
+			Code: errs.CollectionFolderNameDuplicate,
+			Msg:  "收藏夹重名",
+		})
+	default:
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "系统错误",
+		})
+		h.l.Error("创建收藏夹失败", logger.Error(err), logger.Int64("uid", userId))
+	}
+}
+
+// Rename 重命名收藏夹
+func (h *CollectionFolderHandler) Rename(ctx *gin.Context) {
+	type Req struct {
+		Id   int64  `json:"id"`
+		Name string `json:"name"`
+	}
+	var req Req
+	if err := ctx.Bind(&req); err != nil {
+		return
+	}
+	name, ok := h.checkName(ctx, req.Name)
+	if !ok {
+		return
+	}
+	userId, ok := h.userId(ctx)
+	if !ok {
+		return
+	}
+	err := h.svc.Rename(ctx.Request.Context(), req.Id, userId, name)
+	switch {
+	case err == nil:
+		ctx.JSON(http.StatusOK, Result{
+			Msg: "OK",
+		})
+	case errors.Is(err, service.ErrFolderNotFound):
+		ctx.JSON(http.StatusOK, Result{
+			Code: errs.CollectionFolderNotFound,
+			Msg:  "收藏夹不存在",
+		})
+	case errors.Is(err, service.ErrFolderNameDuplicate):
+		ctx.JSON(http.StatusOK, Result{
+			Code: errs.CollectionFolderNameDuplicate,
+			Msg:  "收藏夹重名",
+		})
+	default:
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "系统错误",
+		})
+		h.l.Error("重命名收藏夹失败", logger.Error(err),
+			logger.Int64("uid", userId), logger.Int64("id", req.Id))
+	}
+}
+
+// Delete 删除收藏夹，里面的文章不会取消收藏
+func (h *CollectionFolderHandler) Delete(ctx *gin.Context) {
+	type Req struct {
+		Id int64 `json:"id"`
+	}
+	var req Req
+	if err := ctx.Bind(&req); err != nil {
+		return
+	}
+	userId, ok := h.userId(ctx)
+	if !ok {
+		return
+	}
+	err := h.svc.Delete(ctx.Request.Context(), req.Id, userId)
+	switch {
+	case err == nil:
+		ctx.JSON(http.StatusOK, Result{
+			Msg: "OK",
+		})
+	case errors.Is(err, service.ErrFolderNotFound):
+		ctx.JSON(http.StatusOK, Result{
+			Code: errs.CollectionFolderNotFound,
+			Msg:  "收藏夹不存在",
+		})
+	default:
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "系统错误",
+		})
+		h.l.Error("删除收藏夹失败", logger.Error(err),
+			logger.Int64("uid", userId), logger.Int64("id", req.Id))
+	}
+}
+
+// List 自己的收藏夹列表
+func (h *CollectionFolderHandler) List(ctx *gin.Context) {
+	type Req struct {
+		Offset int `json:"offset"`
+		Limit  int `json:"limit"`
+	}
+	var req Req
+	if err := ctx.Bind(&req); err != nil {
+		return
+	}
+	if req.Limit <= 0 || req.Limit > 100 {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 4,
+			Msg:  "输入有误",
+		})
+		return
+	}
+	userId, ok := h.userId(ctx)
+	if !ok {
+		return
+	}
+	folders, err := h.svc.List(ctx.Request.Context(), userId, req.Offset, req.Limit)
+	if err != nil {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "系统错误",
+		})
+		h.l.Error("查找收藏夹列表失败", logger.Error(err), logger.Int64("uid", userId))
+		return
+	}
+	ctx.JSON(http.StatusOK, Result{
+		Data: slice.Map[domain.CollectionFolder, CollectionFolderVO](folders,
+			func(idx int, src domain.CollectionFolder) CollectionFolderVO {
+				return CollectionFolderVO{
+					Id:    src.Id,
+					Name:  src.Name,
+					Ctime: src.Ctime.UnixMilli(),
+					Utime: src.Utime.UnixMilli(),
+				}
+			}),
+	})
+}
+
+// AddArticle 把文章放进收藏夹
+func (h *CollectionFolderHandler) AddArticle(ctx *gin.Context) {
+	type Req struct {
+		Id    int64 `json:"id"`
+		ArtId int64 `json:"artId"`
+	}
+	var req Req
+	if err := ctx.Bind(&req); err != nil {
+		return
+	}
+	userId, ok := h.userId(ctx)
+	if !ok {
+		return
+	}
+	err := h.svc.AddArticle(ctx.Request.Context(), req.Id, userId, req.ArtId)
+	switch {
+	case err == nil:
+		ctx.JSON(http.StatusOK, Result{
+			Msg: "OK",
+		})
+	case errors.Is(err, service.ErrFolderNotFound):
+		ctx.JSON(http.StatusOK, Result{
+			Code: errs.CollectionFolderNotFound,
+			Msg:  "收藏夹不存在",
+		})
+	case errors.Is(err, service.ErrArticleNotFound):
+		ctx.JSON(http.StatusOK, Result{
+			Code: errs.ArticleNotFound,
+			Msg:  "文章不存在",
+		})
+	case errors.Is(err, service.ErrFolderItemDuplicate):
+		ctx.JSON(http.StatusOK, Result{
+			Code: errs.CollectionFolderItemDuplicate,
+			Msg:  "文章已经在收藏夹里了",
+		})
+	default:
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "系统错误",
+		})
+		h.l.Error("放进收藏夹失败", logger.Error(err), logger.Int64("uid", userId),
+			logger.Int64("id", req.Id), logger.Int64("art_id", req.ArtId))
+	}
+}
+
+// RemoveArticle 把文章从收藏夹移除
+func (h *CollectionFolderHandler) RemoveArticle(ctx *gin.Context) {
+	type Req struct {
+		Id    int64 `json:"id"`
+		ArtId int64 `json:"artId"`
+	}
+	var req Req
+	if err := ctx.Bind(&req); err != nil {
+		return
+	}
+	userId, ok := h.userId(ctx)
+	if !ok {
+		return
+	}
+	err := h.svc.RemoveArticle(ctx.Request.Context(), req.Id, userId, req.ArtId)
+	switch {
+	case err == nil:
+		ctx.JSON(http.StatusOK, Result{
+			Msg: "OK",
+		})
+	case errors.Is(err, service.ErrFolderNotFound):
+		ctx.JSON(http.StatusOK, Result{
+			Code: errs.CollectionFolderNotFound,
+			Msg:  "收藏夹不存在",
+		})
+	default:
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "系统错误",
+		})
+		h.l.Error("从收藏夹移除失败", logger.Error(err), logger.Int64("uid", userId),
+			logger.Int64("id", req.Id), logger.Int64("art_id", req.ArtId))
+	}
+}
+
+// ListArticles 收藏夹里的文章，只返回摘要
+func (h *CollectionFolderHandler) ListArticles(ctx *gin.Context) {
+	type Req struct {
+		Id     int64 `json:"id"`
+		Offset int   `json:"offset"`
+		Limit  int   `json:"limit"`
+	}
+	var req Req
+	if err := ctx.Bind(&req); err != nil {
+		return
+	}
+	if req.Limit <= 0 || req.Limit > 100 {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 4,
+			Msg:  "输入有误",
+		})
+		return
+	}
+	userId, ok := h.userId(ctx)
+	if !ok {
+		return
+	}
+	arts, err := h.svc.ListArticles(ctx.Request.Context(), req.Id, userId, req.Offset, req.Limit)
+	if errors.Is(err, service.ErrFolderNotFound) {
+		ctx.JSON(http.StatusOK, Result{
+			Code: errs.CollectionFolderNotFound,
+			Msg:  "收藏夹不存在",
+		})
+		return
+	}
+	if err != nil {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "系统错误",
+		})
+		h.l.Error("查找收藏夹里的文章失败", logger.Error(err),
+			logger.Int64("uid", userId), logger.Int64("id", req.Id))
+		return
+	}
+	ctx.JSON(http.StatusOK, Result{
+		Data: slice.Map[domain.Article, ArticleVO](arts, func(idx int, src domain.Article) ArticleVO {
+			return ArticleVO{
+				Id:         src.Id,
+				Title:      src.Title,
+				Abstract:   src.Abstract(),
+				AuthorId:   src.Author.Id,
+				AuthorName: src.Author.Name,
+				Status:     src.Status.ToUint8(),
+				Ctime:      src.Ctime.UnixMilli(),
+				Utime:      src.Utime.UnixMilli(),
+			}
+		}),
+	})
+}
+
+// checkName 去掉首尾的空格之后校验收藏夹名称
+// 返回 false 的时候已经写好了响应
+func (h *CollectionFolderHandler) checkName(ctx *gin.Context, name string) (string, bool) {
+	name = strings.TrimSpace(name)
+	if name == "" || utf8.RuneCountInString(name) > folderNameMaxLen {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 4,
+			Msg:  "收藏夹名称有误",
+		})
+		return "", false
+	}
+	return name, true
+}
+
+// userId 拿到登录用户，返回 false 的时候已经写好了响应
+func (h *CollectionFolderHandler) userId(ctx *gin.Context) (int64, bool) {
+	uid, _ := ctx.Get("userId")
+	userId, ok := uid.(int64)
+	if !ok {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "系统错误",
+		})
+		h.l.Error("未发现用户的session信息")
+	}
+	return userId, ok
+}
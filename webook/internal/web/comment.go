@@ -0,0 +1,282 @@
+package web
+
+import (
+	"errors"
+	"github.com/ecodeclub/ekit/slice"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+	"webook/webook/internal/domain"
+	"webook/webook/internal/errs"
+	"webook/webook/internal/service"
+	"webook/webook/pkg/logger"
+)
+
+var _ handler = (*CommentHandler)(nil)
+
+// commentMaxLen 一条评论最多多少个字
+const commentMaxLen = 1000
+
+// CommentHandler 文章的评论
+// /comments/pub 下面的接口不需要登录
+type CommentHandler struct {
+	svc service.CommentService
+	l   logger.Logger
+}
+
+func NewCommentHandler(svc service.CommentService, l logger.Logger) *CommentHandler {
+	return &CommentHandler{svc: svc, l: l}
+}
+
+func (h *CommentHandler) RegisterRouter(server *gin.Engine) {
+	g := server.Group("/comments")
+	g.POST("/create", h.Create)
+	g.POST("/delete", h.Delete)
+	pg := g.Group("/pub")
+	pg.POST("/list", h.List)
+	pg.POST("/replies", h.Replies)
+	pg.GET("/count/:artId", h.Count)
+}
+
+// Create 发表评论或者回复
+func (h *CommentHandler) Create(ctx *gin.Context) {
+	type Req struct {
+		ArtId int64 `json:"artId"`
+		// 回复哪一条评论，0 表示根评论
+		ParentId int64  `json:"parentId"`
+		Content  string `json:"content"`
+	}
+	var req Req
+	if err := ctx.Bind(&req); err != nil {
+		return
+	}
+	content := strings.TrimSpace(req.Content)
+	if content == "" || utf8.RuneCountInString(content) > commentMaxLen {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 4,
+			Msg:  "评论内容有误",
+		})
+		return
+	}
+	uid, _ := ctx.Get("userId")
+	userId, ok := uid.(int64)
+	if !ok {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "系统错误",
+		})
+		h.l.Error("未发现用户的session信息")
+		return
+	}
+	id, err := h.svc.Create(ctx.Request.Context(), domain.Comment{
+		ArtId:       req.ArtId,
+		ParentId:    req.ParentId,
+		Commentator: domain.Author{Id: userId},
+		Content:     content,
+	})
+	var swErr *service.SensitiveWordsError
+	switch {
+	case err == nil:
+		ctx.JSON(http.StatusOK, Result{
+			Data: id,
+		})
+	case errors.As(err, &swErr):
+		ctx.JSON(http.StatusOK, Result{
+			Code: errs.ContentSensitive,
+			Msg:  "评论包含敏感词",
+			Data: swErr.Words,
+		})
+	case errors.Is(err, service.ErrArticleNotFound):
+		ctx.JSON(http.StatusOK, Result{
+			Code: errs.ArticleNotFound,
+			Msg:  "文章不存在",
+		})
+	case errors.Is(err, service.ErrCommentNotFound):
+		ctx.JSON(http.StatusOK, Result{
+			Code: errs.CommentNotFound,
+			Msg:  "回复的评论不存在",
+		})
+	default:
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "系统错误",
+		})
+		h.l.Error("发表评论失败", logger.Error(err),
+			logger.Int64("uid", userId), logger.Int64("art_id", req.ArtId))
+	}
+}
+
+// Delete 删除评论，根评论下面的回复会一起删除
+func (h *CommentHandler) Delete(ctx *gin.Context) {
+	type Req struct {
+		Id int64 `json:"id"`
+	}
+	var req Req
+	if err := ctx.Bind(&req); err != nil {
+		return
+	}
+	uid, _ := ctx.Get("userId")
+	userId, ok := uid.(int64)
+	if !ok {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "系统错误",
+		})
+		h.l.Error("未发现用户的session信息")
+		return
+	}
+	err := h.svc.Delete(ctx.Request.Context(), req.Id, userId)
+	switch {
+	case err == nil:
+		ctx.JSON(http.StatusOK, Result{
+			Msg: "OK",
+		})
+	case errors.Is(err, service.ErrCommentNotFound):
+		ctx.JSON(http.StatusOK, Result{
+			Code: errs.CommentNotFound,
+			Msg:  "评论不存在",
+		})
+	default:
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "系统错误",
+		})
+		h.l.Error("删除评论失败", logger.Error(err),
+			logger.Int64("uid", userId), logger.Int64("id", req.Id))
+	}
+}
+
+// List 文章的根评论，按照时间倒序
+// 第一页 minId 传 0，下一页传上一页最后一条的 id
+func (h *CommentHandler) List(ctx *gin.Context) {
+	type Req struct {
+		ArtId int64 `json:"artId"`
+		MinId int64 `json:"minId"`
+		Limit int   `json:"limit"`
+	}
+	var req Req
+	if err := ctx.Bind(&req); err != nil {
+		return
+	}
+	if req.Limit <= 0 || req.Limit > 100 {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 4,
+			Msg:  "输入有误",
+		})
+		return
+	}
+	cs, err := h.svc.ListRoots(ctx.Request.Context(), req.ArtId, req.MinId, req.Limit)
+	if errors.Is(err, service.ErrArticleNotFound) {
+		ctx.JSON(http.StatusOK, Result{
+			Code: errs.ArticleNotFound,
+			Msg:  "文章不存在",
+		})
+		return
+	}
+	if err != nil {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "系统错误",
+		})
+		h.l.Error("查找评论失败", logger.Error(err), logger.Int64("art_id", req.ArtId))
+		return
+	}
+	ctx.JSON(http.StatusOK, Result{
+		Data: h.toVOs(cs),
+	})
+}
+
+// Replies 根评论下面的回复，按照时间正序
+// 第一页 maxId 传 0，下一页传上一页最后一条的 id
+func (h *CommentHandler) Replies(ctx *gin.Context) {
+	type Req struct {
+		RootId int64 `json:"rootId"`
+		MaxId  int64 `json:"maxId"`
+		Limit  int   `json:"limit"`
+	}
+	var req Req
+	if err := ctx.Bind(&req); err != nil {
+		return
+	}
+	if req.Limit <= 0 || req.Limit > 100 {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 4,
+			Msg:  "输入有误",
+		})
+		return
+	}
+	cs, err := h.svc.ListReplies(ctx.Request.Context(), req.RootId, req.MaxId, req.Limit)
+	switch {
+	case errors.Is(err, service.ErrArticleNotFound):
+		ctx.JSON(http.StatusOK, Result{
+			Code: errs.ArticleNotFound,
+			Msg:  "文章不存在",
+		})
+		return
+	case errors.Is(err, service.ErrCommentNotFound):
+		ctx.JSON(http.StatusOK, Result{
+			Code: errs.CommentNotFound,
+			Msg:  "评论不存在",
+		})
+		return
+	case err != nil:
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "系统错误",
+		})
+		h.l.Error("查找回复失败", logger.Error(err), logger.Int64("root_id", req.RootId))
+		return
+	}
+	ctx.JSON(http.StatusOK, Result{
+		Data: h.toVOs(cs),
+	})
+}
+
+// Count 文章的评论数，包括回复
+func (h *CommentHandler) Count(ctx *gin.Context) {
+	artId, err := strconv.ParseInt(ctx.Param("artId"), 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 4,
+			Msg:  "参数错误",
+		})
+		return
+	}
+	cnt, err := h.svc.Count(ctx.Request.Context(), artId)
+	if errors.Is(err, service.ErrArticleNotFound) {
+		ctx.JSON(http.StatusOK, Result{
+			Code: errs.ArticleNotFound,
+			Msg:  "文章不存在",
+		})
+		return
+	}
+	if err != nil {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "系统错误",
+		})
+		h.l.Error("查找评论数失败", logger.Error(err), logger.Int64("art_id", artId))
+		return
+	}
+	ctx.JSON(http.StatusOK, Result{
+		Data: cnt,
+	})
+}
+
+func (h *CommentHandler) toVOs(cs []domain.Comment) []CommentVO {
+	return slice.Map[domain.Comment, CommentVO](cs, func(idx int, src domain.Comment) CommentVO {
+		return CommentVO{
+			Id:       src.Id,
+			ArtId:    src.ArtId,
+			Uid:      src.Commentator.Id,
+			UserName: src.Commentator.Name,
+			Content:  src.Content,
+			RootId:   src.RootId,
+			ParentId: src.ParentId,
+			ReplyCnt: src.ReplyCnt,
+			Ctime:    src.Ctime.UnixMilli(),
+		}
+	})
+}
@@ -0,0 +1,271 @@
+package web
+
+import (
+	"context"
+	"errors"
+	"github.com/ecodeclub/ekit/slice"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"strconv"
+	"time"
+	"webook/webook/internal/domain"
+	"webook/webook/internal/errs"
+	"webook/webook/internal/service"
+	"webook/webook/pkg/logger"
+)
+
+var _ handler = (*FollowHandler)(nil)
+
+// FollowHandler 关注和关注流，都需要登录
+type FollowHandler struct {
+	svc     service.FollowService
+	feedSvc service.FeedService
+	l       logger.Logger
+}
+
+func NewFollowHandler(svc service.FollowService, feedSvc service.FeedService, l logger.Logger) *FollowHandler {
+	return &FollowHandler{svc: svc, feedSvc: feedSvc, l: l}
+}
+
+func (h *FollowHandler) RegisterRouter(server *gin.Engine) {
+	g := server.Group("/follow")
+	g.POST("/follow", h.Follow)
+	g.POST("/cancel", h.CancelFollow)
+	g.POST("/followers", h.Followers)
+	g.POST("/followees", h.Followees)
+	g.GET("/statics/:uid", h.Statics)
+	g.POST("/feed", h.Feed)
+}
+
+// Follow 关注
+func (h *FollowHandler) Follow(ctx *gin.Context) {
+	type Req struct {
+		Followee int64 `json:"followee"`
+	}
+	var req Req
+	if err := ctx.Bind(&req); err != nil {
+		return
+	}
+	userId, ok := h.userId(ctx)
+	if !ok {
+		return
+	}
+	err := h.svc.Follow(ctx.Request.Context(), userId, req.Followee)
+	switch {
+	case err == nil:
+		ctx.JSON(http.StatusOK, Result{
+			Msg: "OK",
+		})
+	case errors.Is(err, service.ErrFollowSelf):
+		ctx.JSON(http.StatusOK, Result{
+			Code: 4,
+			Msg:  "不能关注自己",
+		})
+	case errors.Is(err, service.ErrUserNotFound):
+		ctx.JSON(http.StatusOK, Result{
+			Code: errs.UserNotFound,
+			Msg:  "用户不存在",
+		})
+	case errors.Is(err, service.ErrFollowDuplicate):
+		ctx.JSON(http.StatusOK, Result{
+			Code: errs.UserAlreadyFollowed,
+			Msg:  "已经关注过了",
+		})
+	default:
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "系统错误",
+		})
+		h.l.Error("关注失败", logger.Error(err),
+			logger.Int64("uid", userId), logger.Int64("followee", req.Followee))
+	}
+}
+
+// CancelFollow 取消关注
+func (h *FollowHandler) CancelFollow(ctx *gin.Context) {
+	type Req struct {
+		Followee int64 `json:"followee"`
+	}
+	var req Req
+	if err := ctx.Bind(&req); err != nil {
+		return
+	}
+	userId, ok := h.userId(ctx)
+	if !ok {
+		return
+	}
+	err := h.svc.CancelFollow(ctx.Request.Context(), userId, req.Followee)
+	if err != nil {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "系统错误",
+		})
+		h.l.Error("取消关注失败", logger.Error(err),
+			logger.Int64("uid", userId), logger.Int64("followee", req.Followee))
+		return
+	}
+	ctx.JSON(http.StatusOK, Result{
+		Msg: "OK",
+	})
+}
+
+// FollowListReq 关注列表和粉丝列表，Uid 为 0 表示查看自己的
+type FollowListReq struct {
+	Uid    int64 `json:"uid"`
+	Offset int   `json:"offset"`
+	Limit  int   `json:"limit"`
+}
+
+// Followers 粉丝列表
+func (h *FollowHandler) Followers(ctx *gin.Context) {
+	h.list(ctx, "查找粉丝列表失败", h.svc.ListFollowers,
+		func(r domain.FollowRelation) domain.Author { return r.Follower })
+}
+
+// Followees 关注列表
+func (h *FollowHandler) Followees(ctx *gin.Context) {
+	h.list(ctx, "查找关注列表失败", h.svc.ListFollowees,
+		func(r domain.FollowRelation) domain.Author { return r.Followee })
+}
+
+// list 粉丝列表和关注列表只是查询的方向不一样，who 取出列表里面要展示的那个人
+func (h *FollowHandler) list(ctx *gin.Context, errMsg string,
+	listFn func(ctx context.Context, uid int64, offset int, limit int) ([]domain.FollowRelation, error),
+	who func(r domain.FollowRelation) domain.Author) {
+	var req FollowListReq
+	if err := ctx.Bind(&req); err != nil {
+		return
+	}
+	if req.Limit <= 0 || req.Limit > 100 {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 4,
+			Msg:  "输入有误",
+		})
+		return
+	}
+	uid := req.Uid
+	if uid <= 0 {
+		userId, ok := h.userId(ctx)
+		if !ok {
+			return
+		}
+		uid = userId
+	}
+	rs, err := listFn(ctx.Request.Context(), uid, req.Offset, req.Limit)
+	if err != nil {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "系统错误",
+		})
+		h.l.Error(errMsg, logger.Error(err), logger.Int64("uid", uid))
+		return
+	}
+	ctx.JSON(http.StatusOK, Result{
+		Data: slice.Map[domain.FollowRelation, FollowVO](rs, func(idx int, src domain.FollowRelation) FollowVO {
+			u := who(src)
+			return FollowVO{
+				Uid:      u.Id,
+				Nickname: u.Name,
+				Ctime:    src.Ctime.UnixMilli(),
+			}
+		}),
+	})
+}
+
+// Statics 关注数和粉丝数，以及当前用户有没有关注
+func (h *FollowHandler) Statics(ctx *gin.Context) {
+	uid, err := strconv.ParseInt(ctx.Param("uid"), 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 4,
+			Msg:  "参数错误",
+		})
+		return
+	}
+	userId, ok := h.userId(ctx)
+	if !ok {
+		return
+	}
+	statics, err := h.svc.GetStatics(ctx.Request.Context(), uid, userId)
+	if err != nil {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "系统错误",
+		})
+		h.l.Error("查找关注数据失败", logger.Error(err), logger.Int64("uid", uid))
+		return
+	}
+	ctx.JSON(http.StatusOK, Result{
+		Data: FollowStaticsVO{
+			Followers: statics.Followers,
+			Followees: statics.Followees,
+			Followed:  statics.Followed,
+		},
+	})
+}
+
+// Feed 关注的作者最近发表的文章
+// Before 是上一页最后一篇文章的 ctime，也就是发表时间，第一页传 0
+// 返回的数量可能少于 Limit，返回空列表才说明没有更多了
+func (h *FollowHandler) Feed(ctx *gin.Context) {
+	type Req struct {
+		Before int64 `json:"before"`
+		Limit  int   `json:"limit"`
+	}
+	var req Req
+	if err := ctx.Bind(&req); err != nil {
+		return
+	}
+	if req.Limit <= 0 || req.Limit > 100 {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 4,
+			Msg:  "输入有误",
+		})
+		return
+	}
+	userId, ok := h.userId(ctx)
+	if !ok {
+		return
+	}
+	before := time.Now()
+	if req.Before > 0 {
+		before = time.UnixMilli(req.Before)
+	}
+	arts, err := h.feedSvc.Following(ctx.Request.Context(), userId, before, req.Limit)
+	if err != nil {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "系统错误",
+		})
+		h.l.Error("查找关注流失败", logger.Error(err), logger.Int64("uid", userId))
+		return
+	}
+	ctx.JSON(http.StatusOK, Result{
+		Data: slice.Map[domain.Article, ArticleVO](arts, func(idx int, src domain.Article) ArticleVO {
+			return ArticleVO{
+				Id:         src.Id,
+				Title:      src.Title,
+				Abstract:   src.Abstract(),
+				AuthorId:   src.Author.Id,
+				AuthorName: src.Author.Name,
+				Status:     src.Status.ToUint8(),
+				Ctime:      src.Ctime.UnixMilli(),
+				Utime:      src.Utime.UnixMilli(),
+			}
+		}),
+	})
+}
+
+// userId 拿到登录用户，返回 false 的时候已经写好了响应
+func (h *FollowHandler) userId(ctx *gin.Context) (int64, bool) {
+	uid, _ := ctx.Get("userId")
+	userId, ok := uid.(int64)
+	if !ok {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "系统错误",
+		})
+		h.l.Error("未发现用户的session信息")
+	}
+	return userId, ok
+}
@@ -1,20 +1,28 @@
 package middleware
 
 import (
+	"errors"
 	"github.com/gin-gonic/gin"
 	"net/http"
+	"strings"
 	web2 "webook/webook/internal/web/jwt"
 )
 
+var errInvalidClaims = errors.New("token 里面的用户信息不对")
+
 // LoginJWTMiddleWareBuilder 登录校验，使用JWT机制
 type LoginJWTMiddleWareBuilder struct {
 	// 不进行登录校验的路径
 	paths []string
+	// 不进行登录校验的路径前缀，用于带路径参数的路由
+	prefixes []string
+	// 登录是可选的路径前缀，登录了就带上用户信息，没登录也放行
+	optionalPrefixes []string
 	web2.JWTHandler
 }
 
-func NewLoginJWTMiddleWareBuilder() *LoginJWTMiddleWareBuilder {
-	return &LoginJWTMiddleWareBuilder{}
+func NewLoginJWTMiddleWareBuilder(hdl web2.JWTHandler) *LoginJWTMiddleWareBuilder {
+	return &LoginJWTMiddleWareBuilder{JWTHandler: hdl}
 }
 
 func (l *LoginJWTMiddleWareBuilder) IgnorePaths(path string) *LoginJWTMiddleWareBuilder {
@@ -22,6 +30,20 @@ func (l *LoginJWTMiddleWareBuilder) IgnorePaths(path string) *LoginJWTMiddleWare
 	return l
 }
 
+// IgnorePathPrefix 以 prefix 开头的路径都不进行登录校验
+// 例如 /articles/pub/:id 这种带路径参数的，没办法用 IgnorePaths 一个个加进去
+func (l *LoginJWTMiddleWareBuilder) IgnorePathPrefix(prefix string) *LoginJWTMiddleWareBuilder {
+	l.prefixes = append(l.prefixes, prefix)
+	return l
+}
+
+// OptionalPathPrefix 以 prefix 开头的路径登录是可选的
+// 例如读者查看文章不需要登录，但是登录了要显示自己有没有点赞
+func (l *LoginJWTMiddleWareBuilder) OptionalPathPrefix(prefix string) *LoginJWTMiddleWareBuilder {
+	l.optionalPrefixes = append(l.optionalPrefixes, prefix)
+	return l
+}
+
 // Build 也可以叫CheckLogin
 func (l *LoginJWTMiddleWareBuilder) Build() gin.HandlerFunc {
 	return func(ctx *gin.Context) {
@@ -31,57 +53,77 @@ func (l *LoginJWTMiddleWareBuilder) Build() gin.HandlerFunc {
 				return
 			}
 		}
-
-		// 校验JWT Token，这里是短token校验，长token不会进来这里
-		// 前端把token放到 Authorization 首部
-		// 如果这里拿不到，后面的解析肯定失败
-		claims := &web2.JWTUserClaims{} // 要用指针，因为要作为参数，让被掉函数修改再返回来
-		// 我这里校验的是短token
-		err := l.CheckToken(ctx, claims, web2.AtKey)
-		//tokenStr := l.ExtractToken(ctx)
-		//token, err := jwt.ParseWithClaims(tokenStr, claims, func(token *jwt.Token) (interface{}, error) {
-		//	// 我这里校验的是短token
-		//	return web2.AtKey, nil
-		//})
-		if err != nil {
-			// 没登录
-			ctx.AbortWithStatus(http.StatusUnauthorized)
-			return
+		for _, prefix := range l.prefixes {
+			if strings.HasPrefix(ctx.Request.URL.Path, prefix) {
+				return
+			}
 		}
-		//if !token.Valid  {
-		//	// 没登录
-		//	ctx.AbortWithStatus(http.StatusUnauthorized)
-		//	return
-		//}
-		// 登录校验
-		if claims.UserAgent != ctx.Request.UserAgent() || claims.Uid == 0 { // Uid是数据库自增主键，我们用了默认从1开始，不可能为0
-			ctx.AbortWithStatus(http.StatusUnauthorized)
-			return
+		for _, prefix := range l.optionalPrefixes {
+			if strings.HasPrefix(ctx.Request.URL.Path, prefix) {
+				// 没有带 token 或者 token 无效都当作没有登录
+				if l.ExtractToken(ctx) != "" {
+					_ = l.check(ctx)
+				}
+				return
+			}
 		}
-		// 方便业务要拿到这个数据
-		ctx.Set("userId", claims.Uid)
-		ctx.Set("claims", claims)
-
-		// 判断有没有退出登录的ssid
-		err = l.CheckSession(ctx, claims.Ssid)
-		if err != nil {
-			// 要么Redis有问题，要么已经退出登录了
+		if err := l.check(ctx); err != nil {
+			// 没登录
 			ctx.AbortWithStatus(http.StatusUnauthorized)
-			return
 		}
+	}
+}
 
-		////刷新jwt token
-		//// 每一分钟刷一次
-		//if claims.ExpiresAt.Sub(time.Now()) > time.Minute*29 {
-		//	return
-		//}
-		//// 过期时间要重新设置一下
-		//claims.ExpiresAt = jwt.NewNumericDate(time.Now().Add(time.Minute * 30))
-		//// 再重新生成token
-		//tokenStr, err = token.SignedString([]byte("HiIilLa4O8Xy3Pm8C5mh5HymYaYt9eTj"))
-		//if err != nil {
-		//	log.Println("jwt 续约失败")
-		//}
-		//ctx.Header("x-jwt-token", tokenStr)
+// check 校验登录态，成功之后把用户信息放到 ctx 里面
+func (l *LoginJWTMiddleWareBuilder) check(ctx *gin.Context) error {
+	// 校验JWT Token，这里是短token校验，长token不会进来这里
+	// 前端把token放到 Authorization 首部
+	// 如果这里拿不到，后面的解析肯定失败
+	claims := &web2.JWTUserClaims{} // 要用指针，因为要作为参数，让被掉函数修改再返回来
+	// 我这里校验的是短token
+	err := l.CheckToken(ctx, claims, web2.AtKey)
+	//tokenStr := l.ExtractToken(ctx)
+	//token, err := jwt.ParseWithClaims(tokenStr, claims, func(token *jwt.Token) (interface{}, error) {
+	//	// 我这里校验的是短token
+	//	return web2.AtKey, nil
+	//})
+	if err != nil {
+		// 没登录
+		return err
+	}
+	//if !token.Valid  {
+	//	// 没登录
+	//	ctx.AbortWithStatus(http.StatusUnauthorized)
+	//	return
+	//}
+	// 登录校验
+	if claims.UserAgent != ctx.Request.UserAgent() || claims.Uid == 0 { // Uid是数据库自增主键，我们用了默认从1开始，不可能为0
+		return errInvalidClaims
 	}
+
+	// 判断有没有退出登录的ssid
+	err = l.CheckSession(ctx, claims.Ssid)
+	if err != nil {
+		// 要么Redis有问题，要么已经退出登录了
+		return err
+	}
+	// 方便业务要拿到这个数据
+	// 确认 session 有效之后再设置，可选登录的路径不会拿到已经退出登录的用户
+	ctx.Set("userId", claims.Uid)
+	ctx.Set("claims", claims)
+
+	////刷新jwt token
+	//// 每一分钟刷一次
+	//if claims.ExpiresAt.Sub(time.Now()) > time.Minute*29 {
+	//	return
+	//}
+	//// 过期时间要重新设置一下
+	//claims.ExpiresAt = jwt.NewNumericDate(time.Now().Add(time.Minute * 30))
+	//// 再重新生成token
+	//tokenStr, err = token.SignedString([]byte("HiIilLa4O8Xy3Pm8C5mh5HymYaYt9eTj"))
+	//if err != nil {
+	//	log.Println("jwt 续约失败")
+	//}
+	//ctx.Header("x-jwt-token", tokenStr)
+	return nil
 }
@@ -0,0 +1,40 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"webook/webook/internal/domain"
+	"webook/webook/internal/service"
+	"webook/webook/pkg/logger"
+)
+
+// PermissionMiddlewareBuilder 权限校验，用在路由分组上，要放在登录校验的后面
+// 权限不放在 JWT 里面，每次都查（有缓存），撤销角色可以马上生效
+type PermissionMiddlewareBuilder struct {
+	svc service.RBACService
+	l   logger.Logger
+}
+
+func NewPermissionMiddlewareBuilder(svc service.RBACService, l logger.Logger) *PermissionMiddlewareBuilder {
+	return &PermissionMiddlewareBuilder{svc: svc, l: l}
+}
+
+// Build 要求用户有 perm 权限，没有权限、没有登录或者查询失败都返回 403
+func (b *PermissionMiddlewareBuilder) Build(perm domain.Permission) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		uid, _ := ctx.Get("userId")
+		userId, ok := uid.(int64)
+		if !ok {
+			ctx.AbortWithStatus(http.StatusForbidden)
+			return
+		}
+		ok, err := b.svc.HasPermission(ctx.Request.Context(), userId, perm)
+		if err != nil {
+			b.l.Error("查询用户权限失败", logger.Error(err),
+				logger.Int64("uid", userId), logger.String("permission", string(perm)))
+		}
+		if !ok {
+			ctx.AbortWithStatus(http.StatusForbidden)
+		}
+	}
+}
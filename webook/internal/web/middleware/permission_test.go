@@ -0,0 +1,89 @@
+package middleware
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"webook/webook/internal/domain"
+	"webook/webook/internal/service"
+	svcmocks "webook/webook/internal/service/mocks"
+	"webook/webook/pkg/logger"
+)
+
+func TestPermissionMiddlewareBuilder_Build(t *testing.T) {
+	testCases := []struct {
+		name     string
+		mock     func(ctrl *gomock.Controller) service.RBACService
+		login    bool
+		wantCode int
+	}{
+		{
+			name: "有权限",
+			mock: func(ctrl *gomock.Controller) service.RBACService {
+				svc := svcmocks.NewMockRBACService(ctrl)
+				svc.EXPECT().HasPermission(gomock.Any(), int64(123), domain.PermissionArticleReview).
+					Return(true, nil)
+				return svc
+			},
+			login:    true,
+			wantCode: http.StatusOK,
+		},
+		{
+			name: "没有权限",
+			mock: func(ctrl *gomock.Controller) service.RBACService {
+				svc := svcmocks.NewMockRBACService(ctrl)
+				svc.EXPECT().HasPermission(gomock.Any(), int64(123), domain.PermissionArticleReview).
+					Return(false, nil)
+				return svc
+			},
+			login:    true,
+			wantCode: http.StatusForbidden,
+		},
+		{
+			name: "查询权限失败",
+			mock: func(ctrl *gomock.Controller) service.RBACService {
+				svc := svcmocks.NewMockRBACService(ctrl)
+				svc.EXPECT().HasPermission(gomock.Any(), int64(123), domain.PermissionArticleReview).
+					Return(false, errors.New("mock db error"))
+				return svc
+			},
+			login:    true,
+			wantCode: http.StatusForbidden,
+		},
+		{
+			name: "没有登录",
+			mock: func(ctrl *gomock.Controller) service.RBACService {
+				return svcmocks.NewMockRBACService(ctrl)
+			},
+			wantCode: http.StatusForbidden,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			server := gin.Default()
+			if tc.login {
+				server.Use(func(ctx *gin.Context) {
+					ctx.Set("userId", int64(123))
+				})
+			}
+			b := NewPermissionMiddlewareBuilder(tc.mock(ctrl), logger.NewNoOpLogger())
+			g := server.Group("/admin", b.Build(domain.PermissionArticleReview))
+			g.GET("/ping", func(ctx *gin.Context) {
+				ctx.String(http.StatusOK, "pong")
+			})
+			req, err := http.NewRequest(http.MethodGet, "/admin/ping", nil)
+			require.NoError(t, err)
+			resp := httptest.NewRecorder()
+			server.ServeHTTP(resp, req)
+			assert.Equal(t, tc.wantCode, resp.Code)
+		})
+	}
+}
@@ -0,0 +1,137 @@
+package web
+
+import (
+	"errors"
+	"github.com/ecodeclub/ekit/slice"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"strconv"
+	"webook/webook/internal/domain"
+	"webook/webook/internal/errs"
+	"webook/webook/internal/service"
+	"webook/webook/internal/web/middleware"
+	"webook/webook/pkg/logger"
+)
+
+var _ handler = (*RBACHandler)(nil)
+
+// RBACHandler 超级管理员给用户授予、撤销角色
+type RBACHandler struct {
+	svc  service.RBACService
+	perm *middleware.PermissionMiddlewareBuilder
+	l    logger.Logger
+}
+
+func NewRBACHandler(svc service.RBACService, perm *middleware.PermissionMiddlewareBuilder, l logger.Logger) *RBACHandler {
+	return &RBACHandler{svc: svc, perm: perm, l: l}
+}
+
+func (h *RBACHandler) RegisterRouter(server *gin.Engine) {
+	g := server.Group("/admin/roles", h.perm.Build(domain.PermissionRoleGrant))
+	g.POST("/grant", h.Grant)
+	g.POST("/revoke", h.Revoke)
+	g.GET("/:uid", h.List)
+}
+
+type RoleReq struct {
+	Uid  int64  `json:"uid"`
+	Role string `json:"role"`
+}
+
+// Grant 授予角色
+func (h *RBACHandler) Grant(ctx *gin.Context) {
+	var req RoleReq
+	if err := ctx.Bind(&req); err != nil {
+		return
+	}
+	operator, ok := h.userId(ctx)
+	if !ok {
+		return
+	}
+	err := h.svc.Grant(ctx.Request.Context(), operator, req.Uid, req.Role)
+	h.writeResult(ctx, err, req, "授予角色失败")
+}
+
+// Revoke 撤销角色
+func (h *RBACHandler) Revoke(ctx *gin.Context) {
+	var req RoleReq
+	if err := ctx.Bind(&req); err != nil {
+		return
+	}
+	operator, ok := h.userId(ctx)
+	if !ok {
+		return
+	}
+	err := h.svc.Revoke(ctx.Request.Context(), operator, req.Uid, req.Role)
+	h.writeResult(ctx, err, req, "撤销角色失败")
+}
+
+// List 用户拥有的角色
+func (h *RBACHandler) List(ctx *gin.Context) {
+	uid, err := strconv.ParseInt(ctx.Param("uid"), 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 4,
+			Msg:  "参数错误",
+		})
+		return
+	}
+	roles, err := h.svc.ListRoles(ctx.Request.Context(), uid)
+	if err != nil {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "系统错误",
+		})
+		h.l.Error("查询用户角色失败", logger.Error(err), logger.Int64("uid", uid))
+		return
+	}
+	ctx.JSON(http.StatusOK, Result{
+		Data: slice.Map[domain.Role, string](roles, func(idx int, src domain.Role) string {
+			return src.Name
+		}),
+	})
+}
+
+func (h *RBACHandler) writeResult(ctx *gin.Context, err error, req RoleReq, logMsg string) {
+	switch {
+	case err == nil:
+		ctx.JSON(http.StatusOK, Result{
+			Msg: "OK",
+		})
+	case errors.Is(err, service.ErrUserNotFound):
+		ctx.JSON(http.StatusOK, Result{
+			Code: errs.UserNotFound,
+			Msg:  "用户不存在",
+		})
+	case errors.Is(err, service.ErrRoleNotFound):
+		ctx.JSON(http.StatusOK, Result{
+			Code: errs.RoleNotFound,
+			Msg:  "角色不存在",
+		})
+	case errors.Is(err, service.ErrRevokeSelf):
+		ctx.JSON(http.StatusOK, Result{
+			Code: 4,
+			Msg:  "不能撤销自己的超级管理员",
+		})
+	default:
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "系统错误",
+		})
+		h.l.Error(logMsg, logger.Error(err),
+			logger.Int64("uid", req.Uid), logger.String("role", req.Role))
+	}
+}
+
+func (h *RBACHandler) userId(ctx *gin.Context) (int64, bool) {
+	uid, _ := ctx.Get("userId")
+	userId, ok := uid.(int64)
+	if !ok {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "系统错误",
+		})
+		h.l.Error("未发现用户的session信息")
+	}
+	return userId, ok
+}
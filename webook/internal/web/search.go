@@ -0,0 +1,79 @@
+package web
+
+import (
+	"github.com/ecodeclub/ekit/slice"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"strings"
+	"unicode/utf8"
+	"webook/webook/internal/domain"
+	"webook/webook/internal/service"
+	"webook/webook/pkg/logger"
+)
+
+var _ handler = (*SearchHandler)(nil)
+
+// queryMaxLen 搜索关键字最多多少个字
+const queryMaxLen = 64
+
+// SearchHandler 搜索，不需要登录
+type SearchHandler struct {
+	svc service.SearchService
+	l   logger.Logger
+}
+
+func NewSearchHandler(svc service.SearchService, l logger.Logger) *SearchHandler {
+	return &SearchHandler{svc: svc, l: l}
+}
+
+func (h *SearchHandler) RegisterRouter(server *gin.Engine) {
+	g := server.Group("/search")
+	g.POST("/articles", h.SearchArticle)
+}
+
+// SearchArticle 搜索已经发表的文章，按照相关度排序
+func (h *SearchHandler) SearchArticle(ctx *gin.Context) {
+	type Req struct {
+		Query  string `json:"query"`
+		Offset int    `json:"offset"`
+		Limit  int    `json:"limit"`
+	}
+	var req Req
+	if err := ctx.Bind(&req); err != nil {
+		return
+	}
+	query := strings.TrimSpace(req.Query)
+	if query == "" || utf8.RuneCountInString(query) > queryMaxLen ||
+		req.Offset < 0 || req.Limit <= 0 || req.Limit > 100 {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 4,
+			Msg:  "输入有误",
+		})
+		return
+	}
+	res, err := h.svc.SearchArticle(ctx.Request.Context(), query, req.Offset, req.Limit)
+	if err != nil {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "系统错误",
+		})
+		h.l.Error("搜索文章失败", logger.Error(err), logger.String("query", query))
+		return
+	}
+	ctx.JSON(http.StatusOK, Result{
+		Data: SearchResultVO[ArticleSearchVO]{
+			Total: res.Total,
+			Items: slice.Map[domain.ArticleHit, ArticleSearchVO](res.Hits, func(idx int, src domain.ArticleHit) ArticleSearchVO {
+				return ArticleSearchVO{
+					Id:               src.Article.Id,
+					Title:            src.Article.Title,
+					TitleHighlight:   src.TitleHighlight,
+					ContentHighlight: src.ContentHighlight,
+					AuthorId:         src.Article.Author.Id,
+					AuthorName:       src.Article.Author.Name,
+					Utime:            src.Article.Utime.UnixMilli(),
+				}
+			}),
+		},
+	})
+}
@@ -0,0 +1,139 @@
+package web
+
+import (
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"io"
+	"net/http"
+	"strings"
+	"webook/webook/internal/domain"
+	"webook/webook/internal/errs"
+	"webook/webook/internal/service"
+	"webook/webook/pkg/logger"
+	"webook/webook/pkg/storage"
+)
+
+// objectPathPrefix 文章里面引用上传的文件用的路径，打开的时候跳转到临时下载链接
+const objectPathPrefix = "/objects/"
+
+var _ handler = (*UploadHandler)(nil)
+
+type UploadHandler struct {
+	svc service.UploadService
+	l   logger.Logger
+}
+
+func NewUploadHandler(svc service.UploadService, l logger.Logger) *UploadHandler {
+	return &UploadHandler{svc: svc, l: l}
+}
+
+func (h *UploadHandler) RegisterRouter(server *gin.Engine) {
+	server.POST("/upload", h.Upload)
+	// 不需要登录，和文章一样谁都能看
+	server.GET(objectPathPrefix+"*key", h.Object)
+}
+
+// Upload 上传图片或者附件，表单字段是 file
+func (h *UploadHandler) Upload(ctx *gin.Context) {
+	// multipart 还有分隔符之类的内容，多留一点
+	ctx.Request.Body = http.MaxBytesReader(ctx.Writer, ctx.Request.Body, domain.MaxUploadSize+1<<20)
+	fh, err := ctx.FormFile("file")
+	var mbe *http.MaxBytesError
+	if errors.As(err, &mbe) || (err == nil && fh.Size > domain.MaxUploadSize) {
+		h.tooLarge(ctx)
+		return
+	}
+	if err != nil {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 4,
+			Msg:  "请选择文件",
+		})
+		return
+	}
+	userId, ok := h.userId(ctx)
+	if !ok {
+		return
+	}
+	f, err := fh.Open()
+	if err != nil {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "系统错误",
+		})
+		h.l.Error("打开上传的文件失败", logger.Error(err))
+		return
+	}
+	defer f.Close()
+	// 多读一个字节，service 就能判断出来是不是超过了大小
+	data, err := io.ReadAll(io.LimitReader(f, domain.MaxUploadSize+1))
+	if err != nil {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "系统错误",
+		})
+		h.l.Error("读取上传的文件失败", logger.Error(err))
+		return
+	}
+	obj, err := h.svc.Upload(ctx.Request.Context(), userId, data)
+	switch {
+	case err == nil:
+		ctx.JSON(http.StatusOK, Result{
+			Data: UploadVO{
+				Key:         obj.Key,
+				Url:         objectPathPrefix + obj.Key,
+				Size:        obj.Size,
+				ContentType: obj.ContentType,
+			},
+		})
+	case errors.Is(err, service.ErrUploadTooLarge):
+		h.tooLarge(ctx)
+	case errors.Is(err, service.ErrUnsupportedUploadType):
+		ctx.JSON(http.StatusOK, Result{
+			Code: errs.UploadUnsupportedType,
+			Msg:  "只能上传图片或者 PDF",
+		})
+	default:
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "系统错误",
+		})
+		h.l.Error("上传文件失败", logger.Error(err), logger.Int64("uid", userId))
+	}
+}
+
+// Object 跳转到对象的临时下载链接
+func (h *UploadHandler) Object(ctx *gin.Context) {
+	key := strings.TrimPrefix(ctx.Param("key"), "/")
+	u, err := h.svc.URL(ctx.Request.Context(), key)
+	if errors.Is(err, service.ErrUploadNotFound) || errors.Is(err, storage.ErrInvalidKey) {
+		ctx.AbortWithStatus(http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		ctx.AbortWithStatus(http.StatusInternalServerError)
+		h.l.Error("生成下载链接失败", logger.Error(err), logger.String("key", key))
+		return
+	}
+	ctx.Redirect(http.StatusFound, u)
+}
+
+func (h *UploadHandler) tooLarge(ctx *gin.Context) {
+	ctx.JSON(http.StatusOK, Result{
+		Code: errs.UploadTooLarge,
+		Msg:  fmt.Sprintf("文件不能超过 %d MB", domain.MaxUploadSize>>20),
+	})
+}
+
+func (h *UploadHandler) userId(ctx *gin.Context) (int64, bool) {
+	uid, _ := ctx.Get("userId")
+	userId, ok := uid.(int64)
+	if !ok {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "系统错误",
+		})
+		h.l.Error("未发现用户的session信息")
+	}
+	return userId, ok
+}
@@ -0,0 +1,110 @@
+package web
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"webook/webook/internal/domain"
+	"webook/webook/internal/errs"
+	"webook/webook/internal/service"
+	svcmocks "webook/webook/internal/service/mocks"
+	"webook/webook/pkg/logger"
+)
+
+func TestUploadHandler_Upload(t *testing.T) {
+	testCases := []struct {
+		name     string
+		mock     func(ctrl *gomock.Controller) service.UploadService
+		file     []byte
+		wantCode int
+		wantRes  Result
+	}{
+		{
+			name: "上传成功",
+			mock: func(ctrl *gomock.Controller) service.UploadService {
+				svc := svcmocks.NewMockUploadService(ctrl)
+				svc.EXPECT().Upload(gomock.Any(), int64(123), []byte("hello")).Return(domain.UploadObject{
+					Key:         "uploads/ab/abc.png",
+					Size:        5,
+					ContentType: "image/png",
+				}, nil)
+				return svc
+			},
+			file:     []byte("hello"),
+			wantCode: http.StatusOK,
+			wantRes: Result{
+				Data: map[string]any{
+					"key":         "uploads/ab/abc.png",
+					"url":         "/objects/uploads/ab/abc.png",
+					"size":        float64(5),
+					"contentType": "image/png",
+				},
+			},
+		},
+		{
+			name: "不支持的类型",
+			mock: func(ctrl *gomock.Controller) service.UploadService {
+				svc := svcmocks.NewMockUploadService(ctrl)
+				svc.EXPECT().Upload(gomock.Any(), int64(123), gomock.Any()).
+					Return(domain.UploadObject{}, service.ErrUnsupportedUploadType)
+				return svc
+			},
+			file:     []byte("<svg></svg>"),
+			wantCode: http.StatusOK,
+			wantRes: Result{
+				Code: errs.UploadUnsupportedType,
+				Msg:  "只能上传图片或者 PDF",
+			},
+		},
+		{
+			name: "文件太大，不会调用 service",
+			mock: func(ctrl *gomock.Controller) service.UploadService {
+				return svcmocks.NewMockUploadService(ctrl)
+			},
+			file:     make([]byte, domain.MaxUploadSize+2<<20),
+			wantCode: http.StatusOK,
+			wantRes: Result{
+				Code: errs.UploadTooLarge,
+				Msg:  "文件不能超过 5 MB",
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			server := gin.Default()
+			server.Use(func(ctx *gin.Context) {
+				ctx.Set("userId", int64(123))
+			})
+			NewUploadHandler(tc.mock(ctrl), logger.NewNoOpLogger()).RegisterRouter(server)
+
+			body := &bytes.Buffer{}
+			w := multipart.NewWriter(body)
+			fw, err := w.CreateFormFile("file", "a.png")
+			require.NoError(t, err)
+			_, err = fw.Write(tc.file)
+			require.NoError(t, err)
+			require.NoError(t, w.Close())
+			req, err := http.NewRequest(http.MethodPost, "/upload", body)
+			require.NoError(t, err)
+			req.Header.Set("Content-Type", w.FormDataContentType())
+			resp := httptest.NewRecorder()
+			server.ServeHTTP(resp, req)
+
+			assert.Equal(t, tc.wantCode, resp.Code)
+			var res Result
+			err = json.NewDecoder(resp.Body).Decode(&res)
+			require.NoError(t, err)
+			assert.Equal(t, tc.wantRes, res)
+		})
+	}
+}
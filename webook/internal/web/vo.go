@@ -0,0 +1,138 @@
+package web
+
+import "time"
+
+// ArticleVO 返回给前端的文章
+type ArticleVO struct {
+	Id    int64  `json:"id"`
+	Title string `json:"title"`
+	// 列表页只返回摘要
+	Abstract string `json:"abstract"`
+	// Content 是 Markdown 原文，Html 是渲染并且过滤之后的内容，只有读者查看详情才有
+	Content string `json:"content"`
+	Html    string `json:"html,omitempty"`
+	// 作者信息，读者查看的时候才有昵称
+	AuthorId   int64  `json:"authorId"`
+	AuthorName string `json:"authorName"`
+	Status     uint8  `json:"status"`
+	Category   uint8  `json:"category"`
+	// 标签只有查看文章详情才有
+	Tags []string `json:"tags,omitempty"`
+	// 毫秒数
+	Ctime int64 `json:"ctime"`
+	Utime int64 `json:"utime"`
+	// 放进回收站的时间，只有回收站列表才有
+	Dtime int64 `json:"dtime,omitempty"`
+	// 定时发表的时间，只有等待发表的文章才有
+	PublishAt int64 `json:"publishAt,omitempty"`
+	// 互动数据，只有读者查看文章详情才有
+	Interactive *InteractiveVO `json:"interactive,omitempty"`
+}
+
+// TagVO 标签云里面的一个标签
+type TagVO struct {
+	Name string `json:"name"`
+	// 带有这个标签的已发表文章数量
+	Cnt int64 `json:"cnt"`
+}
+
+// InteractiveVO 阅读、点赞、收藏
+type InteractiveVO struct {
+	ReadCnt    int64 `json:"readCnt"`
+	LikeCnt    int64 `json:"likeCnt"`
+	CollectCnt int64 `json:"collectCnt"`
+	// 当前用户有没有点赞、收藏
+	Liked     bool `json:"liked"`
+	Collected bool `json:"collected"`
+}
+
+// toMilli 零值的时间返回 0，前端不需要处理一个负数
+func toMilli(t time.Time) int64 {
+	if t.IsZero() {
+		return 0
+	}
+	return t.UnixMilli()
+}
+
+// RevisionVO 文章的历史版本
+type RevisionVO struct {
+	Id       int64  `json:"id"`
+	Title    string `json:"title"`
+	Abstract string `json:"abstract"`
+	Status   uint8  `json:"status"`
+	Ctime    int64  `json:"ctime"`
+}
+
+// CollectionFolderVO 收藏夹
+type CollectionFolderVO struct {
+	Id    int64  `json:"id"`
+	Name  string `json:"name"`
+	Ctime int64  `json:"ctime"`
+	Utime int64  `json:"utime"`
+}
+
+// CommentVO 评论，根评论的 rootId 和 parentId 都是 0
+type CommentVO struct {
+	Id       int64  `json:"id"`
+	ArtId    int64  `json:"artId"`
+	Uid      int64  `json:"uid"`
+	UserName string `json:"userName"`
+	Content  string `json:"content"`
+	RootId   int64  `json:"rootId"`
+	ParentId int64  `json:"parentId"`
+	// 根评论下面有多少条回复，前端按需加载
+	ReplyCnt int64 `json:"replyCnt"`
+	Ctime    int64 `json:"ctime"`
+}
+
+// FollowVO 关注列表和粉丝列表里面的一个人
+type FollowVO struct {
+	Uid      int64  `json:"uid"`
+	Nickname string `json:"nickname"`
+	// 关注的时间
+	Ctime int64 `json:"ctime"`
+}
+
+// FollowStaticsVO 关注数和粉丝数
+type FollowStaticsVO struct {
+	Followers int64 `json:"followers"`
+	Followees int64 `json:"followees"`
+	// 当前用户有没有关注这个人
+	Followed bool `json:"followed"`
+}
+
+// ArticleSearchVO 搜索结果里面的一篇文章
+type ArticleSearchVO struct {
+	Id    int64  `json:"id"`
+	Title string `json:"title"`
+	// 高亮之后的片段，已经转义过 HTML，命中的词用 <em> 包起来
+	TitleHighlight   string `json:"titleHighlight"`
+	ContentHighlight string `json:"contentHighlight"`
+	AuthorId         int64  `json:"authorId"`
+	AuthorName       string `json:"authorName"`
+	Utime            int64  `json:"utime"`
+}
+
+// SearchResultVO 搜索结果，Total 用于分页
+type SearchResultVO[T any] struct {
+	Total int `json:"total"`
+	Items []T `json:"items"`
+}
+
+// ArticleReviewVO 作者看到的审核结果，不暴露是哪个管理员审核的
+type ArticleReviewVO struct {
+	ArtId int64 `json:"artId"`
+	// 1 通过，2 驳回
+	Result uint8  `json:"result"`
+	Reason string `json:"reason"`
+	// 毫秒数
+	Ctime int64 `json:"ctime"`
+}
+
+// UploadVO 上传的结果，文章里面用 Url 引用，Url 不会过期
+type UploadVO struct {
+	Key         string `json:"key"`
+	Url         string `json:"url"`
+	Size        int64  `json:"size"`
+	ContentType string `json:"contentType"`
+}
@@ -0,0 +1,91 @@
+package ioc
+
+import (
+	"context"
+	"fmt"
+	"github.com/spf13/viper"
+	"time"
+	"webook/webook/internal/domain"
+	"webook/webook/internal/repository"
+	"webook/webook/internal/service"
+	"webook/webook/pkg/logger"
+	"webook/webook/pkg/redislock"
+)
+
+// InitRevisionRetention 文章历史版本的保留策略
+func InitRevisionRetention() domain.RevisionRetention {
+	type Config struct {
+		KeepCount int `yaml:"keepCount"`
+		KeepDays  int `yaml:"keepDays"`
+	}
+	// 默认每篇文章保留最近的 50 个版本
+	c := Config{
+		KeepCount: 50,
+	}
+	err := viper.UnmarshalKey("article.revision", &c)
+	if err != nil {
+		fmt.Println("初始化文章历史版本配置失败")
+	}
+	return domain.RevisionRetention{
+		KeepCount: c.KeepCount,
+		KeepDays:  c.KeepDays,
+	}
+}
+
+// InitRecycleBinRetention 回收站的保留策略
+func InitRecycleBinRetention() domain.RecycleBinRetention {
+	type Config struct {
+		KeepDays int `yaml:"keepDays"`
+	}
+	// 默认删除之后 30 天之内可以恢复
+	c := Config{
+		KeepDays: 30,
+	}
+	err := viper.UnmarshalKey("article.recycleBin", &c)
+	if err != nil {
+		fmt.Println("初始化回收站配置失败")
+	}
+	return domain.RecycleBinRetention{
+		KeepDays: c.KeepDays,
+	}
+}
+
+// InitArticleListeners 文章发表、撤回之后要通知的业务
+func InitArticleListeners(feedSvc service.FeedService, searchSvc service.SearchService,
+	rankingSvc service.RankingService) []service.ArticleListener {
+	return []service.ArticleListener{feedSvc, searchSvc, rankingSvc}
+}
+
+// InitFeedConfig 关注流的配置
+func InitFeedConfig() domain.FeedConfig {
+	type Config struct {
+		PushThreshold int64 `yaml:"pushThreshold"`
+		MaxFollowees  int   `yaml:"maxFollowees"`
+	}
+	// 默认全部都是读的时候拉
+	var c Config
+	err := viper.UnmarshalKey("feed", &c)
+	if err != nil {
+		fmt.Println("初始化关注流配置失败")
+	}
+	if c.MaxFollowees <= 0 {
+		c.MaxFollowees = 1000
+	}
+	return domain.FeedConfig{
+		PushThreshold: c.PushThreshold,
+		MaxFollowees:  c.MaxFollowees,
+	}
+}
+
+// InitArticleScheduler 定时发表，启动的时候从数据库加载还没有发表的文章
+func InitArticleScheduler(repo repository.ArticleRepository, listeners []service.ArticleListener,
+	lockClient *redislock.Client, l logger.Logger) service.ArticleScheduler {
+	scheduler := service.NewLocalArticleScheduler(repo, listeners, lockClient, l)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
+	defer cancel()
+	err := scheduler.Load(ctx)
+	if err != nil {
+		panic(err)
+	}
+	return scheduler
+}
@@ -6,6 +6,7 @@ import (
 	"gorm.io/driver/mysql"
 	"gorm.io/gorm"
 	glogger "gorm.io/gorm/logger"
+	"webook/webook/internal/domain"
 	"webook/webook/internal/repository/dao"
 	"webook/webook/pkg/logger"
 )
@@ -48,5 +49,21 @@ func (g gormLogger) Printf(msg string, args ...interface{}) {
 
 func initTable(db *gorm.DB) error {
 	// gorm自动建表
-	return db.AutoMigrate(&dao.User{})
+	err := db.AutoMigrate(&dao.User{}, &dao.Article{}, &dao.PublishedArticle{},
+		&dao.ArticleRevision{}, &dao.Interactive{}, &dao.UserLikeBiz{}, &dao.UserCollectionBiz{},
+		&dao.CollectionFolder{}, &dao.CollectionFolderItem{},
+		&dao.Comment{}, &dao.CommentCount{}, &dao.CronJob{},
+		&dao.FollowRelation{}, &dao.FollowStatics{}, &dao.FeedInbox{},
+		&dao.Tag{}, &dao.ArticleTag{}, &dao.PublishedArticleTag{}, &dao.ArticleReview{},
+		&dao.Role{}, &dao.RolePermission{}, &dao.UserRole{}, &dao.UploadObject{},
+		&dao.AsyncSms{})
+	if err != nil {
+		return err
+	}
+	err = dao.InitBuiltinRoles(db, builtinRoles())
+	if err != nil {
+		return err
+	}
+	return dao.MigrateLegacyArticleStatus(db, domain.ArticleStatusPublished.ToUint8(),
+		domain.ArticleStatusUnpublished.ToUint8())
 }
@@ -0,0 +1,104 @@
+package ioc
+
+import (
+	"context"
+	"fmt"
+	"github.com/robfig/cron/v3"
+	"github.com/spf13/viper"
+	"time"
+	"webook/webook/internal/domain"
+	"webook/webook/internal/job"
+	"webook/webook/internal/service"
+	"webook/webook/pkg/logger"
+	"webook/webook/pkg/redislock"
+)
+
+func InitRecycleBinPurgeJob(svc service.ArticleService, l logger.Logger) *job.RecycleBinPurgeJob {
+	return job.NewRecycleBinPurgeJob(svc, l, time.Minute*10, 100)
+}
+
+func InitRankingJob(svc service.RankingService, lockClient *redislock.Client, l logger.Logger) *job.RankingJob {
+	// 默认每三分钟计算一次，和 InitJobs 里面的默认值一致
+	interval := cronInterval(viper.GetString("job.ranking"), time.Minute*3)
+	return job.NewRankingJob(svc, lockClient, l, time.Minute, interval)
+}
+
+func InitArticleScheduleLoadJob(scheduler service.ArticleScheduler) *job.ArticleScheduleLoadJob {
+	return job.NewArticleScheduleLoadJob(scheduler, time.Second*10)
+}
+
+// cronInterval cron 表达式相邻两次触发的间隔，没有配置或者配置不对的时候返回 def
+func cronInterval(expr string, def time.Duration) time.Duration {
+	sched, err := cron.ParseStandard(expr)
+	if err != nil {
+		return def
+	}
+	next := sched.Next(time.Now())
+	return sched.Next(next).Sub(next)
+}
+
+// InitJobs 初始化所有的定时任务，cron 表达式都可以在配置文件里面修改
+func InitJobs(l logger.Logger, purgeJob *job.RecycleBinPurgeJob, rankingJob *job.RankingJob,
+	scheduleLoadJob *job.ArticleScheduleLoadJob) *cron.Cron {
+	type Config struct {
+		RecycleBinPurge     string `yaml:"recycleBinPurge"`
+		Ranking             string `yaml:"ranking"`
+		ArticleScheduleLoad string `yaml:"articleScheduleLoad"`
+	}
+	// 默认每小时清理一次回收站，每三分钟计算一次热榜，每分钟重新加载一次定时发表
+	c := Config{
+		RecycleBinPurge:     "0 * * * *",
+		Ranking:             "*/3 * * * *",
+		ArticleScheduleLoad: "* * * * *",
+	}
+	err := viper.UnmarshalKey("job", &c)
+	if err != nil {
+		fmt.Println("初始化定时任务配置失败")
+	}
+	if c.ArticleScheduleLoad == "" {
+		// 不能交给 MySQL 里的定时任务，不然只有一个实例会加载
+		c.ArticleScheduleLoad = "* * * * *"
+	}
+	res := cron.New()
+	builder := job.NewCronJobBuilder(l)
+	jobs := []struct {
+		expr string
+		job  job.Job
+	}{
+		{expr: c.RecycleBinPurge, job: purgeJob},
+		{expr: c.Ranking, job: rankingJob},
+		// 每个实例都要加载，没有注册到 InitLocalFuncExecutor
+		{expr: c.ArticleScheduleLoad, job: scheduleLoadJob},
+	}
+	for _, j := range jobs {
+		// 配置成空字符串表示交给 MySQL 里的定时任务调度
+		if j.expr == "" {
+			continue
+		}
+		_, err = res.AddJob(j.expr, builder.Build(j.job))
+		if err != nil {
+			panic(err)
+		}
+	}
+	return res
+}
+
+// InitLocalFuncExecutor 注册可以由 MySQL 里的定时任务调度的本地任务，任务名就是 Job 的名字
+func InitLocalFuncExecutor(purgeJob *job.RecycleBinPurgeJob, rankingJob *job.RankingJob) *job.LocalFuncExecutor {
+	res := job.NewLocalFuncExecutor()
+	for _, j := range []job.Job{purgeJob, rankingJob} {
+		j := j
+		res.RegisterFunc(j.Name(), func(ctx context.Context, cj domain.CronJob) error {
+			// ctx 在任务续约失败的时候会被取消
+			return j.Run(ctx)
+		})
+	}
+	return res
+}
+
+// InitScheduler 调度 MySQL 里的定时任务，表里面有对应的任务才会运行
+func InitScheduler(svc service.CronJobService, local *job.LocalFuncExecutor, l logger.Logger) *job.Scheduler {
+	res := job.NewScheduler(svc, l)
+	res.RegisterExecutor(local)
+	return res
+}
@@ -0,0 +1,45 @@
+package ioc
+
+import (
+	"context"
+	"fmt"
+	"github.com/spf13/viper"
+	"webook/webook/internal/domain"
+	"webook/webook/internal/service"
+	"webook/webook/pkg/logger"
+	"webook/webook/pkg/sensitive"
+)
+
+// InitModerationService 敏感词审核，词库文件修改之后自动生效
+func InitModerationService(l logger.Logger) service.ModerationService {
+	type Config struct {
+		Path   string `yaml:"path"`
+		Review bool   `yaml:"review"`
+		// ReviewAll 所有文章都要人工审核
+		ReviewAll bool `yaml:"reviewAll"`
+	}
+	c := Config{
+		Path: "config/sensitive_words.txt",
+	}
+	err := viper.UnmarshalKey("moderation", &c)
+	if err != nil {
+		fmt.Println("初始化内容审核配置失败")
+	}
+	// 词库加载不了就不能保证合规，直接启动失败
+	dict, err := sensitive.Load(c.Path)
+	if err != nil {
+		panic(err)
+	}
+	go func() {
+		er := dict.Watch(context.Background(), func(err error) {
+			l.Error("重新加载敏感词词库失败", logger.Error(err))
+		})
+		if er != nil {
+			l.Error("监听敏感词词库失败", logger.Error(er))
+		}
+	}()
+	return service.NewDictModerationService(dict, domain.ModerationConfig{
+		Review:    c.Review,
+		ReviewAll: c.ReviewAll,
+	})
+}
@@ -0,0 +1,45 @@
+package ioc
+
+import (
+	"context"
+	"fmt"
+	"github.com/spf13/viper"
+	"time"
+	"webook/webook/internal/domain"
+	"webook/webook/internal/repository"
+	"webook/webook/internal/service"
+	"webook/webook/pkg/logger"
+)
+
+// InitRBACService 启动的时候给配置里的用户授予超级管理员，不然没有人能授予角色
+func InitRBACService(repo repository.RBACRepository, userRepo repository.UserRepository,
+	l logger.Logger) service.RBACService {
+	var uids []int64
+	err := viper.UnmarshalKey("rbac.superAdmins", &uids)
+	if err != nil {
+		fmt.Println("初始化超级管理员配置失败")
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+	defer cancel()
+	for _, uid := range uids {
+		err = repo.Grant(ctx, uid, domain.RoleSuperAdmin)
+		if err != nil {
+			panic(err)
+		}
+	}
+	return service.NewRBACService(repo, userRepo, l)
+}
+
+// builtinRoles 内置角色转换成 DAO 需要的格式
+func builtinRoles() map[string][]string {
+	roles := domain.BuiltinRoles()
+	res := make(map[string][]string, len(roles))
+	for _, r := range roles {
+		perms := make([]string, 0, len(r.Permissions))
+		for _, p := range r.Permissions {
+			perms = append(perms, string(p))
+		}
+		res[r.Name] = perms
+	}
+	return res
+}
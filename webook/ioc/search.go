@@ -0,0 +1,26 @@
+package ioc
+
+import (
+	"fmt"
+	"github.com/spf13/viper"
+	"webook/webook/pkg/search"
+)
+
+// InitSearchIndex 文章的全文索引，保存在本地磁盘上
+func InitSearchIndex() *search.Index {
+	type Config struct {
+		Path string `yaml:"path"`
+	}
+	c := Config{
+		Path: "data/search/article.gob",
+	}
+	err := viper.UnmarshalKey("search", &c)
+	if err != nil {
+		fmt.Println("初始化搜索配置失败")
+	}
+	idx, err := search.Open(c.Path)
+	if err != nil {
+		panic(err)
+	}
+	return idx
+}
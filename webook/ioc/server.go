@@ -10,20 +10,39 @@ import (
 	"strings"
 	"time"
 	"webook/webook/internal/web"
+	web2 "webook/webook/internal/web/jwt"
 	"webook/webook/internal/web/middleware"
 	"webook/webook/pkg/ginx/middlewares/logger"
 	"webook/webook/pkg/ginx/middlewares/ratelimit"
 	ratelimit2 "webook/webook/pkg/ginx/ratelimit"
 	logger2 "webook/webook/pkg/logger"
+	"webook/webook/pkg/storage"
 )
 
 func InitGinServer(middlewares []gin.HandlerFunc, userHandler *web.UserHandler,
-	wechatHandler *web.OAuth2WechatHandler) *gin.Engine {
+	wechatHandler *web.OAuth2WechatHandler, articleHandler *web.ArticleHandler,
+	readerHandler *web.ArticleReaderHandler, folderHandler *web.CollectionFolderHandler,
+	commentHandler *web.CommentHandler, followHandler *web.FollowHandler,
+	searchHandler *web.SearchHandler, reviewHandler *web.ArticleReviewHandler,
+	rbacHandler *web.RBACHandler, uploadHandler *web.UploadHandler, store storage.Storage) *gin.Engine {
 	server := gin.Default()
 	server.Use(middlewares...)
 	// 注册路由
 	userHandler.RegisterRouter(server)
 	wechatHandler.RegisterRoutes(server)
+	articleHandler.RegisterRouter(server)
+	readerHandler.RegisterRouter(server)
+	folderHandler.RegisterRouter(server)
+	commentHandler.RegisterRouter(server)
+	followHandler.RegisterRouter(server)
+	searchHandler.RegisterRouter(server)
+	reviewHandler.RegisterRouter(server)
+	rbacHandler.RegisterRouter(server)
+	uploadHandler.RegisterRouter(server)
+	// 本地存储没有自己的下载服务，由应用校验签名之后返回文件
+	if local, ok := store.(*storage.LocalStorage); ok {
+		server.GET(local.PathPrefix()+"*key", gin.WrapH(local))
+	}
 	return server
 }
 
@@ -33,7 +52,7 @@ func initLimiterOfAccess(cmd redis.Cmdable) ratelimit2.Limiter {
 	return ratelimit2.NewRedisSlidingWindowLimiter(cmd, time.Second, 100)
 }
 
-func InitGinMiddlewares(redisClient redis.Cmdable, l logger2.Logger) []gin.HandlerFunc {
+func InitGinMiddlewares(redisClient redis.Cmdable, jwtHdl web2.JWTHandler, l logger2.Logger) []gin.HandlerFunc {
 	return []gin.HandlerFunc{
 		cordHdl(),
 		logger.NewLoggerBuilder(func(ctx context.Context, al *logger.AccessLog) {
@@ -42,7 +61,7 @@ func InitGinMiddlewares(redisClient redis.Cmdable, l logger2.Logger) []gin.Handl
 				Value: al,
 			})
 		}).AllowReqBody().AllowRespBody().Build(),
-		middleware.NewLoginJWTMiddleWareBuilder().
+		middleware.NewLoginJWTMiddleWareBuilder(jwtHdl).
 			IgnorePaths("/users/signup").
 			IgnorePaths("/users/login").
 			IgnorePaths("/users/login_sms/code/send").
@@ -50,6 +69,15 @@ func InitGinMiddlewares(redisClient redis.Cmdable, l logger2.Logger) []gin.Handl
 			IgnorePaths("/users/refresh_token").
 			IgnorePaths("/oauth2/wechat/oauth2url").
 			IgnorePaths("/oauth2/wechat/callback").
+			// 读者查看已发表的文章不需要登录，登录了要带上是否点赞、收藏
+			OptionalPathPrefix("/articles/pub/").
+			// 看评论不需要登录
+			IgnorePathPrefix("/comments/pub/").
+			// 搜索不需要登录
+			IgnorePathPrefix("/search/").
+			// 上传的文件和文章一样谁都能看，本地存储的下载链接自己带着签名
+			IgnorePathPrefix("/objects/").
+			IgnorePathPrefix("/storage/").
 			Build(),
 		ratelimit.NewBuilder(initLimiterOfAccess(redisClient)).Build(),
 	}
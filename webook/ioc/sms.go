@@ -1,41 +1,180 @@
 package ioc
 
 import (
+	"context"
+	"fmt"
 	"github.com/redis/go-redis/v9"
+	"github.com/spf13/viper"
 	"github.com/tencentcloud/tencentcloud-sdk-go/tencentcloud/common"
 	"github.com/tencentcloud/tencentcloud-sdk-go/tencentcloud/common/profile"
 	tencentSms "github.com/tencentcloud/tencentcloud-sdk-go/tencentcloud/sms/v20210111"
+	"net/http"
 	"os"
 	"time"
+	"webook/webook/internal/repository"
 	"webook/webook/internal/service/sms"
+	"webook/webook/internal/service/sms/aliyun"
+	"webook/webook/internal/service/sms/async"
 	"webook/webook/internal/service/sms/memory"
 	"webook/webook/internal/service/sms/ratelimit"
 	"webook/webook/internal/service/sms/tencent"
+	"webook/webook/internal/service/sms/twilio"
 	ratelimit2 "webook/webook/pkg/ginx/ratelimit"
+	"webook/webook/pkg/logger"
 )
 
-//func InitSMSService() sms.Service {
-//	return initMemorySMSService()
-//}
+// InitSMSService 服务商在配置文件里选择
+// 触发限流或者服务商不健康的时候转成异步发送
+func InitSMSService(cmd redis.Cmdable, repo repository.AsyncSmsRepository, l logger.Logger) sms.Service {
+	type TencentConfig struct {
+		AppId    string `yaml:"appId"`
+		SignName string `yaml:"signName"`
+		Region   string `yaml:"region"`
+	}
+	type AliyunConfig struct {
+		Endpoint string `yaml:"endpoint"`
+		RegionId string `yaml:"regionId"`
+		SignName string `yaml:"signName"`
+	}
+	type TwilioConfig struct {
+		BaseURL    string `yaml:"baseURL"`
+		AccountSid string `yaml:"accountSid"`
+		From       string `yaml:"from"`
+	}
+	type Config struct {
+		// Provider memory、tencent、aliyun、twilio
+		Provider string        `yaml:"provider"`
+		Tencent  TencentConfig `yaml:"tencent"`
+		Aliyun   AliyunConfig  `yaml:"aliyun"`
+		Twilio   TwilioConfig  `yaml:"twilio"`
+	}
+	c := Config{
+		Provider: "memory",
+		Tencent: TencentConfig{
+			AppId:    "1400853424",
+			SignName: "猜猜我是谁",
+			Region:   "ap-guangzhou",
+		},
+		Aliyun: AliyunConfig{
+			Endpoint: "https://dysmsapi.aliyuncs.com",
+			RegionId: "cn-hangzhou",
+		},
+		Twilio: TwilioConfig{
+			BaseURL: "https://api.twilio.com",
+		},
+	}
+	err := viper.UnmarshalKey("sms", &c)
+	if err != nil {
+		fmt.Println("初始化短信服务配置失败")
+	}
+	tpls := initSMSTemplates()
+	var svc sms.Service
+	switch c.Provider {
+	case "memory":
+		svc = initMemorySMSService()
+	case "tencent":
+		svc = initTencentSMSService(c.Tencent.AppId, c.Tencent.SignName, c.Tencent.Region, tpls)
+	case "aliyun":
+		svc = aliyun.NewSMSService(initSMSHTTPClient(), aliyun.Config{
+			Endpoint:        c.Aliyun.Endpoint,
+			RegionId:        c.Aliyun.RegionId,
+			AccessKeyId:     mustLookupEnv("ALIYUN_SMS_ACCESS_KEY_ID"),
+			AccessKeySecret: mustLookupEnv("ALIYUN_SMS_ACCESS_KEY_SECRET"),
+			SignName:        c.Aliyun.SignName,
+		}, tpls)
+	case "twilio":
+		svc = twilio.NewSMSService(initSMSHTTPClient(), twilio.Config{
+			BaseURL:    c.Twilio.BaseURL,
+			AccountSid: c.Twilio.AccountSid,
+			AuthToken:  mustLookupEnv("TWILIO_AUTH_TOKEN"),
+			From:       c.Twilio.From,
+		}, tpls)
+	default:
+		panic(fmt.Sprintf("不支持的短信服务商 %s", c.Provider))
+	}
+	// 每一家短信服务都有各自的限流规则
+	svc = ratelimit.NewService(svc, initSMSLimiter(cmd), c.Provider)
+	return initAsyncSmsService(svc, repo, l)
+}
 
-func InitSMSService(cmd redis.Cmdable) sms.Service {
-	return initMemorySMSService()
+// initSMSTemplates 业务模板对应的各家服务商的模板，配置错了直接启动失败
+func initSMSTemplates() *sms.TemplateRegistry {
+	type ProviderTemplate struct {
+		Id     string   `yaml:"id"`
+		Params []string `yaml:"params"`
+		Body   string   `yaml:"body"`
+	}
+	type Template struct {
+		Name string   `yaml:"name"`
+		Args []string `yaml:"args"`
+		// Providers 服务商的名字要用小写，viper 会把 map 的 key 转成小写
+		Providers map[string]ProviderTemplate `yaml:"providers"`
+	}
+	var c []Template
+	err := viper.UnmarshalKey("sms.templates", &c)
+	if err != nil {
+		panic(err)
+	}
+	defs := make([]sms.TemplateDef, 0, len(c))
+	for _, t := range c {
+		providers := make(map[string]sms.Template, len(t.Providers))
+		for name, p := range t.Providers {
+			providers[name] = sms.Template{Id: p.Id, Params: p.Params, Body: p.Body}
+		}
+		defs = append(defs, sms.TemplateDef{Name: t.Name, Args: t.Args, Providers: providers})
+	}
+	res, err := sms.NewTemplateRegistry(defs)
+	if err != nil {
+		panic(err)
+	}
+	return res
 }
 
-// 腾讯云短信服务
-func initTencentSMSService() sms.Service {
-	secretId, ok := os.LookupEnv("SMS_SECRET_ID")
-	if !ok {
-		panic("找不到环境变量 SMS_SECRET_ID")
+func initAsyncSmsService(svc sms.Service, repo repository.AsyncSmsRepository, l logger.Logger) sms.Service {
+	type Config struct {
+		Workers          int           `yaml:"workers"`
+		MaxRetry         int           `yaml:"maxRetry"`
+		InitBackoff      time.Duration `yaml:"initBackoff"`
+		MaxBackoff       time.Duration `yaml:"maxBackoff"`
+		Window           int           `yaml:"window"`
+		ErrRateThreshold float64       `yaml:"errRateThreshold"`
+	}
+	c := Config{
+		Workers:          2,
+		MaxRetry:         5,
+		InitBackoff:      time.Second * 5,
+		MaxBackoff:       time.Minute * 5,
+		Window:           100,
+		ErrRateThreshold: 0.3,
+	}
+	err := viper.UnmarshalKey("sms.async", &c)
+	if err != nil {
+		fmt.Println("初始化异步短信配置失败")
 	}
-	secretKey, ok := os.LookupEnv("SMS_SECRET_KEY")
+	res := async.NewService(svc, repo, async.Config{
+		MaxRetry:         c.MaxRetry,
+		InitBackoff:      c.InitBackoff,
+		MaxBackoff:       c.MaxBackoff,
+		Window:           c.Window,
+		ErrRateThreshold: c.ErrRateThreshold,
+		SendTimeout:      time.Second * 5,
+		PollInterval:     time.Second,
+		StaleTimeout:     time.Minute,
+	}, l)
+	res.Start(context.Background(), c.Workers)
+	return res
+}
 
+// 腾讯云短信服务
+func initTencentSMSService(appId, signName, region string, tpls *sms.TemplateRegistry) sms.Service {
+	secretId := mustLookupEnv("SMS_SECRET_ID")
+	secretKey := mustLookupEnv("SMS_SECRET_KEY")
 	c, err := tencentSms.NewClient(common.NewCredential(secretId, secretKey),
-		"ap-guangzhou", profile.NewClientProfile())
+		region, profile.NewClientProfile())
 	if err != nil {
-		panic("找不到环境变量 SMS_SECRET_KEY")
+		panic(err)
 	}
-	return tencent.NewSMSService(c, "1400853424", "猜猜我是谁")
+	return tencent.NewSMSService(c, appId, signName, tpls)
 }
 
 // 内存实现
@@ -43,13 +182,20 @@ func initMemorySMSService() sms.Service {
 	return memory.NewSmsService()
 }
 
+func initSMSHTTPClient() *http.Client {
+	return &http.Client{Timeout: time.Second * 5}
+}
+
+func mustLookupEnv(key string) string {
+	val, ok := os.LookupEnv(key)
+	if !ok {
+		panic("找不到环境变量 " + key)
+	}
+	return val
+}
+
 func initSMSLimiter(cmd redis.Cmdable) ratelimit2.Limiter {
 	// 每秒限流3000个，这是腾讯的限流规则
 	// 如果是其它云服务商，限流规则不同就要使用另外的限流器
 	return ratelimit2.NewRedisSlidingWindowLimiter(cmd, time.Second, 3000)
 }
-
-func initRateLimitSmsService(cmd redis.Cmdable) sms.Service {
-	// 每一家短信服务都有各自的限流规则
-	return ratelimit.NewService(initTencentSMSService(), initSMSLimiter(cmd), "TencentSMS")
-}
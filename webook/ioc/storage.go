@@ -0,0 +1,48 @@
+package ioc
+
+import (
+	"fmt"
+	"github.com/spf13/viper"
+	"webook/webook/pkg/storage"
+)
+
+// InitStorage 开发环境用本地目录，线上用兼容 S3 的对象存储
+func InitStorage() storage.Storage {
+	type LocalConfig struct {
+		Dir    string `yaml:"dir"`
+		Prefix string `yaml:"prefix"`
+	}
+	type Config struct {
+		Type  string           `yaml:"type"`
+		Local LocalConfig      `yaml:"local"`
+		S3    storage.S3Config `yaml:"s3"`
+	}
+	c := Config{
+		Type: "local",
+		Local: LocalConfig{
+			Dir:    "data/uploads",
+			Prefix: "/storage/local/",
+		},
+	}
+	err := viper.UnmarshalKey("storage", &c)
+	if err != nil {
+		fmt.Println("初始化对象存储配置失败")
+	}
+	switch c.Type {
+	case "s3":
+		return storage.NewS3Storage(c.S3)
+	case "local":
+		// 下载链接的签名密钥，没有密钥谁都能伪造下载链接
+		secret := mustLookupEnv("STORAGE_LOCAL_SECRET")
+		if secret == "" {
+			panic("环境变量 STORAGE_LOCAL_SECRET 不能为空")
+		}
+		s, err := storage.NewLocalStorage(c.Local.Dir, c.Local.Prefix, []byte(secret))
+		if err != nil {
+			panic(err)
+		}
+		return s
+	default:
+		panic(fmt.Sprintf("不支持的对象存储类型 %s", c.Type))
+	}
+}
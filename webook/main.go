@@ -1,11 +1,36 @@
 package main
 
-import "github.com/spf13/viper"
+import (
+	"context"
+	"flag"
+	"fmt"
+	"github.com/spf13/viper"
+	"time"
+)
 
 func main() {
+	rebuildSearch := flag.Bool("rebuild-search", false, "从线上库重建文章的搜索索引，执行之前要先停掉服务")
+	flag.Parse()
 	initViper()
-	server := initApp()
-	err := server.Run(":8080")
+	if *rebuildSearch {
+		rebuildSearchIndex()
+		return
+	}
+	app := initApp()
+	app.cron.Start()
+	schedulerCtx, cancel := context.WithCancel(context.Background())
+	go func() {
+		_ = app.scheduler.Schedule(schedulerCtx)
+	}()
+	err := app.server.Run(":8080")
+	// 不再抢占新的任务，已经在运行的任务超时之后会被别的实例抢走
+	cancel()
+	// 等待正在运行的定时任务结束，最多等一分钟
+	ctx := app.cron.Stop()
+	select {
+	case <-ctx.Done():
+	case <-time.After(time.Minute):
+	}
 	if err != nil {
 		return
 	}
@@ -18,3 +43,15 @@ func initViper() {
 		panic(err)
 	}
 }
+
+// rebuildSearchIndex 服务运行的时候会覆盖掉索引文件，所以要先停掉服务
+func rebuildSearchIndex() {
+	svc := initSearchService()
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute*10)
+	defer cancel()
+	cnt, err := svc.Rebuild(ctx)
+	if err != nil {
+		panic(err)
+	}
+	fmt.Printf("重建搜索索引成功，一共 %d 篇文章\n", cnt)
+}
@@ -0,0 +1,173 @@
+package diff
+
+import "strings"
+
+// Op 每一行的变更类型
+type Op string
+
+const (
+	OpEqual  Op = "equal"
+	OpInsert Op = "insert"
+	OpDelete Op = "delete"
+)
+
+// Line 行级别的差异
+type Line struct {
+	Op   Op     `json:"op"`
+	Text string `json:"text"`
+}
+
+// Lines 计算从 a 变到 b 的行级别差异
+// 使用的是线性空间的 Myers 算法：找到最短编辑路径中间的一段，再对两边递归
+// 内存只和行数成正比，结果是最短的编辑脚本，和 git diff 的效果差不多
+func Lines(a, b string) []Line {
+	d := &differ{
+		as:  split(a),
+		bs:  split(b),
+		res: []Line{},
+	}
+	d.diff(0, len(d.as), 0, len(d.bs))
+	return d.res
+}
+
+type differ struct {
+	as  []string
+	bs  []string
+	res []Line
+}
+
+// diff 计算 as[aLo:aHi] 到 bs[bLo:bHi] 的差异，按顺序追加到 res
+func (d *differ) diff(aLo, aHi, bLo, bHi int) {
+	// 相同的开头和结尾不需要参与计算
+	for aLo < aHi && bLo < bHi && d.as[aLo] == d.bs[bLo] {
+		d.res = append(d.res, Line{Op: OpEqual, Text: d.as[aLo]})
+		aLo++
+		bLo++
+	}
+	suffix := 0
+	for aHi > aLo && bHi > bLo && d.as[aHi-1] == d.bs[bHi-1] {
+		aHi--
+		bHi--
+		suffix++
+	}
+	switch {
+	case aLo == aHi:
+		for _, text := range d.bs[bLo:bHi] {
+			d.res = append(d.res, Line{Op: OpInsert, Text: text})
+		}
+	case bLo == bHi:
+		for _, text := range d.as[aLo:aHi] {
+			d.res = append(d.res, Line{Op: OpDelete, Text: text})
+		}
+	default:
+		x, y, ok := d.bisect(aLo, aHi, bLo, bHi)
+		if ok {
+			d.diff(aLo, x, bLo, y)
+			d.diff(x, aHi, y, bHi)
+		} else {
+			// 没有相同的行，全部删除再全部插入
+			for _, text := range d.as[aLo:aHi] {
+				d.res = append(d.res, Line{Op: OpDelete, Text: text})
+			}
+			for _, text := range d.bs[bLo:bHi] {
+				d.res = append(d.res, Line{Op: OpInsert, Text: text})
+			}
+		}
+	}
+	for _, text := range d.as[aHi : aHi+suffix] {
+		d.res = append(d.res, Line{Op: OpEqual, Text: text})
+	}
+}
+
+// bisect 从两头同时找最短编辑路径，相遇的地方就是路径中间的一个点 (x, y)
+// 调用之前要保证开头和结尾的行都不相同
+func (d *differ) bisect(aLo, aHi, bLo, bHi int) (int, int, bool) {
+	as, bs := d.as[aLo:aHi], d.bs[bLo:bHi]
+	n, m := len(as), len(bs)
+	maxD := (n + m + 1) / 2
+	// 两边各留一个位置，k 是边界的时候还会读 k-1 和 k+1
+	offset := maxD + 1
+	// vf[offset+k] 从左上角出发，在第 k 条对角线上能走到的最远的 x
+	// vb[offset+k] 从右下角出发，倒着走能走到的最远的距离
+	vf := make([]int, 2*maxD+3)
+	vb := make([]int, 2*maxD+3)
+	for i := range vf {
+		vf[i] = -1
+		vb[i] = -1
+	}
+	vf[offset+1] = 0
+	vb[offset+1] = 0
+	delta := n - m
+	// 差值是奇数的时候在正向的时候检查相遇，否则在反向的时候检查
+	front := delta%2 != 0
+	// 走出边界的对角线不需要再计算
+	var kfStart, kfEnd, kbStart, kbEnd int
+	for step := 0; step < maxD; step++ {
+		for k := -step + kfStart; k <= step-kfEnd; k += 2 {
+			kOffset := offset + k
+			var x int
+			if k == -step || (k != step && vf[kOffset-1] < vf[kOffset+1]) {
+				x = vf[kOffset+1]
+			} else {
+				x = vf[kOffset-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && as[x] == bs[y] {
+				x++
+				y++
+			}
+			vf[kOffset] = x
+			switch {
+			case x > n:
+				kfEnd += 2
+			case y > m:
+				kfStart += 2
+			case front:
+				bOffset := offset + delta - k
+				if bOffset >= 0 && bOffset < len(vb) && vb[bOffset] != -1 && x >= n-vb[bOffset] {
+					return aLo + x, bLo + y, true
+				}
+			}
+		}
+		for k := -step + kbStart; k <= step-kbEnd; k += 2 {
+			kOffset := offset + k
+			var x int
+			if k == -step || (k != step && vb[kOffset-1] < vb[kOffset+1]) {
+				x = vb[kOffset+1]
+			} else {
+				x = vb[kOffset-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && as[n-x-1] == bs[m-y-1] {
+				x++
+				y++
+			}
+			vb[kOffset] = x
+			switch {
+			case x > n:
+				kbEnd += 2
+			case y > m:
+				kbStart += 2
+			case !front:
+				fOffset := offset + delta - k
+				if fOffset >= 0 && fOffset < len(vf) && vf[fOffset] != -1 {
+					fx := vf[fOffset]
+					fy := offset + fx - fOffset
+					if fx >= n-x {
+						return aLo + fx, bLo + fy, true
+					}
+				}
+			}
+		}
+	}
+	return 0, 0, false
+}
+
+func split(s string) []string {
+	if s == "" {
+		return nil
+	}
+	// 统一换行符，避免 Windows 下编辑的内容整篇都不一样
+	s = strings.ReplaceAll(s, "\r\n", "\n")
+	return strings.Split(s, "\n")
+}
@@ -0,0 +1,159 @@
+package diff
+
+import (
+	"github.com/stretchr/testify/assert"
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+func TestLines(t *testing.T) {
+	testCases := []struct {
+		name string
+		a    string
+		b    string
+		want []Line
+	}{
+		{
+			name: "都为空",
+			want: []Line{},
+		},
+		{
+			name: "完全相同",
+			a:    "第一行\n第二行",
+			b:    "第一行\n第二行",
+			want: []Line{
+				{Op: OpEqual, Text: "第一行"},
+				{Op: OpEqual, Text: "第二行"},
+			},
+		},
+		{
+			name: "新增",
+			b:    "第一行",
+			want: []Line{
+				{Op: OpInsert, Text: "第一行"},
+			},
+		},
+		{
+			name: "删除",
+			a:    "第一行",
+			want: []Line{
+				{Op: OpDelete, Text: "第一行"},
+			},
+		},
+		{
+			name: "修改中间一行",
+			a:    "a\nb\nc",
+			b:    "a\nx\nc",
+			want: []Line{
+				{Op: OpEqual, Text: "a"},
+				{Op: OpDelete, Text: "b"},
+				{Op: OpInsert, Text: "x"},
+				{Op: OpEqual, Text: "c"},
+			},
+		},
+		{
+			name: "Windows换行符",
+			a:    "a\r\nb",
+			b:    "a\nb\nc",
+			want: []Line{
+				{Op: OpEqual, Text: "a"},
+				{Op: OpEqual, Text: "b"},
+				{Op: OpInsert, Text: "c"},
+			},
+		},
+		{
+			name: "经典例子",
+			a:    "A\nB\nC\nA\nB\nB\nA",
+			b:    "C\nB\nA\nB\nA\nC",
+			// 最短的编辑脚本不止一个，都是 5 步
+			want: []Line{
+				{Op: OpDelete, Text: "A"},
+				{Op: OpInsert, Text: "C"},
+				{Op: OpEqual, Text: "B"},
+				{Op: OpDelete, Text: "C"},
+				{Op: OpEqual, Text: "A"},
+				{Op: OpEqual, Text: "B"},
+				{Op: OpDelete, Text: "B"},
+				{Op: OpEqual, Text: "A"},
+				{Op: OpInsert, Text: "C"},
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, Lines(tc.a, tc.b))
+		})
+	}
+}
+
+// TestLines_Random 编辑脚本能还原出两边的内容，并且步数等于最短的步数
+func TestLines_Random(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	gen := func() []string {
+		res := make([]string, r.Intn(30))
+		for i := range res {
+			res[i] = string(rune('A' + r.Intn(4)))
+		}
+		return res
+	}
+	for i := 0; i < 500; i++ {
+		as, bs := gen(), gen()
+		lines := Lines(strings.Join(as, "\n"), strings.Join(bs, "\n"))
+		var gotA, gotB []string
+		edits := 0
+		for _, l := range lines {
+			if l.Op != OpInsert {
+				gotA = append(gotA, l.Text)
+			}
+			if l.Op != OpDelete {
+				gotB = append(gotB, l.Text)
+			}
+			if l.Op != OpEqual {
+				edits++
+			}
+		}
+		assert.Equal(t, strings.Join(as, "\n"), strings.Join(gotA, "\n"))
+		assert.Equal(t, strings.Join(bs, "\n"), strings.Join(gotB, "\n"))
+		assert.Equal(t, len(as)+len(bs)-2*lcs(as, bs), edits)
+	}
+}
+
+// TestLines_Large 大文章只改了几行，内存和时间都不会随着行数平方增长
+func TestLines_Large(t *testing.T) {
+	as := make([]string, 200000)
+	for i := range as {
+		as[i] = strings.Repeat("x", i%50)
+	}
+	bs := append([]string{}, as...)
+	bs[1000] = "修改"
+	bs = append(bs[:5000], bs[5001:]...)
+	lines := Lines(strings.Join(as, "\n"), strings.Join(bs, "\n"))
+	edits := 0
+	for _, l := range lines {
+		if l.Op != OpEqual {
+			edits++
+		}
+	}
+	assert.Equal(t, 3, edits)
+}
+
+func lcs(as, bs []string) int {
+	dp := make([][]int, len(as)+1)
+	for i := range dp {
+		dp[i] = make([]int, len(bs)+1)
+	}
+	for i := 1; i <= len(as); i++ {
+		for j := 1; j <= len(bs); j++ {
+			switch {
+			case as[i-1] == bs[j-1]:
+				dp[i][j] = dp[i-1][j-1] + 1
+			case dp[i-1][j] > dp[i][j-1]:
+				dp[i][j] = dp[i-1][j]
+			default:
+				dp[i][j] = dp[i][j-1]
+			}
+		}
+	}
+	return dp[len(as)][len(bs)]
+}
@@ -1,5 +1,7 @@
 package logger
 
+import "time"
+
 // 这里提供一些便利性的转换方法，不需要用户手动构造 Field
 
 func String(key string, value string) Field {
@@ -22,3 +24,17 @@ func Error(err error) Field {
 		Value: err,
 	}
 }
+
+func Int(key string, value int) Field {
+	return Field{
+		Key:   key,
+		Value: value,
+	}
+}
+
+func Duration(key string, value time.Duration) Field {
+	return Field{
+		Key:   key,
+		Value: value,
+	}
+}
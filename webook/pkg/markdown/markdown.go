@@ -0,0 +1,53 @@
+package markdown
+
+import (
+	"bytes"
+	"github.com/microcosm-cc/bluemonday"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/extension"
+	"github.com/yuin/goldmark/renderer/html"
+	stdhtml "html"
+	"regexp"
+	"strings"
+)
+
+var (
+	// md 支持 GitHub 风格的表格、删除线、任务列表和自动链接
+	// 允许作者在 Markdown 里面写 HTML，统一交给 policy 过滤
+	md = goldmark.New(
+		goldmark.WithExtensions(extension.GFM),
+		goldmark.WithRendererOptions(html.WithUnsafe()),
+	)
+	// policy 去掉 script、style、事件处理器和 javascript: 之类的链接
+	// 链接都会加上 rel="nofollow"，防止有人发文章刷外链
+	policy = newPolicy()
+	// textPolicy 去掉所有的标签，只留下文本
+	textPolicy = bluemonday.StrictPolicy()
+)
+
+func newPolicy() *bluemonday.Policy {
+	p := bluemonday.UGCPolicy()
+	// 代码块的语言，前端用来做语法高亮
+	p.AllowAttrs("class").Matching(regexp.MustCompile(`^language-[\w+#-]+$`)).OnElements("code")
+	// 任务列表
+	p.AllowAttrs("type").Matching(regexp.MustCompile(`^checkbox$`)).OnElements("input")
+	p.AllowAttrs("checked", "disabled").OnElements("input")
+	return p
+}
+
+// Render 把 Markdown 渲染成 HTML，结果是过滤过的，可以直接展示给读者
+// 这些对象都是并发安全的
+func Render(src string) (string, error) {
+	var buf bytes.Buffer
+	err := md.Convert([]byte(src), &buf)
+	if err != nil {
+		return "", err
+	}
+	return policy.Sanitize(buf.String()), nil
+}
+
+// PlainText 去掉 HTML 里面的标签，连续的空白合并成一个空格
+func PlainText(h string) string {
+	text := stdhtml.UnescapeString(textPolicy.Sanitize(h))
+	return strings.Join(strings.Fields(text), " ")
+}
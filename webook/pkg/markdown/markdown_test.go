@@ -0,0 +1,64 @@
+package markdown
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestRender(t *testing.T) {
+	testCases := []struct {
+		name string
+		src  string
+		want string
+	}{
+		{
+			name: "普通的 Markdown",
+			src:  "# 标题\n\n**加粗** 和 `代码`",
+			want: "<h1>标题</h1>\n<p><strong>加粗</strong> 和 <code>代码</code></p>\n",
+		},
+		{
+			name: "代码块保留语言",
+			src:  "```go\nfmt.Println(1)\n```",
+			want: "<pre><code class=\"language-go\">fmt.Println(1)\n</code></pre>\n",
+		},
+		{
+			name: "去掉 script",
+			src:  "你好<script>alert(1)</script>",
+			want: "<p>你好</p>\n",
+		},
+		{
+			name: "去掉事件处理器",
+			src:  `<img src="https://example.com/a.png" onerror="alert(1)">`,
+			want: `<img src="https://example.com/a.png">`,
+		},
+		{
+			name: "去掉不安全的链接",
+			src:  "[点我](javascript:alert(1))",
+			want: "<p>点我</p>\n",
+		},
+		{
+			name: "链接加上 nofollow",
+			src:  "[webook](https://example.com)",
+			want: "<p><a href=\"https://example.com\" rel=\"nofollow\">webook</a></p>\n",
+		},
+		{
+			name: "引用上传的图片",
+			src:  "![图](/objects/uploads/ab/abc.png)",
+			want: "<p><img src=\"/objects/uploads/ab/abc.png\" alt=\"图\"></p>\n",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			res, err := Render(tc.src)
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, res)
+		})
+	}
+}
+
+func TestPlainText(t *testing.T) {
+	res, err := Render("# 标题\n\n第一段 &lt;b&gt;\n\n- 列表\n- [链接](https://example.com)")
+	require.NoError(t, err)
+	assert.Equal(t, "标题 第一段 <b> 列表 链接", PlainText(res))
+}
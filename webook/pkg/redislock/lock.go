@@ -0,0 +1,176 @@
+package redislock
+
+import (
+	"context"
+	_ "embed"
+	"errors"
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrFailedToPreemptLock 锁被别人拿着
+	ErrFailedToPreemptLock = errors.New("redislock: 抢锁失败")
+	// ErrLockNotHold 锁已经过期了，或者被别人拿走了
+	ErrLockNotHold = errors.New("redislock: 没有持有锁")
+)
+
+var (
+	//go:embed lua/unlock.lua
+	luaUnlock string
+	//go:embed lua/refresh.lua
+	luaRefresh string
+)
+
+// Client 基于 Redis 的分布式锁
+type Client struct {
+	client redis.Cmdable
+	// 锁的值，每次加锁都不一样，释放和续约的时候用来确认锁还是自己的
+	valuer func() string
+}
+
+func NewClient(client redis.Cmdable) *Client {
+	return &Client{
+		client: client,
+		valuer: func() string {
+			return uuid.New().String()
+		},
+	}
+}
+
+// TryLock 尝试加锁，锁被别人拿着的时候返回 ErrFailedToPreemptLock，不会等待
+// 加锁成功之后在后台自动续约，直到调用 Unlock 或者续约失败
+func (c *Client) TryLock(ctx context.Context, key string, expiration time.Duration) (*Lock, error) {
+	val := c.valuer()
+	ok, err := c.client.SetNX(ctx, key, val, expiration).Result()
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, ErrFailedToPreemptLock
+	}
+	l := newLock(c.client, key, val, expiration)
+	go l.autoRefresh()
+	return l, nil
+}
+
+// Lock 已经拿到的锁
+type Lock struct {
+	client     redis.Cmdable
+	key        string
+	value      string
+	expiration time.Duration
+	// 续约的间隔，也是单次续约的超时时间
+	refreshInterval time.Duration
+
+	// 锁丢了或者释放了之后会被取消
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	stopOnce sync.Once
+	stop     chan struct{}
+}
+
+func newLock(client redis.Cmdable, key string, value string, expiration time.Duration) *Lock {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Lock{
+		client:     client,
+		key:        key,
+		value:      value,
+		expiration: expiration,
+		// 过期之前有两次续约的机会
+		refreshInterval: expiration / 3,
+		ctx:             ctx,
+		cancel:          cancel,
+		stop:            make(chan struct{}),
+	}
+}
+
+// Ctx 持有锁期间要做的事情应该用这个 context
+// 锁丢了之后它会被取消，这个时候别的实例可能已经拿到锁了，要尽快停下来
+func (l *Lock) Ctx() context.Context {
+	return l.ctx
+}
+
+// Refresh 续约，一般不需要手动调用
+func (l *Lock) Refresh(ctx context.Context) error {
+	res, err := l.client.Eval(ctx, luaRefresh, []string{l.key},
+		l.value, l.expiration.Milliseconds()).Int64()
+	if err != nil {
+		return err
+	}
+	if res != 1 {
+		return ErrLockNotHold
+	}
+	return nil
+}
+
+// Unlock 释放锁，同时停止续约
+// 锁已经不是自己的了会返回 ErrLockNotHold
+func (l *Lock) Unlock(ctx context.Context) error {
+	l.stopOnce.Do(func() {
+		close(l.stop)
+	})
+	l.cancel()
+	res, err := l.client.Eval(ctx, luaUnlock, []string{l.key}, l.value).Int64()
+	if err != nil {
+		return err
+	}
+	if res != 1 {
+		return ErrLockNotHold
+	}
+	return nil
+}
+
+// Keep 停止续约，但是不删除锁，让它在 expiration 之后自己过期
+// 用来在一段时间之内挡住别的实例，expiration 不是正数的时候直接释放
+func (l *Lock) Keep(ctx context.Context, expiration time.Duration) error {
+	if expiration <= 0 {
+		return l.Unlock(ctx)
+	}
+	l.stopOnce.Do(func() {
+		close(l.stop)
+	})
+	l.cancel()
+	res, err := l.client.Eval(ctx, luaRefresh, []string{l.key},
+		l.value, expiration.Milliseconds()).Int64()
+	if err != nil {
+		return err
+	}
+	if res != 1 {
+		return ErrLockNotHold
+	}
+	return nil
+}
+
+func (l *Lock) autoRefresh() {
+	ticker := time.NewTicker(l.refreshInterval)
+	defer ticker.Stop()
+	lastRefresh := time.Now()
+	for {
+		select {
+		case <-ticker.C:
+			ctx, cancel := context.WithTimeout(context.Background(), l.refreshInterval)
+			err := l.Refresh(ctx)
+			cancel()
+			switch {
+			case err == nil:
+				lastRefresh = time.Now()
+			case errors.Is(err, ErrLockNotHold):
+				l.cancel()
+				return
+			default:
+				// 可能只是网络抖动，下一次再试
+				// 超过过期时间都没有续约成功，锁肯定已经丢了
+				if time.Since(lastRefresh) >= l.expiration {
+					l.cancel()
+					return
+				}
+			}
+		case <-l.stop:
+			return
+		}
+	}
+}
@@ -0,0 +1,168 @@
+package redislock
+
+import (
+	"context"
+	"errors"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"testing"
+	"time"
+	redismock "webook/webook/mock/redis"
+)
+
+func TestClient_TryLock(t *testing.T) {
+	testCases := []struct {
+		name    string
+		mock    func(ctrl *gomock.Controller) redis.Cmdable
+		wantErr error
+	}{
+		{
+			name: "加锁成功",
+			mock: func(ctrl *gomock.Controller) redis.Cmdable {
+				client := redismock.NewMockCmdable(ctrl)
+				client.EXPECT().SetNX(gomock.Any(), "lock:key", "value", time.Minute).
+					Return(redis.NewBoolResult(true, nil))
+				// 测试结束的时候释放
+				client.EXPECT().Eval(gomock.Any(), luaUnlock, []string{"lock:key"}, "value").
+					Return(redis.NewCmdResult(int64(1), nil))
+				return client
+			},
+		},
+		{
+			name: "锁被别人拿着",
+			mock: func(ctrl *gomock.Controller) redis.Cmdable {
+				client := redismock.NewMockCmdable(ctrl)
+				client.EXPECT().SetNX(gomock.Any(), "lock:key", "value", time.Minute).
+					Return(redis.NewBoolResult(false, nil))
+				return client
+			},
+			wantErr: ErrFailedToPreemptLock,
+		},
+		{
+			name: "Redis 出错",
+			mock: func(ctrl *gomock.Controller) redis.Cmdable {
+				client := redismock.NewMockCmdable(ctrl)
+				client.EXPECT().SetNX(gomock.Any(), "lock:key", "value", time.Minute).
+					Return(redis.NewBoolResult(false, errors.New("mock redis error")))
+				return client
+			},
+			wantErr: errors.New("mock redis error"),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			c := NewClient(tc.mock(ctrl))
+			c.valuer = func() string {
+				return "value"
+			}
+			l, err := c.TryLock(context.Background(), "lock:key", time.Minute)
+			assert.Equal(t, tc.wantErr, err)
+			if err != nil {
+				return
+			}
+			assert.NoError(t, l.Unlock(context.Background()))
+		})
+	}
+}
+
+func TestLock_Unlock(t *testing.T) {
+	testCases := []struct {
+		name    string
+		mock    func(ctrl *gomock.Controller) redis.Cmdable
+		wantErr error
+	}{
+		{
+			name: "释放成功",
+			mock: func(ctrl *gomock.Controller) redis.Cmdable {
+				client := redismock.NewMockCmdable(ctrl)
+				client.EXPECT().Eval(gomock.Any(), luaUnlock, []string{"lock:key"}, "value").
+					Return(redis.NewCmdResult(int64(1), nil))
+				return client
+			},
+		},
+		{
+			name: "锁已经不是自己的了",
+			mock: func(ctrl *gomock.Controller) redis.Cmdable {
+				client := redismock.NewMockCmdable(ctrl)
+				client.EXPECT().Eval(gomock.Any(), luaUnlock, []string{"lock:key"}, "value").
+					Return(redis.NewCmdResult(int64(0), nil))
+				return client
+			},
+			wantErr: ErrLockNotHold,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			l := newLock(tc.mock(ctrl), "lock:key", "value", time.Minute)
+			err := l.Unlock(context.Background())
+			assert.Equal(t, tc.wantErr, err)
+			// 释放之后 context 就取消了
+			assert.Error(t, l.Ctx().Err())
+		})
+	}
+}
+
+// 下面的测试用 miniredis 跑真正的 Lua 脚本
+
+func newMiniRedisClient(t *testing.T) (*miniredis.Miniredis, *Client) {
+	mr := miniredis.RunT(t)
+	return mr, NewClient(redis.NewClient(&redis.Options{Addr: mr.Addr()}))
+}
+
+func TestLock_AutoRefresh(t *testing.T) {
+	mr, c := newMiniRedisClient(t)
+	l, err := c.TryLock(context.Background(), "lock:key", time.Millisecond*300)
+	require.NoError(t, err)
+	// miniredis 不会自己过期，手动让时间流逝，续约之后过期时间又回到 300ms
+	mr.FastForward(time.Millisecond * 250)
+	assert.Eventually(t, func() bool {
+		return mr.TTL("lock:key") > time.Millisecond*250
+	}, time.Second, time.Millisecond*20)
+	// 别人拿不到锁
+	_, err = c.TryLock(context.Background(), "lock:key", time.Millisecond*300)
+	assert.Equal(t, ErrFailedToPreemptLock, err)
+
+	require.NoError(t, l.Unlock(context.Background()))
+	assert.False(t, mr.Exists("lock:key"))
+}
+
+func TestLock_Keep(t *testing.T) {
+	mr, c := newMiniRedisClient(t)
+	l, err := c.TryLock(context.Background(), "lock:key", time.Millisecond*300)
+	require.NoError(t, err)
+	require.NoError(t, l.Keep(context.Background(), time.Minute))
+	assert.Error(t, l.Ctx().Err())
+	// 锁还在，过期时间换成了新的
+	assert.Equal(t, time.Minute, mr.TTL("lock:key"))
+	_, err = c.TryLock(context.Background(), "lock:key", time.Millisecond*300)
+	assert.Equal(t, ErrFailedToPreemptLock, err)
+	// 不再续约，过期之后别人就能拿到
+	mr.FastForward(time.Minute)
+	_, err = c.TryLock(context.Background(), "lock:key", time.Millisecond*300)
+	assert.NoError(t, err)
+}
+
+func TestLock_Lost(t *testing.T) {
+	mr, c := newMiniRedisClient(t)
+	l, err := c.TryLock(context.Background(), "lock:key", time.Millisecond*300)
+	require.NoError(t, err)
+	// 锁过期之后被别人拿走了
+	mr.Set("lock:key", "other")
+	select {
+	case <-l.Ctx().Done():
+	case <-time.After(time.Second):
+		t.Fatal("锁丢了之后 context 没有取消")
+	}
+	// 不能把别人的锁删掉
+	assert.Equal(t, ErrLockNotHold, l.Unlock(context.Background()))
+	val, err := mr.Get("lock:key")
+	require.NoError(t, err)
+	assert.Equal(t, "other", val)
+}
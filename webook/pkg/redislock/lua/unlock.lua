@@ -0,0 +1,6 @@
+-- 确认锁还是自己的，再删除
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+    return redis.call("DEL", KEYS[1])
+else
+    return 0
+end
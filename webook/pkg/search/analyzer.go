@@ -0,0 +1,80 @@
+package search
+
+import "unicode"
+
+// segment 文本里面连续的一段中文或者连续的一个单词
+type segment struct {
+	runes []rune
+	han   bool
+}
+
+// segments 按照中文和非中文切分，单词统一转成小写，标点和空白都是分隔符
+func segments(text string) []segment {
+	var (
+		res []segment
+		cur []rune
+		han bool
+	)
+	flush := func() {
+		if len(cur) > 0 {
+			res = append(res, segment{runes: cur, han: han})
+			cur = nil
+		}
+	}
+	for _, r := range text {
+		isHan := unicode.Is(unicode.Han, r)
+		if !isHan && !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			flush()
+			continue
+		}
+		if len(cur) > 0 && isHan != han {
+			flush()
+		}
+		han = isHan
+		cur = append(cur, unicode.ToLower(r))
+	}
+	flush()
+	return res
+}
+
+// indexTerms 建索引用的词
+// 中文不分词，同时索引单字和相邻的两个字，这样查一个字和查词都能命中
+func indexTerms(text string) []string {
+	var res []string
+	for _, seg := range segments(text) {
+		if !seg.han {
+			res = append(res, string(seg.runes))
+			continue
+		}
+		for i := range seg.runes {
+			res = append(res, string(seg.runes[i]))
+			if i+1 < len(seg.runes) {
+				res = append(res, string(seg.runes[i:i+2]))
+			}
+		}
+	}
+	return res
+}
+
+// queryTerms 查询用的词，已经去重
+// 中文只有一个字的时候查单字，否则查相邻的两个字，所有的词都要命中
+func queryTerms(query string) []string {
+	var res []string
+	seen := make(map[string]struct{})
+	add := func(term string) {
+		if _, ok := seen[term]; !ok {
+			seen[term] = struct{}{}
+			res = append(res, term)
+		}
+	}
+	for _, seg := range segments(query) {
+		if !seg.han || len(seg.runes) == 1 {
+			add(string(seg.runes))
+			continue
+		}
+		for i := 0; i+1 < len(seg.runes); i++ {
+			add(string(seg.runes[i : i+2]))
+		}
+	}
+	return res
+}
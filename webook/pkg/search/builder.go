@@ -0,0 +1,48 @@
+package search
+
+// Builder 重建索引，分批加入文档，Commit 之前原来的索引不受影响
+type Builder struct {
+	idx *Index
+	mem *Index
+}
+
+// NewBuilder 开始重建索引
+func (idx *Index) NewBuilder() *Builder {
+	return &Builder{idx: idx, mem: newIndex(idx.path)}
+}
+
+// Add 加入一批文档，Id 重复的以后加入的为准
+func (b *Builder) Add(docs ...Document) {
+	for _, doc := range docs {
+		b.mem.deleteLocked(doc.Id)
+		b.mem.addLocked(doc)
+	}
+}
+
+// Count 已经加入了多少篇文档
+func (b *Builder) Count() int {
+	return len(b.mem.docs)
+}
+
+// Commit 写快照并且替换掉原来的所有文档，之前的日志都会被删掉。
+// 重建期间写入的文档也会被覆盖，所以重建的时候要停止写入
+func (b *Builder) Commit() error {
+	idx := b.idx
+	idx.snapMutex.Lock()
+	defer idx.snapMutex.Unlock()
+	idx.mutex.Lock()
+	defer idx.mutex.Unlock()
+	// 先换一个新的日志，快照覆盖掉之前所有的日志，写完之后即使没删掉旧日志也不会再重放
+	seq := idx.seq
+	err := idx.rotateLocked()
+	if err != nil {
+		return err
+	}
+	idx.logCnt = 0
+	err = idx.writeSnapshot(snapshot{Seq: seq, Docs: b.mem.docsLocked()})
+	if err != nil {
+		return err
+	}
+	idx.docs, idx.tfs, idx.postings = b.mem.docs, b.mem.tfs, b.mem.postings
+	return idx.removeSegments(seq)
+}
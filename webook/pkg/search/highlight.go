@@ -0,0 +1,78 @@
+package search
+
+import (
+	"html"
+	"strings"
+	"unicode"
+)
+
+// highlight 截取第一个命中的词附近的片段，命中的词用 <em> 包起来
+// 其它的部分都会转义 HTML，前端可以直接展示
+func highlight(text string, terms []string, size int) string {
+	rs := []rune(text)
+	// 逐个字符转小写，保证下标和原文一一对应
+	lower := make([]rune, len(rs))
+	for i, r := range rs {
+		lower[i] = unicode.ToLower(r)
+	}
+	matched := make([]bool, len(rs))
+	first := -1
+	for _, term := range terms {
+		tr := []rune(term)
+		for i := 0; i+len(tr) <= len(lower); i++ {
+			if !hasPrefix(lower[i:], tr) {
+				continue
+			}
+			for j := i; j < i+len(tr); j++ {
+				matched[j] = true
+			}
+			if first < 0 || i < first {
+				first = i
+			}
+		}
+	}
+	start := 0
+	if first > size/4 {
+		// 命中的词前面留一点上下文
+		start = first - size/4
+	}
+	end := start + size
+	if end > len(rs) {
+		end = len(rs)
+	}
+	var sb strings.Builder
+	if start > 0 {
+		sb.WriteString("...")
+	}
+	for i := start; i < end; {
+		j := i
+		for j < end && matched[j] == matched[i] {
+			j++
+		}
+		seg := html.EscapeString(string(rs[i:j]))
+		if matched[i] {
+			sb.WriteString("<em>")
+			sb.WriteString(seg)
+			sb.WriteString("</em>")
+		} else {
+			sb.WriteString(seg)
+		}
+		i = j
+	}
+	if end < len(rs) {
+		sb.WriteString("...")
+	}
+	return sb.String()
+}
+
+func hasPrefix(rs []rune, prefix []rune) bool {
+	if len(rs) < len(prefix) {
+		return false
+	}
+	for i := range prefix {
+		if rs[i] != prefix[i] {
+			return false
+		}
+	}
+	return true
+}
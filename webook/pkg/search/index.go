@@ -0,0 +1,281 @@
+package search
+
+import (
+	"math"
+	"os"
+	"sort"
+	"sync"
+	"sync/atomic"
+)
+
+// defaultFragmentSize 高亮片段默认最多多少个字
+const defaultFragmentSize = 100
+
+// Document 要索引的文档
+type Document struct {
+	Id string
+	// Fields 需要全文检索的字段
+	Fields map[string]string
+	// Stored 只存储不索引的字段，查询的时候原样返回
+	Stored map[string]string
+}
+
+// Request 查询条件，Query 里面所有的词都要命中
+type Request struct {
+	Query string
+	// Fields 在哪些字段里面查，以及每个字段的权重
+	Fields map[string]float64
+	Offset int
+	Limit  int
+	// Highlight 要高亮的字段
+	Highlight []string
+	// FragmentSize 高亮片段最多多少个字，0 表示使用默认值
+	FragmentSize int
+}
+
+type Hit struct {
+	Id     string
+	Score  float64
+	Fields map[string]string
+	Stored map[string]string
+	// Fragments 高亮之后的片段，已经转义过 HTML
+	Fragments map[string]string
+}
+
+type Result struct {
+	// Total 一共命中了多少篇，用于分页
+	Total int
+	Hits  []Hit
+}
+
+// Index 嵌入式的全文索引
+// 倒排表全部在内存里，每次修改只往日志文件末尾追加一条记录，
+// 日志多了之后在后台把所有文档写成一个快照，再删掉快照已经包含了的日志。
+// 启动的时候先读快照，再按顺序重放日志。适合文档数量不多的场景，多个实例之间不会同步
+type Index struct {
+	path string
+
+	mutex sync.RWMutex
+	docs  map[string]Document
+	// tfs 每篇文档每个字段的词频
+	tfs map[string]map[string]map[string]int
+	// postings 倒排表，词 -> 包含这个词的文档
+	postings map[string]map[string]struct{}
+
+	// log 当前追加写的日志，seq 是它的序号，logCnt 是它之前还没有合并到快照里的记录数
+	log    *os.File
+	seq    int64
+	logCnt int
+	// snapMutex 保证同一时刻只有一个 goroutine 在写快照
+	snapMutex  sync.Mutex
+	compacting atomic.Bool
+	compactWg  sync.WaitGroup
+}
+
+func newIndex(path string) *Index {
+	return &Index{
+		path:     path,
+		docs:     make(map[string]Document),
+		tfs:      make(map[string]map[string]map[string]int),
+		postings: make(map[string]map[string]struct{}),
+	}
+}
+
+// Open 打开 path 对应的索引，文件不存在的时候是一个空的索引
+func Open(path string) (*Index, error) {
+	idx := newIndex(path)
+	err := idx.loadSnapshot()
+	if err != nil {
+		return nil, err
+	}
+	segs, err := idx.segments()
+	if err != nil {
+		return nil, err
+	}
+	for _, seg := range segs {
+		if seg.seq <= idx.seq {
+			// 快照写完了，但是还没来得及删掉
+			continue
+		}
+		cnt, err := idx.replay(seg.path)
+		if err != nil {
+			return nil, err
+		}
+		idx.logCnt += cnt
+		idx.seq = seg.seq
+	}
+	err = idx.rotateLocked()
+	if err != nil {
+		return nil, err
+	}
+	return idx, nil
+}
+
+// Close 等后台的快照写完，再关闭日志文件
+func (idx *Index) Close() error {
+	idx.compactWg.Wait()
+	idx.snapMutex.Lock()
+	defer idx.snapMutex.Unlock()
+	idx.mutex.Lock()
+	defer idx.mutex.Unlock()
+	return idx.log.Close()
+}
+
+// Index 新增或者更新文档
+func (idx *Index) Index(doc Document) error {
+	idx.mutex.Lock()
+	defer idx.mutex.Unlock()
+	err := idx.appendLocked(logRecord{Op: opIndex, Doc: doc})
+	if err != nil {
+		return err
+	}
+	idx.deleteLocked(doc.Id)
+	idx.addLocked(doc)
+	return nil
+}
+
+// Delete 删除文档，文档不存在也不会报错
+func (idx *Index) Delete(id string) error {
+	idx.mutex.Lock()
+	defer idx.mutex.Unlock()
+	if _, ok := idx.docs[id]; !ok {
+		return nil
+	}
+	err := idx.appendLocked(logRecord{Op: opDelete, Doc: Document{Id: id}})
+	if err != nil {
+		return err
+	}
+	idx.deleteLocked(id)
+	return nil
+}
+
+// Count 索引里面有多少篇文档
+func (idx *Index) Count() int {
+	idx.mutex.RLock()
+	defer idx.mutex.RUnlock()
+	return len(idx.docs)
+}
+
+// Search 按照 TF-IDF 打分，分数相同的按照 Id 排序
+func (idx *Index) Search(req Request) Result {
+	terms := queryTerms(req.Query)
+	if len(terms) == 0 {
+		return Result{}
+	}
+	idx.mutex.RLock()
+	defer idx.mutex.RUnlock()
+	hits := make([]Hit, 0)
+	for id := range idx.candidatesLocked(terms) {
+		score, ok := idx.scoreLocked(id, terms, req.Fields)
+		if ok {
+			hits = append(hits, Hit{Id: id, Score: score})
+		}
+	}
+	sort.Slice(hits, func(i, j int) bool {
+		if hits[i].Score == hits[j].Score {
+			return hits[i].Id < hits[j].Id
+		}
+		return hits[i].Score > hits[j].Score
+	})
+	res := Result{Total: len(hits)}
+	if req.Offset >= len(hits) {
+		return res
+	}
+	hits = hits[req.Offset:]
+	if req.Limit > 0 && req.Limit < len(hits) {
+		hits = hits[:req.Limit]
+	}
+	size := req.FragmentSize
+	if size <= 0 {
+		size = defaultFragmentSize
+	}
+	for i := range hits {
+		doc := idx.docs[hits[i].Id]
+		hits[i].Fields = doc.Fields
+		hits[i].Stored = doc.Stored
+		hits[i].Fragments = make(map[string]string, len(req.Highlight))
+		for _, field := range req.Highlight {
+			hits[i].Fragments[field] = highlight(doc.Fields[field], terms, size)
+		}
+	}
+	res.Hits = hits
+	return res
+}
+
+// candidatesLocked 包含所有词的文档，从最短的倒排表开始求交集
+func (idx *Index) candidatesLocked(terms []string) map[string]struct{} {
+	sorted := make([]map[string]struct{}, 0, len(terms))
+	for _, term := range terms {
+		docs, ok := idx.postings[term]
+		if !ok {
+			return nil
+		}
+		sorted = append(sorted, docs)
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		return len(sorted[i]) < len(sorted[j])
+	})
+	res := make(map[string]struct{}, len(sorted[0]))
+	for id := range sorted[0] {
+		res[id] = struct{}{}
+	}
+	for _, docs := range sorted[1:] {
+		for id := range res {
+			if _, ok := docs[id]; !ok {
+				delete(res, id)
+			}
+		}
+	}
+	return res
+}
+
+// scoreLocked 每个词都要在指定的字段里面出现，不然就不算命中
+func (idx *Index) scoreLocked(id string, terms []string, fields map[string]float64) (float64, bool) {
+	var score float64
+	n := float64(len(idx.docs))
+	for _, term := range terms {
+		idf := math.Log(1 + n/float64(len(idx.postings[term])))
+		var termScore float64
+		for field, boost := range fields {
+			termScore += boost * float64(idx.tfs[id][field][term]) * idf
+		}
+		if termScore == 0 {
+			return 0, false
+		}
+		score += termScore
+	}
+	return score, true
+}
+
+func (idx *Index) addLocked(doc Document) {
+	idx.docs[doc.Id] = doc
+	tfs := make(map[string]map[string]int, len(doc.Fields))
+	for field, text := range doc.Fields {
+		tf := make(map[string]int)
+		for _, term := range indexTerms(text) {
+			tf[term]++
+			docs, ok := idx.postings[term]
+			if !ok {
+				docs = make(map[string]struct{})
+				idx.postings[term] = docs
+			}
+			docs[doc.Id] = struct{}{}
+		}
+		tfs[field] = tf
+	}
+	idx.tfs[doc.Id] = tfs
+}
+
+func (idx *Index) deleteLocked(id string) {
+	for _, tf := range idx.tfs[id] {
+		for term := range tf {
+			docs := idx.postings[term]
+			delete(docs, id)
+			if len(docs) == 0 {
+				delete(idx.postings, term)
+			}
+		}
+	}
+	delete(idx.tfs, id)
+	delete(idx.docs, id)
+}
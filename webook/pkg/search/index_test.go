@@ -0,0 +1,205 @@
+package search
+
+import (
+	"bytes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+)
+
+var testFields = map[string]float64{"title": 2, "content": 1}
+
+func newTestIndex(t *testing.T) (*Index, string) {
+	path := filepath.Join(t.TempDir(), "search", "test.gob")
+	idx, err := Open(path)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_ = idx.Close()
+	})
+	docs := []Document{
+		{Id: "1", Fields: map[string]string{"title": "Go 语言入门", "content": "学习 Go 的并发编程"},
+			Stored: map[string]string{"author_id": "123"}},
+		{Id: "2", Fields: map[string]string{"title": "Redis 分布式锁", "content": "用 Go 实现一个 Redis 分布式锁"}},
+		{Id: "3", Fields: map[string]string{"title": "MySQL 索引", "content": "联合索引的最左前缀原则"}},
+	}
+	for _, doc := range docs {
+		require.NoError(t, idx.Index(doc))
+	}
+	return idx, path
+}
+
+func ids(res Result) []string {
+	var res2 []string
+	for _, hit := range res.Hits {
+		res2 = append(res2, hit.Id)
+	}
+	return res2
+}
+
+func TestIndex_Search(t *testing.T) {
+	idx, _ := newTestIndex(t)
+	testCases := []struct {
+		name      string
+		req       Request
+		wantTotal int
+		wantIds   []string
+	}{
+		{
+			name:      "英文不区分大小写，标题权重更高",
+			req:       Request{Query: "go", Fields: testFields},
+			wantTotal: 2,
+			wantIds:   []string{"1", "2"},
+		},
+		{
+			name:      "中文词语",
+			req:       Request{Query: "分布式", Fields: testFields},
+			wantTotal: 1,
+			wantIds:   []string{"2"},
+		},
+		{
+			name:      "中文单字",
+			req:       Request{Query: "锁", Fields: testFields},
+			wantTotal: 1,
+			wantIds:   []string{"2"},
+		},
+		{
+			name:      "所有的词都要命中",
+			req:       Request{Query: "Go 索引", Fields: testFields},
+			wantTotal: 0,
+		},
+		{
+			name:      "只在指定的字段里面查",
+			req:       Request{Query: "go", Fields: map[string]float64{"title": 1}},
+			wantTotal: 1,
+			wantIds:   []string{"1"},
+		},
+		{
+			name:      "分页",
+			req:       Request{Query: "go", Fields: testFields, Offset: 1, Limit: 1},
+			wantTotal: 2,
+			wantIds:   []string{"2"},
+		},
+		{
+			name:      "超过最后一页",
+			req:       Request{Query: "go", Fields: testFields, Offset: 2, Limit: 1},
+			wantTotal: 2,
+		},
+		{
+			name: "只有标点",
+			req:  Request{Query: "，。", Fields: testFields},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			res := idx.Search(tc.req)
+			assert.Equal(t, tc.wantTotal, res.Total)
+			assert.Equal(t, tc.wantIds, ids(res))
+		})
+	}
+}
+
+func TestIndex_Highlight(t *testing.T) {
+	idx, _ := newTestIndex(t)
+	res := idx.Search(Request{Query: "redis", Fields: testFields, Highlight: []string{"title", "content"}})
+	require.Len(t, res.Hits, 1)
+	assert.Equal(t, "<em>Redis</em> 分布式锁", res.Hits[0].Fragments["title"])
+	assert.Equal(t, "用 Go 实现一个 <em>Redis</em> 分布式锁", res.Hits[0].Fragments["content"])
+
+	// 太长的内容只截取命中的附近
+	text := "开头有很多很多很多的内容，然后才是关键字出现的地方，后面还有内容"
+	assert.Equal(t, "...才是<em>关键</em>字出现的...", highlight(text, []string{"关键"}, 8))
+	// HTML 要转义
+	assert.Equal(t, "&lt;b&gt;<em>关键</em>&lt;/b&gt;", highlight("<b>关键</b>", []string{"关键"}, 100))
+}
+
+func TestIndex_Persist(t *testing.T) {
+	idx, path := newTestIndex(t)
+	require.NoError(t, idx.Delete("2"))
+	require.NoError(t, idx.Index(Document{Id: "1", Fields: map[string]string{"title": "Rust 入门"}}))
+	require.NoError(t, idx.Close())
+
+	// 重新打开之后重放日志，数据还在
+	idx, err := Open(path)
+	require.NoError(t, err)
+	assert.Equal(t, 2, idx.Count())
+	assert.Equal(t, 0, idx.Search(Request{Query: "redis", Fields: testFields}).Total)
+	// 更新之后旧的词不会再命中
+	assert.Equal(t, 0, idx.Search(Request{Query: "go", Fields: testFields}).Total)
+	res := idx.Search(Request{Query: "rust", Fields: testFields})
+	assert.Equal(t, []string{"1"}, ids(res))
+
+	b := idx.NewBuilder()
+	b.Add(Document{Id: "4", Fields: map[string]string{"title": "旧的标题"}})
+	b.Add(Document{Id: "4", Fields: map[string]string{"title": "重建"}},
+		Document{Id: "5", Fields: map[string]string{"title": "重建第二篇"}})
+	assert.Equal(t, 2, b.Count())
+	// 提交之前不影响原来的索引
+	assert.Equal(t, 2, idx.Count())
+	assert.Equal(t, 0, idx.Search(Request{Query: "重建", Fields: testFields}).Total)
+	require.NoError(t, b.Commit())
+	assert.Equal(t, []string{"4", "5"}, ids(idx.Search(Request{Query: "重建", Fields: testFields})))
+	require.NoError(t, idx.Delete("5"))
+	require.NoError(t, idx.Close())
+
+	idx, err = Open(path)
+	require.NoError(t, err)
+	defer idx.Close()
+	assert.Equal(t, 1, idx.Count())
+	assert.Equal(t, []string{"4"}, ids(idx.Search(Request{Query: "重建", Fields: testFields})))
+	assert.Equal(t, 0, idx.Search(Request{Query: "旧的", Fields: testFields}).Total)
+	assert.Equal(t, 0, idx.Search(Request{Query: "rust", Fields: testFields}).Total)
+}
+
+func TestIndex_Compact(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.gob")
+	idx, err := Open(path)
+	require.NoError(t, err)
+	// 反复更新同一批文档，日志超过阈值之后在后台合并成快照
+	for i := 0; i < compactMinRecords*3; i++ {
+		require.NoError(t, idx.Index(Document{Id: strconv.Itoa(i % 10),
+			Fields: map[string]string{"title": "第" + strconv.Itoa(i) + "次"}}))
+	}
+	require.NoError(t, idx.Delete("0"))
+	// Close 会等后台的合并结束
+	require.NoError(t, idx.Close())
+
+	segs, err := idx.segments()
+	require.NoError(t, err)
+	assert.Less(t, len(segs), 3)
+	var logCnt int
+	for _, seg := range segs {
+		data, err := os.ReadFile(seg.path)
+		require.NoError(t, err)
+		logCnt += bytes.Count(data, []byte{'\n'})
+	}
+	assert.Less(t, logCnt, compactMinRecords*3)
+
+	idx, err = Open(path)
+	require.NoError(t, err)
+	defer idx.Close()
+	assert.Equal(t, 9, idx.Count())
+	last := compactMinRecords*3 - 1
+	assert.Equal(t, []string{strconv.Itoa(last % 10)},
+		ids(idx.Search(Request{Query: "第" + strconv.Itoa(last) + "次", Fields: testFields})))
+}
+
+func TestIndex_ReplayTruncatedLog(t *testing.T) {
+	idx, path := newTestIndex(t)
+	require.NoError(t, idx.Close())
+	segs, err := idx.segments()
+	require.NoError(t, err)
+	// 模拟崩溃的时候最后一条只写了一半
+	f, err := os.OpenFile(segs[len(segs)-1].path, os.O_WRONLY|os.O_APPEND, 0o644)
+	require.NoError(t, err)
+	_, err = f.WriteString(`{"op":"index","doc":{"Id":"4","Fie`)
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+
+	idx, err = Open(path)
+	require.NoError(t, err)
+	defer idx.Close()
+	assert.Equal(t, 3, idx.Count())
+}
@@ -0,0 +1,225 @@
+package search
+
+import (
+	"bytes"
+	"encoding/gob"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// compactMinRecords 日志至少攒到这么多条才会合并到快照里，
+// 文档多的时候阈值是文档数量，保证写快照的开销均摊到每次修改上是常数
+const compactMinRecords = 1024
+
+const (
+	opIndex  = "index"
+	opDelete = "delete"
+)
+
+// logRecord 日志里的一条记录，一行一个 JSON
+type logRecord struct {
+	Op  string   `json:"op"`
+	Doc Document `json:"doc"`
+}
+
+type logSegment struct {
+	seq  int64
+	path string
+}
+
+// appendLocked 追加一条日志，不会 fsync。
+// 索引可以从线上库重建，机器宕机丢掉最后几条修改是可以接受的
+func (idx *Index) appendLocked(rec logRecord) error {
+	data, err := json.Marshal(rec)
+	if err != nil {
+		return err
+	}
+	_, err = idx.log.Write(append(data, '\n'))
+	if err != nil {
+		return err
+	}
+	idx.logCnt++
+	if idx.logCnt > compactMinRecords && idx.logCnt > len(idx.docs) &&
+		idx.compacting.CompareAndSwap(false, true) {
+		idx.compactWg.Add(1)
+		go idx.compact()
+	}
+	return nil
+}
+
+// compact 把当前的文档写成快照，然后删掉快照已经包含了的日志
+// 写快照的时候不持有 mutex，不影响查询和写入
+func (idx *Index) compact() {
+	defer idx.compactWg.Done()
+	defer idx.compacting.Store(false)
+	idx.snapMutex.Lock()
+	defer idx.snapMutex.Unlock()
+	idx.mutex.Lock()
+	docs := idx.docsLocked()
+	seq := idx.seq
+	err := idx.rotateLocked()
+	if err == nil {
+		idx.logCnt = 0
+	}
+	idx.mutex.Unlock()
+	if err != nil {
+		// 日志都还在，下次再合并
+		return
+	}
+	if idx.writeSnapshot(snapshot{Seq: seq, Docs: docs}) != nil {
+		return
+	}
+	_ = idx.removeSegments(seq)
+}
+
+// rotateLocked 换一个新的日志文件继续写
+func (idx *Index) rotateLocked() error {
+	err := os.MkdirAll(filepath.Dir(idx.path), 0o755)
+	if err != nil {
+		return err
+	}
+	f, err := os.OpenFile(idx.segmentPath(idx.seq+1), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return err
+	}
+	if idx.log != nil {
+		_ = idx.log.Close()
+	}
+	idx.log = f
+	idx.seq++
+	return nil
+}
+
+func (idx *Index) segmentPath(seq int64) string {
+	return fmt.Sprintf("%s.%08d.log", idx.path, seq)
+}
+
+// segments 按照序号从小到大返回所有的日志文件
+func (idx *Index) segments() ([]logSegment, error) {
+	paths, err := filepath.Glob(idx.path + ".*.log")
+	if err != nil {
+		return nil, err
+	}
+	res := make([]logSegment, 0, len(paths))
+	for _, p := range paths {
+		seq, err := strconv.ParseInt(strings.TrimSuffix(strings.TrimPrefix(p, idx.path+"."), ".log"), 10, 64)
+		if err != nil {
+			// 不是我们写的文件
+			continue
+		}
+		res = append(res, logSegment{seq: seq, path: p})
+	}
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].seq < res[j].seq
+	})
+	return res, nil
+}
+
+// removeSegments 删掉序号不超过 seq 的日志
+func (idx *Index) removeSegments(seq int64) error {
+	segs, err := idx.segments()
+	if err != nil {
+		return err
+	}
+	for _, seg := range segs {
+		if seg.seq > seq {
+			break
+		}
+		err = os.Remove(seg.path)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// replay 重放一个日志文件，返回重放了多少条
+func (idx *Index) replay(path string) (int, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+	dec := json.NewDecoder(f)
+	cnt := 0
+	for {
+		var rec logRecord
+		// 崩溃的时候最后一条可能只写了一半，读到这里就结束
+		if dec.Decode(&rec) != nil {
+			return cnt, nil
+		}
+		switch rec.Op {
+		case opIndex:
+			idx.deleteLocked(rec.Doc.Id)
+			idx.addLocked(rec.Doc)
+		case opDelete:
+			idx.deleteLocked(rec.Doc.Id)
+		}
+		cnt++
+	}
+}
+
+// snapshot 快照，Seq 之前（包括 Seq）的日志都已经包含在里面了
+type snapshot struct {
+	Seq  int64
+	Docs []Document
+}
+
+func (idx *Index) loadSnapshot() error {
+	data, err := os.ReadFile(idx.path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	var snap snapshot
+	err = gob.NewDecoder(bytes.NewReader(data)).Decode(&snap)
+	if err != nil {
+		return err
+	}
+	for _, doc := range snap.Docs {
+		idx.addLocked(doc)
+	}
+	idx.seq = snap.Seq
+	return nil
+}
+
+func (idx *Index) docsLocked() []Document {
+	docs := make([]Document, 0, len(idx.docs))
+	for _, doc := range idx.docs {
+		docs = append(docs, doc)
+	}
+	return docs
+}
+
+// writeSnapshot 先写临时文件再重命名，写到一半崩溃了也不会破坏原来的文件
+func (idx *Index) writeSnapshot(snap snapshot) error {
+	err := os.MkdirAll(filepath.Dir(idx.path), 0o755)
+	if err != nil {
+		return err
+	}
+	f, err := os.CreateTemp(filepath.Dir(idx.path), filepath.Base(idx.path)+".tmp*")
+	if err != nil {
+		return err
+	}
+	err = gob.NewEncoder(f).Encode(snap)
+	if err == nil {
+		err = f.Sync()
+	}
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		_ = os.Remove(f.Name())
+		return err
+	}
+	return os.Rename(f.Name(), idx.path)
+}
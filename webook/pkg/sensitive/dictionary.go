@@ -0,0 +1,132 @@
+package sensitive
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"github.com/fsnotify/fsnotify"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// Dictionary 从文件加载的敏感词词库，文件修改之后自动重新加载
+//
+// 文件一行一个词，# 开头的是注释。词后面可以跟上级别，用空白隔开：
+//
+//	违禁词
+//	擦边词 review
+//
+// 没有写级别的词直接拦截，review 级别的词需要人工审核
+type Dictionary struct {
+	path     string
+	matchers atomic.Pointer[matchers]
+}
+
+type matchers struct {
+	block  *Matcher
+	review *Matcher
+}
+
+const (
+	levelReview = "review"
+	// reloadDelay 文件最后一次变更之后多久重新加载
+	reloadDelay = time.Millisecond * 100
+)
+
+// Load 加载词库，文件不存在或者格式不对的时候返回错误
+func Load(path string) (*Dictionary, error) {
+	d := &Dictionary{path: filepath.Clean(path)}
+	return d, d.Reload()
+}
+
+// NewDictionary 直接用给定的词构造词库，不对应任何文件，主要用于测试
+func NewDictionary(block []string, review []string) *Dictionary {
+	d := &Dictionary{}
+	d.matchers.Store(&matchers{block: NewMatcher(block), review: NewMatcher(review)})
+	return d
+}
+
+// Reload 重新加载词库，失败的时候继续使用之前的词库
+func (d *Dictionary) Reload() error {
+	f, err := os.Open(d.path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	var block, review []string
+	scanner := bufio.NewScanner(f)
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fields := strings.Fields(line)
+		switch {
+		case len(fields) == 1:
+			block = append(block, fields[0])
+		case len(fields) == 2 && fields[1] == levelReview:
+			review = append(review, fields[0])
+		default:
+			return fmt.Errorf("敏感词词库格式错误 %s:%d", d.path, lineNo)
+		}
+	}
+	if err = scanner.Err(); err != nil {
+		return err
+	}
+	d.matchers.Store(&matchers{block: NewMatcher(block), review: NewMatcher(review)})
+	return nil
+}
+
+// Match 分别返回命中的需要拦截的词和需要人工审核的词
+func (d *Dictionary) Match(text string) (block []string, review []string) {
+	m := d.matchers.Load()
+	return m.block.Match(text), m.review.Match(text)
+}
+
+// Watch 监听词库文件，一直运行到 ctx 被取消
+// 编辑器保存文件的时候经常是先写临时文件再改名，所以监听的是文件所在的目录
+// 写文件的时候会先清空再写入，最后一次变更之后等一会再加载，避免加载到写了一半的文件
+// 重新加载失败的时候调用 onErr，不会中断监听
+func (d *Dictionary) Watch(ctx context.Context, onErr func(err error)) error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+	defer watcher.Close()
+	err = watcher.Add(filepath.Dir(d.path))
+	if err != nil {
+		return err
+	}
+	timer := time.NewTimer(time.Hour)
+	timer.Stop()
+	defer timer.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return nil
+			}
+			if filepath.Clean(event.Name) != d.path ||
+				!(event.Has(fsnotify.Write) || event.Has(fsnotify.Create)) {
+				continue
+			}
+			timer.Reset(reloadDelay)
+		case <-timer.C:
+			if er := d.Reload(); er != nil {
+				onErr(er)
+			}
+		case er, ok := <-watcher.Errors:
+			if !ok {
+				return nil
+			}
+			onErr(er)
+		}
+	}
+}
@@ -0,0 +1,137 @@
+package sensitive
+
+import (
+	"golang.org/x/text/unicode/norm"
+	"strings"
+	"unicode"
+)
+
+// Matcher Aho-Corasick 自动机，一次扫描就能找出文本里面所有的词
+// 构造之后是只读的，可以并发使用
+type Matcher struct {
+	nodes []node
+	words []string
+}
+
+type node struct {
+	next map[rune]int32
+	// fail 匹配失败的时候跳转到的节点，也就是当前路径最长的、在树里面的后缀
+	fail int32
+	// word 以这个节点结尾的词在 words 里面的下标，-1 表示不是词的结尾
+	word int32
+	// out 沿着 fail 链往上最近的一个词的结尾，-1 表示没有
+	out int32
+}
+
+// NewMatcher 匹配的时候忽略大小写、全角半角，也忽略空格和标点，防止 "敏 感 词" 这种绕过
+func NewMatcher(words []string) *Matcher {
+	m := &Matcher{nodes: []node{newNode()}}
+	for _, w := range words {
+		m.insert(w)
+	}
+	m.build()
+	return m
+}
+
+func newNode() node {
+	return node{fail: 0, word: -1, out: -1}
+}
+
+func (m *Matcher) insert(word string) {
+	cur := int32(0)
+	empty := true
+	for _, r := range normalize(word) {
+		empty = false
+		if m.nodes[cur].next == nil {
+			m.nodes[cur].next = make(map[rune]int32)
+		}
+		nxt, ok := m.nodes[cur].next[r]
+		if !ok {
+			m.nodes = append(m.nodes, newNode())
+			nxt = int32(len(m.nodes) - 1)
+			m.nodes[cur].next[r] = nxt
+		}
+		cur = nxt
+	}
+	if empty || m.nodes[cur].word >= 0 {
+		return
+	}
+	m.nodes[cur].word = int32(len(m.words))
+	m.words = append(m.words, word)
+}
+
+// build 按照层次遍历计算 fail 指针
+func (m *Matcher) build() {
+	queue := make([]int32, 0, len(m.nodes))
+	for _, child := range m.nodes[0].next {
+		queue = append(queue, child)
+	}
+	for len(queue) > 0 {
+		cur := queue[0]
+		queue = queue[1:]
+		for r, child := range m.nodes[cur].next {
+			f := m.nodes[cur].fail
+			for f > 0 && !m.has(f, r) {
+				f = m.nodes[f].fail
+			}
+			if nxt, ok := m.nodes[f].next[r]; ok && nxt != child {
+				m.nodes[child].fail = nxt
+			}
+			fail := m.nodes[child].fail
+			if m.nodes[fail].word >= 0 {
+				m.nodes[child].out = fail
+			} else {
+				m.nodes[child].out = m.nodes[fail].out
+			}
+			queue = append(queue, child)
+		}
+	}
+}
+
+func (m *Matcher) has(n int32, r rune) bool {
+	_, ok := m.nodes[n].next[r]
+	return ok
+}
+
+// Match 返回文本里面出现过的词，按照第一次出现的顺序，不重复
+func (m *Matcher) Match(text string) []string {
+	var (
+		res  []string
+		seen map[int32]struct{}
+		cur  int32
+	)
+	for _, r := range normalize(text) {
+		for cur > 0 && !m.has(cur, r) {
+			cur = m.nodes[cur].fail
+		}
+		if nxt, ok := m.nodes[cur].next[r]; ok {
+			cur = nxt
+		}
+		for n := cur; n > 0; n = m.nodes[n].out {
+			w := m.nodes[n].word
+			if w < 0 {
+				continue
+			}
+			if seen == nil {
+				seen = make(map[int32]struct{}, 4)
+			}
+			if _, ok := seen[w]; !ok {
+				seen[w] = struct{}{}
+				res = append(res, m.words[w])
+			}
+		}
+	}
+	return res
+}
+
+// normalize 全角转半角、统一小写，只保留文字和数字
+func normalize(s string) []rune {
+	s = strings.ToLower(norm.NFKC.String(s))
+	res := make([]rune, 0, len(s))
+	for _, r := range s {
+		if unicode.IsLetter(r) || unicode.IsNumber(r) {
+			res = append(res, r)
+		}
+	}
+	return res
+}
@@ -0,0 +1,84 @@
+package sensitive
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestMatcher_Match(t *testing.T) {
+	testCases := []struct {
+		name  string
+		words []string
+		text  string
+		want  []string
+	}{
+		{
+			name:  "没有命中",
+			words: []string{"赌博", "毒品"},
+			text:  "今天天气不错",
+		},
+		{
+			name:  "按照出现的顺序返回，不重复",
+			words: []string{"赌博", "毒品"},
+			text:  "毒品和赌博，还有毒品",
+			want:  []string{"毒品", "赌博"},
+		},
+		{
+			name:  "词是另一个词的后缀",
+			words: []string{"she", "he", "hers"},
+			text:  "ushers",
+			want:  []string{"she", "he", "hers"},
+		},
+		{
+			name:  "忽略大小写、全角和中间的符号",
+			words: []string{"Casino"},
+			text:  "ＣＡＳ-i n*o",
+			want:  []string{"Casino"},
+		},
+		{
+			name:  "空的词库",
+			words: nil,
+			text:  "任何内容",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			m := NewMatcher(tc.words)
+			assert.Equal(t, tc.want, m.Match(tc.text))
+		})
+	}
+}
+
+func TestDictionary_Watch(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "words.txt")
+	require.NoError(t, os.WriteFile(path, []byte("# 注释\n赌博\n擦边 review\n"), 0644))
+	d, err := Load(path)
+	require.NoError(t, err)
+	block, review := d.Match("赌博擦边")
+	assert.Equal(t, []string{"赌博"}, block)
+	assert.Equal(t, []string{"擦边"}, review)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		_ = d.Watch(ctx, func(err error) {})
+	}()
+	// 等待开始监听
+	time.Sleep(time.Millisecond * 100)
+	require.NoError(t, os.WriteFile(path, []byte("毒品\n"), 0644))
+	assert.Eventually(t, func() bool {
+		block, review = d.Match("赌博毒品擦边")
+		return len(block) == 1 && block[0] == "毒品" && len(review) == 0
+	}, time.Second*3, time.Millisecond*50)
+
+	// 格式错误的时候继续使用之前的词库
+	require.NoError(t, os.WriteFile(path, []byte("毒品 unknown\n"), 0644))
+	time.Sleep(time.Millisecond * 500)
+	block, _ = d.Match("毒品")
+	assert.Equal(t, []string{"毒品"}, block)
+}
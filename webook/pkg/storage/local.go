@@ -0,0 +1,155 @@
+package storage
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// LocalStorage 把对象保存在本地目录，开发和测试用
+// 本地没有单独的下载服务，下载链接指向应用自己的 prefix 路径，由 ServeHTTP 校验签名之后返回文件
+type LocalStorage struct {
+	dir    string
+	prefix string
+	secret []byte
+	now    func() time.Time
+}
+
+// NewLocalStorage prefix 是下载链接的路径前缀，比如 /objects/local/
+func NewLocalStorage(dir string, prefix string, secret []byte) (*LocalStorage, error) {
+	err := os.MkdirAll(dir, 0o755)
+	if err != nil {
+		return nil, err
+	}
+	if !strings.HasSuffix(prefix, "/") {
+		prefix += "/"
+	}
+	return &LocalStorage{dir: dir, prefix: prefix, secret: secret, now: time.Now}, nil
+}
+
+// PathPrefix 下载链接的路径前缀，要把这个前缀的请求交给 ServeHTTP
+func (s *LocalStorage) PathPrefix() string {
+	return s.prefix
+}
+
+func (s *LocalStorage) Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error {
+	p, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	err = os.MkdirAll(filepath.Dir(p), 0o755)
+	if err != nil {
+		return err
+	}
+	// 先写临时文件再改名，读的人不会读到写了一半的文件
+	f, err := os.CreateTemp(filepath.Dir(p), ".upload-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+	_, err = io.Copy(f, r)
+	if er := f.Close(); err == nil {
+		err = er
+	}
+	if err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), p)
+}
+
+func (s *LocalStorage) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	p, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(p)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, ErrObjectNotFound
+	}
+	return f, err
+}
+
+func (s *LocalStorage) Delete(ctx context.Context, key string) error {
+	p, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	err = os.Remove(p)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	return err
+}
+
+func (s *LocalStorage) PresignedURL(ctx context.Context, key string, expire time.Duration) (string, error) {
+	_, err := s.path(key)
+	if err != nil {
+		return "", err
+	}
+	expires := strconv.FormatInt(s.now().Add(expire).Unix(), 10)
+	q := url.Values{}
+	q.Set("expires", expires)
+	q.Set("signature", s.sign(key, expires))
+	return s.prefix + key + "?" + q.Encode(), nil
+}
+
+// ServeHTTP 校验下载链接的签名和有效期，通过之后返回文件
+func (s *LocalStorage) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	key := strings.TrimPrefix(r.URL.Path, s.prefix)
+	expires := r.URL.Query().Get("expires")
+	signature := r.URL.Query().Get("signature")
+	exp, err := strconv.ParseInt(expires, 10, 64)
+	if err != nil || s.now().Unix() > exp ||
+		!hmac.Equal([]byte(signature), []byte(s.sign(key, expires))) {
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+	p, err := s.path(key)
+	if err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	f, err := os.Open(p)
+	if err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	// 内容是不会变的，key 就是内容的摘要
+	w.Header().Set("Cache-Control", "private, max-age=86400")
+	http.ServeContent(w, r, path.Base(key), info.ModTime(), f)
+}
+
+func (s *LocalStorage) sign(key string, expires string) string {
+	mac := hmac.New(sha256.New, s.secret)
+	mac.Write([]byte(key))
+	mac.Write([]byte{'\n'})
+	mac.Write([]byte(expires))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// path key 对应的文件路径，不允许跳出 dir
+func (s *LocalStorage) path(key string) (string, error) {
+	if key == "" || strings.HasPrefix(key, "/") || key != path.Clean(key) ||
+		key == ".." || strings.HasPrefix(key, "../") {
+		return "", ErrInvalidKey
+	}
+	return filepath.Join(s.dir, filepath.FromSlash(key)), nil
+}
@@ -0,0 +1,98 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestLocalStorage(t *testing.T) {
+	s, err := NewLocalStorage(t.TempDir(), "/objects/local", []byte("secret"))
+	require.NoError(t, err)
+	ctx := context.Background()
+	data := []byte("hello")
+	err = s.Put(ctx, "ab/abc.png", bytes.NewReader(data), int64(len(data)), "image/png")
+	require.NoError(t, err)
+
+	r, err := s.Get(ctx, "ab/abc.png")
+	require.NoError(t, err)
+	got, err := io.ReadAll(r)
+	require.NoError(t, err)
+	require.NoError(t, r.Close())
+	assert.Equal(t, data, got)
+
+	_, err = s.Get(ctx, "ab/not-exist.png")
+	assert.Equal(t, ErrObjectNotFound, err)
+	err = s.Put(ctx, "../escape.png", bytes.NewReader(data), int64(len(data)), "image/png")
+	assert.Equal(t, ErrInvalidKey, err)
+
+	require.NoError(t, s.Delete(ctx, "ab/abc.png"))
+	// 删除不存在的对象不报错
+	require.NoError(t, s.Delete(ctx, "ab/abc.png"))
+	_, err = s.Get(ctx, "ab/abc.png")
+	assert.Equal(t, ErrObjectNotFound, err)
+}
+
+func TestLocalStorage_ServeHTTP(t *testing.T) {
+	s, err := NewLocalStorage(t.TempDir(), "/objects/local/", []byte("secret"))
+	require.NoError(t, err)
+	ctx := context.Background()
+	data := []byte("hello")
+	err = s.Put(ctx, "ab/abc.png", bytes.NewReader(data), int64(len(data)), "image/png")
+	require.NoError(t, err)
+	now := time.Now()
+	s.now = func() time.Time { return now }
+	u, err := s.PresignedURL(ctx, "ab/abc.png", time.Minute)
+	require.NoError(t, err)
+
+	testCases := []struct {
+		name     string
+		url      string
+		after    time.Duration
+		wantCode int
+		wantBody string
+	}{
+		{
+			name:     "签名正确",
+			url:      u,
+			wantCode: http.StatusOK,
+			wantBody: "hello",
+		},
+		{
+			name:     "链接过期",
+			url:      u,
+			after:    time.Minute * 2,
+			wantCode: http.StatusForbidden,
+		},
+		{
+			name:     "换了 key",
+			url:      "/objects/local/ab/other.png" + u[len("/objects/local/ab/abc.png"):],
+			wantCode: http.StatusForbidden,
+		},
+		{
+			name:     "没有签名",
+			url:      "/objects/local/ab/abc.png",
+			wantCode: http.StatusForbidden,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			s.now = func() time.Time { return now.Add(tc.after) }
+			req, err := http.NewRequest(http.MethodGet, tc.url, nil)
+			require.NoError(t, err)
+			resp := httptest.NewRecorder()
+			s.ServeHTTP(resp, req)
+			assert.Equal(t, tc.wantCode, resp.Code)
+			if tc.wantBody != "" {
+				assert.Equal(t, tc.wantBody, resp.Body.String())
+			}
+		})
+	}
+}
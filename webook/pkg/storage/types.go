@@ -0,0 +1,26 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"time"
+)
+
+var (
+	ErrObjectNotFound = errors.New("对象不存在")
+	// ErrInvalidKey key 为空，或者想跳出存储的目录
+	ErrInvalidKey = errors.New("对象的 key 不合法")
+)
+
+// Storage 对象存储，图片和附件都存在这里，业务只保存 key
+type Storage interface {
+	// Put 保存对象，key 已经存在的时候覆盖
+	Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error
+	// Get 读取对象，不存在返回 ErrObjectNotFound，调用者负责关闭
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	// Delete 删除对象，不存在也不会报错
+	Delete(ctx context.Context, key string) error
+	// PresignedURL 生成一个有效期为 expire 的下载链接，拿到链接的人不需要登录
+	PresignedURL(ctx context.Context, key string, expire time.Duration) (string, error)
+}
@@ -3,31 +3,69 @@
 package main
 
 import (
-	"github.com/gin-gonic/gin"
 	"github.com/google/wire"
 	"webook/webook/internal/repository"
 	cache "webook/webook/internal/repository/cache/Redis"
+	"webook/webook/internal/repository/cache/local"
 	"webook/webook/internal/repository/dao"
 	"webook/webook/internal/service"
 	"webook/webook/internal/web"
 	web2 "webook/webook/internal/web/jwt"
+	"webook/webook/internal/web/middleware"
 	"webook/webook/ioc"
+	"webook/webook/pkg/redislock"
 )
 
-func initApp() *gin.Engine {
+func initApp() *App {
 	wire.Build(
 		/******** 最底层依赖 ********/
 		ioc.InitDB, ioc.InitRedis,
-		dao.NewUserDAO,
+		dao.NewUserDAO, dao.NewGORMArticleDAO, dao.NewGORMArticleRevisionDAO,
+		dao.NewGORMInteractiveDAO, cache.NewRedisInteractiveCache, dao.NewGORMCollectionFolderDAO,
+		dao.NewGORMCommentDAO, cache.NewRedisRankingCache, local.NewRankingLocalCache,
+		dao.NewGORMFollowDAO, cache.NewRedisFollowCache, dao.NewGORMFeedDAO, dao.NewGORMArticleReviewDAO,
+		dao.NewGORMRBACDAO, cache.NewRedisRBACCache, dao.NewGORMUploadDAO,
 		cache.NewRedisUserCache, cache.NewRedisCodeCache,
 		repository.NewUserRepository, repository.NewCacheCodeRepository,
-		service.NewUserService, service.NewSmsCodeService,
-		ioc.InitOAuth2WechatService, ioc.InitSMSService,
+		repository.NewCacheArticleRepository, repository.NewCacheArticleRevisionRepository,
+		repository.NewCachedInteractiveRepository, service.NewInteractiveService,
+		repository.NewCacheCollectionFolderRepository, service.NewCollectionFolderService,
+		repository.NewCacheCommentRepository, service.NewCommentService,
+		repository.NewCachedRankingRepository, service.NewBatchRankingService,
+		repository.NewCachedFollowRepository, service.NewFollowService,
+		repository.NewCacheFeedRepository, service.NewFeedService, ioc.InitFeedConfig,
+		ioc.InitSearchIndex, repository.NewLocalSearchRepository, service.NewSearchService,
+		service.NewUserService, service.NewSmsCodeService, service.NewArticleService,
+		ioc.InitOAuth2WechatService, ioc.InitSMSService, ioc.InitRevisionRetention,
+		dao.NewGORMAsyncSmsDAO, repository.NewPreemptAsyncSmsRepository,
+		ioc.InitRecycleBinRetention, ioc.InitArticleScheduler, ioc.InitArticleListeners,
+		ioc.InitModerationService, repository.NewCacheArticleReviewRepository, service.NewArticleReviewService,
+		repository.NewCachedRBACRepository, ioc.InitRBACService, middleware.NewPermissionMiddlewareBuilder,
+		repository.NewCacheUploadRepository, service.NewUploadService, ioc.InitStorage,
 		web.NewUserHandler, web.NewOAuth2WechatHandler, web2.NewRedisJWTHandler,
+		web.NewArticleHandler, web.NewArticleReaderHandler, web.NewCollectionFolderHandler,
+		web.NewCommentHandler, web.NewFollowHandler, web.NewSearchHandler,
+		web.NewArticleReviewHandler, web.NewRBACHandler, web.NewUploadHandler,
 		/******** 公共组件 ********/
-		ioc.InitZapLogger, ioc.InitGinMiddlewares,
+		ioc.InitZapLogger, ioc.InitGinMiddlewares, redislock.NewClient,
 		/******** 初始化Server ********/
 		ioc.InitGinServer,
+		/******** 定时任务 ********/
+		ioc.InitRecycleBinPurgeJob, ioc.InitRankingJob, ioc.InitArticleScheduleLoadJob, ioc.InitJobs,
+		dao.NewGORMCronJobDAO, repository.NewPreemptCronJobRepository, service.NewCronJobService,
+		ioc.InitLocalFuncExecutor, ioc.InitScheduler,
+		wire.Struct(new(App), "*"),
 	)
-	return new(gin.Engine)
+	return new(App)
+}
+
+// initSearchService 重建搜索索引的命令用，不需要启动服务
+func initSearchService() service.SearchService {
+	wire.Build(
+		ioc.InitDB, ioc.InitRedis, ioc.InitZapLogger,
+		dao.NewUserDAO, cache.NewRedisUserCache, repository.NewUserRepository,
+		dao.NewGORMArticleDAO, repository.NewCacheArticleRepository,
+		ioc.InitSearchIndex, repository.NewLocalSearchRepository, service.NewSearchService,
+	)
+	return nil
 }
@@ -7,22 +7,25 @@
 package main
 
 import (
-	"github.com/gin-gonic/gin"
 	"webook/webook/internal/repository"
 	"webook/webook/internal/repository/cache/Redis"
+	"webook/webook/internal/repository/cache/local"
 	"webook/webook/internal/repository/dao"
 	"webook/webook/internal/service"
 	web2 "webook/webook/internal/web"
 	"webook/webook/internal/web/jwt"
+	"webook/webook/internal/web/middleware"
 	"webook/webook/ioc"
+	"webook/webook/pkg/redislock"
 )
 
 // Injectors from wire.go:
 
-func initApp() *gin.Engine {
+func initApp() *App {
 	cmdable := ioc.InitRedis()
 	logger := ioc.InitZapLogger()
-	v := ioc.InitGinMiddlewares(cmdable, logger)
+	jwtHandler := web.NewRedisJWTHandler(cmdable)
+	v := ioc.InitGinMiddlewares(cmdable, jwtHandler, logger)
 	db := ioc.InitDB(logger)
 	userDAO := dao.NewUserDAO(db)
 	userCache := cache.NewRedisUserCache(cmdable)
@@ -30,12 +33,99 @@ func initApp() *gin.Engine {
 	userService := service.NewUserService(userRepository)
 	codeCache := cache.NewRedisCodeCache(cmdable)
 	codeRepository := repository.NewCacheCodeRepository(codeCache)
-	smsService := ioc.InitSMSService(cmdable)
+	asyncSmsDAO := dao.NewGORMAsyncSmsDAO(db)
+	asyncSmsRepository := repository.NewPreemptAsyncSmsRepository(asyncSmsDAO)
+	smsService := ioc.InitSMSService(cmdable, asyncSmsRepository, logger)
 	codeService := service.NewSmsCodeService(codeRepository, smsService)
-	jwtHandler := web.NewRedisJWTHandler(cmdable)
 	userHandler := web2.NewUserHandler(userService, codeService, jwtHandler, logger)
 	wechatService := ioc.InitOAuth2WechatService()
 	oAuth2WechatHandler := web2.NewOAuth2WechatHandler(wechatService, userService, jwtHandler)
-	engine := ioc.InitGinServer(v, userHandler, oAuth2WechatHandler)
-	return engine
+	articleDAO := dao.NewGORMArticleDAO(db)
+	articleRepository := repository.NewCacheArticleRepository(articleDAO, userRepository, logger)
+	articleRevisionDAO := dao.NewGORMArticleRevisionDAO(db)
+	articleRevisionRepository := repository.NewCacheArticleRevisionRepository(articleRevisionDAO)
+	revisionRetention := ioc.InitRevisionRetention()
+	recycleBinRetention := ioc.InitRecycleBinRetention()
+	followDAO := dao.NewGORMFollowDAO(db)
+	followCache := cache.NewRedisFollowCache(cmdable)
+	followRepository := repository.NewCachedFollowRepository(followDAO, followCache, userRepository)
+	feedDAO := dao.NewGORMFeedDAO(db)
+	feedRepository := repository.NewCacheFeedRepository(feedDAO)
+	feedConfig := ioc.InitFeedConfig()
+	feedService := service.NewFeedService(followRepository, feedRepository, articleRepository, feedConfig, logger)
+	index := ioc.InitSearchIndex()
+	searchRepository := repository.NewLocalSearchRepository(index)
+	searchService := service.NewSearchService(searchRepository, articleRepository, userRepository, logger)
+	interactiveDAO := dao.NewGORMInteractiveDAO(db)
+	interactiveCache := cache.NewRedisInteractiveCache(cmdable)
+	interactiveRepository := repository.NewCachedInteractiveRepository(interactiveDAO, interactiveCache)
+	interactiveService := service.NewInteractiveService(interactiveRepository)
+	rankingCache := cache.NewRedisRankingCache(cmdable)
+	rankingLocalCache := local.NewRankingLocalCache()
+	rankingRepository := repository.NewCachedRankingRepository(rankingCache, rankingLocalCache)
+	rankingService := service.NewBatchRankingService(articleRepository, interactiveRepository, rankingRepository, logger)
+	v2 := ioc.InitArticleListeners(feedService, searchService, rankingService)
+	client := redislock.NewClient(cmdable)
+	articleScheduler := ioc.InitArticleScheduler(articleRepository, v2, client, logger)
+	moderationService := ioc.InitModerationService(logger)
+	articleService := service.NewArticleService(articleRepository, articleRevisionRepository, revisionRetention, recycleBinRetention, articleScheduler, moderationService, v2, logger)
+	articleHandler := web2.NewArticleHandler(articleService, logger)
+	articleReaderHandler := web2.NewArticleReaderHandler(articleService, interactiveService, rankingService, logger)
+	collectionFolderDAO := dao.NewGORMCollectionFolderDAO(db)
+	collectionFolderRepository := repository.NewCacheCollectionFolderRepository(collectionFolderDAO)
+	collectionFolderService := service.NewCollectionFolderService(collectionFolderRepository, articleRepository, interactiveRepository, logger)
+	collectionFolderHandler := web2.NewCollectionFolderHandler(collectionFolderService, logger)
+	commentDAO := dao.NewGORMCommentDAO(db)
+	commentRepository := repository.NewCacheCommentRepository(commentDAO, userRepository)
+	commentService := service.NewCommentService(commentRepository, articleRepository, moderationService, logger)
+	commentHandler := web2.NewCommentHandler(commentService, logger)
+	followService := service.NewFollowService(followRepository, userRepository)
+	followHandler := web2.NewFollowHandler(followService, feedService, logger)
+	searchHandler := web2.NewSearchHandler(searchService, logger)
+	articleReviewDAO := dao.NewGORMArticleReviewDAO(db)
+	articleReviewRepository := repository.NewCacheArticleReviewRepository(articleReviewDAO)
+	articleReviewService := service.NewArticleReviewService(articleService, articleRepository, articleReviewRepository, logger)
+	rbacdao := dao.NewGORMRBACDAO(db)
+	rbacCache := cache.NewRedisRBACCache(cmdable)
+	rbacRepository := repository.NewCachedRBACRepository(rbacdao, rbacCache)
+	rbacService := ioc.InitRBACService(rbacRepository, userRepository, logger)
+	permissionMiddlewareBuilder := middleware.NewPermissionMiddlewareBuilder(rbacService, logger)
+	articleReviewHandler := web2.NewArticleReviewHandler(articleReviewService, permissionMiddlewareBuilder, logger)
+	rbacHandler := web2.NewRBACHandler(rbacService, permissionMiddlewareBuilder, logger)
+	uploadDAO := dao.NewGORMUploadDAO(db)
+	uploadRepository := repository.NewCacheUploadRepository(uploadDAO)
+	storage := ioc.InitStorage()
+	uploadService := service.NewUploadService(uploadRepository, storage, logger)
+	uploadHandler := web2.NewUploadHandler(uploadService, logger)
+	engine := ioc.InitGinServer(v, userHandler, oAuth2WechatHandler, articleHandler, articleReaderHandler, collectionFolderHandler, commentHandler, followHandler, searchHandler, articleReviewHandler, rbacHandler, uploadHandler, storage)
+	recycleBinPurgeJob := ioc.InitRecycleBinPurgeJob(articleService, logger)
+	rankingJob := ioc.InitRankingJob(rankingService, client, logger)
+	articleScheduleLoadJob := ioc.InitArticleScheduleLoadJob(articleScheduler)
+	cron := ioc.InitJobs(logger, recycleBinPurgeJob, rankingJob, articleScheduleLoadJob)
+	cronJobDAO := dao.NewGORMCronJobDAO(db)
+	cronJobRepository := repository.NewPreemptCronJobRepository(cronJobDAO)
+	cronJobService := service.NewCronJobService(cronJobRepository, logger)
+	localFuncExecutor := ioc.InitLocalFuncExecutor(recycleBinPurgeJob, rankingJob)
+	scheduler := ioc.InitScheduler(cronJobService, localFuncExecutor, logger)
+	app := &App{
+		server:    engine,
+		cron:      cron,
+		scheduler: scheduler,
+	}
+	return app
+}
+
+func initSearchService() service.SearchService {
+	logger := ioc.InitZapLogger()
+	db := ioc.InitDB(logger)
+	articleDAO := dao.NewGORMArticleDAO(db)
+	userDAO := dao.NewUserDAO(db)
+	cmdable := ioc.InitRedis()
+	userCache := cache.NewRedisUserCache(cmdable)
+	userRepository := repository.NewUserRepository(userDAO, userCache)
+	articleRepository := repository.NewCacheArticleRepository(articleDAO, userRepository, logger)
+	index := ioc.InitSearchIndex()
+	searchRepository := repository.NewLocalSearchRepository(index)
+	searchService := service.NewSearchService(searchRepository, articleRepository, userRepository, logger)
+	return searchService
 }